@@ -9,8 +9,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
+	"uPIMulator/src/misc"
 	"uPIMulator/src/simulator/chiplet"
 )
 
@@ -20,6 +20,8 @@ import (
 type ChipletModelSpec struct {
 	Name     string             `json:"name"`
 	Sequence []ChipletStageSpec `json:"sequence"`
+	// Topology 为异构封装给出逐芯粒的硬件覆盖（如缓冲带宽），由模拟器在初始化时读取。
+	Topology *chiplet.ModelTopology `json:"topology,omitempty"`
 }
 
 // stageCommandSet bundles the command groups generated for a single stage.
@@ -140,6 +142,7 @@ func (spec *ChipletModelSpec) BuildCommands(config *chiplet.Config, topology *ch
 	nextID := int32(0)
 	prevID := int32(-1)
 	stageCompletionIDs := make([][]int32, 0)
+	stagePrecisions := make([]string, 0)
 
 	for _, stage := range spec.Sequence {
 		repeat := stage.Repeat
@@ -148,6 +151,8 @@ func (spec *ChipletModelSpec) BuildCommands(config *chiplet.Config, topology *ch
 		}
 		for i := 0; i < repeat; i++ {
 			stageDeps := resolveStageDependencies(stage.Dependencies, stageCompletionIDs)
+			producerPrecision := resolveProducerPrecision(stage.Dependencies, stagePrecisions)
+			precision := strings.ToLower(metadataString(stage.Metadata, "precision", ""))
 			set, err := buildStageCommands(stage, defaultRows, defaultCols, config, topology)
 			if err != nil {
 				return nil, err
@@ -155,8 +160,14 @@ func (spec *ChipletModelSpec) BuildCommands(config *chiplet.Config, topology *ch
 
 			if len(set.Groups) == 0 {
 				stageCompletionIDs = append(stageCompletionIDs, prevIDSlice(prevID))
+				stagePrecisions = append(stagePrecisions, producerPrecision)
 				continue
 			}
+			annotateDtypeConversion(set.Groups, producerPrecision, precision)
+			if precision == "" {
+				precision = producerPrecision
+			}
+			stagePrecisions = append(stagePrecisions, precision)
 
 			for groupIdx := range set.Groups {
 				group := set.Groups[groupIdx]
@@ -235,7 +246,7 @@ func buildStageCommands(stage ChipletStageSpec, defaultRows int, defaultCols int
 		}
 		set.Groups = append(set.Groups, wrapWithHostTransfers(stage, []chiplet.CommandDescriptor{cmd}, config, topology, defaultRows, defaultCols))
 	case "moe_gating":
-		cmds := buildMoEGatingCommands(stage, defaultRows, defaultCols)
+		cmds := buildMoEGatingCommands(stage, defaultRows, defaultCols, config)
 		set.Groups = append(set.Groups, wrapWithHostTransfers(stage, cmds, config, topology, defaultRows, defaultCols))
 	case "transfer":
 		set.Groups = append(set.Groups, []chiplet.CommandDescriptor{buildTransferCommand(stage, config, topology)})
@@ -265,7 +276,7 @@ func buildStageCommands(stage ChipletStageSpec, defaultRows int, defaultCols int
 	return set, nil
 }
 
-func buildMoEGatingCommands(stage ChipletStageSpec, defaultRows int, defaultCols int) []chiplet.CommandDescriptor {
+func buildMoEGatingCommands(stage ChipletStageSpec, defaultRows int, defaultCols int, config *chiplet.Config) []chiplet.CommandDescriptor {
 	tokens := stage.Tokens
 	if tokens <= 0 {
 		tokens = defaultRows
@@ -316,7 +327,7 @@ func buildMoEGatingCommands(stage ChipletStageSpec, defaultRows int, defaultCols
 	}
 	selectedExperts := []int{}
 	if selectExperts {
-		selectedExperts = chooseExperts(stage, candidateExperts, topK)
+		selectedExperts = chooseExperts(stage, candidateExperts, topK, config)
 	}
 	metaBase := map[string]interface{}{
 		"top_k":             topK,
@@ -420,7 +431,42 @@ func buildMoEGatingCommands(stage ChipletStageSpec, defaultRows int, defaultCols
 	attachStageMetadata(&reduceCmd, stage, "moe_topk_select", reduceMeta)
 	attachStageMetadata(&hostCmd, stage, "moe_gating_fetch", hostMeta)
 
-	return []chiplet.CommandDescriptor{spuCmd, reduceCmd, hostCmd}
+	if config == nil || config.GatingSoftmaxOps <= 0 {
+		return []chiplet.CommandDescriptor{spuCmd, reduceCmd, hostCmd}
+	}
+	softmaxCmd := buildMoEGatingSoftmaxCommand(stage, metaBase, tokens, features, len(candidateExperts), config.GatingSoftmaxOps)
+	return []chiplet.CommandDescriptor{spuCmd, softmaxCmd, reduceCmd, hostCmd}
+}
+
+// buildMoEGatingSoftmaxCommand 生成 gating 分数之后、topk_select 之前的 softmax 步骤：
+// 每个 token 对全部候选专家的 logit 做 exp/归一化，SFU 工作量为 tokens×num_experts×opsPerLogit。
+func buildMoEGatingSoftmaxCommand(stage ChipletStageSpec, metaBase map[string]interface{}, tokens int, features int, numExperts int, opsPerLogit int) chiplet.CommandDescriptor {
+	numExperts = maxInt(metadataInt(stage.Metadata, "num_experts", numExperts), 1)
+	logits := maxInt(tokens*numExperts, 1)
+
+	meta := cloneMetadata(metaBase)
+	meta["op"] = "moe_gating_softmax"
+	meta["precision"] = "fp16"
+	meta["num_experts"] = numExperts
+	meta["special_ops"] = logits * opsPerLogit
+	meta["vector_ops"] = logits
+	meta["scalar_ops"] = tokens
+	meta["activation_bytes"] = logits * 2
+	meta["output_bytes"] = logits * 2
+
+	cmd := chiplet.CommandDescriptor{
+		Kind:       chiplet.CommandKindPeSpuOp,
+		Target:     chiplet.TaskTargetDigital,
+		ChipletID:  -1,
+		Queue:      int32(features),
+		Aux0:       uint32(tokens),
+		Aux1:       uint32(numExperts),
+		Latency:    int32(logits * opsPerLogit),
+		ExecDomain: chiplet.ExecDomainSpu,
+		Metadata:   meta,
+	}
+	attachStageMetadata(&cmd, stage, "moe_gating_softmax", meta)
+	return cmd
 }
 
 func maxInt(a, b int) int {
@@ -881,22 +927,28 @@ func buildLayerNormCommands(stage ChipletStageSpec, defaultRows, defaultCols int
 	return cmds
 }
 
-func chooseExperts(stage ChipletStageSpec, candidates []int, topK int) []int {
+func chooseExperts(stage ChipletStageSpec, candidates []int, topK int, config *chiplet.Config) []int {
 	if len(candidates) == 0 || topK <= 0 {
 		return nil
 	}
 	if topK > len(candidates) {
 		topK = len(candidates)
 	}
-	seed := stage.ExpertSeed
-	if seed == 0 {
-		seed = int64(len(stage.Name))<<32 | int64(stage.BufferID&0xffff)<<16 | int64(stage.Queue&0xffff)
-	}
-	if seed == 0 {
-		seed = time.Now().UnixNano()
+	// 显式给出 expert_seed 时沿用原来的序列，保证已有模型的专家选择不变；
+	// 否则从 --chiplet_rng_seed 派生，以名字/缓冲/队列作为 fork key 区分不同 stage。
+	var rng *rand.Rand
+	if stage.ExpertSeed != 0 {
+		rng = rand.New(rand.NewSource(stage.ExpertSeed))
+	} else {
+		master := int64(1)
+		if config != nil && config.RngSeed != 0 {
+			master = config.RngSeed
+		}
+		key := int64(len(stage.Name))<<32 | int64(stage.BufferID&0xffff)<<16 | int64(stage.Queue&0xffff)
+		streams := new(misc.RNGStreams)
+		streams.Init(master)
+		rng = streams.Fork(misc.RNGStreamExpertSelection, key)
 	}
-	source := rand.NewSource(seed)
-	rng := rand.New(source)
 	chosen := make([]int, 0, topK)
 	available := append([]int(nil), candidates...)
 	for len(chosen) < topK && len(available) > 0 {
@@ -1393,6 +1445,48 @@ func resolveStageDependencies(deps []int, stageCompletionIDs [][]int32) []int32
 	return results
 }
 
+// resolveProducerPrecision 返回 stage 输入激活的精度：有显式 deps 时取第一个声明了
+// precision 的依赖，否则沿用上一个 stage 的精度。未声明 precision 的 stage 透传上游精度。
+func resolveProducerPrecision(deps []int, stagePrecisions []string) string {
+	if len(deps) == 0 {
+		if len(stagePrecisions) == 0 {
+			return ""
+		}
+		return stagePrecisions[len(stagePrecisions)-1]
+	}
+	for _, dep := range deps {
+		if dep >= 0 && dep < len(stagePrecisions) && stagePrecisions[dep] != "" {
+			return stagePrecisions[dep]
+		}
+	}
+	return ""
+}
+
+// annotateDtypeConversion 在 stage 的 precision 与上游不同时，为其最后一个数字命令
+// （即写回 stage 输出的命令）写入 input_dtype/output_dtype，使平台在写回前计入一次
+// 精度转换 pass。命令已显式给出 input_dtype 时保持不变。
+func annotateDtypeConversion(groups [][]chiplet.CommandDescriptor, producer string, precision string) {
+	if producer == "" || precision == "" || producer == precision {
+		return
+	}
+	for groupIdx := len(groups) - 1; groupIdx >= 0; groupIdx-- {
+		for cmdIdx := len(groups[groupIdx]) - 1; cmdIdx >= 0; cmdIdx-- {
+			cmd := &groups[groupIdx][cmdIdx]
+			if cmd.Target != chiplet.TaskTargetDigital {
+				continue
+			}
+			if cmd.Metadata == nil {
+				cmd.Metadata = make(map[string]interface{})
+			}
+			if _, ok := cmd.Metadata["input_dtype"]; !ok {
+				cmd.Metadata["input_dtype"] = producer
+				cmd.Metadata["output_dtype"] = precision
+			}
+			return
+		}
+	}
+}
+
 func wireStageDependencies(groups [][]chiplet.CommandDescriptor, stageDeps []int32, prevID int32, chain bool) int32 {
 	lastID := prevID
 	for groupIdx := range groups {
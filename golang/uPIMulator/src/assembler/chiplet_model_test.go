@@ -1,6 +1,8 @@
 package assembler
 
 import (
+	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"testing"
@@ -179,3 +181,68 @@ func findTransfer(cmds []chiplet.CommandDescriptor, flag uint32) int {
 	}
 	return -1
 }
+
+func TestMoEGatingSoftmaxIssuedBeforeTopK(t *testing.T) {
+	t.Parallel()
+
+	stage := ChipletStageSpec{Type: "moe_gating", Tokens: 32, Features: 64, Experts: []MoEExpertSpec{{Chiplet: 0}, {Chiplet: 1}}}
+	if cmds := buildMoEGatingCommands(stage, 32, 64, &chiplet.Config{}); len(cmds) != 3 {
+		t.Fatalf("softmax step should be absent by default, got %d commands", len(cmds))
+	}
+
+	cmds := buildMoEGatingCommands(stage, 32, 64, &chiplet.Config{GatingSoftmaxOps: 4})
+	ops := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		ops = append(ops, metadataString(cmd.Metadata, "op", ""))
+	}
+	if len(ops) != 4 || ops[1] != "moe_gating_softmax" || ops[2] != "topk_select" {
+		t.Fatalf("unexpected gating command order %v", ops)
+	}
+	if got := metadataInt(cmds[1].Metadata, "special_ops", 0); got != 32*2*4 {
+		t.Fatalf("softmax special_ops = %d, want tokens*experts*ops = %d", got, 32*2*4)
+	}
+}
+
+func TestChooseExpertsFollowsChipletRngSeed(t *testing.T) {
+	t.Parallel()
+
+	candidates := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	stage := ChipletStageSpec{Name: "moe0", BufferID: 3}
+	pick := func(seed int64) string {
+		return fmt.Sprint(chooseExperts(stage, candidates, 4, &chiplet.Config{RngSeed: seed}))
+	}
+
+	if pick(7) != pick(7) {
+		t.Fatalf("same --chiplet_rng_seed should select the same experts")
+	}
+	changed := false
+	for seed := int64(8); seed < 16 && !changed; seed++ {
+		changed = pick(seed) != pick(7)
+	}
+	if !changed {
+		t.Fatalf("changing --chiplet_rng_seed never changed the selected experts")
+	}
+}
+
+func TestChooseExpertsKeepsExplicitExpertSeedSequence(t *testing.T) {
+	t.Parallel()
+
+	candidates := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	stage := ChipletStageSpec{Name: "moe0", ExpertSeed: 42}
+
+	rng := rand.New(rand.NewSource(42))
+	available := append([]int(nil), candidates...)
+	want := make([]int, 0, 4)
+	for len(want) < 4 {
+		index := rng.Intn(len(available))
+		want = append(want, available[index])
+		available = append(available[:index], available[index+1:]...)
+	}
+
+	for _, seed := range []int64{1, 7, 99} {
+		got := chooseExperts(stage, candidates, 4, &chiplet.Config{RngSeed: seed})
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("expert_seed=42 with --chiplet_rng_seed=%d selected %v, want baseline %v", seed, got, want)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
 )
 
 type kernelManifest struct {
@@ -82,6 +84,28 @@ func TestEmitChipletKernelManifest(t *testing.T) {
 	assertIntField(t, topk.Default, "scalar_ops", 256*2)
 }
 
+func TestChipletKernelManifestOpsAreKnownToSimulator(t *testing.T) {
+	tempDir := t.TempDir()
+
+	compiler := &Compiler{bin_dirpath: tempDir}
+	compiler.EmitChipletKernelManifest()
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "chiplet_kernels.json"))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var manifest kernelManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("unmarshal manifest: %v", err)
+	}
+	for _, kernel := range manifest.Kernels {
+		op, _ := kernel.Metadata["op"].(string)
+		if !chiplet.IsKnownCommandOp(op) {
+			t.Fatalf("kernel %s emits op %q unknown to the simulator", kernel.Name, op)
+		}
+	}
+}
+
 func assertIntField(t *testing.T, m map[string]interface{}, key string, want int) {
 	t.Helper()
 	val, ok := m[key]
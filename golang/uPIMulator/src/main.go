@@ -141,6 +141,18 @@ func InitCommandLineParser() *misc.CommandLineParser {
 		"16",
 		"RRAM program pulse count per write",
 	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"rram_program_early_exit_prob",
+		"0.0",
+		"per-pulse probability that an RRAM cell converges during program-and-verify",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_program_verify",
+		"0",
+		"charge rram_program_pulses program-and-verify pulses on every chiplet RRAM weight load (0 = weight loads skip programming)",
+	)
 	command_line_parser.AddOption(
 		misc.INT,
 		"rram_array_rows",
@@ -381,6 +393,438 @@ func InitCommandLineParser() *misc.CommandLineParser {
 		"",
 		"path to an optional chiplet model JSON",
 	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_weight_neighbor_sharing",
+		"0",
+		"serve RRAM weight-load misses from neighboring RRAM chiplets when resident (1=yes, 0=no)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_result_sample_rate",
+		"1.0",
+		"fraction of RRAM results written to chiplet_results.csv (first/last per chiplet and outliers are always kept)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_result_sample_seed",
+		"0",
+		"RNG seed used for result-log sampling (0 = derive from --chiplet_rng_seed)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_result_sample_stream_key",
+		"0",
+		"key of the result-log sampling stream forked from --chiplet_rng_seed",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_result_error_threshold",
+		"1.0",
+		"absolute error above which an RRAM result is always kept in the result log",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_digital_l2_bytes",
+		"0",
+		"per-chiplet L2 capacity in bytes that absorbs scratch overflow (<=0 disables spilling)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_digital_l2_bw",
+		"256",
+		"L2 spill bandwidth in bytes/cycle",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_transfer_schedule_overhead",
+		"0",
+		"fixed DMA descriptor-programming cycles charged per transfer_schedule command",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_deadlock_cycles",
+		"0",
+		"abort the chiplet run after this many cycles without dispatching a task while work is pending (0 disables)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dump_on_error",
+		"0",
+		"write a replayable command/config snapshot to <bin_dirpath>/chiplet_repro on deadlock or abort",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_repro_path",
+		"",
+		"replay the remaining command graph from a chiplet_repro directory instead of bin_dirpath",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_rram_load_path_mode",
+		"none",
+		"MoE expert weight-load/activation path model (none|serial|overlap)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_scheduler",
+		"fifo",
+		"chiplet task scheduler (fifo|oldest_batch)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_weight_prefetch",
+		"0",
+		"prefetch the next streaming batch's first-layer RRAM weights (1 = enable)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_weight_capacity",
+		"0",
+		"resident weight bytes per RRAM chiplet before LRU eviction (0 = unbounded)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_activation_offload",
+		"0",
+		"spill digital activations to host over DMA when the buffer is full (1 = enable)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_energy_calibration",
+		"",
+		"JSON file of energy scale factors per command kind or component (pe/spu/vpu/adc/dac)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dvfs",
+		"0",
+		"enable per-chiplet DVFS driven by recent utilization (1=on)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_dvfs_levels",
+		"100,50",
+		"DVFS frequency levels as percent of the nominal clock, descending from 100",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dvfs_window",
+		"1000",
+		"domain ticks per DVFS utilization window",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dvfs_low_util",
+		"20",
+		"utilization percent below which a chiplet steps down one DVFS level",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dvfs_high_util",
+		"60",
+		"utilization percent above which a chiplet steps up one DVFS level",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_transfer_min_latency",
+		"0",
+		"minimum cycles charged per transfer (serialization/arbitration floor, 0=off)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_pipeline_checksum",
+		"0",
+		"propagate a dataflow checksum through every stage and verify it at the LM head (1=on)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_rram_weight_layout",
+		"none",
+		"placement of logical weight tiles onto RRAM chiplets: none, block or cyclic",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_reduction_cost_model",
+		"linear",
+		"cost model for topk_select/PeReduce: linear (tokens*topK) or nlogn (tokens*C*log2 C)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_noc_booksim_cache_size",
+		"0",
+		"entries in the BookSim latency estimate cache (0 disables caching)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_noc_booksim_cache_bucket",
+		"64",
+		"byte granularity used to bucket BookSim cache keys",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_noc_booksim_cache_ttl",
+		"0",
+		"cycles a cached BookSim estimate stays valid (0 = until evicted)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_gqa_group_size",
+		"1",
+		"query heads sharing one KV head (grouped-query attention; 1 = MHA)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_kv_heads",
+		"0",
+		"split the KV cache into per-KV-head regions with independent eviction (0 = shared)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_summary",
+		"-1",
+		"print a run summary to stdout at completion (1=on, 0=off, -1=only when stdout is a terminal)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_output_write_bw",
+		"0",
+		"bytes per RRAM cycle written from the sense amps/ADCs into the output buffer (0 = unlimited)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rng_seed",
+		"1",
+		"master seed from which every stochastic feature derives its own independent RNG stream",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_cmd_fetch_latency",
+		"0",
+		"cycles for an orchestrator-issued command to reach its target chiplet before it can execute (0 = instant)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_max_moe_sessions",
+		"0",
+		"maximum concurrent MoE dispatch sessions; further gating fetches wait for one to finalize (0 = unlimited)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_scheduler_compare",
+		"",
+		"run the command graph once per scheduler (a,b) and write chiplet_scheduler_compare.csv instead of per-run stats",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_cycle_order",
+		"digital,rram,transfer",
+		"order in which clock domains advance within one platform cycle (a permutation of digital,rram,transfer)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_moe_skip_empty_experts",
+		"1",
+		"elide the command group of a selected expert whose expert_tokens count is zero (0 = always issue)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_activation_banks",
+		"1",
+		"split each digital cluster's activation buffer into N ping-pong banks so loads overlap compute (1 = single pool)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_bootstrap_iters",
+		"1",
+		"repeat the built-in bootstrap pipeline N times, chained, when no command file is given",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_tile_depth",
+		"0",
+		"reduction depth one RRAM tile holds; deeper CIM commands split across tiles (0 = sa_rows x sas_per_tile_dim)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_psum_lanes",
+		"0",
+		"lanes of the RRAM partial-sum accumulator that combines split CIM commands (0 = do not model the combine)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_snapshot_interval",
+		"0",
+		"append cumulative aggregate metrics to chiplet_timeseries.csv every N cycles (<=0 disables)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_weight_load_bw",
+		"0",
+		"bytes per cycle of the dedicated RRAM weight-load path; overrides per-command weight-load latency (0 = keep command latency)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_host_dispatch_per_cycle",
+		"0",
+		"maximum tasks the host runtime dispatches per host cycle, on top of the hardware issue limit (0 = unlimited)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_digital_raw_latency",
+		"0",
+		"cycles a digital buffer write needs to drain before a dependent load (source_buffer) may read it (0 = no RAW stall)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_digital_issue_width",
+		"0",
+		"scalar+vector+special ops each digital compute cluster can dispatch per cycle (0 = unlimited)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_transfer_priority",
+		"0",
+		"honour transfer priority metadata critical|normal|bulk under interconnect congestion: critical transfers preempt in-flight bulk ones, which re-queue with their remaining bytes (0 = strict FIFO)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_moe_alltoall_bw",
+		"0",
+		"bytes per cycle shared by a MoE all-to-all token shuffle (0 = independent per-expert transfers)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_buffer_warnings",
+		"1",
+		"warn at startup when a buffer cannot hold a single 128x128 tile's working set (0 = disable)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_transfer_mode",
+		"cycle_accurate",
+		"interconnect transfer model (cycle_accurate = transfers occupy the link and contend | analytical = cost added to a latency budget)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_dequant_lanes",
+		"0",
+		"scaling ops per cycle of the RRAM weight dequantization unit (0 = fold dequantization into post-processing)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_weight_bits",
+		"8",
+		"precision in bits of weights stored in RRAM; lower precision needs more dequantization work per output",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_idle_powerdown_cycles",
+		"0",
+		"idle cycles after which a chiplet enters a low-leakage retention state (0 = never power down)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_wakeup_latency",
+		"32",
+		"cycles a powered-down chiplet needs to wake up before its next task can start",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_half_duplex",
+		"0",
+		"digital<->RRAM links are half-duplex: to_rram and to_digital traffic share one bandwidth budget and contend (0 = full duplex)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_retirement_latency",
+		"0",
+		"cycles between a task finishing and its result becoming visible to dependents (0 = release dependents immediately)",
+	)
+	command_line_parser.AddOption(
+		misc.STRING,
+		"chiplet_transpose_stride_penalty",
+		"4.0",
+		"effective-bandwidth divisor for strided accesses in a transpose load/store pass (1 = same cost as a contiguous copy)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_max_inflight_transfers",
+		"0",
+		"maximum number of outstanding transfers (DMA/NoC tags); the orchestrator holds transfer issue at the limit (0 = unlimited)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_transfer_pipeline_chunks",
+		"0",
+		"stream a digital->RRAM transfer in N chunks while its producing digital compute runs, so only the last chunk waits for completion (0/1 = wait for the full result)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_ecc_overhead_pct",
+		"0",
+		"ECC/parity overhead as a percentage of payload bytes, charged to interconnect and DMA transfer bandwidth and energy but not buffer occupancy",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_moe_gating_softmax_ops",
+		"0",
+		"SFU ops per gating logit for an explicit MoE gating softmax step issued before topk_select, costing tokens x num_experts logits (0 = no softmax step)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_collective_broadcast_cycles",
+		"0",
+		"control-plane cycles per participating chiplet for the host to broadcast a collective's launch command, added to its startup latency",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_rram_cross_check",
+		"0",
+		"recompute each RRAM result digitally and report the discrepancy beyond error_abs (1 = enable, doubles CIM numeric work)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_prefetch_distance",
+		"0",
+		"number of waiting digital tasks whose load data a cluster prefetches with spare load bandwidth (0 = no prefetch)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_strict_commands",
+		"0",
+		"abort on commands whose kind or metadata op the model does not handle (0 = count and warn)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_dump_final_graph",
+		"0",
+		"write the fully transformed command graph to chiplet_graph_final.json at the end of the run (1 = enable)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_barrier_cycles_per_producer",
+		"0",
+		"host cycles a PE barrier spends collecting each producer's completion before releasing dependents (0 = barriers are free)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_profile",
+		"0",
+		"time the scheduler/orchestrator and device-model portions of each cycle and report their share of wall time (1 = enable)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_max_cycles",
+		"0",
+		"stop the chiplet run after this many platform cycles (0 = no cap)",
+	)
+	command_line_parser.AddOption(
+		misc.INT,
+		"chiplet_confirm_unlimited",
+		"0",
+		"allow unlimited host streaming (--chiplet_host_stream_total_batches <= 0) without --chiplet_max_cycles (1 = confirm)",
+	)
 
 	command_line_parser.AddOption(
 		misc.STRING,
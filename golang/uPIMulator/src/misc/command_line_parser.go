@@ -107,6 +107,14 @@ func (this *CommandLineParser) StringParameter(option string) string {
 	return command_line_option.StringParameter()
 }
 
+func (this *CommandLineParser) FloatParameter(option string) float64 {
+	float_param, err := strconv.ParseFloat(strings.TrimSpace(this.StringParameter(option)), 64)
+	if err != nil {
+		panic(err)
+	}
+	return float_param
+}
+
 func (this *CommandLineParser) DataPrepParams() []int {
 	string_params := strings.Split(this.StringParameter("data_prep_params"), ",")
 
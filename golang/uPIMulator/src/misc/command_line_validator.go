@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -138,6 +139,301 @@ func (this *CommandLineValidator) Validate() {
 			panic(err)
 		}
 
+		sampleRate := this.command_line_parser.FloatParameter("chiplet_result_sample_rate")
+		if sampleRate < 0 || sampleRate > 1 {
+			err := errors.New("chiplet_result_sample_rate must be in [0, 1]")
+			panic(err)
+		}
+
+		if this.command_line_parser.FloatParameter("chiplet_result_error_threshold") < 0 {
+			err := errors.New("chiplet_result_error_threshold < 0")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_deadlock_cycles") < 0 {
+			err := errors.New("chiplet_deadlock_cycles < 0")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_weight_capacity") < 0 {
+			err := errors.New("chiplet_rram_weight_capacity < 0")
+			panic(err)
+		}
+
+		if path := strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_energy_calibration")); path != "" {
+			if _, statErr := os.Stat(path); statErr != nil {
+				err := errors.New("chiplet_energy_calibration file does not exist")
+				panic(err)
+			}
+		}
+
+		if _, orderErr := ParseCycleOrder(this.command_line_parser.StringParameter("chiplet_cycle_order")); orderErr != nil {
+			err := errors.New("chiplet_cycle_order is invalid: " + orderErr.Error())
+			panic(err)
+		}
+
+		if _, levelErr := ParseDvfsLevels(this.command_line_parser.StringParameter("chiplet_dvfs_levels")); levelErr != nil {
+			err := errors.New("chiplet_dvfs_levels is invalid: " + levelErr.Error())
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_dvfs_window") <= 0 {
+			err := errors.New("chiplet_dvfs_window must be positive")
+			panic(err)
+		}
+
+		dvfsLow := this.command_line_parser.IntParameter("chiplet_dvfs_low_util")
+		dvfsHigh := this.command_line_parser.IntParameter("chiplet_dvfs_high_util")
+		if dvfsLow < 0 || dvfsHigh > 100 || dvfsLow >= dvfsHigh {
+			err := errors.New("chiplet_dvfs_low_util and chiplet_dvfs_high_util must satisfy 0 <= low < high <= 100")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_transfer_min_latency") < 0 {
+			err := errors.New("chiplet_transfer_min_latency must be non-negative")
+			panic(err)
+		}
+
+		if compare := strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_scheduler_compare")); compare != "" {
+			names := strings.Split(compare, ",")
+			if len(names) != 2 || strings.TrimSpace(names[0]) == "" || strings.TrimSpace(names[1]) == "" {
+				err := errors.New("chiplet_scheduler_compare must name exactly two schedulers as a,b")
+				panic(err)
+			}
+		}
+
+		if this.command_line_parser.FloatParameter("chiplet_transpose_stride_penalty") < 1 {
+			err := errors.New("chiplet_transpose_stride_penalty must be at least 1")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_max_inflight_transfers") < 0 {
+			err := errors.New("chiplet_max_inflight_transfers must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_transfer_pipeline_chunks") < 0 {
+			err := errors.New("chiplet_transfer_pipeline_chunks must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_ecc_overhead_pct") < 0 {
+			err := errors.New("chiplet_ecc_overhead_pct must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_moe_gating_softmax_ops") < 0 {
+			err := errors.New("chiplet_moe_gating_softmax_ops must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_collective_broadcast_cycles") < 0 {
+			err := errors.New("chiplet_collective_broadcast_cycles must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_cross_check") < 0 {
+			err := errors.New("chiplet_rram_cross_check must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_prefetch_distance") < 0 {
+			err := errors.New("chiplet_prefetch_distance must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_strict_commands") < 0 {
+			err := errors.New("chiplet_strict_commands must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_dump_final_graph") < 0 {
+			err := errors.New("chiplet_dump_final_graph must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_barrier_cycles_per_producer") < 0 {
+			err := errors.New("chiplet_barrier_cycles_per_producer must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_profile") < 0 {
+			err := errors.New("chiplet_profile must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_max_cycles") < 0 {
+			err := errors.New("chiplet_max_cycles must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_host_stream_total_batches") <= 0 &&
+			this.command_line_parser.IntParameter("chiplet_max_cycles") == 0 &&
+			this.command_line_parser.IntParameter("chiplet_confirm_unlimited") == 0 {
+			err := errors.New("chiplet_host_stream_total_batches <= 0 streams batches forever; set --chiplet_max_cycles or pass --chiplet_confirm_unlimited 1")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_retirement_latency") < 0 {
+			err := errors.New("chiplet_retirement_latency must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_half_duplex") < 0 {
+			err := errors.New("chiplet_half_duplex must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_idle_powerdown_cycles") < 0 {
+			err := errors.New("chiplet_idle_powerdown_cycles must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_wakeup_latency") < 0 {
+			err := errors.New("chiplet_wakeup_latency must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_dequant_lanes") < 0 {
+			err := errors.New("chiplet_rram_dequant_lanes must be non-negative")
+			panic(err)
+		}
+
+		if weightBits := this.command_line_parser.IntParameter("chiplet_rram_weight_bits"); weightBits <= 0 || weightBits > 16 {
+			err := errors.New("chiplet_rram_weight_bits must be in [1, 16]")
+			panic(err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_transfer_mode"))) {
+		case "cycle_accurate", "analytical":
+		default:
+			err := errors.New("chiplet_transfer_mode must be cycle_accurate or analytical")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_buffer_warnings") < 0 {
+			err := errors.New("chiplet_buffer_warnings must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_moe_alltoall_bw") < 0 {
+			err := errors.New("chiplet_moe_alltoall_bw must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_transfer_priority") < 0 {
+			err := errors.New("chiplet_transfer_priority must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_digital_issue_width") < 0 {
+			err := errors.New("chiplet_digital_issue_width must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_digital_raw_latency") < 0 {
+			err := errors.New("chiplet_digital_raw_latency must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_host_dispatch_per_cycle") < 0 {
+			err := errors.New("chiplet_host_dispatch_per_cycle must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_weight_load_bw") < 0 {
+			err := errors.New("chiplet_rram_weight_load_bw must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_tile_depth") < 0 {
+			err := errors.New("chiplet_rram_tile_depth must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_psum_lanes") < 0 {
+			err := errors.New("chiplet_rram_psum_lanes must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_bootstrap_iters") < 1 {
+			err := errors.New("chiplet_bootstrap_iters must be at least 1")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_activation_banks") < 1 {
+			err := errors.New("chiplet_activation_banks must be at least 1")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_max_moe_sessions") < 0 {
+			err := errors.New("chiplet_max_moe_sessions must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_cmd_fetch_latency") < 0 {
+			err := errors.New("chiplet_cmd_fetch_latency must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_rram_output_write_bw") < 0 {
+			err := errors.New("chiplet_rram_output_write_bw must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_gqa_group_size") <= 0 {
+			err := errors.New("chiplet_gqa_group_size must be positive")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_kv_heads") < 0 {
+			err := errors.New("chiplet_kv_heads must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_noc_booksim_cache_size") < 0 {
+			err := errors.New("chiplet_noc_booksim_cache_size must be non-negative")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_noc_booksim_cache_bucket") <= 0 {
+			err := errors.New("chiplet_noc_booksim_cache_bucket must be positive")
+			panic(err)
+		}
+
+		if this.command_line_parser.IntParameter("chiplet_noc_booksim_cache_ttl") < 0 {
+			err := errors.New("chiplet_noc_booksim_cache_ttl must be non-negative")
+			panic(err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_reduction_cost_model"))) {
+		case "linear", "nlogn":
+		default:
+			err := errors.New("chiplet_reduction_cost_model must be linear or nlogn")
+			panic(err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_rram_weight_layout"))) {
+		case "none", "block", "cyclic":
+		default:
+			err := errors.New("chiplet_rram_weight_layout must be none, block or cyclic")
+			panic(err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_rram_load_path_mode"))) {
+		case "none", "serial", "overlap":
+		default:
+			err := errors.New("chiplet_rram_load_path_mode must be none, serial or overlap")
+			panic(err)
+		}
+
+		reproPath := strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_repro_path"))
+		if reproPath != "" {
+			if _, statErr := os.Stat(filepath.Join(reproPath, "chiplet_commands.json")); statErr != nil {
+				panic(fmt.Errorf("chiplet_repro_path %s has no chiplet_commands.json", reproPath))
+			}
+		}
+
 		modelPath := strings.TrimSpace(this.command_line_parser.StringParameter("chiplet_model_path"))
 		if modelPath != "" {
 			if _, statErr := os.Stat(modelPath); os.IsNotExist(statErr) {
@@ -167,6 +463,17 @@ func (this *CommandLineValidator) Validate() {
 		panic(err)
 	}
 
+	if this.command_line_parser.IntParameter("chiplet_rram_program_verify") < 0 {
+		err := errors.New("chiplet_rram_program_verify must be non-negative")
+		panic(err)
+	}
+
+	earlyExit := this.command_line_parser.FloatParameter("rram_program_early_exit_prob")
+	if earlyExit < 0 || earlyExit > 1 {
+		err := errors.New("rram_program_early_exit_prob must be in [0, 1]")
+		panic(err)
+	}
+
 	if this.command_line_parser.IntParameter("rram_array_rows") <= 0 {
 		err := errors.New("rram_array_rows <= 0")
 		panic(err)
@@ -1,8 +1,10 @@
 package misc
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +15,7 @@ type runtimeConfig struct {
 	rramReadLatency   int
 	rramWriteLatency  int
 	rramProgramPulses int
+	rramProgramExit   float64
 	rramArrayRows     int
 	rramArrayCols     int
 	rramCellPrecision int
@@ -56,6 +59,80 @@ type chipletRuntimeConfig struct {
 	hostStreamTotalBatches  int
 	hostStreamLowWatermark  int
 	hostStreamHighWatermark int
+	rramWeightNeighborShare bool
+	resultSampleRate        float64
+	resultSampleSeed        int64
+	resultSampleStreamKey   int64
+	resultErrorThreshold    float64
+	digitalL2Bytes          int64
+	digitalL2Bandwidth      int64
+	transferScheduleCycles  int
+	deadlockCycles          int
+	dumpOnError             bool
+	reproPath               string
+	rramLoadPathMode        string
+	schedulerMode           string
+	weightPrefetch          bool
+	rramWeightCapacity      int64
+	activationOffload       bool
+	energyCalibration       string
+	dvfs                    bool
+	dvfsLevels              []int
+	dvfsWindow              int
+	dvfsLowUtil             int
+	dvfsHighUtil            int
+	transferMinLatency      int
+	pipelineChecksum        bool
+	rramWeightLayout        string
+	reductionCostModel      string
+	nocBooksimCacheSize     int
+	nocBooksimCacheBucket   int64
+	nocBooksimCacheTtl      int
+	gqaGroupSize            int
+	kvHeads                 int
+	summary                 int
+	rramOutputWriteBw       int64
+	rngSeed                 int64
+	cmdFetchLatency         int
+	maxMoeSessions          int
+	schedulerCompare        string
+	cycleOrder              []string
+	moeSkipEmptyExperts     int
+	activationBanks         int
+	bootstrapIters          int
+	rramTileDepth           int
+	rramPsumLanes           int
+	snapshotInterval        int
+	rramWeightLoadBw        int64
+	hostDispatchPerCycle    int
+	digitalRawLatency       int
+	digitalIssueWidth       int
+	transferPriority        int
+	moeAlltoallBw           int
+	bufferWarnings          int
+	transferMode            string
+	rramDequantLanes        int
+	rramWeightBits          int
+	idlePowerdownCycles     int
+	wakeupLatency           int
+	halfDuplex              int
+	retirementLatency       int
+	modelPath               string
+	maxCycles               int
+	confirmUnlimited        int
+	profile                 int
+	barrierCyclesPerProd    int
+	dumpFinalGraph          int
+	strictCommands          int
+	prefetchDistance        int
+	rramCrossCheck          int
+	broadcastCycles         int
+	gatingSoftmaxOps        int
+	eccOverheadPct          int
+	transferPipelineChunks  int
+	maxInflightTransfers    int
+	transposeStridePenalty  float64
+	rramProgramVerify       bool
 }
 
 var globalConfig = runtimeConfig{
@@ -106,6 +183,80 @@ var globalChipletConfig = chipletRuntimeConfig{
 	hostStreamTotalBatches:  1,
 	hostStreamLowWatermark:  1,
 	hostStreamHighWatermark: 2,
+	rramWeightNeighborShare: false,
+	resultSampleRate:        1.0,
+	resultSampleSeed:        0,
+	resultSampleStreamKey:   0,
+	resultErrorThreshold:    1.0,
+	digitalL2Bytes:          0,
+	digitalL2Bandwidth:      256,
+	transferScheduleCycles:  0,
+	deadlockCycles:          0,
+	dumpOnError:             false,
+	reproPath:               "",
+	rramLoadPathMode:        "none",
+	schedulerMode:           "fifo",
+	weightPrefetch:          false,
+	rramWeightCapacity:      0,
+	activationOffload:       false,
+	energyCalibration:       "",
+	dvfs:                    false,
+	dvfsLevels:              []int{100, 50},
+	dvfsWindow:              1000,
+	dvfsLowUtil:             20,
+	dvfsHighUtil:            60,
+	transferMinLatency:      0,
+	pipelineChecksum:        false,
+	rramWeightLayout:        "none",
+	reductionCostModel:      "linear",
+	nocBooksimCacheSize:     0,
+	nocBooksimCacheBucket:   64,
+	nocBooksimCacheTtl:      0,
+	gqaGroupSize:            1,
+	kvHeads:                 0,
+	summary:                 -1,
+	rramOutputWriteBw:       0,
+	rngSeed:                 1,
+	cmdFetchLatency:         0,
+	maxMoeSessions:          0,
+	schedulerCompare:        "",
+	cycleOrder:              []string{"digital", "rram", "transfer"},
+	moeSkipEmptyExperts:     1,
+	activationBanks:         1,
+	bootstrapIters:          1,
+	rramTileDepth:           0,
+	rramPsumLanes:           0,
+	snapshotInterval:        0,
+	rramWeightLoadBw:        0,
+	hostDispatchPerCycle:    0,
+	digitalRawLatency:       0,
+	digitalIssueWidth:       0,
+	transferPriority:        0,
+	moeAlltoallBw:           0,
+	bufferWarnings:          1,
+	transferMode:            "cycle_accurate",
+	rramDequantLanes:        0,
+	rramWeightBits:          8,
+	idlePowerdownCycles:     0,
+	wakeupLatency:           32,
+	halfDuplex:              0,
+	retirementLatency:       0,
+	modelPath:               "",
+	maxCycles:               0,
+	confirmUnlimited:        0,
+	profile:                 0,
+	barrierCyclesPerProd:    0,
+	dumpFinalGraph:          0,
+	strictCommands:          0,
+	prefetchDistance:        0,
+	rramCrossCheck:          0,
+	broadcastCycles:         0,
+	gatingSoftmaxOps:        0,
+	eccOverheadPct:          0,
+	transferPipelineChunks:  0,
+	maxInflightTransfers:    0,
+	transposeStridePenalty:  4.0,
+	rramProgramVerify:       false,
 }
 
 func ConfigureRuntime(parser *CommandLineParser) {
@@ -121,6 +272,7 @@ func ConfigureRuntime(parser *CommandLineParser) {
 	globalConfig.rramReadLatency = int(parser.IntParameter("rram_read_latency"))
 	globalConfig.rramWriteLatency = int(parser.IntParameter("rram_write_latency"))
 	globalConfig.rramProgramPulses = int(parser.IntParameter("rram_program_pulses"))
+	globalConfig.rramProgramExit = parser.FloatParameter("rram_program_early_exit_prob")
 	globalConfig.rramArrayRows = int(parser.IntParameter("rram_array_rows"))
 	globalConfig.rramArrayCols = int(parser.IntParameter("rram_array_cols"))
 	globalConfig.rramCellPrecision = int(parser.IntParameter("rram_cell_precision"))
@@ -164,6 +316,84 @@ func ConfigureRuntime(parser *CommandLineParser) {
 	globalChipletConfig.hostStreamTotalBatches = int(parser.IntParameter("chiplet_host_stream_total_batches"))
 	globalChipletConfig.hostStreamLowWatermark = int(parser.IntParameter("chiplet_host_stream_low_watermark"))
 	globalChipletConfig.hostStreamHighWatermark = int(parser.IntParameter("chiplet_host_stream_high_watermark"))
+	globalChipletConfig.rramWeightNeighborShare = parser.IntParameter("chiplet_rram_weight_neighbor_sharing") != 0
+	globalChipletConfig.resultSampleRate = parser.FloatParameter("chiplet_result_sample_rate")
+	globalChipletConfig.resultSampleSeed = parser.IntParameter("chiplet_result_sample_seed")
+	globalChipletConfig.resultSampleStreamKey = parser.IntParameter("chiplet_result_sample_stream_key")
+	globalChipletConfig.resultErrorThreshold = parser.FloatParameter("chiplet_result_error_threshold")
+	globalChipletConfig.digitalL2Bytes = parser.IntParameter("chiplet_digital_l2_bytes")
+	globalChipletConfig.digitalL2Bandwidth = parser.IntParameter("chiplet_digital_l2_bw")
+	globalChipletConfig.transferScheduleCycles = int(parser.IntParameter("chiplet_transfer_schedule_overhead"))
+	globalChipletConfig.deadlockCycles = int(parser.IntParameter("chiplet_deadlock_cycles"))
+	globalChipletConfig.dumpOnError = parser.IntParameter("chiplet_dump_on_error") != 0
+	globalChipletConfig.reproPath = strings.TrimSpace(parser.StringParameter("chiplet_repro_path"))
+	globalChipletConfig.rramLoadPathMode = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_rram_load_path_mode")))
+	globalChipletConfig.schedulerMode = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_scheduler")))
+	globalChipletConfig.weightPrefetch = parser.IntParameter("chiplet_weight_prefetch") != 0
+	globalChipletConfig.rramWeightCapacity = int64(parser.IntParameter("chiplet_rram_weight_capacity"))
+	globalChipletConfig.activationOffload = parser.IntParameter("chiplet_activation_offload") != 0
+	globalChipletConfig.energyCalibration = strings.TrimSpace(parser.StringParameter("chiplet_energy_calibration"))
+	globalChipletConfig.dvfs = parser.IntParameter("chiplet_dvfs") != 0
+	if levels, err := ParseDvfsLevels(parser.StringParameter("chiplet_dvfs_levels")); err == nil {
+		globalChipletConfig.dvfsLevels = levels
+	}
+	globalChipletConfig.dvfsWindow = int(parser.IntParameter("chiplet_dvfs_window"))
+	globalChipletConfig.dvfsLowUtil = int(parser.IntParameter("chiplet_dvfs_low_util"))
+	globalChipletConfig.dvfsHighUtil = int(parser.IntParameter("chiplet_dvfs_high_util"))
+	globalChipletConfig.transferMinLatency = int(parser.IntParameter("chiplet_transfer_min_latency"))
+	globalChipletConfig.pipelineChecksum = parser.IntParameter("chiplet_pipeline_checksum") != 0
+	globalChipletConfig.rramWeightLayout = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_rram_weight_layout")))
+	globalChipletConfig.reductionCostModel = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_reduction_cost_model")))
+	globalChipletConfig.nocBooksimCacheSize = int(parser.IntParameter("chiplet_noc_booksim_cache_size"))
+	globalChipletConfig.nocBooksimCacheBucket = parser.IntParameter("chiplet_noc_booksim_cache_bucket")
+	globalChipletConfig.nocBooksimCacheTtl = int(parser.IntParameter("chiplet_noc_booksim_cache_ttl"))
+	globalChipletConfig.gqaGroupSize = int(parser.IntParameter("chiplet_gqa_group_size"))
+	globalChipletConfig.kvHeads = int(parser.IntParameter("chiplet_kv_heads"))
+	globalChipletConfig.summary = int(parser.IntParameter("chiplet_summary"))
+	globalChipletConfig.rramOutputWriteBw = parser.IntParameter("chiplet_rram_output_write_bw")
+	globalChipletConfig.rngSeed = parser.IntParameter("chiplet_rng_seed")
+	globalChipletConfig.cmdFetchLatency = int(parser.IntParameter("chiplet_cmd_fetch_latency"))
+	globalChipletConfig.maxMoeSessions = int(parser.IntParameter("chiplet_max_moe_sessions"))
+	globalChipletConfig.schedulerCompare = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_scheduler_compare")))
+	if order, err := ParseCycleOrder(parser.StringParameter("chiplet_cycle_order")); err == nil {
+		globalChipletConfig.cycleOrder = order
+	}
+	globalChipletConfig.moeSkipEmptyExperts = int(parser.IntParameter("chiplet_moe_skip_empty_experts"))
+	globalChipletConfig.activationBanks = int(parser.IntParameter("chiplet_activation_banks"))
+	globalChipletConfig.bootstrapIters = int(parser.IntParameter("chiplet_bootstrap_iters"))
+	globalChipletConfig.rramTileDepth = int(parser.IntParameter("chiplet_rram_tile_depth"))
+	globalChipletConfig.rramPsumLanes = int(parser.IntParameter("chiplet_rram_psum_lanes"))
+	globalChipletConfig.snapshotInterval = int(parser.IntParameter("chiplet_snapshot_interval"))
+	globalChipletConfig.rramWeightLoadBw = parser.IntParameter("chiplet_rram_weight_load_bw")
+	globalChipletConfig.hostDispatchPerCycle = int(parser.IntParameter("chiplet_host_dispatch_per_cycle"))
+	globalChipletConfig.digitalRawLatency = int(parser.IntParameter("chiplet_digital_raw_latency"))
+	globalChipletConfig.digitalIssueWidth = int(parser.IntParameter("chiplet_digital_issue_width"))
+	globalChipletConfig.transferPriority = int(parser.IntParameter("chiplet_transfer_priority"))
+	globalChipletConfig.moeAlltoallBw = int(parser.IntParameter("chiplet_moe_alltoall_bw"))
+	globalChipletConfig.bufferWarnings = int(parser.IntParameter("chiplet_buffer_warnings"))
+	globalChipletConfig.transferMode = strings.ToLower(strings.TrimSpace(parser.StringParameter("chiplet_transfer_mode")))
+	globalChipletConfig.rramDequantLanes = int(parser.IntParameter("chiplet_rram_dequant_lanes"))
+	globalChipletConfig.rramWeightBits = int(parser.IntParameter("chiplet_rram_weight_bits"))
+	globalChipletConfig.idlePowerdownCycles = int(parser.IntParameter("chiplet_idle_powerdown_cycles"))
+	globalChipletConfig.wakeupLatency = int(parser.IntParameter("chiplet_wakeup_latency"))
+	globalChipletConfig.halfDuplex = int(parser.IntParameter("chiplet_half_duplex"))
+	globalChipletConfig.retirementLatency = int(parser.IntParameter("chiplet_retirement_latency"))
+	globalChipletConfig.modelPath = strings.TrimSpace(parser.StringParameter("chiplet_model_path"))
+	globalChipletConfig.maxCycles = int(parser.IntParameter("chiplet_max_cycles"))
+	globalChipletConfig.confirmUnlimited = int(parser.IntParameter("chiplet_confirm_unlimited"))
+	globalChipletConfig.profile = int(parser.IntParameter("chiplet_profile"))
+	globalChipletConfig.barrierCyclesPerProd = int(parser.IntParameter("chiplet_barrier_cycles_per_producer"))
+	globalChipletConfig.dumpFinalGraph = int(parser.IntParameter("chiplet_dump_final_graph"))
+	globalChipletConfig.strictCommands = int(parser.IntParameter("chiplet_strict_commands"))
+	globalChipletConfig.prefetchDistance = int(parser.IntParameter("chiplet_prefetch_distance"))
+	globalChipletConfig.rramCrossCheck = int(parser.IntParameter("chiplet_rram_cross_check"))
+	globalChipletConfig.broadcastCycles = int(parser.IntParameter("chiplet_collective_broadcast_cycles"))
+	globalChipletConfig.gatingSoftmaxOps = int(parser.IntParameter("chiplet_moe_gating_softmax_ops"))
+	globalChipletConfig.eccOverheadPct = int(parser.IntParameter("chiplet_ecc_overhead_pct"))
+	globalChipletConfig.transferPipelineChunks = int(parser.IntParameter("chiplet_transfer_pipeline_chunks"))
+	globalChipletConfig.maxInflightTransfers = int(parser.IntParameter("chiplet_max_inflight_transfers"))
+	globalChipletConfig.transposeStridePenalty = parser.FloatParameter("chiplet_transpose_stride_penalty")
+	globalChipletConfig.rramProgramVerify = parser.IntParameter("chiplet_rram_program_verify") != 0
 }
 
 func (this *ConfigLoader) Init() {}
@@ -262,6 +492,10 @@ func (this *ConfigLoader) RramProgramPulses() int {
 	return globalConfig.rramProgramPulses
 }
 
+func (this *ConfigLoader) RramProgramEarlyExitProb() float64 {
+	return globalConfig.rramProgramExit
+}
+
 func (this *ConfigLoader) MemoryType() string {
 	return globalConfig.memoryType
 }
@@ -439,6 +673,358 @@ func (this *ConfigLoader) ChipletHostStreamHighWatermark() int {
 	return globalChipletConfig.hostStreamHighWatermark
 }
 
+func (this *ConfigLoader) ChipletRramWeightNeighborSharing() bool {
+	return globalChipletConfig.rramWeightNeighborShare
+}
+
+func (this *ConfigLoader) ChipletResultSampleRate() float64 {
+	return globalChipletConfig.resultSampleRate
+}
+
+func (this *ConfigLoader) ChipletResultSampleSeed() int64 {
+	return globalChipletConfig.resultSampleSeed
+}
+
+func (this *ConfigLoader) ChipletResultSampleStreamKey() int64 {
+	return globalChipletConfig.resultSampleStreamKey
+}
+
+func (this *ConfigLoader) ChipletResultErrorThreshold() float64 {
+	return globalChipletConfig.resultErrorThreshold
+}
+
+func (this *ConfigLoader) ChipletDigitalL2Bytes() int64 {
+	return globalChipletConfig.digitalL2Bytes
+}
+
+func (this *ConfigLoader) ChipletDigitalL2Bandwidth() int64 {
+	return globalChipletConfig.digitalL2Bandwidth
+}
+
+func (this *ConfigLoader) ChipletTransferScheduleOverhead() int {
+	return globalChipletConfig.transferScheduleCycles
+}
+
+func (this *ConfigLoader) ChipletDeadlockCycles() int {
+	return globalChipletConfig.deadlockCycles
+}
+
+func (this *ConfigLoader) ChipletDumpOnError() bool {
+	return globalChipletConfig.dumpOnError
+}
+
+func (this *ConfigLoader) ChipletReproPath() string {
+	return globalChipletConfig.reproPath
+}
+
+func (this *ConfigLoader) ChipletRramLoadPathMode() string {
+	return globalChipletConfig.rramLoadPathMode
+}
+
+func (this *ConfigLoader) ChipletScheduler() string {
+	return globalChipletConfig.schedulerMode
+}
+
+func (this *ConfigLoader) ChipletWeightPrefetch() bool {
+	return globalChipletConfig.weightPrefetch
+}
+
+func (this *ConfigLoader) ChipletRramWeightCapacity() int64 {
+	return globalChipletConfig.rramWeightCapacity
+}
+
+func (this *ConfigLoader) ChipletActivationOffload() bool {
+	return globalChipletConfig.activationOffload
+}
+
+func (this *ConfigLoader) ChipletEnergyCalibration() string {
+	return globalChipletConfig.energyCalibration
+}
+
+func (this *ConfigLoader) ChipletDvfs() bool {
+	return globalChipletConfig.dvfs
+}
+
+func (this *ConfigLoader) ChipletDvfsLevels() []int {
+	return append([]int(nil), globalChipletConfig.dvfsLevels...)
+}
+
+func (this *ConfigLoader) ChipletDvfsWindow() int {
+	return globalChipletConfig.dvfsWindow
+}
+
+func (this *ConfigLoader) ChipletDvfsLowUtil() int {
+	return globalChipletConfig.dvfsLowUtil
+}
+
+func (this *ConfigLoader) ChipletDvfsHighUtil() int {
+	return globalChipletConfig.dvfsHighUtil
+}
+
+func (this *ConfigLoader) ChipletTransferMinLatency() int {
+	return globalChipletConfig.transferMinLatency
+}
+
+func (this *ConfigLoader) ChipletPipelineChecksum() bool {
+	return globalChipletConfig.pipelineChecksum
+}
+
+func (this *ConfigLoader) ChipletRramWeightLayout() string {
+	return globalChipletConfig.rramWeightLayout
+}
+
+func (this *ConfigLoader) ChipletReductionCostModel() string {
+	return globalChipletConfig.reductionCostModel
+}
+
+func (this *ConfigLoader) ChipletNocBooksimCacheSize() int {
+	return globalChipletConfig.nocBooksimCacheSize
+}
+
+func (this *ConfigLoader) ChipletNocBooksimCacheBucket() int64 {
+	return globalChipletConfig.nocBooksimCacheBucket
+}
+
+func (this *ConfigLoader) ChipletNocBooksimCacheTtl() int {
+	return globalChipletConfig.nocBooksimCacheTtl
+}
+
+func (this *ConfigLoader) ChipletGqaGroupSize() int {
+	return globalChipletConfig.gqaGroupSize
+}
+
+func (this *ConfigLoader) ChipletKvHeads() int {
+	return globalChipletConfig.kvHeads
+}
+
+func (this *ConfigLoader) ChipletSummary() int {
+	return globalChipletConfig.summary
+}
+
+func (this *ConfigLoader) ChipletRramOutputWriteBw() int64 {
+	return globalChipletConfig.rramOutputWriteBw
+}
+
+func (this *ConfigLoader) ChipletRngSeed() int64 {
+	return globalChipletConfig.rngSeed
+}
+
+func (this *ConfigLoader) ChipletCmdFetchLatency() int {
+	return globalChipletConfig.cmdFetchLatency
+}
+
+func (this *ConfigLoader) ChipletMaxMoeSessions() int {
+	return globalChipletConfig.maxMoeSessions
+}
+
+func (this *ConfigLoader) ChipletSchedulerCompare() string {
+	return globalChipletConfig.schedulerCompare
+}
+
+func (this *ConfigLoader) ChipletCycleOrder() []string {
+	return append([]string(nil), globalChipletConfig.cycleOrder...)
+}
+
+func (this *ConfigLoader) ChipletMoeSkipEmptyExperts() int {
+	return globalChipletConfig.moeSkipEmptyExperts
+}
+
+func (this *ConfigLoader) ChipletActivationBanks() int {
+	return globalChipletConfig.activationBanks
+}
+
+func (this *ConfigLoader) ChipletBootstrapIters() int {
+	return globalChipletConfig.bootstrapIters
+}
+
+func (this *ConfigLoader) ChipletRramTileDepth() int {
+	return globalChipletConfig.rramTileDepth
+}
+
+func (this *ConfigLoader) ChipletRramPsumLanes() int {
+	return globalChipletConfig.rramPsumLanes
+}
+
+func (this *ConfigLoader) ChipletSnapshotInterval() int {
+	return globalChipletConfig.snapshotInterval
+}
+
+func (this *ConfigLoader) ChipletRramWeightLoadBw() int64 {
+	return globalChipletConfig.rramWeightLoadBw
+}
+
+func (this *ConfigLoader) ChipletHostDispatchPerCycle() int {
+	return globalChipletConfig.hostDispatchPerCycle
+}
+
+func (this *ConfigLoader) ChipletDigitalRawLatency() int {
+	return globalChipletConfig.digitalRawLatency
+}
+
+func (this *ConfigLoader) ChipletDigitalIssueWidth() int {
+	return globalChipletConfig.digitalIssueWidth
+}
+
+func (this *ConfigLoader) ChipletTransferPriority() int {
+	return globalChipletConfig.transferPriority
+}
+
+func (this *ConfigLoader) ChipletMoeAlltoallBw() int {
+	return globalChipletConfig.moeAlltoallBw
+}
+
+func (this *ConfigLoader) ChipletBufferWarnings() int {
+	return globalChipletConfig.bufferWarnings
+}
+
+func (this *ConfigLoader) ChipletTransferMode() string {
+	return globalChipletConfig.transferMode
+}
+
+func (this *ConfigLoader) ChipletRramDequantLanes() int {
+	return globalChipletConfig.rramDequantLanes
+}
+
+func (this *ConfigLoader) ChipletRramWeightBits() int {
+	return globalChipletConfig.rramWeightBits
+}
+
+func (this *ConfigLoader) ChipletIdlePowerdownCycles() int {
+	return globalChipletConfig.idlePowerdownCycles
+}
+
+func (this *ConfigLoader) ChipletWakeupLatency() int {
+	return globalChipletConfig.wakeupLatency
+}
+
+func (this *ConfigLoader) ChipletHalfDuplex() int {
+	return globalChipletConfig.halfDuplex
+}
+
+func (this *ConfigLoader) ChipletRetirementLatency() int {
+	return globalChipletConfig.retirementLatency
+}
+
+func (this *ConfigLoader) ChipletModelPath() string {
+	return globalChipletConfig.modelPath
+}
+
+func (this *ConfigLoader) ChipletMaxCycles() int {
+	return globalChipletConfig.maxCycles
+}
+
+func (this *ConfigLoader) ChipletConfirmUnlimited() int {
+	return globalChipletConfig.confirmUnlimited
+}
+
+func (this *ConfigLoader) ChipletProfile() int {
+	return globalChipletConfig.profile
+}
+
+func (this *ConfigLoader) ChipletBarrierCyclesPerProducer() int {
+	return globalChipletConfig.barrierCyclesPerProd
+}
+
+func (this *ConfigLoader) ChipletDumpFinalGraph() int {
+	return globalChipletConfig.dumpFinalGraph
+}
+
+func (this *ConfigLoader) ChipletStrictCommands() int {
+	return globalChipletConfig.strictCommands
+}
+
+func (this *ConfigLoader) ChipletPrefetchDistance() int {
+	return globalChipletConfig.prefetchDistance
+}
+
+func (this *ConfigLoader) ChipletRramCrossCheck() int {
+	return globalChipletConfig.rramCrossCheck
+}
+
+func (this *ConfigLoader) ChipletCollectiveBroadcastCycles() int {
+	return globalChipletConfig.broadcastCycles
+}
+
+func (this *ConfigLoader) ChipletMoeGatingSoftmaxOps() int {
+	return globalChipletConfig.gatingSoftmaxOps
+}
+
+func (this *ConfigLoader) ChipletEccOverheadPct() int {
+	return globalChipletConfig.eccOverheadPct
+}
+
+func (this *ConfigLoader) ChipletTransferPipelineChunks() int {
+	return globalChipletConfig.transferPipelineChunks
+}
+
+func (this *ConfigLoader) ChipletMaxInflightTransfers() int {
+	return globalChipletConfig.maxInflightTransfers
+}
+
+func (this *ConfigLoader) ChipletTransposeStridePenalty() float64 {
+	return globalChipletConfig.transposeStridePenalty
+}
+
+func (this *ConfigLoader) ChipletRramProgramVerify() bool {
+	return globalChipletConfig.rramProgramVerify
+}
+
+// ParseDvfsLevels parses a comma separated list of frequency levels given as a
+// percentage of the nominal clock. The first level must be 100 and the list
+// must be strictly descending so index 0 is always the nominal frequency.
+func ParseDvfsLevels(text string) ([]int, error) {
+	fields := strings.Split(text, ",")
+	levels := make([]int, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, errors.New("dvfs level " + strconv.Quote(field) + " is not an integer")
+		}
+		if value <= 0 || value > 100 {
+			return nil, errors.New("dvfs levels must be within (0, 100]")
+		}
+		if len(levels) > 0 && value >= levels[len(levels)-1] {
+			return nil, errors.New("dvfs levels must be strictly descending")
+		}
+		levels = append(levels, value)
+	}
+	if len(levels) == 0 || levels[0] != 100 {
+		return nil, errors.New("dvfs levels must start at 100")
+	}
+	return levels, nil
+}
+
+// ParseCycleOrder parses --chiplet_cycle_order, a comma-separated permutation
+// of the clock domains digital, rram and transfer.
+func ParseCycleOrder(text string) ([]string, error) {
+	order := make([]string, 0, 3)
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(text, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		switch field {
+		case "digital", "rram", "transfer":
+		default:
+			return nil, errors.New("cycle order domain " + strconv.Quote(field) + " is not one of digital, rram, transfer")
+		}
+		if seen[field] {
+			return nil, errors.New("cycle order lists " + strconv.Quote(field) + " more than once")
+		}
+		seen[field] = true
+		order = append(order, field)
+	}
+	if len(order) != 3 {
+		return nil, errors.New("cycle order must list digital, rram and transfer exactly once")
+	}
+	return order, nil
+}
+
 func resolveRamulatorConfigPath(configPath, rootDir string) string {
 	return resolveConfigPath(configPath, rootDir)
 }
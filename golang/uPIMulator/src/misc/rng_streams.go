@@ -0,0 +1,63 @@
+package misc
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// 各随机特性使用的命名流。新增随机消费者时应登记新的名字，而不是复用已有流，
+// 这样开启/关闭某个特性不会扰动其他特性的随机序列。
+const (
+	RNGStreamExpertSelection = "expert_selection"
+	RNGStreamResultSample    = "result_sample"
+	RNGStreamRramProgram     = "rram_program"
+)
+
+// RNGStreams 从一个主种子派生互相独立的命名随机流。
+// 每个流的种子只取决于主种子与流名（以及可选的 key），与其他流的消费次数无关。
+type RNGStreams struct {
+	master  int64
+	streams map[string]*rand.Rand
+}
+
+func (this *RNGStreams) Init(master int64) {
+	this.master = master
+	this.streams = make(map[string]*rand.Rand)
+}
+
+func (this *RNGStreams) Master() int64 {
+	return this.master
+}
+
+// Seed 返回 name 流的派生种子。
+func (this *RNGStreams) Seed(name string) int64 {
+	return this.deriveSeed(name, 0)
+}
+
+// Stream 返回 name 对应的共享流；同一 provider 内多次调用得到同一个 *rand.Rand。
+func (this *RNGStreams) Stream(name string) *rand.Rand {
+	if rng, found := this.streams[name]; found {
+		return rng
+	}
+	rng := rand.New(rand.NewSource(this.Seed(name)))
+	this.streams[name] = rng
+	return rng
+}
+
+// Fork 返回由 (主种子, name, key) 决定的新流，用于按 chiplet 或 stage 区分的子序列。
+func (this *RNGStreams) Fork(name string, key int64) *rand.Rand {
+	return rand.New(rand.NewSource(this.deriveSeed(name, key)))
+}
+
+func (this *RNGStreams) deriveSeed(name string, key int64) int64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(name))
+	seed := hasher.Sum64() ^ uint64(this.master)*0x9e3779b97f4a7c15 ^ uint64(key)*0xbf58476d1ce4e5b9
+	// splitmix64 终混，避免相邻主种子/key 产生相关序列。
+	seed ^= seed >> 30
+	seed *= 0xbf58476d1ce4e5b9
+	seed ^= seed >> 27
+	seed *= 0x94d049bb133111eb
+	seed ^= seed >> 31
+	return int64(seed)
+}
@@ -0,0 +1,35 @@
+package misc
+
+import "testing"
+
+func TestRNGStreamsAreIndependent(t *testing.T) {
+	t.Parallel()
+
+	baseline := new(RNGStreams)
+	baseline.Init(7)
+	withSampling := new(RNGStreams)
+	withSampling.Init(7)
+
+	// Result sampling drawing between expert selections must not shift the
+	// expert-selection sequence.
+	for i := 0; i < 16; i++ {
+		withSampling.Stream(RNGStreamResultSample).Int63()
+		want := baseline.Stream(RNGStreamExpertSelection).Intn(64)
+		got := withSampling.Stream(RNGStreamExpertSelection).Intn(64)
+		if got != want {
+			t.Fatalf("draw %d: expert selection changed from %d to %d with result sampling enabled", i, want, got)
+		}
+	}
+
+	if baseline.Seed(RNGStreamExpertSelection) == baseline.Seed(RNGStreamResultSample) {
+		t.Fatalf("expected distinct seeds per stream")
+	}
+	if baseline.Fork(RNGStreamRramProgram, 0).Int63() == baseline.Fork(RNGStreamRramProgram, 1).Int63() {
+		t.Fatalf("expected forks with different keys to differ")
+	}
+	other := new(RNGStreams)
+	other.Init(8)
+	if other.Seed(RNGStreamExpertSelection) == baseline.Seed(RNGStreamExpertSelection) {
+		t.Fatalf("expected master seed to change derived seeds")
+	}
+}
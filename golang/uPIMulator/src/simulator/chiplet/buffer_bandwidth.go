@@ -0,0 +1,81 @@
+package chiplet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BufferBandwidth overrides the PE-array buffer bandwidths of one digital
+// chiplet. A nil field keeps the value from the shared digital parameters.
+type BufferBandwidth struct {
+	LoadBytesPerCycle  *int64 `json:"load_bytes_per_cycle"`
+	StoreBytesPerCycle *int64 `json:"store_bytes_per_cycle"`
+}
+
+// ModelTopology is the "topology" section of a chiplet model JSON. It carries
+// per-chiplet hardware overrides for heterogeneous packages; the stage
+// sequence in the same file is consumed by the assembler.
+type ModelTopology struct {
+	Digital map[string]BufferBandwidth `json:"digital,omitempty"`
+}
+
+type modelTopologyFile struct {
+	Topology json.RawMessage `json:"topology"`
+}
+
+// LoadBufferBandwidths reads per-chiplet buffer bandwidth overrides from the
+// topology section of a chiplet model JSON:
+// {"topology": {"digital": {"<chiplet id>": {"load_bytes_per_cycle": N,
+// "store_bytes_per_cycle": M}}}}. A model without a topology section has no
+// overrides. Chiplet IDs must lie in [0, numDigital) and every configured
+// bandwidth must be positive.
+func LoadBufferBandwidths(path string, numDigital int) (map[int]BufferBandwidth, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file modelTopologyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("chiplet model %s: %v", path, err)
+	}
+	if len(file.Topology) == 0 {
+		return nil, nil
+	}
+	var raw ModelTopology
+	decoder := json.NewDecoder(bytes.NewReader(file.Topology))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("chiplet model %s topology: %v", path, err)
+	}
+
+	overrides := make(map[int]BufferBandwidth, len(raw.Digital))
+	for key, bw := range raw.Digital {
+		id, convErr := strconv.Atoi(strings.TrimSpace(key))
+		if convErr != nil || id < 0 || id >= numDigital {
+			return nil, fmt.Errorf("chiplet model %s topology: digital chiplet %q out of range [0, %d)", path, key, numDigital)
+		}
+		if bw.LoadBytesPerCycle != nil && *bw.LoadBytesPerCycle <= 0 {
+			return nil, fmt.Errorf("chiplet model %s topology: load_bytes_per_cycle for chiplet %d must be positive", path, id)
+		}
+		if bw.StoreBytesPerCycle != nil && *bw.StoreBytesPerCycle <= 0 {
+			return nil, fmt.Errorf("chiplet model %s topology: store_bytes_per_cycle for chiplet %d must be positive", path, id)
+		}
+		overrides[id] = bw
+	}
+	return overrides, nil
+}
+
+// BufferBandwidthIDs lists the overridden chiplet IDs in ascending order.
+func BufferBandwidthIDs(overrides map[int]BufferBandwidth) []int {
+	ids := make([]int, 0, len(overrides))
+	for id := range overrides {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -0,0 +1,56 @@
+package chiplet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeBufferBandwidths wraps a topology section into a minimal model JSON.
+func writeBufferBandwidths(t *testing.T, topology string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.json")
+	body := `{"name": "bw", "sequence": [{"type": "elementwise"}], "topology": ` + topology + `}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write model: %v", err)
+	}
+	return path
+}
+
+func TestLoadBufferBandwidths(t *testing.T) {
+	overrides, err := LoadBufferBandwidths(writeBufferBandwidths(t,
+		`{"digital": {"1": {"load_bytes_per_cycle": 8192}, "0": {"store_bytes_per_cycle": 512}}}`), 2)
+	if err != nil {
+		t.Fatalf("load buffer bandwidths: %v", err)
+	}
+	if ids := BufferBandwidthIDs(overrides); len(ids) != 2 || ids[0] != 0 || ids[1] != 1 {
+		t.Fatalf("unexpected chiplet ids %v", ids)
+	}
+	if bw := overrides[1]; bw.LoadBytesPerCycle == nil || *bw.LoadBytesPerCycle != 8192 || bw.StoreBytesPerCycle != nil {
+		t.Fatalf("chiplet 1 override = %+v, want load 8192 only", bw)
+	}
+	if bw := overrides[0]; bw.StoreBytesPerCycle == nil || *bw.StoreBytesPerCycle != 512 || bw.LoadBytesPerCycle != nil {
+		t.Fatalf("chiplet 0 override = %+v, want store 512 only", bw)
+	}
+
+	for _, body := range []string{
+		`{"digital": {"2": {"load_bytes_per_cycle": 4096}}}`,
+		`{"digital": {"x": {"load_bytes_per_cycle": 4096}}}`,
+		`{"digital": {"0": {"load_bytes_per_cycle": 0}}}`,
+		`{"digital": {"0": {"store_bytes_per_cycle": -1}}}`,
+		`{"digital": {"0": {"load_bw": 4096}}}`,
+		`{"rram": {}}`,
+	} {
+		if _, err := LoadBufferBandwidths(writeBufferBandwidths(t, body), 2); err == nil {
+			t.Fatalf("expected %s to be rejected", body)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "plain_model.json")
+	if err := os.WriteFile(path, []byte(`{"name": "plain", "sequence": [{"type": "elementwise"}]}`), 0o644); err != nil {
+		t.Fatalf("write model: %v", err)
+	}
+	if overrides, err := LoadBufferBandwidths(path, 2); err != nil || len(overrides) != 0 {
+		t.Fatalf("a model without a topology section should have no overrides, got %v (%v)", overrides, err)
+	}
+}
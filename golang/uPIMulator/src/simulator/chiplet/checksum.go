@@ -0,0 +1,126 @@
+package chiplet
+
+import "sort"
+
+// pipelineChecksumKey is the command metadata entry that carries the running
+// dataflow checksum from producers to consumers.
+const pipelineChecksumKey = "pipeline_checksum"
+
+const (
+	checksumOffset uint64 = 14695981039346656037
+	checksumPrime  uint64 = 1099511628211
+)
+
+// mixChecksum folds value into sum with an FNV-1a style step.
+func mixChecksum(sum uint64, value uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		sum ^= value & 0xff
+		sum *= checksumPrime
+		value >>= 8
+	}
+	return sum
+}
+
+// stageChecksum is the deterministic update a stage applies to its input
+// checksum. It only depends on the command kind so the expected value can be
+// derived from the graph alone.
+func stageChecksum(in uint64, payload interface{}) uint64 {
+	kind := uint64(CommandKindInvalid)
+	if cmd, ok := payload.(*CommandDescriptor); ok && cmd != nil {
+		kind = uint64(cmd.Kind)
+	}
+	return mixChecksum(in, kind+1)
+}
+
+// AdvancePipelineChecksum applies the stage update to a command carrying a
+// pipeline checksum. Commands without one are left untouched, so executors can
+// call it unconditionally.
+func AdvancePipelineChecksum(payload interface{}) {
+	cmd, ok := payload.(*CommandDescriptor)
+	if !ok || cmd == nil || cmd.Metadata == nil {
+		return
+	}
+	in, ok := cmd.Metadata[pipelineChecksumKey].(uint64)
+	if !ok {
+		return
+	}
+	cmd.Metadata[pipelineChecksumKey] = stageChecksum(in, cmd)
+}
+
+// combineDepChecksums merges the checksums of a node's dependencies in
+// ascending node order. Missing producers are skipped, which is what makes a
+// dropped or reordered producer visible downstream.
+func combineDepChecksums(deps []int, values map[int]uint64) uint64 {
+	ids := append([]int(nil), deps...)
+	sort.Ints(ids)
+	sum := checksumOffset
+	for _, dep := range dedupeIntSlice(ids) {
+		if value, ok := values[dep]; ok {
+			sum = mixChecksum(sum, value)
+		}
+	}
+	return sum
+}
+
+// stampPipelineChecksum records the input checksum for node on its command
+// before it is issued.
+func (this *HostOrchestrator) stampPipelineChecksum(node *OpNode) {
+	if this.checksumActual == nil || node == nil {
+		return
+	}
+	cmd, ok := node.Payload.(*CommandDescriptor)
+	if !ok || cmd == nil {
+		return
+	}
+	// 流式批次的命令可能与模板共享 metadata map，写入前先复制一份。
+	meta := make(map[string]interface{}, len(cmd.Metadata)+1)
+	for key, value := range cmd.Metadata {
+		meta[key] = value
+	}
+	cmd.Metadata = meta
+	cmd.Metadata[pipelineChecksumKey] = combineDepChecksums(node.Deps, this.checksumActual)
+}
+
+// recordPipelineChecksum captures the checksum produced by a completed node
+// and, for the LM-head stage, verifies it against the value implied by the
+// graph.
+func (this *HostOrchestrator) recordPipelineChecksum(node *OpNode) {
+	if this.checksumActual == nil || node == nil {
+		return
+	}
+	expected := stageChecksum(combineDepChecksums(node.Deps, this.checksumExpected), node.Payload)
+	this.checksumExpected[node.ID] = expected
+
+	cmd, ok := node.Payload.(*CommandDescriptor)
+	if !ok || cmd == nil {
+		this.checksumActual[node.ID] = expected
+		return
+	}
+	actual, _ := cmd.Metadata[pipelineChecksumKey].(uint64)
+	this.checksumActual[node.ID] = actual
+
+	if cmd.Kind != CommandKindHostLmHead {
+		return
+	}
+	this.checksumVerified++
+	if actual != expected {
+		this.checksumMismatches++
+	}
+}
+
+// PipelineChecksumMismatches returns how many LM-head stages finished with a
+// checksum that differs from the one expected from the graph.
+func (this *HostOrchestrator) PipelineChecksumMismatches() int {
+	if this == nil {
+		return 0
+	}
+	return this.checksumMismatches
+}
+
+// PipelineChecksumVerified returns how many LM-head stages were checked.
+func (this *HostOrchestrator) PipelineChecksumVerified() int {
+	if this == nil {
+		return 0
+	}
+	return this.checksumVerified
+}
@@ -0,0 +1,66 @@
+package chiplet
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// runChecksumPipeline drives a small embed -> GEMM -> transfer -> LM-head graph
+// to completion. Tasks whose node ID is listed in skip complete without
+// applying their stage update, as a dropped stage would.
+func runChecksumPipeline(t *testing.T, skip map[int]bool) *HostOrchestrator {
+	t.Helper()
+
+	commands := []CommandDescriptor{
+		{ID: 0, Kind: CommandKindHostEmbedLookup, Target: TaskTargetHost},
+		{ID: 1, Kind: CommandKindPeGemm, Target: TaskTargetDigital, Dependencies: []int32{0}},
+		{ID: 2, Kind: CommandKindTransferD2Host, Target: TaskTargetTransfer, Dependencies: []int32{1}},
+		{ID: 3, Kind: CommandKindHostLmHead, Target: TaskTargetHost, Dependencies: []int32{2}},
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	commandPath := filepath.Join(t.TempDir(), "chiplet_commands.json")
+	if err := os.WriteFile(commandPath, data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	config := &Config{NumDigitalChiplets: 1, NumRramChiplets: 1, PipelineChecksum: true}
+	orch := new(HostOrchestrator)
+	orch.Init(config, BuildTopology(config), commandPath)
+	t.Cleanup(orch.Fini)
+
+	for step := 0; step < 32 && orch.HasPendingWork(); step++ {
+		for _, task := range orch.Advance() {
+			if !skip[task.NodeID] {
+				AdvancePipelineChecksum(task.Payload)
+			}
+			orch.NotifyTaskCompletion(task.NodeID)
+		}
+	}
+	return orch
+}
+
+func TestPipelineChecksumMatchesWhenEveryStageRuns(t *testing.T) {
+	t.Parallel()
+
+	orch := runChecksumPipeline(t, nil)
+	if orch.PipelineChecksumVerified() != 1 {
+		t.Fatalf("expected LM head to be verified once, got %d", orch.PipelineChecksumVerified())
+	}
+	if orch.PipelineChecksumMismatches() != 0 {
+		t.Fatalf("expected no mismatches, got %d", orch.PipelineChecksumMismatches())
+	}
+}
+
+func TestPipelineChecksumFlagsDroppedTransfer(t *testing.T) {
+	t.Parallel()
+
+	orch := runChecksumPipeline(t, map[int]bool{2: true})
+	if orch.PipelineChecksumMismatches() != 1 {
+		t.Fatalf("expected dropped transfer to cause a mismatch, got %d", orch.PipelineChecksumMismatches())
+	}
+}
@@ -1,6 +1,10 @@
 package chiplet
 
-import "uPIMulator/src/linker/kernel/instruction"
+import (
+	"strings"
+
+	"uPIMulator/src/linker/kernel/instruction"
+)
 
 // CommandKind enumerates the high-level chiplet commands exposed through the
 // Chiplet ISA extension. It mirrors the dedicated opcodes introduced in the
@@ -93,13 +97,63 @@ const (
 
 // Metadata keys for transfer endpoints and hop metrics.
 const (
-	MetadataKeySrcDigital   = "src_digital"
-	MetadataKeyDstDigital   = "dst_digital"
-	MetadataKeySrcRram      = "src_rram"
-	MetadataKeyDstRram      = "dst_rram"
-	MetadataKeyTransferHops = "transfer_hops"
+	MetadataKeySrcDigital        = "src_digital"
+	MetadataKeyDstDigital        = "dst_digital"
+	MetadataKeySrcRram           = "src_rram"
+	MetadataKeyDstRram           = "dst_rram"
+	MetadataKeyTransferHops      = "transfer_hops"
+	MetadataKeyCollectiveID      = "collective_id"
+	MetadataKeyCollectivePhase   = "collective_phase"
+	MetadataKeyCollectiveCycles  = "collective_cycles"
+	MetadataKeyCollectiveMembers = "collective_members"
 )
 
+// knownCommandOps lists the metadata "op" labels emitted by the compiler's
+// kernel manifest, the assembler and the orchestrator's MoE expansion, plus
+// those the platform models directly.
+var knownCommandOps = map[string]bool{
+	"attention":               true,
+	"elementwise":             true,
+	"gemm":                    true,
+	"layernorm":               true,
+	"layernorm_affine":        true,
+	"layernorm_mean":          true,
+	"layernorm_norm":          true,
+	"layernorm_var":           true,
+	"moe_barrier":             true,
+	"moe_expert_execute":      true,
+	"moe_expert_merge":        true,
+	"moe_expert_post":         true,
+	"moe_expert_stage":        true,
+	"moe_expert_transfer_in":  true,
+	"moe_expert_transfer_out": true,
+	"moe_expert_weight_load":  true,
+	"moe_gating_fetch":        true,
+	"moe_gating_scores":       true,
+	"moe_gating_softmax":      true,
+	"rram_execute":            true,
+	"rram_post":               true,
+	"rram_stage_act":          true,
+	"softmax_exp":             true,
+	"softmax_norm":            true,
+	"softmax_reduce_max":      true,
+	"softmax_reduce_sum":      true,
+	"softmax_sub":             true,
+	"swiglu":                  true,
+	"token_prep":              true,
+	"topk_select":             true,
+	"transfer_cim2digital":    true,
+	"transfer_host2cim":       true,
+	"transpose":               true,
+}
+
+// IsKnownCommandOp reports whether op is a recognised metadata "op" label.
+// An empty op is always accepted.
+func IsKnownCommandOp(op string) bool {
+	op = strings.ToLower(strings.TrimSpace(op))
+	return op == "" || knownCommandOps[op]
+}
+
 // String returns a human-readable identifier for debugging/logging.
 func (k CommandKind) String() string {
 	switch k {
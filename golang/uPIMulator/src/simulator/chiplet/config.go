@@ -19,6 +19,10 @@ type Config struct {
 	RramDacBits             int
 	RramAdcBits             int
 	RramClockMhz            int
+	RramProgramPulses       int
+	RramWriteLatency        int
+	RramProgramEarlyExit    float64
+	RramProgramVerify       bool
 	InterconnectClockMhz    int
 	TransferBandwidthDr     int64
 	TransferBandwidthRd     int64
@@ -38,8 +42,89 @@ type Config struct {
 	HostStreamTotalBatches  int
 	HostStreamLowWatermark  int
 	HostStreamHighWatermark int
+	RramWeightNeighborShare bool
+	ResultSampleRate        float64
+	ResultSampleSeed        int64
+	ResultSampleStreamKey   int64
+	ResultErrorThreshold    float64
+	DigitalL2Bytes          int64
+	DigitalL2Bandwidth      int64
+	TransferScheduleCycles  int
+	DeadlockCycles          int
+	DumpOnError             bool
+	ReproPath               string
+	RramLoadPathMode        string
+	SchedulerMode           string
+	WeightPrefetch          bool
+	RramWeightCapacity      int64
+	ActivationOffload       bool
+	EnergyCalibrationPath   string
+	EnergyCalibration       map[string]float64
+	ModelPath               string
+	BufferBandwidths        map[int]BufferBandwidth
+	MaxCycles               int
+	ConfirmUnlimited        int
+	Profile                 int
+	BarrierProducerCycles   int
+	DumpFinalGraph          int
+	StrictCommands          int
+	PrefetchDistance        int
+	RramCrossCheck          int
+	BroadcastCycles         int
+	GatingSoftmaxOps        int
+	EccOverheadPct          int
+	TransferPipelineChunks  int
+	MaxInflightTransfers    int
+	TransposeStridePenalty  float64
+	Dvfs                    bool
+	DvfsLevels              []int
+	DvfsWindow              int
+	DvfsLowUtil             int
+	DvfsHighUtil            int
+	TransferMinLatency      int
+	PipelineChecksum        bool
+	RramWeightLayout        string
+	ReductionCostModel      string
+	NocBooksimCacheSize     int
+	NocBooksimCacheBucket   int64
+	NocBooksimCacheTtl      int
+	GqaGroupSize            int
+	KvHeads                 int
+	Summary                 int
+	RramOutputWriteBw       int64
+	RngSeed                 int64
+	CmdFetchLatency         int
+	MaxMoeSessions          int
+	SchedulerCompare        []string
+	CycleOrder              []string
+	MoeSkipEmptyExperts     int
+	ActivationBanks         int
+	BootstrapIters          int
+	RramTileDepth           int
+	RramPsumLanes           int
+	SnapshotInterval        int
+	RramWeightLoadBw        int64
+	HostDispatchPerCycle    int
+	DigitalRawLatency       int
+	DigitalIssueWidth       int
+	TransferPriority        int
+	MoeAlltoallBw           int
+	BufferWarnings          int
+	TransferMode            string
+	RramDequantLanes        int
+	RramWeightBits          int
+	IdlePowerdownCycles     int
+	WakeupLatency           int
+	HalfDuplex              int
+	RetirementLatency       int
 }
 
+// Reduction cost models for --chiplet_reduction_cost_model.
+const (
+	ReductionCostLinear = "linear"
+	ReductionCostNlogn  = "nlogn"
+)
+
 // LoadConfig pulls chiplet-specific parameters from the shared ConfigLoader.
 func LoadConfig(loader *misc.ConfigLoader) *Config {
 	config := new(Config)
@@ -59,6 +144,10 @@ func LoadConfig(loader *misc.ConfigLoader) *Config {
 	config.RramDacBits = loader.ChipletRramDacBits()
 	config.RramAdcBits = loader.ChipletRramAdcBits()
 	config.RramClockMhz = loader.ChipletRramClockMhz()
+	config.RramProgramPulses = loader.RramProgramPulses()
+	config.RramWriteLatency = loader.RramWriteLatency()
+	config.RramProgramEarlyExit = loader.RramProgramEarlyExitProb()
+	config.RramProgramVerify = loader.ChipletRramProgramVerify()
 	config.InterconnectClockMhz = loader.ChipletInterconnectClockMhz()
 	config.TransferBandwidthDr = loader.ChipletTransferBandwidthDr()
 	config.TransferBandwidthRd = loader.ChipletTransferBandwidthRd()
@@ -78,6 +167,79 @@ func LoadConfig(loader *misc.ConfigLoader) *Config {
 	config.HostStreamTotalBatches = loader.ChipletHostStreamTotalBatches()
 	config.HostStreamLowWatermark = loader.ChipletHostStreamLowWatermark()
 	config.HostStreamHighWatermark = loader.ChipletHostStreamHighWatermark()
+	config.RramWeightNeighborShare = loader.ChipletRramWeightNeighborSharing()
+	config.ResultSampleRate = loader.ChipletResultSampleRate()
+	config.ResultSampleSeed = loader.ChipletResultSampleSeed()
+	config.ResultSampleStreamKey = loader.ChipletResultSampleStreamKey()
+	config.ResultErrorThreshold = loader.ChipletResultErrorThreshold()
+	config.DigitalL2Bytes = loader.ChipletDigitalL2Bytes()
+	config.DigitalL2Bandwidth = loader.ChipletDigitalL2Bandwidth()
+	config.TransferScheduleCycles = loader.ChipletTransferScheduleOverhead()
+	config.DeadlockCycles = loader.ChipletDeadlockCycles()
+	config.DumpOnError = loader.ChipletDumpOnError()
+	config.ReproPath = loader.ChipletReproPath()
+	config.RramLoadPathMode = loader.ChipletRramLoadPathMode()
+	config.SchedulerMode = loader.ChipletScheduler()
+	config.WeightPrefetch = loader.ChipletWeightPrefetch()
+	config.RramWeightCapacity = loader.ChipletRramWeightCapacity()
+	config.ActivationOffload = loader.ChipletActivationOffload()
+	config.EnergyCalibrationPath = loader.ChipletEnergyCalibration()
+	config.Dvfs = loader.ChipletDvfs()
+	config.DvfsLevels = loader.ChipletDvfsLevels()
+	config.DvfsWindow = loader.ChipletDvfsWindow()
+	config.DvfsLowUtil = loader.ChipletDvfsLowUtil()
+	config.DvfsHighUtil = loader.ChipletDvfsHighUtil()
+	config.TransferMinLatency = loader.ChipletTransferMinLatency()
+	config.PipelineChecksum = loader.ChipletPipelineChecksum()
+	config.RramWeightLayout = loader.ChipletRramWeightLayout()
+	config.ReductionCostModel = loader.ChipletReductionCostModel()
+	config.NocBooksimCacheSize = loader.ChipletNocBooksimCacheSize()
+	config.NocBooksimCacheBucket = loader.ChipletNocBooksimCacheBucket()
+	config.NocBooksimCacheTtl = loader.ChipletNocBooksimCacheTtl()
+	config.GqaGroupSize = loader.ChipletGqaGroupSize()
+	config.KvHeads = loader.ChipletKvHeads()
+	config.Summary = loader.ChipletSummary()
+	config.RramOutputWriteBw = loader.ChipletRramOutputWriteBw()
+	config.RngSeed = loader.ChipletRngSeed()
+	config.CmdFetchLatency = loader.ChipletCmdFetchLatency()
+	config.MaxMoeSessions = loader.ChipletMaxMoeSessions()
+	config.SchedulerCompare = ParseSchedulerCompare(loader.ChipletSchedulerCompare())
+	config.CycleOrder = loader.ChipletCycleOrder()
+	config.MoeSkipEmptyExperts = loader.ChipletMoeSkipEmptyExperts()
+	config.ActivationBanks = loader.ChipletActivationBanks()
+	config.BootstrapIters = loader.ChipletBootstrapIters()
+	config.RramTileDepth = loader.ChipletRramTileDepth()
+	config.RramPsumLanes = loader.ChipletRramPsumLanes()
+	config.SnapshotInterval = loader.ChipletSnapshotInterval()
+	config.RramWeightLoadBw = loader.ChipletRramWeightLoadBw()
+	config.HostDispatchPerCycle = loader.ChipletHostDispatchPerCycle()
+	config.DigitalRawLatency = loader.ChipletDigitalRawLatency()
+	config.DigitalIssueWidth = loader.ChipletDigitalIssueWidth()
+	config.TransferPriority = loader.ChipletTransferPriority()
+	config.MoeAlltoallBw = loader.ChipletMoeAlltoallBw()
+	config.BufferWarnings = loader.ChipletBufferWarnings()
+	config.TransferMode = loader.ChipletTransferMode()
+	config.RramDequantLanes = loader.ChipletRramDequantLanes()
+	config.RramWeightBits = loader.ChipletRramWeightBits()
+	config.IdlePowerdownCycles = loader.ChipletIdlePowerdownCycles()
+	config.WakeupLatency = loader.ChipletWakeupLatency()
+	config.HalfDuplex = loader.ChipletHalfDuplex()
+	config.RetirementLatency = loader.ChipletRetirementLatency()
+	config.ModelPath = loader.ChipletModelPath()
+	config.MaxCycles = loader.ChipletMaxCycles()
+	config.ConfirmUnlimited = loader.ChipletConfirmUnlimited()
+	config.Profile = loader.ChipletProfile()
+	config.BarrierProducerCycles = loader.ChipletBarrierCyclesPerProducer()
+	config.DumpFinalGraph = loader.ChipletDumpFinalGraph()
+	config.StrictCommands = loader.ChipletStrictCommands()
+	config.PrefetchDistance = loader.ChipletPrefetchDistance()
+	config.RramCrossCheck = loader.ChipletRramCrossCheck()
+	config.BroadcastCycles = loader.ChipletCollectiveBroadcastCycles()
+	config.GatingSoftmaxOps = loader.ChipletMoeGatingSoftmaxOps()
+	config.EccOverheadPct = loader.ChipletEccOverheadPct()
+	config.TransferPipelineChunks = loader.ChipletTransferPipelineChunks()
+	config.MaxInflightTransfers = loader.ChipletMaxInflightTransfers()
+	config.TransposeStridePenalty = loader.ChipletTransposeStridePenalty()
 
 	return config
 }
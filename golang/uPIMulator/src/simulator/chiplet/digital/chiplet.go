@@ -68,6 +68,17 @@ type TaskDescriptor struct {
 	TargetBuffer     string
 	BufferBytes      int64
 	PeConcurrency    int
+	// ConversionOps 为输出写回前的 dtype 转换向量操作数（如 FP32 累加器→FP16），在 SPU 上执行。
+	ConversionOps int
+	// EnergyScale 为按命令类型的能耗校准系数，0 表示未校准（等同 1）。
+	EnergyScale float64
+	// SourceBuffer 为 load 阶段读取的片上 buffer（如前一任务写回的 scratch），空表示从片外读入。
+	SourceBuffer string
+	// Wasted 标记投机/最终被丢弃的工作，其能耗与周期另计入 WastedEnergyPJ/WastedCycles。
+	Wasted bool
+	// StrideFactor 为跨步访存（如转置）相对连续访存的有效带宽折损倍数，load/store 周期按此放大；
+	// 不大于 1 表示连续访问。
+	StrideFactor float64
 }
 
 type taskPhase int
@@ -108,6 +119,7 @@ type digitalTask struct {
 	computeCycleConsumed bool
 	macCount             int64
 	peConcurrency        int
+	energyScale          float64
 
 	spuActiveClusters int
 	spuCycleConsumed  bool
@@ -115,6 +127,8 @@ type digitalTask struct {
 	vectorOps         int
 	specialOps        int
 	vpuOps            int
+	conversionOps     int
+	conversionCycles  int
 	registersRd       int
 	registersWr       int
 	vpuActiveUnits    int
@@ -129,7 +143,21 @@ type digitalTask struct {
 	writebackActive   bool
 	targetBuffer      string
 	storeBuffer       string
+	sourceBuffer      string
 	bufferBytes       int64
+	spillBytes        int64
+	spillRemaining    int
+
+	activationBanks    []int
+	activationReleased bool
+	inputsReserved     bool
+
+	wasted       bool
+	energyPJ     float64
+	startCycle   int
+	strideFactor float64
+
+	dispatchLimitedCycles int
 }
 
 type computeCluster struct {
@@ -174,6 +202,16 @@ type computeCluster struct {
 	spuActiveThisCycle      int
 	vpuActiveThisCycle      int
 	tasksCompletedThisCycle int
+	usefulThisCycle         bool
+	loadThisCycle           bool
+	activationBankBytes     int64
+	activationBankBusy      []bool
+	rawLatency              int
+	issueWidth              int
+	rawReadyAt              map[string]int
+	prefetchDistance        int
+	prefetchThisCycle       bool
+	localCycle              int
 	totalLoadBytes          int64
 	totalStoreBytes         int64
 	parent                  *Chiplet
@@ -241,6 +279,13 @@ func newComputeClusterWithOffsets(
 		"scratch":    NewBuffer(fmt.Sprintf("Cluster%dScratch", id), scratchBuffer, storeBW),
 	}
 
+	var activationBankBusy []bool
+	activationBankBytes := int64(0)
+	if banks := params.Buffer.ActivationBanks; banks > 1 {
+		activationBankBusy = make([]bool, banks)
+		activationBankBytes = activationBuffer / int64(banks)
+	}
+
 	return &computeCluster{
 		id:             id,
 		peArrays:       peArrays,
@@ -277,6 +322,13 @@ func newComputeClusterWithOffsets(
 		loadBandwidth:  loadBW,
 		storeBandwidth: storeBW,
 		parent:         parent,
+
+		activationBankBytes: activationBankBytes,
+		activationBankBusy:  activationBankBusy,
+		rawLatency:          params.Buffer.RawLatencyCycles,
+		issueWidth:          params.Spu.IssueWidth,
+		rawReadyAt:          make(map[string]int),
+		prefetchDistance:    params.Buffer.PrefetchDistance,
 	}
 }
 
@@ -319,14 +371,102 @@ func (cluster *computeCluster) canAcceptDescriptor(desc *TaskDescriptor) bool {
 	if desc.WeightBytes > cluster.bufferCapacity("weights") {
 		return false
 	}
-	if desc.OutputBytes > cluster.bufferCapacity("scratch") {
+	if desc.OutputBytes > cluster.bufferCapacity("scratch")+cluster.l2Free() {
 		return false
 	}
 	return true
 }
 
+// WorkingSetShortfall describes a cluster buffer that cannot hold one task's
+// working set. RecommendedBytes is the chiplet-level size (summed over
+// clusters) that would let every cluster accept the task.
+type WorkingSetShortfall struct {
+	Buffer           string
+	CapacityBytes    int64
+	RequiredBytes    int64
+	RecommendedBytes int64
+}
+
+// WorkingSetShortfalls runs the canAcceptDescriptor comparisons against the
+// smallest cluster of an empty chiplet. Any shortfall means such a task can
+// never be accepted and the run would stall.
+func (c *Chiplet) WorkingSetShortfalls(desc *TaskDescriptor) []WorkingSetShortfall {
+	if desc == nil || len(c.clusters) == 0 {
+		return nil
+	}
+	clusters := int64(len(c.clusters))
+	l2 := int64(0)
+	if c.l2 != nil {
+		l2 = c.l2.Capacity()
+	}
+	minCapacity := func(name string) int64 {
+		capacity := int64(-1)
+		for _, cluster := range c.clusters {
+			if value := cluster.bufferCapacity(name); capacity < 0 || value < capacity {
+				capacity = value
+			}
+		}
+		return capacity
+	}
+
+	shortfalls := make([]WorkingSetShortfall, 0)
+	check := func(name string, required int64, spare int64) {
+		capacity := minCapacity(name)
+		if required <= capacity+spare {
+			return
+		}
+		shortfalls = append(shortfalls, WorkingSetShortfall{
+			Buffer:           name,
+			CapacityBytes:    capacity,
+			RequiredBytes:    required,
+			RecommendedBytes: (required - spare) * clusters,
+		})
+	}
+	check("activation", desc.InputBytes, 0)
+	check("weights", desc.WeightBytes, 0)
+	check("scratch", desc.OutputBytes, l2)
+	return shortfalls
+}
+
+func (cluster *computeCluster) l2Free() int64 {
+	if cluster.parent == nil || cluster.parent.l2 == nil {
+		return 0
+	}
+	return cluster.parent.l2.Capacity() - cluster.parent.l2.Occupancy()
+}
+
+// reserveWithSpill places as much of the output as fits into scratch and
+// pushes the overflow to the chiplet-level L2. The overflow drains at L2
+// bandwidth, which is charged as stall cycles on the task's store phase.
+func (cluster *computeCluster) reserveWithSpill(task *digitalTask, buffer *Buffer) bool {
+	chiplet := cluster.parent
+	if chiplet == nil || chiplet.l2 == nil || buffer == nil {
+		return false
+	}
+	free := buffer.Capacity() - buffer.Occupancy()
+	if free < 0 {
+		free = 0
+	}
+	overflow := task.outputBytes - free
+	if overflow <= 0 || !chiplet.l2.Reserve(overflow) {
+		return false
+	}
+	if free > 0 && !buffer.Reserve(free) {
+		chiplet.l2.Release(overflow)
+		return false
+	}
+	task.spillBytes = overflow
+	task.spillRemaining = chiplet.l2.TransferCycles(overflow)
+	task.writebackBytes = free
+	chiplet.ScratchSpillBytes += overflow
+	spillEnergy := float64(overflow) * chiplet.params.Buffer.L2EnergyPJPerByte
+	chiplet.DynamicEnergyPJ += spillEnergy
+	task.energyPJ += spillEnergy
+	return true
+}
+
 func (cluster *computeCluster) processLoad(chiplet *Chiplet) bool {
-	if len(cluster.loadActive) == 0 {
+	if len(cluster.loadActive) == 0 && (cluster.prefetchDistance <= 0 || cluster.totalWaiting() == 0) {
 		return false
 	}
 
@@ -338,12 +478,19 @@ func (cluster *computeCluster) processLoad(chiplet *Chiplet) bool {
 	progress := false
 	bytesTransferred := int64(0)
 
+	rawStalled := false
 	next := cluster.loadActive[:0]
 	for _, task := range cluster.loadActive {
 		if remaining <= 0 {
 			next = append(next, task)
 			continue
 		}
+		if cluster.rawHazard(task) {
+			// 源 buffer 中的写回尚未排空，读取需等待。
+			rawStalled = true
+			next = append(next, task)
+			continue
+		}
 		if debugDigitalEvents < debugDigitalEventLimit {
 			debugDigitalEvents++
 			fmt.Printf("[chiplet-debug] load cluster=%d task=%s loadRemaining=%d loadProgress=%d totalLoad=%d activation=%d weight=%d\n",
@@ -372,6 +519,19 @@ func (cluster *computeCluster) processLoad(chiplet *Chiplet) bool {
 		}
 	}
 	cluster.loadActive = next
+	if remaining > 0 && cluster.prefetchDistance > 0 {
+		// 当前任务的 load 用剩的带宽提前搬运排队任务的数据。
+		if prefetched := cluster.prefetchWaiting(chiplet, &remaining); prefetched > 0 {
+			progress = true
+			bytesTransferred += prefetched
+		}
+	}
+	if rawStalled {
+		if chiplet != nil {
+			chiplet.RawHazardStallCycles++
+		}
+		progress = true
+	}
 	if bytesTransferred > 0 {
 		cluster.loadBytesThisCycle += bytesTransferred
 		cluster.totalLoadBytes += bytesTransferred
@@ -382,6 +542,44 @@ func (cluster *computeCluster) processLoad(chiplet *Chiplet) bool {
 	return progress
 }
 
+// prefetchWaiting 按晋升顺序为前 prefetchDistance 个等待任务预取 load 数据，
+// 返回本周期预取的字节数。任务激活时若数据已全部就位则直接跳过 load 阶段。
+func (cluster *computeCluster) prefetchWaiting(chiplet *Chiplet, budget *int64) int64 {
+	queues := [][]*digitalTask{
+		cluster.waitingBuffer,
+		cluster.waitingBarrier,
+		cluster.waitingPe,
+		cluster.waitingVpu,
+		cluster.waitingSpu,
+		cluster.waitingMisc,
+	}
+	slots := cluster.prefetchDistance
+	prefetched := int64(0)
+	for _, queue := range queues {
+		for _, task := range queue {
+			if slots <= 0 || *budget <= 0 {
+				break
+			}
+			slots--
+			if task.loadProgress >= task.totalLoadBytes || cluster.rawHazard(task) {
+				continue
+			}
+			if !cluster.reserveInputs(task) {
+				// 输入 buffer 没有空间容纳预取数据。
+				continue
+			}
+			prefetched += cluster.consumeLoad(task, budget)
+		}
+	}
+	if prefetched > 0 {
+		cluster.prefetchThisCycle = true
+		if chiplet != nil {
+			chiplet.PrefetchBytes += prefetched
+		}
+	}
+	return prefetched
+}
+
 func (cluster *computeCluster) processCompute(chiplet *Chiplet) bool {
 	if len(cluster.computeActive) == 0 {
 		return false
@@ -473,6 +671,16 @@ func (cluster *computeCluster) processStore(chiplet *Chiplet) bool {
 				task.writebackBytes,
 			)
 		}
+		if task.spillRemaining > 0 {
+			// overflow still draining to L2; the store path waits.
+			task.spillRemaining--
+			if chiplet != nil {
+				chiplet.SpillStallCycles++
+			}
+			progress = true
+			next = append(next, task)
+			continue
+		}
 		before := remaining
 		consumed := cluster.consumeStore(task, &remaining)
 		if consumed > 0 {
@@ -619,6 +827,10 @@ func (cluster *computeCluster) queuePostCompute(task *digitalTask, chiplet *Chip
 
 	task.computeRemaining = 0
 	task.computeCycleConsumed = false
+	if len(cluster.activationBankBusy) > 0 {
+		// 激活已被 compute 读完，bank 交给下一个 tile 的 load。
+		cluster.releaseActivation(task)
+	}
 	if task.totalStoreBytes > 0 && task.storeRemaining > 0 {
 		task.writebackActive = true
 	}
@@ -635,9 +847,25 @@ func (cluster *computeCluster) queuePostStore(task *digitalTask, chiplet *Chiple
 	if chiplet != nil {
 		chiplet.addStoreEnergyForTask(task)
 	}
+	if cluster.rawLatency > 0 && task.totalStoreBytes > 0 {
+		dest := task.storeBuffer
+		if dest == "" {
+			dest = "scratch"
+		}
+		cluster.rawReadyAt[dest] = cluster.localCycle + 1 + cluster.rawLatency
+	}
 	cluster.scheduleNextPhase(task)
 }
 
+// rawHazard reports whether task would start reading a buffer whose most
+// recent store has not drained yet. Only the first read of a load is checked.
+func (cluster *computeCluster) rawHazard(task *digitalTask) bool {
+	if cluster.rawLatency <= 0 || task.sourceBuffer == "" || task.loadProgress > 0 {
+		return false
+	}
+	return cluster.localCycle < cluster.rawReadyAt[task.sourceBuffer]
+}
+
 func (cluster *computeCluster) enqueueTask(task *digitalTask) {
 	if task == nil {
 		return
@@ -647,6 +875,7 @@ func (cluster *computeCluster) enqueueTask(task *digitalTask) {
 	} else {
 		debugDigitalEvents = 0
 	}
+	task.startCycle = cluster.localCycle
 	if task.remainingCycles() <= 0 {
 		cluster.finishTask(task, cluster.parent)
 		return
@@ -715,6 +944,12 @@ func (cluster *computeCluster) activateTask(task *digitalTask) {
 	case task.loadRemaining > 0:
 		task.currentPhase = taskPhaseLoad
 		cluster.loadActive = append(cluster.loadActive, task)
+	case task.totalLoadBytes > 0 && task.loadProgress >= task.totalLoadBytes:
+		// 数据已在等待期间预取完成，补记 load 能耗后直接进入下一阶段。
+		if cluster.parent != nil {
+			cluster.parent.PrefetchHits++
+		}
+		cluster.queuePostLoad(task, cluster.parent)
 	default:
 		cluster.scheduleNextPhase(task)
 	}
@@ -758,6 +993,51 @@ func (cluster *computeCluster) reserveResources(task *digitalTask) bool {
 	}
 
 	task.writebackBytes = 0
+	if !cluster.reserveInputs(task) {
+		return false
+	}
+
+	if task.outputBytes > 0 {
+		dest := strings.ToLower(strings.TrimSpace(task.storeBuffer))
+		if dest == "" {
+			dest = "scratch"
+			if task.targetBuffer != "" {
+				dest = strings.ToLower(strings.TrimSpace(task.targetBuffer))
+			}
+		}
+		buffer := cluster.buffer(dest)
+		if buffer != nil {
+			if !buffer.Reserve(task.outputBytes) && !(dest == "scratch" && cluster.reserveWithSpill(task, buffer)) {
+				fmt.Printf("[chiplet-debug] cluster %d %s reserve failed: req=%d cap=%d occ=%d\n",
+					cluster.id,
+					dest,
+					task.outputBytes,
+					buffer.Capacity(),
+					buffer.Occupancy(),
+				)
+				if task.loadProgress == 0 {
+					// 尚未预取任何数据时归还输入空间；已预取的数据继续占用其预留。
+					cluster.releaseInputs(task)
+				}
+				return false
+			}
+			task.storeBuffer = dest
+			if task.spillBytes <= 0 {
+				task.writebackBytes = task.outputBytes
+			}
+		}
+	}
+
+	return true
+}
+
+// reserveInputs 为任务预留激活与权重 buffer 空间；预取等待任务的 load 数据前也需先
+// 取得这部分预留，保证搬入的数据有处存放。已预留时直接返回成功。
+func (cluster *computeCluster) reserveInputs(task *digitalTask) bool {
+	if task.inputsReserved {
+		return true
+	}
+	task.activationReleased = false
 
 	if task.activationBytes > 0 {
 		buffer := cluster.buffer("activation")
@@ -772,6 +1052,12 @@ func (cluster *computeCluster) reserveResources(task *digitalTask) bool {
 				return false
 			}
 		}
+		if !cluster.acquireActivationBank(task) {
+			if buffer != nil {
+				buffer.Release(task.activationBytes)
+			}
+			return false
+		}
 	}
 
 	if task.weightBytes > 0 {
@@ -784,64 +1070,90 @@ func (cluster *computeCluster) reserveResources(task *digitalTask) bool {
 					buffer.Capacity(),
 					buffer.Occupancy(),
 				)
-				if task.activationBytes > 0 {
-					if act := cluster.buffer("activation"); act != nil {
-						act.Release(task.activationBytes)
-					}
-				}
+				cluster.releaseActivation(task)
 				return false
 			}
 		}
 	}
 
-	if task.outputBytes > 0 {
-		dest := strings.ToLower(strings.TrimSpace(task.storeBuffer))
-		if dest == "" {
-			dest = "scratch"
-			if task.targetBuffer != "" {
-				dest = strings.ToLower(strings.TrimSpace(task.targetBuffer))
-			}
+	task.inputsReserved = true
+	return true
+}
+
+// releaseInputs 归还 reserveInputs 取得的激活与权重空间。
+func (cluster *computeCluster) releaseInputs(task *digitalTask) {
+	if !task.inputsReserved {
+		return
+	}
+	if task.weightBytes > 0 {
+		if w := cluster.buffer("weights"); w != nil {
+			w.Release(task.weightBytes)
 		}
-		buffer := cluster.buffer(dest)
-		if buffer != nil {
-			if !buffer.Reserve(task.outputBytes) {
-				fmt.Printf("[chiplet-debug] cluster %d %s reserve failed: req=%d cap=%d occ=%d\n",
-					cluster.id,
-					dest,
-					task.outputBytes,
-					buffer.Capacity(),
-					buffer.Occupancy(),
-				)
-				if task.weightBytes > 0 {
-					if w := cluster.buffer("weights"); w != nil {
-						w.Release(task.weightBytes)
-					}
-				}
-				if task.activationBytes > 0 {
-					if act := cluster.buffer("activation"); act != nil {
-						act.Release(task.activationBytes)
-					}
-				}
-				return false
+	}
+	cluster.releaseActivation(task)
+	task.inputsReserved = false
+}
+
+// acquireActivationBank 在分 bank 模式下为任务分配空闲的激活 bank；超过单个 bank
+// 的 tile 需同时占用多个 bank，失去与后续 load 的重叠。单一缓冲池模式下直接成功。
+func (cluster *computeCluster) acquireActivationBank(task *digitalTask) bool {
+	banks := len(cluster.activationBankBusy)
+	if banks == 0 {
+		return true
+	}
+	need := 1
+	if cluster.activationBankBytes > 0 {
+		need = int((task.activationBytes + cluster.activationBankBytes - 1) / cluster.activationBankBytes)
+	}
+	if need > banks {
+		need = banks
+	}
+	free := make([]int, 0, need)
+	for bank, busy := range cluster.activationBankBusy {
+		if !busy {
+			free = append(free, bank)
+			if len(free) == need {
+				break
 			}
-			task.storeBuffer = dest
-			task.writebackBytes = task.outputBytes
 		}
 	}
-
+	if len(free) < need {
+		return false
+	}
+	for _, bank := range free {
+		cluster.activationBankBusy[bank] = true
+	}
+	task.activationBanks = free
+	if need > 1 && cluster.parent != nil {
+		cluster.parent.ActivationBankOverflows++
+	}
 	return true
 }
 
-func (cluster *computeCluster) releaseResources(task *digitalTask) {
-	if task == nil {
+// releaseActivation 归还任务的激活字节及其 bank；compute 结束时提前释放后，
+// 任务完成时的再次调用不会重复归还。
+func (cluster *computeCluster) releaseActivation(task *digitalTask) {
+	if task.activationReleased {
 		return
 	}
-
 	if task.activationBytes > 0 {
 		if buffer := cluster.buffer("activation"); buffer != nil {
 			buffer.Release(task.activationBytes)
 		}
 	}
+	for _, bank := range task.activationBanks {
+		cluster.activationBankBusy[bank] = false
+	}
+	task.activationBanks = nil
+	task.activationReleased = true
+}
+
+func (cluster *computeCluster) releaseResources(task *digitalTask) {
+	if task == nil {
+		return
+	}
+
+	cluster.releaseActivation(task)
 
 	if task.weightBytes > 0 {
 		if buffer := cluster.buffer("weights"); buffer != nil {
@@ -860,6 +1172,14 @@ func (cluster *computeCluster) releaseResources(task *digitalTask) {
 		task.writebackBytes = 0
 	}
 
+	if task.spillBytes > 0 {
+		if cluster.parent != nil && cluster.parent.l2 != nil {
+			cluster.parent.l2.Release(task.spillBytes)
+		}
+		task.spillBytes = 0
+		task.spillRemaining = 0
+	}
+
 	if bytes := task.bufferBytes; bytes > 0 {
 		switch strings.ToLower(task.targetBuffer) {
 		case "activation", "activations":
@@ -988,6 +1308,13 @@ func (cluster *computeCluster) finishTask(task *digitalTask, chiplet *Chiplet) {
 			chiplet.SpuSpecialOps += int64(task.specialOps)
 		}
 	}
+	if task.conversionOps > 0 && chiplet != nil {
+		chiplet.DtypeConversionOps += int64(task.conversionOps)
+		chiplet.DtypeConversionCycles += int64(task.conversionCycles)
+	}
+	if task.dispatchLimitedCycles > 0 && chiplet != nil {
+		chiplet.DispatchLimitedCycles += int64(task.dispatchLimitedCycles)
+	}
 
 	cluster.executedTasks++
 	if cluster.pendingCycles < 0 {
@@ -1005,6 +1332,10 @@ func (cluster *computeCluster) finishTask(task *digitalTask, chiplet *Chiplet) {
 	}
 	if chiplet != nil {
 		chiplet.recordTaskEnergy(task)
+		if task.wasted {
+			chiplet.WastedEnergyPJ += task.energyPJ
+			chiplet.WastedCycles += int64(cluster.localCycle - task.startCycle)
+		}
 	}
 	task.currentPhase = taskPhaseComplete
 	cluster.promoteWaiting()
@@ -1039,12 +1370,12 @@ func (cluster *computeCluster) consumeLoad(task *digitalTask, budget *int64) int
 	if bandwidth <= 0 {
 		bandwidth = 2048
 	}
-	transfer := int64(bandwidth)
+	transfer := stridedBytes(int64(bandwidth), task.strideFactor)
 	if transfer > remaining {
 		transfer = remaining
 	}
-	if budget != nil && transfer > *budget {
-		transfer = *budget
+	if budget != nil && transfer > stridedBytes(*budget, task.strideFactor) {
+		transfer = stridedBytes(*budget, task.strideFactor)
 	}
 	if transfer <= 0 {
 		return 0
@@ -1066,7 +1397,7 @@ func (cluster *computeCluster) consumeLoad(task *digitalTask, budget *int64) int
 	}
 
 	if budget != nil {
-		*budget -= transfer
+		*budget -= stridedBudget(transfer, task.strideFactor)
 		if *budget < 0 {
 			*budget = 0
 		}
@@ -1094,12 +1425,12 @@ func (cluster *computeCluster) consumeStore(task *digitalTask, budget *int64) in
 	if bandwidth <= 0 {
 		bandwidth = 4096
 	}
-	transfer := int64(bandwidth)
+	transfer := stridedBytes(int64(bandwidth), task.strideFactor)
 	if transfer > remaining {
 		transfer = remaining
 	}
-	if budget != nil && transfer > *budget {
-		transfer = *budget
+	if budget != nil && transfer > stridedBytes(*budget, task.strideFactor) {
+		transfer = stridedBytes(*budget, task.strideFactor)
 	}
 	if transfer <= 0 {
 		return 0
@@ -1143,7 +1474,7 @@ func (cluster *computeCluster) consumeStore(task *digitalTask, budget *int64) in
 	}
 
 	if budget != nil {
-		*budget -= transfer
+		*budget -= stridedBudget(transfer, task.strideFactor)
 		if *budget < 0 {
 			*budget = 0
 		}
@@ -1159,6 +1490,8 @@ func (cluster *computeCluster) tick(chiplet *Chiplet) (int, bool) {
 	cluster.spuActiveThisCycle = 0
 	cluster.vpuActiveThisCycle = 0
 	cluster.tasksCompletedThisCycle = 0
+	cluster.prefetchThisCycle = false
+	cluster.localCycle++
 	cluster.promoteWaiting()
 
 	loadProgress := cluster.processLoad(chiplet)
@@ -1168,6 +1501,8 @@ func (cluster *computeCluster) tick(chiplet *Chiplet) (int, bool) {
 	vpuProgress := cluster.processVpu(chiplet)
 
 	progress := loadProgress || computeProgress || storeProgress || spuProgress || vpuProgress
+	cluster.usefulThisCycle = computeProgress || spuProgress || vpuProgress
+	cluster.loadThisCycle = loadProgress
 	if progress && cluster.pendingCycles > 0 {
 		cluster.pendingCycles--
 		if cluster.pendingCycles < 0 {
@@ -1207,7 +1542,11 @@ func (cluster *computeCluster) buildTaskFromDescriptor(desc *TaskDescriptor, tas
 		requiresVpu:     desc.RequiresVpu,
 		targetBuffer:    desc.TargetBuffer,
 		storeBuffer:     strings.ToLower(strings.TrimSpace(desc.TargetBuffer)),
+		sourceBuffer:    strings.ToLower(strings.TrimSpace(desc.SourceBuffer)),
+		wasted:          desc.Wasted,
 		bufferBytes:     desc.BufferBytes,
+		energyScale:     desc.EnergyScale,
+		strideFactor:    desc.StrideFactor,
 	}
 
 	task.totalLoadBytes = desc.InputBytes + desc.WeightBytes
@@ -1383,6 +1722,31 @@ func (cluster *computeCluster) buildTaskFromDescriptor(desc *TaskDescriptor, tas
 		task.specialOps = 0
 	}
 
+	if desc.ConversionOps > 0 {
+		// 转换 pass 复用 SPU 向量通路：按元素一次向量操作，额外占用 SPU 阶段周期。
+		cycles, activeClusters := cluster.estimateSpuWork(&TaskDescriptor{VectorOps: desc.ConversionOps})
+		task.spuRemaining += cycles
+		if activeClusters > task.spuActiveClusters {
+			task.spuActiveClusters = activeClusters
+		}
+		task.vectorOps += desc.ConversionOps
+		task.conversionOps = desc.ConversionOps
+		task.conversionCycles = cycles
+	}
+
+	// 前端派发由 SPU 与 VPU 共享：若派发全部操作所需周期超过两者执行周期之和，
+	// 差值计入 SPU 阶段（无 SPU 阶段时计入 VPU 阶段）。
+	if executeCycles := task.spuRemaining + task.vpuRemaining; executeCycles > 0 {
+		if dispatchCycles := cluster.dispatchCycles(desc); dispatchCycles > executeCycles {
+			task.dispatchLimitedCycles = dispatchCycles - executeCycles
+			if task.spuRemaining > 0 {
+				task.spuRemaining += task.dispatchLimitedCycles
+			} else {
+				task.vpuRemaining += task.dispatchLimitedCycles
+			}
+		}
+	}
+
 	switch {
 	case task.loadRemaining > 0:
 		task.currentPhase = taskPhaseLoad
@@ -1409,7 +1773,7 @@ func (cluster *computeCluster) estimateLoadCycles(desc *TaskDescriptor) int {
 	total := 0
 	total += cluster.transferCyclesForBuffer("activation", desc.InputBytes, 2048)
 	total += cluster.transferCyclesForBuffer("weights", desc.WeightBytes, 2048)
-	return total
+	return stridedCycles(total, desc.StrideFactor)
 }
 
 func (cluster *computeCluster) estimateStoreCycles(desc *TaskDescriptor) int {
@@ -1420,7 +1784,33 @@ func (cluster *computeCluster) estimateStoreCycles(desc *TaskDescriptor) int {
 	if buffer == "" {
 		buffer = "scratch"
 	}
-	return cluster.transferCyclesForBuffer(buffer, desc.OutputBytes, 4096)
+	return stridedCycles(cluster.transferCyclesForBuffer(buffer, desc.OutputBytes, 4096), desc.StrideFactor)
+}
+
+func stridedCycles(cycles int, factor float64) int {
+	if factor <= 1 || cycles <= 0 {
+		return cycles
+	}
+	return int(math.Ceil(float64(cycles) * factor))
+}
+
+// stridedBytes 返回跨步访存在给定带宽预算下实际能搬运的字节数（有效带宽 = 预算 / factor）。
+func stridedBytes(budget int64, factor float64) int64 {
+	if factor <= 1 || budget <= 0 {
+		return budget
+	}
+	if bytes := int64(float64(budget) / factor); bytes > 0 {
+		return bytes
+	}
+	return 1
+}
+
+// stridedBudget 返回跨步搬运 bytes 字节所占用的端口带宽。
+func stridedBudget(bytes int64, factor float64) int64 {
+	if factor <= 1 || bytes <= 0 {
+		return bytes
+	}
+	return int64(math.Ceil(float64(bytes) * factor))
 }
 
 func (cluster *computeCluster) transferCyclesForBuffer(name string, bytes int64, fallbackBandwidth int64) int {
@@ -1556,6 +1946,16 @@ func (cluster *computeCluster) estimateSpuWork(desc *TaskDescriptor) (int, int)
 	return cycles, requiredClusters
 }
 
+// dispatchCycles 返回在 issueWidth 限制下派发全部 scalar/vector/special（含数据类型转换）
+// 操作所需的周期数，不区分这些操作最终落在 SPU 还是 VPU；issueWidth<=0 时不限制，返回 0。
+func (cluster *computeCluster) dispatchCycles(desc *TaskDescriptor) int {
+	if cluster.issueWidth <= 0 {
+		return 0
+	}
+	ops := desc.ScalarOps + desc.VectorOps + desc.SpecialOps + desc.ConversionOps
+	return (ops + cluster.issueWidth - 1) / cluster.issueWidth
+}
+
 func (cluster *computeCluster) estimateVpuWork(desc *TaskDescriptor) (int, int) {
 	ops := desc.VectorOps
 	if ops <= 0 {
@@ -1863,7 +2263,35 @@ type Chiplet struct {
 	CycleTasksCompleted int
 	TotalLoadBytes      int64
 	TotalStoreBytes     int64
-
+	ScratchSpillBytes   int64
+	SpillStallCycles    int64
+	// DtypeConversionOps/Cycles 累计输出 dtype 转换 pass 的操作数与 SPU 周期。
+	DtypeConversionOps    int64
+	DtypeConversionCycles int64
+	// UsefulBusyCycles 计入 compute/SPU/VPU 有进展的 cluster 周期，OverheadBusyCycles
+	// 计入只有 load/store 进展的周期，二者之和等于 BusyCycles。
+	UsefulBusyCycles   int
+	OverheadBusyCycles int
+	// LoadComputeOverlapCycles 计入 load 与 compute/SPU/VPU 同时有进展的 cluster 周期，
+	// LoadStallCycles 计入只有 load 进展、计算单元空等的周期。
+	LoadComputeOverlapCycles int64
+	LoadStallCycles          int64
+	// PrefetchHits 统计激活时 load 数据已被预取完毕的任务数，PrefetchBytes 累计为等待任务
+	// 预取的字节，PrefetchOverlapCycles 计入预取与 compute/SPU/VPU 同时进展的 cluster 周期。
+	PrefetchHits          int64
+	PrefetchBytes         int64
+	PrefetchOverlapCycles int64
+	// ActivationBankOverflows 统计激活超过单个 bank、需占用多个 bank 的 tile 数。
+	ActivationBankOverflows int64
+	// RawHazardStallCycles 计入 load 因源 buffer 写回未排空（RAW 冒险）而等待的 cluster 周期。
+	RawHazardStallCycles int64
+	// WastedEnergyPJ/WastedCycles 累计带 Wasted 标记任务的动态能耗与驻留周期。
+	WastedEnergyPJ float64
+	WastedCycles   int64
+	// DispatchLimitedCycles 计入 SPU/VPU 阶段因 issue width 不足而超出执行单元吞吐的周期。
+	DispatchLimitedCycles int64
+
+	l2                   *Buffer
 	params               Parameters
 	DynamicEnergyPJ      float64
 	StaticEnergyPJ       float64
@@ -2003,6 +2431,9 @@ func NewChiplet(
 		vpuOffset += len(cluster.vpuUnits)
 	}
 
+	if params.Buffer.L2Bytes > 0 {
+		chiplet.l2 = NewBuffer(fmt.Sprintf("Chiplet%dL2", id), params.Buffer.L2Bytes, params.Buffer.L2BandwidthBytesPerCycle)
+	}
 	chiplet.clusters = clusters
 	chiplet.PeBusyCycles = make([]int64, totalPe)
 	chiplet.SpuClusterBusy = make([]int64, totalSpu)
@@ -2023,6 +2454,7 @@ func (c *Chiplet) addLoadEnergyForTask(task *digitalTask) {
 	activationEnergy := float64(task.activationBytes) * (c.params.Buffer.ReadEnergyPJPerByte + c.params.PeArray.ActivationReadPJPerByte)
 	weightEnergy := float64(task.weightBytes) * (c.params.Buffer.ReadEnergyPJPerByte + c.params.PeArray.WeightReadPJPerByte)
 	c.DynamicEnergyPJ += activationEnergy + weightEnergy
+	task.energyPJ += activationEnergy + weightEnergy
 }
 
 func (c *Chiplet) addStoreEnergyForTask(task *digitalTask) {
@@ -2038,6 +2470,7 @@ func (c *Chiplet) addStoreEnergyForTask(task *digitalTask) {
 	}
 	energy := float64(storeBytes) * (c.params.Buffer.WriteEnergyPJPerByte + c.params.PeArray.OutputWritePJPerByte)
 	c.DynamicEnergyPJ += energy
+	task.energyPJ += energy
 }
 
 func (c *Chiplet) recordTaskEnergy(task *digitalTask) {
@@ -2050,6 +2483,11 @@ func (c *Chiplet) recordTaskEnergy(task *digitalTask) {
 	spuSpecialEnergy := float64(task.specialOps) * c.params.Spu.SpecialEnergyPJ
 	spuEnergy := spuScalarEnergy + spuVectorEnergy + spuSpecialEnergy
 	vpuEnergy := float64(task.vpuOps) * c.params.Vpu.VectorEnergyPJ
+	if task.energyScale > 0 {
+		peEnergy *= task.energyScale
+		spuEnergy *= task.energyScale
+		vpuEnergy *= task.energyScale
+	}
 
 	if peEnergy > 0 {
 		c.PeEnergyPJ += peEnergy
@@ -2066,6 +2504,7 @@ func (c *Chiplet) recordTaskEnergy(task *digitalTask) {
 	}
 
 	c.DynamicEnergyPJ += peEnergy + spuEnergy + vpuEnergy
+	task.energyPJ += peEnergy + spuEnergy + vpuEnergy
 }
 
 func (c *Chiplet) AddInterconnectEnergy(bytes int64) {
@@ -2079,9 +2518,22 @@ func (c *Chiplet) AccumulateStaticEnergy(cycles int) {
 	if cycles <= 0 {
 		return
 	}
+	c.StaticEnergyPJ += float64(cycles) * c.StaticEnergyPerCyclePJ()
+}
+
+// BufferBandwidths returns the load and store buffer bandwidths, in bytes per
+// cycle, that the chiplet's compute clusters were built with.
+func (c *Chiplet) BufferBandwidths() (int64, int64) {
+	if c == nil || len(c.clusters) == 0 {
+		return 0, 0
+	}
+	return c.clusters[0].loadBandwidth, c.clusters[0].storeBandwidth
+}
+
+// StaticEnergyPerCyclePJ returns the leakage energy charged for one chiplet cycle.
+func (c *Chiplet) StaticEnergyPerCyclePJ() float64 {
 	totalMw := c.params.StaticPowerMw + c.params.Buffer.LeakagePowerMw + c.params.LeakageOverheadMw
-	perCycle := c.energyPerCyclePJ(totalMw)
-	c.StaticEnergyPJ += float64(cycles) * perCycle
+	return c.energyPerCyclePJ(totalMw)
 }
 
 func (c *Chiplet) energyPerCyclePJ(powerMw float64) float64 {
@@ -2157,6 +2609,23 @@ func (c *Chiplet) selectCluster(desc *TaskDescriptor) *computeCluster {
 	return c.clusters[0]
 }
 
+// EstimateSpuCycles 返回描述符在 SPU 阶段的周期估计（不含搬运），供平台按算子统计开销。
+func (c *Chiplet) EstimateSpuCycles(desc *TaskDescriptor) int {
+	if desc == nil || len(c.clusters) == 0 {
+		return 0
+	}
+	cycles, _ := c.clusters[0].estimateSpuWork(desc)
+	return cycles
+}
+
+// EstimateMemoryCycles 返回描述符 load 与 store 阶段的周期估计（含跨步访存折损）。
+func (c *Chiplet) EstimateMemoryCycles(desc *TaskDescriptor) int {
+	if desc == nil {
+		return 0
+	}
+	return c.estimateLoadCycles(desc) + c.estimateStoreCycles(desc)
+}
+
 // RecordComputeTask is kept for backwards compatibility with Phase 2 callers.
 // The new pipeline accounts for executions automatically, so this becomes a
 // no-op.
@@ -2218,6 +2687,19 @@ func (c *Chiplet) Tick() {
 		cyclesConsumed += consumed
 		if busy {
 			busyClusters++
+			if cluster.usefulThisCycle {
+				c.UsefulBusyCycles++
+			} else {
+				c.OverheadBusyCycles++
+			}
+			if cluster.loadThisCycle && cluster.usefulThisCycle {
+				c.LoadComputeOverlapCycles++
+			} else if cluster.loadThisCycle {
+				c.LoadStallCycles++
+			}
+			if cluster.prefetchThisCycle && cluster.usefulThisCycle {
+				c.PrefetchOverlapCycles++
+			}
 		}
 		c.CycleLoadBytes += cluster.loadBytesThisCycle
 		c.CycleStoreBytes += cluster.storeBytesThisCycle
@@ -2314,8 +2796,9 @@ func (c *Chiplet) BufferUsage(name string) int64 {
 	return total
 }
 
-// bufferCapacity exposes the configured capacity for a buffer.
-func (c *Chiplet) bufferCapacity(name string) int64 {
+// BufferCapacity exposes the configured capacity for a buffer.
+func (c *Chiplet) BufferCapacity(name string) int64 {
+	name = strings.ToLower(name)
 	var total int64
 	for _, cluster := range c.clusters {
 		total += cluster.bufferCapacity(name)
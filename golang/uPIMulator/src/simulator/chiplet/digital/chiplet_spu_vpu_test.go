@@ -116,3 +116,419 @@ func TestChipletExecutesReduceTaskViaSpu(t *testing.T) {
 		t.Fatalf("expected SPU energy to remain zero for reduce task, got %.6f", chiplet.SpuEnergyPJ)
 	}
 }
+
+func TestChipletSpillsScratchOverflowToL2(t *testing.T) {
+	params := DefaultParameters()
+	params.Buffer.L2Bytes = 1 << 20
+	params.Buffer.L2BandwidthBytesPerCycle = 256
+	// 单 cluster，scratch 4KB，输出 6KB 时溢出 2KB 到 L2。
+	chiplet := NewChiplet(0, 1, 16, 16, 1, 1<<16, 4096, params)
+
+	desc := &TaskDescriptor{
+		Kind:             TaskKindElementwise,
+		Description:      "spill_unit_test",
+		ExecUnit:         ExecUnitSpu,
+		VectorOps:        64,
+		OutputBytes:      6144,
+		RequiresSpu:      true,
+		PreferredCluster: 0,
+	}
+	if !chiplet.SubmitDescriptor(desc) {
+		t.Fatalf("SubmitDescriptor failed")
+	}
+
+	tickUntilIdle(t, chiplet, 4096)
+
+	if chiplet.ExecutedTasks != 1 {
+		t.Fatalf("expected 1 executed task, got %d", chiplet.ExecutedTasks)
+	}
+	if chiplet.ScratchSpillBytes != 2048 {
+		t.Fatalf("expected 2048 spilled bytes, got %d", chiplet.ScratchSpillBytes)
+	}
+	if chiplet.SpillStallCycles != 8 {
+		t.Fatalf("expected 8 spill stall cycles, got %d", chiplet.SpillStallCycles)
+	}
+	if chiplet.l2.Occupancy() != 0 {
+		t.Fatalf("expected L2 to drain after completion, got %d", chiplet.l2.Occupancy())
+	}
+}
+
+func TestChipletAppliesTaskEnergyScale(t *testing.T) {
+	run := func(scale float64) *Chiplet {
+		chiplet := NewChiplet(0, 4, 128, 128, 4, 0, 0, DefaultParameters())
+		desc := &TaskDescriptor{
+			Kind:        TaskKindVpuOp,
+			Description: "vpu_energy_scale",
+			ExecUnit:    ExecUnitVpu,
+			VectorOps:   2048,
+			RequiresVpu: true,
+			EnergyScale: scale,
+		}
+		if !chiplet.SubmitDescriptor(desc) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		tickUntilIdle(t, chiplet, 2048)
+		return chiplet
+	}
+
+	base := run(0)
+	scaled := run(2.5)
+	if base.VpuEnergyPJ <= 0 {
+		t.Fatalf("expected VPU energy to be recorded")
+	}
+	if diff := scaled.VpuEnergyPJ - 2.5*base.VpuEnergyPJ; diff > 1e-9 || diff < -1e-9 {
+		t.Fatalf("scaled VPU energy %.6f, want %.6f", scaled.VpuEnergyPJ, 2.5*base.VpuEnergyPJ)
+	}
+}
+
+func TestChipletChargesDtypeConversionOnSpu(t *testing.T) {
+	// A pipeline of small FP32-accumulating GEMMs, each converting its 64x64
+	// output to FP16, pays SPU cycles the unconverted pipeline does not.
+	run := func(conversionOps int) (*Chiplet, int) {
+		chiplet := NewChiplet(0, 4, 128, 128, 4, 0, 0, DefaultParameters())
+		cycles := 0
+		for i := 0; i < 8; i++ {
+			desc := &TaskDescriptor{
+				Kind:          TaskKindTileGemm,
+				Description:   "gemm_mixed_precision",
+				ExecUnit:      ExecUnitPe,
+				RequiresPe:    true,
+				ProblemM:      64,
+				ProblemN:      64,
+				ProblemK:      64,
+				OutputBytes:   64 * 64 * 2,
+				ConversionOps: conversionOps,
+			}
+			if !chiplet.SubmitDescriptor(desc) {
+				t.Fatalf("SubmitDescriptor failed")
+			}
+			for chiplet.Busy() || chiplet.PendingTasks > 0 {
+				chiplet.Tick()
+				cycles++
+			}
+		}
+		return chiplet, cycles
+	}
+
+	plain, plainCycles := run(0)
+	mixed, mixedCycles := run(64 * 64)
+	if plain.DtypeConversionOps != 0 || plain.DtypeConversionCycles != 0 {
+		t.Fatalf("expected no conversion without dtype change")
+	}
+	if mixed.DtypeConversionOps != 8*64*64 {
+		t.Fatalf("expected %d conversion ops, got %d", 8*64*64, mixed.DtypeConversionOps)
+	}
+	if mixed.DtypeConversionCycles <= 0 || mixedCycles <= plainCycles {
+		t.Fatalf("expected conversion to cost SPU time: plain=%d mixed=%d conversion=%d", plainCycles, mixedCycles, mixed.DtypeConversionCycles)
+	}
+}
+
+func TestChipletSplitsUsefulAndOverheadBusyCycles(t *testing.T) {
+	compute := NewChiplet(0, 1, 128, 128, 1, 0, 0, DefaultParameters())
+	if !compute.SubmitDescriptor(&TaskDescriptor{
+		Kind:        TaskKindVpuOp,
+		Description: "useful_only_unit_test",
+		ExecUnit:    ExecUnitVpu,
+		VectorOps:   2048,
+		RequiresVpu: true,
+	}) {
+		t.Fatalf("SubmitDescriptor failed")
+	}
+	tickUntilIdle(t, compute, 4096)
+	if compute.BusyCycles == 0 || compute.UsefulBusyCycles != compute.BusyCycles || compute.OverheadBusyCycles != 0 {
+		t.Fatalf("expected all busy cycles useful, got busy=%d useful=%d overhead=%d",
+			compute.BusyCycles, compute.UsefulBusyCycles, compute.OverheadBusyCycles)
+	}
+
+	memory := NewChiplet(0, 1, 128, 128, 1, 0, 0, DefaultParameters())
+	if !memory.SubmitDescriptor(&TaskDescriptor{
+		Kind:        TaskKindElementwise,
+		Description: "load_heavy_unit_test",
+		ExecUnit:    ExecUnitSpu,
+		VectorOps:   64,
+		InputBytes:  1 << 20,
+		OutputBytes: 1 << 20,
+		RequiresSpu: true,
+	}) {
+		t.Fatalf("SubmitDescriptor failed")
+	}
+	tickUntilIdle(t, memory, 1<<16)
+	if memory.OverheadBusyCycles == 0 || memory.UsefulBusyCycles+memory.OverheadBusyCycles != memory.BusyCycles {
+		t.Fatalf("expected load/store overhead summing to busy, got busy=%d useful=%d overhead=%d",
+			memory.BusyCycles, memory.UsefulBusyCycles, memory.OverheadBusyCycles)
+	}
+}
+
+func TestChipletActivationBanksOverlapLoadWithCompute(t *testing.T) {
+	// 单 cluster、64KB 激活缓冲、低 load 带宽：每个 24KB tile 的 load 与上一 tile 的
+	// compute 相当，ping-pong bank 在 compute 结束即释放，让下一 tile 提前开始 load。
+	run := func(banks int, inputBytes int64) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.Buffer.ActivationBanks = banks
+		params.PeArray.LoadBandwidthBytesPerCycle = 64
+		params.PeArray.StoreBandwidthBytesPerCycle = 256
+		chiplet := NewChiplet(0, 1, 128, 128, 1, 64*1024, 1<<20, params)
+		for i := 0; i < 8; i++ {
+			if !chiplet.SubmitDescriptor(&TaskDescriptor{
+				Kind:        TaskKindTileGemm,
+				Description: "banked_gemm",
+				ExecUnit:    ExecUnitPe,
+				RequiresPe:  true,
+				ProblemM:    64,
+				ProblemN:    64,
+				ProblemK:    64,
+				InputBytes:  inputBytes,
+				OutputBytes: 64 * 1024,
+			}) {
+				t.Fatalf("SubmitDescriptor failed")
+			}
+		}
+		cycles := 0
+		for chiplet.Busy() || chiplet.PendingTasks > 0 {
+			chiplet.Tick()
+			cycles++
+			if cycles > 1<<16 {
+				t.Fatalf("chiplet still busy after %d cycles", cycles)
+			}
+		}
+		return chiplet, cycles
+	}
+
+	pooled, pooledCycles := run(1, 24*1024)
+	banked, bankedCycles := run(2, 24*1024)
+	if banked.LoadStallCycles >= pooled.LoadStallCycles || bankedCycles >= pooledCycles {
+		t.Fatalf("expected banks to cut load stalls: pooled stall=%d cycles=%d, banked stall=%d cycles=%d",
+			pooled.LoadStallCycles, pooledCycles, banked.LoadStallCycles, bankedCycles)
+	}
+	if banked.LoadComputeOverlapCycles <= pooled.LoadComputeOverlapCycles {
+		t.Fatalf("expected more load/compute overlap with banks: pooled=%d banked=%d",
+			pooled.LoadComputeOverlapCycles, banked.LoadComputeOverlapCycles)
+	}
+
+	// 40KB tile 超过 32KB bank，需占满两个 bank，基本失去重叠。
+	oversized, _ := run(2, 40*1024)
+	if oversized.ActivationBankOverflows != 8 || oversized.ExecutedTasks != 8 {
+		t.Fatalf("expected 8 tiles spanning both banks, got overflows=%d executed=%d",
+			oversized.ActivationBankOverflows, oversized.ExecutedTasks)
+	}
+	if oversized.LoadComputeOverlapCycles*10 >= banked.LoadComputeOverlapCycles {
+		t.Fatalf("expected tiles filling every bank to lose overlap: oversized=%d banked=%d",
+			oversized.LoadComputeOverlapCycles, banked.LoadComputeOverlapCycles)
+	}
+}
+
+func TestChipletPrefetchHidesNextTaskLoad(t *testing.T) {
+	// scratch 只容纳一个 64KB 输出，后续 tile 只能在等待队列里排队；激活缓冲能再放下
+	// 一个 40KB tile，预取距离为 1 时下一个 tile 先预留输入空间，其 load 与当前 tile 的
+	// compute/store 重叠。
+	run := func(distance int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.Buffer.PrefetchDistance = distance
+		params.PeArray.LoadBandwidthBytesPerCycle = 64
+		params.PeArray.StoreBandwidthBytesPerCycle = 64
+		chiplet := NewChiplet(0, 1, 128, 128, 1, 96*1024, 64*1024, params)
+		for i := 0; i < 8; i++ {
+			if !chiplet.SubmitDescriptor(&TaskDescriptor{
+				Kind:        TaskKindTileGemm,
+				Description: "prefetch_gemm",
+				ExecUnit:    ExecUnitPe,
+				RequiresPe:  true,
+				ProblemM:    64,
+				ProblemN:    64,
+				ProblemK:    64,
+				InputBytes:  40 * 1024,
+				OutputBytes: 64 * 1024,
+			}) {
+				t.Fatalf("SubmitDescriptor failed")
+			}
+		}
+		cycles := 0
+		for chiplet.Busy() || chiplet.PendingTasks > 0 {
+			chiplet.Tick()
+			cycles++
+			if cycles > 1<<16 {
+				t.Fatalf("chiplet still busy after %d cycles", cycles)
+			}
+		}
+		return chiplet, cycles
+	}
+
+	base, baseCycles := run(0)
+	prefetch, prefetchCycles := run(1)
+	if base.PrefetchHits != 0 || base.PrefetchBytes != 0 {
+		t.Fatalf("expected no prefetch when disabled, got hits=%d bytes=%d", base.PrefetchHits, base.PrefetchBytes)
+	}
+	if prefetch.ExecutedTasks != 8 || prefetch.PrefetchHits == 0 || prefetch.PrefetchOverlapCycles == 0 {
+		t.Fatalf("expected prefetch hits with overlap, got executed=%d hits=%d overlap=%d",
+			prefetch.ExecutedTasks, prefetch.PrefetchHits, prefetch.PrefetchOverlapCycles)
+	}
+	if prefetch.LoadStallCycles >= base.LoadStallCycles || prefetchCycles >= baseCycles {
+		t.Fatalf("expected prefetch to cut load stalls: base stall=%d cycles=%d, prefetch stall=%d cycles=%d",
+			base.LoadStallCycles, baseCycles, prefetch.LoadStallCycles, prefetchCycles)
+	}
+	if prefetch.TotalLoadBytes != base.TotalLoadBytes {
+		t.Fatalf("prefetch must move the same bytes: base=%d prefetch=%d", base.TotalLoadBytes, prefetch.TotalLoadBytes)
+	}
+}
+
+func TestChipletRawHazardStallsDependentLoad(t *testing.T) {
+	// 生产者写回 scratch 后立即提交读取同一 scratch 的消费者，RAW 延迟应完整计入。
+	run := func(rawLatency int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.Buffer.RawLatencyCycles = rawLatency
+		chiplet := NewChiplet(0, 1, 16, 16, 1, 1<<16, 1<<16, params)
+		if !chiplet.SubmitDescriptor(&TaskDescriptor{
+			Kind:             TaskKindElementwise,
+			Description:      "raw_producer",
+			ExecUnit:         ExecUnitSpu,
+			VectorOps:        64,
+			OutputBytes:      4096,
+			RequiresSpu:      true,
+			TargetBuffer:     "scratch",
+			PreferredCluster: 0,
+		}) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		for chiplet.ExecutedTasks == 0 {
+			chiplet.Tick()
+		}
+		if !chiplet.SubmitDescriptor(&TaskDescriptor{
+			Kind:             TaskKindElementwise,
+			Description:      "raw_consumer",
+			ExecUnit:         ExecUnitSpu,
+			VectorOps:        64,
+			InputBytes:       4096,
+			RequiresSpu:      true,
+			SourceBuffer:     "scratch",
+			PreferredCluster: 0,
+		}) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		cycles := 0
+		for chiplet.Busy() || chiplet.PendingTasks > 0 {
+			chiplet.Tick()
+			cycles++
+			if cycles > 4096 {
+				t.Fatalf("chiplet still busy after %d cycles", cycles)
+			}
+		}
+		return chiplet, cycles
+	}
+
+	base, baseCycles := run(0)
+	hazard, hazardCycles := run(20)
+	if base.RawHazardStallCycles != 0 {
+		t.Fatalf("expected no RAW stalls without latency, got %d", base.RawHazardStallCycles)
+	}
+	if hazard.RawHazardStallCycles == 0 || hazardCycles-baseCycles != int(hazard.RawHazardStallCycles) {
+		t.Fatalf("expected consumer to pay the RAW stall: base=%d hazard=%d stalls=%d",
+			baseCycles, hazardCycles, hazard.RawHazardStallCycles)
+	}
+}
+
+func TestChipletAccountsWastedTasksSeparately(t *testing.T) {
+	params := DefaultParameters()
+	chiplet := NewChiplet(0, 1, 16, 16, 1, 1<<16, 1<<16, params)
+	for _, wasted := range []bool{false, true} {
+		if !chiplet.SubmitDescriptor(&TaskDescriptor{
+			Kind:             TaskKindElementwise,
+			Description:      "wasted_unit_test",
+			ExecUnit:         ExecUnitSpu,
+			VectorOps:        256,
+			InputBytes:       2048,
+			OutputBytes:      2048,
+			RequiresSpu:      true,
+			PreferredCluster: 0,
+			Wasted:           wasted,
+		}) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		tickUntilIdle(t, chiplet, 4096)
+	}
+
+	// 两个任务完全相同，投机任务恰好占一半动态能耗。
+	if diff := chiplet.WastedEnergyPJ*2 - chiplet.DynamicEnergyPJ; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("expected wasted energy to be half of %.3f pJ, got %.3f", chiplet.DynamicEnergyPJ, chiplet.WastedEnergyPJ)
+	}
+	if chiplet.WastedCycles <= 0 {
+		t.Fatalf("expected wasted cycles to be recorded, got %d", chiplet.WastedCycles)
+	}
+}
+
+func TestChipletNarrowIssueWidthBindsMixedSpuTask(t *testing.T) {
+	run := func(issueWidth int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.Spu.IssueWidth = issueWidth
+		chiplet := NewChiplet(0, 1, 16, 16, 1, 1<<16, 1<<16, params)
+		if !chiplet.SubmitDescriptor(&TaskDescriptor{
+			Kind:             TaskKindElementwise,
+			Description:      "issue_width_unit_test",
+			ExecUnit:         ExecUnitSpu,
+			ScalarOps:        4096,
+			VectorOps:        4096,
+			RequiresSpu:      true,
+			PreferredCluster: 0,
+		}) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		cycles := 0
+		for chiplet.Busy() || chiplet.PendingTasks > 0 {
+			chiplet.Tick()
+			cycles++
+			if cycles > 1<<16 {
+				t.Fatalf("chiplet still busy after %d cycles", cycles)
+			}
+		}
+		return chiplet, cycles
+	}
+
+	unlimited, unlimitedCycles := run(0)
+	wide, wideCycles := run(64)
+	narrow, narrowCycles := run(4)
+	if unlimited.DispatchLimitedCycles != 0 || wide.DispatchLimitedCycles != 0 || wideCycles != unlimitedCycles {
+		t.Fatalf("expected a wide front end to leave execution-bound timing unchanged: unlimited=%d wide=%d limited=%d",
+			unlimitedCycles, wideCycles, wide.DispatchLimitedCycles)
+	}
+	// 8192 个操作按每周期 4 个派发需 2048 周期，而执行单元只需 1024 周期。
+	if narrow.DispatchLimitedCycles != 1024 || narrowCycles-unlimitedCycles != 1024 {
+		t.Fatalf("expected narrow issue to add 1024 dispatch-bound cycles, got limited=%d cycles %d -> %d",
+			narrow.DispatchLimitedCycles, unlimitedCycles, narrowCycles)
+	}
+}
+
+func TestChipletIssueWidthAlsoBindsVpuTask(t *testing.T) {
+	run := func(issueWidth int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.Spu.IssueWidth = issueWidth
+		chiplet := NewChiplet(0, 1, 16, 16, 1, 1<<16, 1<<16, params)
+		if !chiplet.SubmitDescriptor(&TaskDescriptor{
+			Kind:        TaskKindVpuOp,
+			Description: "issue_width_vpu_unit_test",
+			ExecUnit:    ExecUnitVpu,
+			VectorOps:   4096,
+			RequiresVpu: true,
+		}) {
+			t.Fatalf("SubmitDescriptor failed")
+		}
+		cycles := 0
+		for chiplet.Busy() || chiplet.PendingTasks > 0 {
+			chiplet.Tick()
+			cycles++
+			if cycles > 1<<16 {
+				t.Fatalf("chiplet still busy after %d cycles", cycles)
+			}
+		}
+		return chiplet, cycles
+	}
+
+	unlimited, unlimitedCycles := run(0)
+	narrow, narrowCycles := run(1)
+	if unlimited.DispatchLimitedCycles != 0 {
+		t.Fatalf("unlimited issue width should not be dispatch-bound, got %d", unlimited.DispatchLimitedCycles)
+	}
+	// 4096 个向量操作按每周期 1 个派发需 4096 周期，远超 VPU 执行周期。
+	if narrow.DispatchLimitedCycles <= 0 || narrowCycles-unlimitedCycles != int(narrow.DispatchLimitedCycles) {
+		t.Fatalf("expected a VPU-only task to pay dispatch cycles, got limited=%d cycles %d -> %d",
+			narrow.DispatchLimitedCycles, unlimitedCycles, narrowCycles)
+	}
+}
@@ -36,6 +36,10 @@ type SPUParameters struct {
 	VectorThroughput     int
 	SpecialLatencyCycles int
 	ClusterAreaMm2       float64
+	// IssueWidth caps the scalar+vector+special ops a compute cluster can
+	// dispatch per cycle, shared by its SPU clusters and VPU units. 0 leaves
+	// dispatch unlimited so only the execution-unit throughput applies.
+	IssueWidth int
 }
 
 // VPUParameters models dedicated vector processing units that can operate in
@@ -57,6 +61,24 @@ type BufferParameters struct {
 	WriteEnergyPJPerByte float64
 	AreaMm2              float64
 	LeakagePowerMw       float64
+	// L2 models the next memory level that absorbs scratch overflow. A zero
+	// capacity disables spilling and keeps the hard-stall behaviour.
+	L2Bytes                  int64
+	L2BandwidthBytesPerCycle int64
+	L2EnergyPJPerByte        float64
+	// ActivationBanks splits each cluster's activation buffer into ping-pong
+	// banks of equal size. A task occupies one bank and frees it once its
+	// compute phase has consumed the activations, so the next tile can load
+	// while the current one stores. Values <= 1 keep the single shared pool.
+	ActivationBanks int
+	// RawLatencyCycles is the write-to-read latency inside a buffer: a load
+	// that reads a buffer region whose last store finished fewer than this
+	// many cycles ago stalls until the write has drained. 0 disables it.
+	RawLatencyCycles int
+	// PrefetchDistance lets a cluster spend load bandwidth left over by the
+	// active tasks on the next PrefetchDistance waiting tasks, so their data
+	// is already staged when they activate. 0 disables prefetching.
+	PrefetchDistance int
 }
 
 // InterconnectParameters captures the cost of moving data to/from the host or
@@ -101,12 +123,15 @@ func DefaultParameters() Parameters {
 			UnitAreaMm2:     0.06,
 		},
 		Buffer: BufferParameters{
-			ActivationBytes:      8 * 1024 * 1024,
-			ScratchBytes:         8 * 1024 * 1024,
-			ReadEnergyPJPerByte:  0.28,
-			WriteEnergyPJPerByte: 0.31,
-			AreaMm2:              3.2,
-			LeakagePowerMw:       12.0,
+			ActivationBytes:          8 * 1024 * 1024,
+			ScratchBytes:             8 * 1024 * 1024,
+			ReadEnergyPJPerByte:      0.28,
+			WriteEnergyPJPerByte:     0.31,
+			AreaMm2:                  3.2,
+			LeakagePowerMw:           12.0,
+			L2Bytes:                  0,
+			L2BandwidthBytesPerCycle: 256,
+			L2EnergyPJPerByte:        1.1,
 		},
 		Interconnect: InterconnectParameters{
 			BytesPerCycle:   1024,
@@ -0,0 +1,85 @@
+package chiplet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// energyCalibrationComponents are the hardware components whose per-operation
+// energy can be scaled independently of the command that exercises them.
+var energyCalibrationComponents = map[string]bool{
+	"pe":  true,
+	"spu": true,
+	"vpu": true,
+	"adc": true,
+	"dac": true,
+}
+
+// energyCalibrationKinds are the command kinds with modelled dynamic energy;
+// transfer and host commands are charged through the interconnect model and
+// cannot be calibrated here.
+var energyCalibrationKinds = []CommandKind{
+	CommandKindPeGemm,
+	CommandKindPeAttentionHead,
+	CommandKindPeElementwise,
+	CommandKindPeSpuOp,
+	CommandKindPeVpuOp,
+	CommandKindPeReduce,
+	CommandKindRramStageAct,
+	CommandKindRramExecute,
+	CommandKindRramPost,
+	CommandKindRramWeightLoad,
+}
+
+// LoadEnergyCalibration reads a JSON object mapping command kinds (by opcode,
+// e.g. "pe_cmd_gemm") or components (pe/spu/vpu/adc/dac) to positive energy
+// scale factors.
+func LoadEnergyCalibration(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]float64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("energy calibration %s: %v", path, err)
+	}
+
+	calibratable := make(map[string]bool, len(energyCalibrationKinds))
+	for _, kind := range energyCalibrationKinds {
+		calibratable[kind.String()] = true
+	}
+
+	factors := make(map[string]float64, len(raw))
+	for key, factor := range raw {
+		name := strings.ToLower(strings.TrimSpace(key))
+		if !energyCalibrationComponents[name] && !calibratable[name] {
+			return nil, fmt.Errorf("energy calibration %s: unknown command kind or component %q", path, key)
+		}
+		if factor <= 0 {
+			return nil, fmt.Errorf("energy calibration %s: factor for %q must be positive", path, key)
+		}
+		factors[name] = factor
+	}
+	return factors, nil
+}
+
+// EnergyScale returns the calibration factor for key, or 1 when unset.
+func EnergyScale(factors map[string]float64, key string) float64 {
+	if factor, ok := factors[key]; ok {
+		return factor
+	}
+	return 1.0
+}
+
+// EnergyCalibrationKeys lists the configured keys in sorted order.
+func EnergyCalibrationKeys(factors map[string]float64) []string {
+	keys := make([]string, 0, len(factors))
+	for key := range factors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
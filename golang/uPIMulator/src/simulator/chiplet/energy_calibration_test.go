@@ -0,0 +1,43 @@
+package chiplet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCalibration(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("write calibration: %v", err)
+	}
+	return path
+}
+
+func TestLoadEnergyCalibration(t *testing.T) {
+	factors, err := LoadEnergyCalibration(writeCalibration(t, `{"PE_CMD_GEMM": 1.5, " adc ": 0.8}`))
+	if err != nil {
+		t.Fatalf("load calibration: %v", err)
+	}
+	if got := EnergyScale(factors, CommandKindPeGemm.String()); got != 1.5 {
+		t.Fatalf("pe_cmd_gemm factor = %.2f, want 1.5", got)
+	}
+	if got := EnergyScale(factors, "adc"); got != 0.8 {
+		t.Fatalf("adc factor = %.2f, want 0.8", got)
+	}
+	if got := EnergyScale(factors, "vpu"); got != 1.0 {
+		t.Fatalf("unset factor = %.2f, want 1", got)
+	}
+
+	for _, body := range []string{
+		`{"xfer_cmd_host2d": 2.0}`,
+		`{"sram": 2.0}`,
+		`{"pe": 0}`,
+		`["pe"]`,
+	} {
+		if _, err := LoadEnergyCalibration(writeCalibration(t, body)); err == nil {
+			t.Fatalf("expected %s to be rejected", body)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package chiplet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// crossTargetEdge is a dependency from a producer on one chiplet type to a
+// consumer on the other with no transfer node carrying the data across.
+type crossTargetEdge struct {
+	Producer int
+	Consumer int
+}
+
+const maxCrossTargetWarnings = 10
+
+// isComputeTarget reports whether target is a digital or RRAM chiplet.
+func isComputeTarget(target TaskTarget) bool {
+	return target == TaskTargetDigital || target == TaskTargetRram
+}
+
+// findCrossTargetMissingTransfers checks every explicit dependency edge
+// between a digital and an RRAM node. An edge is accepted when some other
+// path from the producer reaches the consumer through a transfer node;
+// otherwise the consumer would run on data that was never moved. Implicit
+// sequential edges (nodes without declared dependencies) only order execution
+// and are skipped.
+func findCrossTargetMissingTransfers(graph *OpGraph, explicit map[int]bool) []crossTargetEdge {
+	if graph == nil {
+		return nil
+	}
+	ids := sortedNodeIDs(graph)
+	edges := make([]crossTargetEdge, 0)
+	for _, id := range ids {
+		consumer := graph.Nodes[id]
+		if consumer == nil || !explicit[id] || !isComputeTarget(consumer.Target) {
+			continue
+		}
+		for _, dep := range consumer.Deps {
+			producer := graph.Nodes[dep]
+			if producer == nil || !isComputeTarget(producer.Target) || producer.Target == consumer.Target {
+				continue
+			}
+			if !transferPathExists(graph, dep, id) {
+				edges = append(edges, crossTargetEdge{Producer: dep, Consumer: id})
+			}
+		}
+	}
+	return edges
+}
+
+// transferPathExists walks the dependencies of consumer back towards producer
+// and reports whether producer is reachable through at least one transfer node.
+func transferPathExists(graph *OpGraph, producer int, consumer int) bool {
+	type state struct {
+		node    int
+		through bool
+	}
+	visited := make(map[state]bool)
+	stack := make([]state, 0)
+	if node := graph.Nodes[consumer]; node != nil {
+		for _, dep := range node.Deps {
+			if dep != producer {
+				stack = append(stack, state{node: dep})
+			}
+		}
+	}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := graph.Nodes[current.node]
+		if node == nil {
+			continue
+		}
+		if node.Target == TaskTargetTransfer {
+			current.through = true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		for _, dep := range node.Deps {
+			if dep == producer {
+				if current.through {
+					return true
+				}
+				continue
+			}
+			stack = append(stack, state{node: dep, through: current.through})
+		}
+	}
+	return false
+}
+
+// validateCrossTargetEdges records and reports digital/RRAM dependencies that
+// bypass the interconnect.
+func (this *HostOrchestrator) validateCrossTargetEdges(graph *OpGraph, explicit map[int]bool) {
+	edges := findCrossTargetMissingTransfers(graph, explicit)
+	this.crossTargetMissingTransfer = len(edges)
+	if len(edges) == 0 {
+		return
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Consumer != edges[j].Consumer {
+			return edges[i].Consumer < edges[j].Consumer
+		}
+		return edges[i].Producer < edges[j].Producer
+	})
+	for idx, edge := range edges {
+		if idx >= maxCrossTargetWarnings {
+			fmt.Printf("[chiplet] warning: %d more cross-target edges without a transfer omitted\n", len(edges)-idx)
+			break
+		}
+		producer := graph.Nodes[edge.Producer]
+		consumer := graph.Nodes[edge.Consumer]
+		fmt.Printf("[chiplet] warning: node %d (%s) depends on node %d (%s) across %s/%s without a transfer\n",
+			edge.Consumer, nodeKindLabel(consumer), edge.Producer, nodeKindLabel(producer),
+			producer.Target.String(), consumer.Target.String())
+	}
+}
+
+// CrossTargetMissingTransfers returns how many digital/RRAM dependencies in the
+// loaded command graph have no transfer between producer and consumer.
+func (this *HostOrchestrator) CrossTargetMissingTransfers() int {
+	if this == nil {
+		return 0
+	}
+	return this.crossTargetMissingTransfer
+}
+
+func nodeKindLabel(node *OpNode) string {
+	if node == nil {
+		return "?"
+	}
+	if cmd, ok := node.Payload.(*CommandDescriptor); ok && cmd != nil {
+		return cmd.Kind.String()
+	}
+	return fmt.Sprintf("%v", node.Payload)
+}
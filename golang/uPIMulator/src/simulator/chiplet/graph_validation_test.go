@@ -0,0 +1,51 @@
+package chiplet
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCommandGraphFlagsCrossTargetEdgesWithoutTransfer(t *testing.T) {
+	t.Parallel()
+
+	commands := []CommandDescriptor{
+		{ID: 0, Kind: CommandKindPeGemm, Target: TaskTargetDigital},
+		// RRAM execute reads the GEMM output directly: flagged.
+		{ID: 1, Kind: CommandKindRramExecute, Target: TaskTargetRram, Dependencies: []int32{0}},
+		// GEMM -> transfer -> stage is well formed.
+		{ID: 2, Kind: CommandKindTransferSchedule, Target: TaskTargetTransfer, Dependencies: []int32{0}},
+		{ID: 3, Kind: CommandKindRramStageAct, Target: TaskTargetRram, Dependencies: []int32{2}},
+		// A direct ordering edge next to a transfer path is also fine.
+		{ID: 4, Kind: CommandKindRramExecute, Target: TaskTargetRram, Dependencies: []int32{0, 2}},
+		// Same-target edges are never flagged.
+		{ID: 5, Kind: CommandKindRramPost, Target: TaskTargetRram, Dependencies: []int32{4}},
+		// Digital consumer of an RRAM result without a transfer back: flagged.
+		{ID: 6, Kind: CommandKindPeElementwise, Target: TaskTargetDigital, Dependencies: []int32{5}},
+		// Implicit sequential edge (no declared deps) is not a data edge.
+		{ID: 7, Kind: CommandKindRramStageAct, Target: TaskTargetRram},
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	commandPath := filepath.Join(t.TempDir(), "chiplet_commands.json")
+	if err := os.WriteFile(commandPath, data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	config := &Config{NumDigitalChiplets: 1, NumRramChiplets: 1}
+	orch := new(HostOrchestrator)
+	orch.Init(config, BuildTopology(config), commandPath)
+	defer orch.Fini()
+
+	if got := orch.CrossTargetMissingTransfers(); got != 2 {
+		t.Fatalf("expected 2 cross-target edges without transfer, got %d", got)
+	}
+
+	edges := findCrossTargetMissingTransfers(orch.graph, map[int]bool{1: true, 3: true, 4: true, 5: true, 6: true})
+	if len(edges) != 2 || edges[0] != (crossTargetEdge{Producer: 0, Consumer: 1}) || edges[1] != (crossTargetEdge{Producer: 5, Consumer: 6}) {
+		t.Fatalf("unexpected flagged edges %+v", edges)
+	}
+}
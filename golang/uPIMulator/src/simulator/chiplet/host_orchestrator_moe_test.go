@@ -91,6 +91,10 @@ func TestMoeSessionLifecycle(t *testing.T) {
 	if barrierID < 0 {
 		t.Fatalf("expected barrier node among merge nodes")
 	}
+	barrierCmd := orch.graph.Nodes[barrierID].Payload.(*CommandDescriptor)
+	if producers := metadataInt(barrierCmd.Metadata, "producers", -1); producers != len(event.SelectedExperts) {
+		t.Fatalf("barrier should wait on %d producers, got %d", len(event.SelectedExperts), producers)
+	}
 
 	deps := orch.graph.Nodes[residualID].Deps
 	for _, dep := range deps {
@@ -132,3 +136,336 @@ func TestMoeSessionLifecycle(t *testing.T) {
 		t.Fatalf("barrier %d should be cleared from owner map", barrierID)
 	}
 }
+
+func TestMoeExpertWeightLoadOverlapsTransferIn(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		NumDigitalChiplets:     1,
+		NumRramChiplets:        1,
+		HostStreamTotalBatches: 1,
+		RramLoadPathMode:       "overlap",
+	}
+	orch := new(HostOrchestrator)
+	orch.Init(cfg, nil, "")
+	defer orch.Fini()
+
+	gatingID := 7
+	graph := NewOpGraph()
+	graph.AddNode(&OpNode{
+		ID:      gatingID,
+		Target:  TaskTargetHost,
+		Payload: &CommandDescriptor{Kind: CommandKindHostGatingFetch, Target: TaskTargetHost},
+	})
+	orch.setGraph(graph)
+
+	orch.handleGatingFetchEvent(gatingID, &HostEvent{
+		Kind:             CommandKindHostGatingFetch,
+		TopK:             1,
+		Tokens:           4,
+		Features:         64,
+		CandidateExperts: []int{0},
+		SelectedExperts:  []int{0},
+		Metadata:         map[string]interface{}{"op": "moe_gating_fetch"},
+	})
+
+	var weightID, transferID, stageID = -1, -1, -1
+	for id, node := range orch.graph.Nodes {
+		cmd, ok := node.Payload.(*CommandDescriptor)
+		if !ok || cmd == nil {
+			continue
+		}
+		switch cmd.Kind {
+		case CommandKindRramWeightLoad:
+			weightID = id
+		case CommandKindRramStageAct:
+			stageID = id
+		case CommandKindTransferSchedule:
+			if cmd.Flags&TransferFlagDirectionMask == TransferFlagDigitalToRram {
+				transferID = id
+			}
+		}
+	}
+	if weightID < 0 || transferID < 0 || stageID < 0 {
+		t.Fatalf("expected weight load, transfer-in and stage nodes, got %d/%d/%d", weightID, transferID, stageID)
+	}
+
+	weightDeps := orch.graph.Nodes[weightID].Deps
+	transferDeps := orch.graph.Nodes[transferID].Deps
+	if len(weightDeps) != 1 || weightDeps[0] != gatingID || len(transferDeps) != 1 || transferDeps[0] != gatingID {
+		t.Fatalf("weight load and transfer-in should both depend only on gating: %v %v", weightDeps, transferDeps)
+	}
+	stageDeps := map[int]bool{}
+	for _, dep := range orch.graph.Nodes[stageID].Deps {
+		stageDeps[dep] = true
+	}
+	if len(stageDeps) != 2 || !stageDeps[weightID] || !stageDeps[transferID] {
+		t.Fatalf("stage should wait on weight load and transfer-in, got %v", orch.graph.Nodes[stageID].Deps)
+	}
+}
+
+func TestMoeSessionLimitDefersGatingFetches(t *testing.T) {
+	t.Parallel()
+
+	const fetches = 6
+	const limit = 2
+	cfg := &Config{
+		NumDigitalChiplets:     1,
+		NumRramChiplets:        1,
+		HostStreamTotalBatches: 1,
+		MaxMoeSessions:         limit,
+	}
+	orch := new(HostOrchestrator)
+	orch.Init(cfg, nil, "")
+	defer orch.Fini()
+
+	graph := NewOpGraph()
+	for i := 0; i < fetches; i++ {
+		gatingID := 100 + 2*i
+		graph.AddNode(&OpNode{
+			ID:      gatingID,
+			Target:  TaskTargetHost,
+			Payload: &CommandDescriptor{Kind: CommandKindHostGatingFetch, Target: TaskTargetHost},
+		})
+		graph.AddNode(&OpNode{
+			ID:      gatingID + 1,
+			Target:  TaskTargetDigital,
+			Latency: 1,
+			Deps:    []int{gatingID},
+			Payload: &CommandDescriptor{Kind: CommandKindPeElementwise, Target: TaskTargetDigital},
+		})
+	}
+	orch.setGraph(graph)
+
+	checkCap := func(stage string) {
+		if len(orch.moeSessions) > limit {
+			t.Fatalf("%s: %d sessions exceed cap %d", stage, len(orch.moeSessions), limit)
+		}
+	}
+
+	// All gating fetches complete back to back, overlapping each other.
+	for i := 0; i < fetches; i++ {
+		gatingID := 100 + 2*i
+		orch.NotifyHostEvent(gatingID, &HostEvent{
+			Kind:             CommandKindHostGatingFetch,
+			TopK:             2,
+			Tokens:           4,
+			Features:         64,
+			CandidateExperts: []int{0, 1},
+			SelectedExperts:  []int{0, 1},
+			Metadata:         map[string]interface{}{"op": "moe_gating_fetch"},
+		})
+		orch.NotifyTaskCompletion(gatingID)
+		checkCap("issue")
+	}
+	if orch.MoeSessionDeferrals() != fetches-limit {
+		t.Fatalf("expected %d deferrals, got %d", fetches-limit, orch.MoeSessionDeferrals())
+	}
+	for _, id := range orch.readyQueue {
+		if id > 100 && id < 100+2*fetches && id%2 == 1 {
+			t.Fatalf("successor %d of a deferred/open session became ready early", id)
+		}
+	}
+
+	for len(orch.moeSessions) > 0 {
+		for _, session := range orch.moeSessions {
+			for _, mergeID := range session.mergeList() {
+				if mergeID != session.barrierNode {
+					orch.NotifyTaskCompletion(mergeID)
+					checkCap("merge")
+				}
+			}
+			orch.NotifyTaskCompletion(session.barrierNode)
+			checkCap("barrier")
+			break
+		}
+	}
+
+	if len(orch.moeDeferredFetches) != 0 || orch.MoeSessionPeak() != limit {
+		t.Fatalf("expected all fetches expanded with peak %d, got pending=%d peak=%d", limit, len(orch.moeDeferredFetches), orch.MoeSessionPeak())
+	}
+	ready := map[int]bool{}
+	for _, id := range orch.readyQueue {
+		ready[id] = true
+	}
+	for i := 0; i < fetches; i++ {
+		if succ := 100 + 2*i + 1; !ready[succ] {
+			t.Fatalf("successor %d never became ready", succ)
+		}
+	}
+}
+
+func TestMoeEmptyExpertsAreSkipped(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		NumDigitalChiplets:     1,
+		NumRramChiplets:        4,
+		HostStreamTotalBatches: 1,
+		MoeSkipEmptyExperts:    1,
+	}
+	orch := new(HostOrchestrator)
+	orch.Init(cfg, nil, "")
+	defer orch.Fini()
+
+	graph := NewOpGraph()
+	for _, gatingID := range []int{10, 20} {
+		graph.AddNode(&OpNode{
+			ID:      gatingID,
+			Target:  TaskTargetHost,
+			Payload: &CommandDescriptor{Kind: CommandKindHostGatingFetch, Target: TaskTargetHost},
+		})
+		graph.AddNode(&OpNode{
+			ID:      gatingID + 1,
+			Target:  TaskTargetDigital,
+			Latency: 1,
+			Deps:    []int{gatingID},
+			Payload: &CommandDescriptor{Kind: CommandKindPeElementwise, Target: TaskTargetDigital},
+		})
+	}
+	orch.setGraph(graph)
+
+	event := func(tokens []int) *HostEvent {
+		return &HostEvent{
+			Kind:             CommandKindHostGatingFetch,
+			TopK:             3,
+			Tokens:           4,
+			Features:         64,
+			CandidateExperts: []int{0, 1, 2},
+			SelectedExperts:  []int{0, 1, 2},
+			Metadata:         map[string]interface{}{"op": "moe_gating_fetch", "expert_tokens": tokens},
+		}
+	}
+
+	// Expert 1 receives no tokens: its whole group is elided and the barrier waits on two merges.
+	groupSize := len(orch.buildExpertCommandGroup(event(nil), 1))
+	orch.handleGatingFetchEvent(10, event([]int{3, 0, 1}))
+	session := orch.moeSessions[10]
+	if session == nil || len(session.expertIDs) != 2 || session.expertIDs[0] != 0 || session.expertIDs[1] != 2 {
+		t.Fatalf("expected experts [0 2] dispatched, got %+v", session)
+	}
+	if deps := orch.graph.Nodes[session.barrierNode].Deps; len(deps) != 2 {
+		t.Fatalf("expected barrier to wait on 2 merges, got %v", deps)
+	}
+	if orch.MoeSkippedExpertCommands() != groupSize {
+		t.Fatalf("expected %d skipped commands, got %d", groupSize, orch.MoeSkippedExpertCommands())
+	}
+	if load := orch.MoeExpertTokens(); len(load) != 3 || load[0] != 3 || load[1] != 0 || load[2] != 1 {
+		t.Fatalf("expected per-expert tokens {0:3 1:0 2:1}, got %v", load)
+	}
+
+	// No expert receives tokens: no session is opened and the successor follows the gating node.
+	orch.handleGatingFetchEvent(20, event([]int{0, 0, 0}))
+	if _, open := orch.moeSessions[20]; open {
+		t.Fatalf("expected no session when every expert is empty")
+	}
+	if deps := orch.graph.Nodes[21].Deps; len(deps) != 1 || deps[0] != 20 {
+		t.Fatalf("expected successor to keep depending on gating node, got %v", deps)
+	}
+	if orch.MoeSkippedExpertCommands() != 4*groupSize {
+		t.Fatalf("expected %d skipped commands, got %d", 4*groupSize, orch.MoeSkippedExpertCommands())
+	}
+}
+
+func TestMoeAlltoallSharesCollectiveLatency(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		NumDigitalChiplets: 1,
+		NumRramChiplets:    4,
+		MoeAlltoallBw:      256,
+	}
+	orch := new(HostOrchestrator)
+	orch.Init(cfg, nil, "")
+	defer orch.Fini()
+
+	event := &HostEvent{
+		Kind:             CommandKindHostGatingFetch,
+		TopK:             3,
+		Tokens:           4,
+		Features:         64,
+		ActivationBytes:  512,
+		OutputBytes:      256,
+		CandidateExperts: []int{0, 1, 2, 3},
+		SelectedExperts:  []int{0, 1, 5},
+		Metadata:         map[string]interface{}{"op": "moe_gating_fetch"},
+	}
+	groups := make([][]CommandDescriptor, 0, 3)
+	for _, expertID := range event.SelectedExperts {
+		groups = append(groups, orch.buildExpertCommandGroup(event, expertID))
+	}
+	orch.applyMoeAlltoall(groups, nil)
+
+	// Experts 1 and 5 share RRAM chiplet 1: dispatch 3*512B over 256B/cycle plus one
+	// arbitration cycle for the second destination; combine 3*256B likewise.
+	for _, group := range groups {
+		for _, cmd := range group {
+			if cmd.Kind != CommandKindTransferSchedule {
+				continue
+			}
+			want, phase := 4, "combine"
+			if cmd.Flags == TransferFlagDigitalToRram {
+				want, phase = 7, "dispatch"
+			}
+			if cmd.Metadata[MetadataKeyCollectiveCycles] != want || cmd.Metadata[MetadataKeyCollectivePhase] != phase || cmd.Metadata["moe_alltoall"] != true {
+				t.Fatalf("expected %s phase of %d cycles, got %v (%v)", phase, want, cmd.Metadata[MetadataKeyCollectiveCycles], cmd.Metadata["op"])
+			}
+		}
+	}
+	if orch.MoeAlltoallCollectives() != 1 || orch.MoeAlltoallBytes() != 3*512+3*256 || orch.MoeAlltoallCycles() != 11 {
+		t.Fatalf("unexpected all-to-all totals: %d collectives, %d bytes, %d cycles",
+			orch.MoeAlltoallCollectives(), orch.MoeAlltoallBytes(), orch.MoeAlltoallCycles())
+	}
+}
+
+func TestCollectiveBroadcastScalesWithParticipants(t *testing.T) {
+	t.Parallel()
+
+	dispatchLatency := func(selected []int) (interface{}, *HostOrchestrator) {
+		cfg := &Config{
+			NumDigitalChiplets: 1,
+			NumRramChiplets:    4,
+			MoeAlltoallBw:      256,
+			BroadcastCycles:    3,
+		}
+		orch := new(HostOrchestrator)
+		orch.Init(cfg, nil, "")
+		t.Cleanup(orch.Fini)
+
+		event := &HostEvent{
+			Kind:             CommandKindHostGatingFetch,
+			TopK:             len(selected),
+			Tokens:           4,
+			Features:         64,
+			ActivationBytes:  512,
+			OutputBytes:      256,
+			CandidateExperts: []int{0, 1, 2, 3},
+			SelectedExperts:  selected,
+			Metadata:         map[string]interface{}{"op": "moe_gating_fetch"},
+		}
+		groups := make([][]CommandDescriptor, 0, len(selected))
+		for _, expertID := range selected {
+			groups = append(groups, orch.buildExpertCommandGroup(event, expertID))
+		}
+		orch.applyMoeAlltoall(groups, nil)
+		for _, cmd := range groups[0] {
+			if cmd.Kind == CommandKindTransferSchedule && cmd.Flags == TransferFlagDigitalToRram {
+				return cmd.Metadata[MetadataKeyCollectiveCycles], orch
+			}
+		}
+		t.Fatalf("no dispatch transfer in expert group")
+		return nil, nil
+	}
+
+	// 1 个 digital + 2 个 RRAM 参与者：2*512B/256 + 1 仲裁 + 3*3 广播。
+	small, smallOrch := dispatchLatency([]int{0, 1})
+	// 1 个 digital + 4 个 RRAM 参与者：4*512B/256 + 3 仲裁 + 5*3 广播。
+	large, largeOrch := dispatchLatency([]int{0, 1, 2, 3})
+	if small != 4+1+9 || large != 8+3+15 {
+		t.Fatalf("unexpected dispatch startup: small=%d large=%d", small, large)
+	}
+	if smallOrch.CollectiveBroadcastCycles() != 9 || largeOrch.CollectiveBroadcastCycles() != 15 {
+		t.Fatalf("unexpected broadcast totals: small=%d large=%d",
+			smallOrch.CollectiveBroadcastCycles(), largeOrch.CollectiveBroadcastCycles())
+	}
+}
@@ -15,3 +15,12 @@ type TransferLatencyQuery struct {
 // TransferLatencyEstimator 尝试返回更精确的传输延迟（周期）。
 // 第二个返回值为 false 时表示估算失败，调用者应回退到带宽模型。
 type TransferLatencyEstimator func(TransferLatencyQuery) (int, bool)
+
+// EccWireBytes 返回按 --chiplet_ecc_overhead_pct 附加 ECC/校验字节后实际上链路的字节数；
+// 带宽/延迟估算应使用该值，缓冲区占用仍按有效载荷计。
+func EccWireBytes(config *Config, bytes int64) int64 {
+	if config == nil || config.EccOverheadPct <= 0 || bytes <= 0 {
+		return bytes
+	}
+	return bytes + (bytes*int64(config.EccOverheadPct)+99)/100
+}
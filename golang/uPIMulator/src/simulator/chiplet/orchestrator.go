@@ -18,46 +18,77 @@ import (
 // placeholder emits one batch of initialization tasks so that the scheduling
 // pipeline can be exercised end-to-end.
 type HostOrchestrator struct {
-	config                  *Config
-	topology                *Topology
-	graph                   *OpGraph
-	commandPath             string
-	remainingDeps           map[int]int
-	readyQueue              []int
-	inFlight                map[int]bool
-	nodeResources           map[int]*resourceUsage
-	nodeBatch               map[int]int
-	batchOutstanding        map[int]int
-	minWaitCycles           int
-	throttleCycles          int
-	digitalRR               int
-	rramRR                  int
-	lastDigitalID           int
-	lastRramID              int
-	transferBytesEstimate   int64
-	transferBandwidthBytes  int64
-	maxIssuePerCycle        int
-	maxDigitalPerCycle      int
-	maxRramPerCycle         int
-	maxTransferBytes        int64
-	digitalBufferLimit      int64
-	rramBufferLimit         int64
-	interconnectBufferLimit int64
-	enableResourceLimits    bool
-	streamEnabled           bool
-	streamTemplate          *OpGraph
-	streamLowWatermark      int
-	streamHighWatermark     int
-	streamTotalBatches      int
-	streamBatchesIssued     int
-	streamBatchesCompleted  int
-	streamActiveBatches     int
-	nextNodeID              int
-	hostEvents              map[int]*HostEvent
-	outstanding             outstandingTracker
-	moeSessions             map[int]*moeDispatchSession
-	moeMergeOwners          map[int]int
-	transferEstimator       TransferLatencyEstimator
+	config                     *Config
+	topology                   *Topology
+	graph                      *OpGraph
+	commandPath                string
+	commandGraph               []CommandDescriptor
+	remainingDeps              map[int]int
+	readyQueue                 []int
+	inFlight                   map[int]bool
+	nodeResources              map[int]*resourceUsage
+	nodeBatch                  map[int]int
+	batchOutstanding           map[int]int
+	minWaitCycles              int
+	throttleCycles             int
+	digitalRR                  int
+	rramRR                     int
+	lastDigitalID              int
+	lastRramID                 int
+	transferBytesEstimate      int64
+	transferBandwidthBytes     int64
+	maxIssuePerCycle           int
+	maxDigitalPerCycle         int
+	maxRramPerCycle            int
+	maxTransferBytes           int64
+	digitalBufferLimit         int64
+	rramBufferLimit            int64
+	interconnectBufferLimit    int64
+	enableResourceLimits       bool
+	streamEnabled              bool
+	streamTemplate             *OpGraph
+	streamLowWatermark         int
+	streamHighWatermark        int
+	streamTotalBatches         int
+	streamBatchesIssued        int
+	streamBatchesCompleted     int
+	streamActiveBatches        int
+	nextNodeID                 int
+	hostEvents                 map[int]*HostEvent
+	outstanding                outstandingTracker
+	moeSessions                map[int]*moeDispatchSession
+	moeMergeOwners             map[int]int
+	moeDeferredFetches         []deferredGatingFetch
+	moeExpanding               bool
+	moeSessionDeferrals        int
+	moeSessionPeak             int
+	moeSkippedExpertCommands   int
+	moeAlltoallCollectives     int
+	moeAlltoallBytes           int64
+	moeAlltoallCycles          int64
+	collectiveBroadcastCycles  int64
+	moeExpertTokens            map[int]int64
+	bootstrapIters             int
+	hostDispatchBoundCycles    int
+	transferEstimator          TransferLatencyEstimator
+	advanceTicks               int
+	batchStartTick             map[int]int
+	batchLatencies             []int
+	activeBatchTicks           int64
+	activeBatchPeak            int
+	producerSpan               map[int]int
+	inflightTransferNodes      map[int]bool
+	inflightTransferLimitHit   bool
+	inflightLimitedCycles      int
+	prefetchTemplateIDs        []int
+	prefetchIssued             map[int]bool
+	prefetchedBatches          int
+	crossTargetMissingTransfer int
+	checksumActual             map[int]uint64
+	checksumExpected           map[int]uint64
+	checksumVerified           int
+	checksumMismatches         int
+	weightLayoutPlaced         int
 }
 
 const debugMaxDebugEvents = 50
@@ -97,6 +128,7 @@ func (this *HostOrchestrator) Init(config *Config, topology *Topology, commandPa
 	this.remainingDeps = make(map[int]int)
 	this.readyQueue = make([]int, 0)
 	this.inFlight = make(map[int]bool)
+	this.inflightTransferNodes = nil
 	this.enableResourceLimits = config.HostLimitResources
 	this.nodeBatch = make(map[int]int)
 	this.batchOutstanding = make(map[int]int)
@@ -190,6 +222,16 @@ func (this *HostOrchestrator) Init(config *Config, topology *Topology, commandPa
 	this.outstanding = outstandingTracker{}
 	this.moeSessions = make(map[int]*moeDispatchSession)
 	this.moeMergeOwners = make(map[int]int)
+	this.moeDeferredFetches = nil
+	this.moeSessionDeferrals = 0
+	this.moeSessionPeak = 0
+	this.moeSkippedExpertCommands = 0
+	this.moeAlltoallCollectives = 0
+	this.moeAlltoallBytes = 0
+	this.moeAlltoallCycles = 0
+	this.collectiveBroadcastCycles = 0
+	this.moeExpertTokens = make(map[int]int64)
+	this.hostDispatchBoundCycles = 0
 
 	if topology != nil {
 		if topology.Digital.PeCols > 0 {
@@ -214,6 +256,10 @@ func (this *HostOrchestrator) Init(config *Config, topology *Topology, commandPa
 			}
 		}
 	}
+	this.bootstrapIters = 0
+	if this.commandGraph != nil && this.loadCommands(cloneCommands(this.commandGraph)) {
+		return
+	}
 	if commandPath != "" && this.loadCommandGraph(commandPath) {
 		return
 	}
@@ -227,6 +273,7 @@ func (this *HostOrchestrator) Fini() {
 	this.remainingDeps = nil
 	this.readyQueue = nil
 	this.inFlight = nil
+	this.inflightTransferNodes = nil
 	this.nodeResources = nil
 	this.nodeBatch = nil
 	this.batchOutstanding = nil
@@ -263,7 +310,9 @@ func (this *HostOrchestrator) Fini() {
 // Advance returns the next task to stage. Future versions will incorporate
 // dependency checks and adaptive batching.
 func (this *HostOrchestrator) Advance() []*Task {
+	this.advanceTicks++
 	this.ensureStreamingCapacity()
+	this.sampleActiveBatches()
 
 	if this.throttleCycles > 0 {
 		this.throttleCycles--
@@ -276,10 +325,23 @@ func (this *HostOrchestrator) Advance() []*Task {
 	var transferIssued int64 = 0
 	requeue := make([]int, 0)
 
+	if this.config != nil && this.config.SchedulerMode == "oldest_batch" && len(this.readyQueue) > 1 {
+		sort.SliceStable(this.readyQueue, func(i, j int) bool {
+			return this.nodeBatch[this.readyQueue[i]] < this.nodeBatch[this.readyQueue[j]]
+		})
+	}
+
+	this.inflightTransferLimitHit = false
+	hostLimit := this.hostDispatchLimit()
 	for len(this.readyQueue) > 0 {
 		if this.maxIssuePerCycle > 0 && len(result) >= this.maxIssuePerCycle {
 			break
 		}
+		if hostLimit > 0 && len(result) >= hostLimit {
+			// 硬件侧仍可继续发射，但 host 调度线程本周期已用尽派发预算。
+			this.hostDispatchBoundCycles++
+			break
+		}
 
 		nodeID := this.readyQueue[0]
 		this.readyQueue = this.readyQueue[1:]
@@ -298,6 +360,7 @@ func (this *HostOrchestrator) Advance() []*Task {
 			continue
 		}
 
+		this.stampPipelineChecksum(node)
 		task := this.createTaskFromNode(node)
 		this.inFlight[nodeID] = true
 		if task != nil {
@@ -317,6 +380,9 @@ func (this *HostOrchestrator) Advance() []*Task {
 	if len(requeue) > 0 {
 		this.readyQueue = append(requeue, this.readyQueue...)
 	}
+	if this.inflightTransferLimitHit {
+		this.inflightLimitedCycles++
+	}
 
 	if len(result) == 0 {
 		return nil
@@ -324,6 +390,8 @@ func (this *HostOrchestrator) Advance() []*Task {
 	return result
 }
 
+// bootstrapTasks 在没有命令文件时构造 6 节点的合成流水线；--chiplet_bootstrap_iters
+// 将其首尾相接重复 N 次，得到形状已知、规模可调的负载。
 func (this *HostOrchestrator) bootstrapTasks() {
 	if this.topology == nil {
 		return
@@ -333,61 +401,84 @@ func (this *HostOrchestrator) bootstrapTasks() {
 	this.lastDigitalID = -1
 	this.lastRramID = -1
 
-	graph.AddNode(&OpNode{
-		ID:      0,
-		Type:    TaskTypeDataMove,
-		Target:  TaskTargetDigital,
-		Latency: 4,
-		Payload: "tokenize",
-	})
-	graph.AddNode(&OpNode{
-		ID:      1,
-		Type:    TaskTypeCompute,
-		Target:  TaskTargetDigital,
-		Latency: this.topology.Digital.PeCols,
-		Deps:    []int{0},
-		Payload: "attention",
-	})
-	graph.AddNode(&OpNode{
-		ID:      2,
-		Type:    TaskTypeDataMove,
-		Target:  TaskTargetTransfer,
-		Latency: this.topology.Digital.PeCols / 8,
-		Deps:    []int{1},
-		Payload: "transfer_to_rram",
-	})
-	graph.AddNode(&OpNode{
-		ID:      3,
-		Type:    TaskTypeCim,
-		Target:  TaskTargetRram,
-		Latency: this.topology.Rram.SaRows,
-		Deps:    []int{2},
-		Payload: "cim",
-	})
-	graph.AddNode(&OpNode{
-		ID:      4,
-		Type:    TaskTypeDataMove,
-		Target:  TaskTargetTransfer,
-		Latency: this.topology.Digital.PeCols / 8,
-		Deps:    []int{3},
-		Payload: "transfer_to_digital",
-	})
-	graph.AddNode(&OpNode{
-		ID:      5,
-		Type:    TaskTypeCompute,
-		Target:  TaskTargetDigital,
-		Latency: this.topology.Digital.PeCols / 2,
-		Deps:    []int{4},
-		Payload: "postprocess",
-	})
+	iters := 1
+	if this.config != nil && this.config.BootstrapIters > 1 {
+		iters = this.config.BootstrapIters
+	}
+	this.bootstrapIters = iters
+
+	for iter := 0; iter < iters; iter++ {
+		base := iter * 6
+		var entryDeps []int
+		if iter > 0 {
+			entryDeps = []int{base - 1}
+		}
+		graph.AddNode(&OpNode{
+			ID:      base,
+			Type:    TaskTypeDataMove,
+			Target:  TaskTargetDigital,
+			Latency: 4,
+			Deps:    entryDeps,
+			Payload: "tokenize",
+		})
+		graph.AddNode(&OpNode{
+			ID:      base + 1,
+			Type:    TaskTypeCompute,
+			Target:  TaskTargetDigital,
+			Latency: this.topology.Digital.PeCols,
+			Deps:    []int{base},
+			Payload: "attention",
+		})
+		graph.AddNode(&OpNode{
+			ID:      base + 2,
+			Type:    TaskTypeDataMove,
+			Target:  TaskTargetTransfer,
+			Latency: this.topology.Digital.PeCols / 8,
+			Deps:    []int{base + 1},
+			Payload: "transfer_to_rram",
+		})
+		graph.AddNode(&OpNode{
+			ID:      base + 3,
+			Type:    TaskTypeCim,
+			Target:  TaskTargetRram,
+			Latency: this.topology.Rram.SaRows,
+			Deps:    []int{base + 2},
+			Payload: "cim",
+		})
+		graph.AddNode(&OpNode{
+			ID:      base + 4,
+			Type:    TaskTypeDataMove,
+			Target:  TaskTargetTransfer,
+			Latency: this.topology.Digital.PeCols / 8,
+			Deps:    []int{base + 3},
+			Payload: "transfer_to_digital",
+		})
+		graph.AddNode(&OpNode{
+			ID:      base + 5,
+			Type:    TaskTypeCompute,
+			Target:  TaskTargetDigital,
+			Latency: this.topology.Digital.PeCols / 2,
+			Deps:    []int{base + 4},
+			Payload: "postprocess",
+		})
+	}
 
 	this.setGraph(graph)
 }
 
+// BootstrapIters 返回合成 bootstrap 流水线的重复次数；使用命令文件时为 0。
+func (this *HostOrchestrator) BootstrapIters() int {
+	if this == nil {
+		return 0
+	}
+	return this.bootstrapIters
+}
+
 func (this *HostOrchestrator) setGraph(graph *OpGraph) {
 	this.remainingDeps = make(map[int]int)
 	this.readyQueue = make([]int, 0)
 	this.inFlight = make(map[int]bool)
+	this.inflightTransferNodes = nil
 	if this.enableResourceLimits {
 		this.nodeResources = make(map[int]*resourceUsage)
 	} else {
@@ -395,6 +486,20 @@ func (this *HostOrchestrator) setGraph(graph *OpGraph) {
 	}
 	this.nodeBatch = make(map[int]int)
 	this.batchOutstanding = make(map[int]int)
+	this.batchStartTick = make(map[int]int)
+	this.batchLatencies = nil
+	this.prefetchTemplateIDs = nil
+	this.prefetchIssued = make(map[int]bool)
+	this.prefetchedBatches = 0
+	this.checksumActual = nil
+	this.checksumExpected = nil
+	this.checksumVerified = 0
+	this.checksumMismatches = 0
+	this.weightLayoutPlaced = 0
+	if this.config != nil && this.config.PipelineChecksum {
+		this.checksumActual = make(map[int]uint64)
+		this.checksumExpected = make(map[int]uint64)
+	}
 	this.streamBatchesIssued = 0
 	this.streamBatchesCompleted = 0
 	this.streamActiveBatches = 0
@@ -403,6 +508,9 @@ func (this *HostOrchestrator) setGraph(graph *OpGraph) {
 	if this.streamEnabled && graph != nil {
 		this.streamTemplate = graph.Clone()
 		this.graph = NewOpGraph()
+		if this.config != nil && this.config.WeightPrefetch {
+			this.prefetchTemplateIDs = firstLayerWeightLoads(this.streamTemplate)
+		}
 		this.ensureStreamingCapacity()
 		return
 	}
@@ -598,12 +706,120 @@ func (this *HostOrchestrator) instantiateStreamBatch() bool {
 	}
 
 	this.batchOutstanding[batchID] = outstanding
+	if this.batchStartTick != nil {
+		this.batchStartTick[batchID] = this.advanceTicks
+	}
 	this.streamActiveBatches++
 	this.streamBatchesIssued++
 
 	return true
 }
 
+// firstLayerWeightLoads returns the template weight-load nodes that no other
+// weight load precedes, i.e. the loads a fresh batch needs before its first
+// RRAM stage can run.
+func firstLayerWeightLoads(template *OpGraph) []int {
+	if template == nil {
+		return nil
+	}
+	ids := sortedNodeIDs(template)
+	weightBefore := make(map[int]bool, len(ids))
+	var visit func(id int) bool
+	visit = func(id int) bool {
+		if seen, ok := weightBefore[id]; ok {
+			return seen
+		}
+		weightBefore[id] = false
+		node := template.Nodes[id]
+		if node == nil {
+			return false
+		}
+		result := false
+		for _, dep := range node.Deps {
+			if templateWeightLoad(template.Nodes[dep]) != nil || visit(dep) {
+				result = true
+				break
+			}
+		}
+		weightBefore[id] = result
+		return result
+	}
+
+	first := make([]int, 0)
+	for _, id := range ids {
+		if templateWeightLoad(template.Nodes[id]) != nil && !visit(id) {
+			first = append(first, id)
+		}
+	}
+	return first
+}
+
+func templateWeightLoad(node *OpNode) *CommandDescriptor {
+	if node == nil {
+		return nil
+	}
+	switch payload := node.Payload.(type) {
+	case *CommandDescriptor:
+		if payload != nil && payload.Kind == CommandKindRramWeightLoad {
+			return payload
+		}
+	case CommandDescriptor:
+		if payload.Kind == CommandKindRramWeightLoad {
+			return &payload
+		}
+	}
+	return nil
+}
+
+// maybePrefetchNextBatch issues speculative first-layer weight loads for
+// batch+1 once half of the current batch has drained, so the loads overlap
+// the tail of its compute. The loads are tagged "prefetch" and do not count
+// toward any batch; the platform decides whether capacity allows them.
+func (this *HostOrchestrator) maybePrefetchNextBatch(batchID int, remaining int) {
+	if len(this.prefetchTemplateIDs) == 0 || this.streamTemplate == nil || this.prefetchIssued == nil {
+		return
+	}
+	next := batchID + 1
+	if this.prefetchIssued[next] || next < this.streamBatchesIssued {
+		return
+	}
+	if this.streamTotalBatches > 0 && next >= this.streamTotalBatches {
+		return
+	}
+	if remaining*2 > len(this.streamTemplate.Nodes) {
+		return
+	}
+	this.prefetchIssued[next] = true
+
+	commands := make([]CommandDescriptor, 0, len(this.prefetchTemplateIDs))
+	for _, templateID := range this.prefetchTemplateIDs {
+		tmpl := templateWeightLoad(this.streamTemplate.Nodes[templateID])
+		if tmpl == nil {
+			continue
+		}
+		cmd := *tmpl
+		cmd.Dependencies = nil
+		annotateStreamCommand(&cmd, next, templateID)
+		cmd.Metadata["prefetch"] = true
+		commands = append(commands, cmd)
+	}
+	for _, id := range this.AppendCommandGroup(commands, nil, false) {
+		delete(this.nodeBatch, id)
+	}
+	if len(commands) > 0 {
+		this.prefetchedBatches++
+	}
+}
+
+// PrefetchedWeightBatches returns how many upcoming batches had their
+// first-layer weights prefetched.
+func (this *HostOrchestrator) PrefetchedWeightBatches() int {
+	if this == nil {
+		return 0
+	}
+	return this.prefetchedBatches
+}
+
 func sortedNodeIDs(graph *OpGraph) []int {
 	ids := make([]int, 0, len(graph.Nodes))
 	for id := range graph.Nodes {
@@ -675,17 +891,56 @@ func annotateStreamCommand(cmd *CommandDescriptor, batchID int, templateID int)
 	cmd.Metadata = meta
 }
 
-func (this *HostOrchestrator) loadCommandGraph(commandPath string) bool {
-	path := filepath.Clean(commandPath)
-	data, err := os.ReadFile(path)
+// ReadCommandGraph parses a chiplet_commands.json file into command
+// descriptors.
+func ReadCommandGraph(commandPath string) ([]CommandDescriptor, error) {
+	data, err := os.ReadFile(filepath.Clean(commandPath))
 	if err != nil {
-		return false
+		return nil, err
 	}
-
 	var commands []CommandDescriptor
 	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// SetCommandGraph supplies an already parsed command graph, so that several
+// runs can share one read of chiplet_commands.json. Init then loads a private
+// copy of it instead of reading its command path.
+func (this *HostOrchestrator) SetCommandGraph(commands []CommandDescriptor) {
+	this.commandGraph = commands
+}
+
+// cloneCommands copies commands deeply enough that a run mutating command
+// metadata or dependencies leaves the shared graph untouched.
+func cloneCommands(commands []CommandDescriptor) []CommandDescriptor {
+	clone := make([]CommandDescriptor, len(commands))
+	for idx, cmd := range commands {
+		if cmd.Dependencies != nil {
+			cmd.Dependencies = append([]int32(nil), cmd.Dependencies...)
+		}
+		if cmd.Metadata != nil {
+			meta := make(map[string]interface{}, len(cmd.Metadata))
+			for key, value := range cmd.Metadata {
+				meta[key] = value
+			}
+			cmd.Metadata = meta
+		}
+		clone[idx] = cmd
+	}
+	return clone
+}
+
+func (this *HostOrchestrator) loadCommandGraph(commandPath string) bool {
+	commands, err := ReadCommandGraph(commandPath)
+	if err != nil {
 		return false
 	}
+	return this.loadCommands(commands)
+}
+
+func (this *HostOrchestrator) loadCommands(commands []CommandDescriptor) bool {
 	if len(commands) == 0 {
 		return false
 	}
@@ -694,6 +949,7 @@ func (this *HostOrchestrator) loadCommandGraph(commandPath string) bool {
 	this.lastDigitalID = -1
 	this.lastRramID = -1
 	prevID := -1
+	explicit := make(map[int]bool, len(commands))
 
 	for idx := range commands {
 		cmdCopy := commands[idx]
@@ -726,6 +982,7 @@ func (this *HostOrchestrator) loadCommandGraph(commandPath string) bool {
 		for _, dep := range cmdCopy.Dependencies {
 			deps = append(deps, int(dep))
 		}
+		explicit[nodeID] = len(deps) > 0
 		if len(deps) == 0 && prevID >= 0 {
 			deps = append(deps, prevID)
 		}
@@ -751,6 +1008,7 @@ func (this *HostOrchestrator) loadCommandGraph(commandPath string) bool {
 		prevID = nodeID
 	}
 
+	this.validateCrossTargetEdges(graph, explicit)
 	this.setGraph(graph)
 	return true
 }
@@ -822,11 +1080,16 @@ func (this *HostOrchestrator) canIssueNode(node *OpNode, digitalIssued *int, rra
 			this.outstanding.Rram += usage.Rram
 		}
 	case TaskTargetTransfer:
+		if limit := this.maxInflightTransfers(); limit > 0 && len(this.inflightTransferNodes) >= limit {
+			// DMA/NoC 的 tag 与描述符槽位耗尽，带宽有余也无法再发射。
+			this.inflightTransferLimitHit = true
+			return false
+		}
 		bytes := this.transferBytesEstimate
 		if isCommand && cmd.PayloadBytes > 0 {
 			bytes = int64(cmd.PayloadBytes)
 		}
-		limitCost := bytes
+		limitCost := EccWireBytes(this.config, bytes)
 		if this.maxTransferBytes > 0 && limitCost > this.maxTransferBytes {
 			limitCost = this.maxTransferBytes
 		}
@@ -834,6 +1097,12 @@ func (this *HostOrchestrator) canIssueNode(node *OpNode, digitalIssued *int, rra
 			return false
 		}
 		*transferIssued += limitCost
+		if this.maxInflightTransfers() > 0 {
+			if this.inflightTransferNodes == nil {
+				this.inflightTransferNodes = make(map[int]bool)
+			}
+			this.inflightTransferNodes[node.ID] = true
+		}
 		if useLimits {
 			if usage.Transfer <= 0 {
 				usage.Transfer = bytes
@@ -856,6 +1125,22 @@ func (this *HostOrchestrator) canIssueNode(node *OpNode, digitalIssued *int, rra
 	return true
 }
 
+func (this *HostOrchestrator) maxInflightTransfers() int {
+	if this.config == nil {
+		return 0
+	}
+	return this.config.MaxInflightTransfers
+}
+
+// TransferInflightLimitedCycles 返回因在途传输数达到 --chiplet_max_inflight_transfers
+// 而暂停发射传输的节拍数。
+func (this *HostOrchestrator) TransferInflightLimitedCycles() int {
+	if this == nil {
+		return 0
+	}
+	return this.inflightLimitedCycles
+}
+
 func (this *HostOrchestrator) NotifyBackpressure(waitCycles int) {
 	if waitCycles <= this.minWaitCycles {
 		return
@@ -877,6 +1162,8 @@ func (this *HostOrchestrator) NotifyTaskCompletion(nodeID int) {
 	}
 
 	delete(this.inFlight, nodeID)
+	delete(this.inflightTransferNodes, nodeID)
+	this.recordPipelineChecksum(this.graph.Nodes[nodeID])
 	if this.enableResourceLimits {
 		if usage, ok := this.nodeResources[nodeID]; ok && usage != nil {
 			if usage.Digital > 0 {
@@ -913,6 +1200,10 @@ func (this *HostOrchestrator) NotifyTaskCompletion(nodeID int) {
 					remaining--
 					if remaining <= 0 {
 						delete(this.batchOutstanding, batchID)
+						if start, ok := this.batchStartTick[batchID]; ok {
+							this.batchLatencies = append(this.batchLatencies, this.advanceTicks-start)
+							delete(this.batchStartTick, batchID)
+						}
 						this.streamActiveBatches--
 						if this.streamActiveBatches < 0 {
 							this.streamActiveBatches = 0
@@ -920,6 +1211,7 @@ func (this *HostOrchestrator) NotifyTaskCompletion(nodeID int) {
 						this.streamBatchesCompleted++
 					} else {
 						this.batchOutstanding[batchID] = remaining
+						this.maybePrefetchNextBatch(batchID, remaining)
 					}
 				}
 			}
@@ -933,16 +1225,7 @@ func (this *HostOrchestrator) NotifyTaskCompletion(nodeID int) {
 		this.handleHostEvent(nodeID, event)
 	}
 
-	for _, succ := range this.graph.Successors(nodeID) {
-		if _, exists := this.remainingDeps[succ]; exists {
-			if this.remainingDeps[succ] > 0 {
-				this.remainingDeps[succ]--
-			}
-			if this.remainingDeps[succ] == 0 {
-				this.readyQueue = append(this.readyQueue, succ)
-			}
-		}
-	}
+	this.releaseSuccessors(nodeID)
 
 	if debugCompleteCounter < debugMaxDebugEvents {
 		if node, ok := this.graph.Nodes[nodeID]; ok && node != nil {
@@ -1009,6 +1292,10 @@ func (this *HostOrchestrator) handleHostEvent(nodeID int, event *HostEvent) {
 
 	switch event.Kind {
 	case CommandKindHostGatingFetch:
+		if this.moeSessionLimitReached() {
+			this.deferGatingFetch(nodeID, event)
+			return
+		}
 		this.handleGatingFetchEvent(nodeID, event)
 	default:
 		// Other host events will be wired in future phases.
@@ -1061,12 +1348,33 @@ func (this *HostOrchestrator) handleGatingFetchEvent(nodeID int, event *HostEven
 	mergeNodeIDs := make([]int, 0, len(selected))
 	resolvedDigitalID := event.DigitalChiplet
 
-	for _, expertID := range selected {
-		group := this.buildExpertCommandGroup(event, expertID)
+	expertTokens := metadataIntSlice(event.Metadata, "expert_tokens")
+	this.recordExpertLoad(event, selected, expertTokens)
+	groups := make([][]CommandDescriptor, len(selected))
+	for index, expertID := range selected {
+		groups[index] = this.buildExpertCommandGroup(event, expertID)
+	}
+	this.applyMoeAlltoall(groups, expertTokens)
+	for index, expertID := range selected {
+		group := groups[index]
 		if len(group) == 0 {
 			continue
 		}
-		newIDs := this.AppendCommandGroup(group, baseDeps, true)
+		if this.expertIsEmpty(expertTokens, index) {
+			// 容量丢弃或门控后未分到 token 的专家不发射任何命令，barrier 只等待实际发射的专家。
+			this.moeSkippedExpertCommands += len(group)
+			continue
+		}
+		var newIDs []int
+		if this.loadPathMode() == "overlap" && len(group) > 2 && group[0].Kind == CommandKindRramWeightLoad {
+			// 权重加载与激活传入并行发射，stage 同时依赖二者。
+			weightIDs := this.AppendCommandGroup(group[:1], baseDeps, false)
+			rest := append([]CommandDescriptor(nil), group[1:]...)
+			rest[1].Dependencies = []int32{int32(weightIDs[0])}
+			newIDs = append(weightIDs, this.AppendCommandGroup(rest, baseDeps, true)...)
+		} else {
+			newIDs = this.AppendCommandGroup(group, baseDeps, true)
+		}
 		if hasBatch {
 			this.assignNodesToBatch(newIDs, parentBatch)
 		}
@@ -1105,6 +1413,8 @@ func (this *HostOrchestrator) handleGatingFetchEvent(nodeID int, event *HostEven
 		barrierMeta["features"] = event.Features
 		barrierMeta["selected_experts"] = append([]int(nil), selected...)
 		barrierMeta["candidate_experts"] = append([]int(nil), event.CandidateExperts...)
+		barrierMeta["active_experts"] = append([]int(nil), session.expertIDs...)
+		barrierMeta["producers"] = len(mergeNodeIDs)
 
 		barrierLatency := metadataInt(event.Metadata, "barrier_latency", 1)
 		if barrierLatency <= 0 {
@@ -1143,6 +1453,9 @@ func (this *HostOrchestrator) handleGatingFetchEvent(nodeID int, event *HostEven
 	if session.outstanding > 0 {
 		this.deferSuccessorsForSession(session)
 		this.moeSessions[nodeID] = session
+		if len(this.moeSessions) > this.moeSessionPeak {
+			this.moeSessionPeak = len(this.moeSessions)
+		}
 	} else {
 		this.finalizeMoeSession(nodeID, session)
 	}
@@ -1255,6 +1568,13 @@ func (this *HostOrchestrator) buildExpertCommandGroup(event *HostEvent, expertID
 		return meta
 	}
 
+	loadPathMode := this.loadPathMode()
+	weightTag := fmt.Sprintf("moe_expert%d", expertID)
+	stageMeta := expertMeta("moe_expert_stage")
+	if loadPathMode != "none" {
+		stageMeta["weight_tag"] = weightTag
+	}
+
 	transferInDescMeta := expertMeta("moe_expert_transfer_in")
 	if bandwidthDr > 0 {
 		transferInDescMeta["transfer_bandwidth_bytes"] = bandwidthDr
@@ -1282,7 +1602,7 @@ func (this *HostOrchestrator) buildExpertCommandGroup(event *HostEvent, expertID
 			Aux2:         uint32(kDim),
 			Aux3:         uint32(outputBytes),
 			Latency:      int32(stageLatency),
-			Metadata:     expertMeta("moe_expert_stage"),
+			Metadata:     stageMeta,
 		},
 		{
 			Kind:         CommandKindRramExecute,
@@ -1312,6 +1632,22 @@ func (this *HostOrchestrator) buildExpertCommandGroup(event *HostEvent, expertID
 		},
 	}
 
+	if loadPathMode != "none" {
+		weightMeta := expertMeta("moe_expert_weight_load")
+		weightMeta["weight_tag"] = weightTag
+		weightLoad := CommandDescriptor{
+			Kind:        CommandKindRramWeightLoad,
+			Target:      TaskTargetRram,
+			ChipletID:   int32(rramID),
+			PayloadAddr: uint32(weightBytes),
+			Aux0:        uint32(rows),
+			Aux1:        uint32(cols),
+			Aux2:        uint32(kDim),
+			Metadata:    weightMeta,
+		}
+		group = append([]CommandDescriptor{weightLoad}, group...)
+	}
+
 	transferOutDescMeta := expertMeta("moe_expert_transfer_out")
 	if bandwidthRd > 0 {
 		transferOutDescMeta["transfer_bandwidth_bytes"] = bandwidthRd
@@ -1418,10 +1754,203 @@ func (this *HostOrchestrator) HasPendingWork() bool {
 	return false
 }
 
+// BatchLatencies returns the issue-to-completion latency, in orchestrator ticks
+// (one per digital-domain tick), of every streaming batch completed so far.
+func (this *HostOrchestrator) BatchLatencies() []int {
+	if this == nil {
+		return nil
+	}
+	return append([]int(nil), this.batchLatencies...)
+}
+
+// sampleActiveBatches 每个节拍累加在途批次数，积分除以节拍数即有效 batch size。
+func (this *HostOrchestrator) sampleActiveBatches() {
+	this.activeBatchTicks += int64(this.streamActiveBatches)
+	if this.streamActiveBatches > this.activeBatchPeak {
+		this.activeBatchPeak = this.streamActiveBatches
+	}
+}
+
+// AverageActiveBatches returns the time-averaged number of in-flight streaming
+// batches over all orchestrator ticks, i.e. the effective batch size sustained.
+func (this *HostOrchestrator) AverageActiveBatches() float64 {
+	if this == nil || this.advanceTicks == 0 {
+		return 0
+	}
+	return float64(this.activeBatchTicks) / float64(this.advanceTicks)
+}
+
+// PeakActiveBatches returns the largest number of concurrently active batches.
+func (this *HostOrchestrator) PeakActiveBatches() int {
+	if this == nil {
+		return 0
+	}
+	return this.activeBatchPeak
+}
+
+// loadPathMode returns how MoE expert groups model the weight-load and
+// activation paths: "none" (no explicit weight load), "serial" or "overlap".
+func (this *HostOrchestrator) loadPathMode() string {
+	if this == nil || this.config == nil || this.config.RramLoadPathMode == "" {
+		return "none"
+	}
+	return this.config.RramLoadPathMode
+}
+
+// BlockedNodes counts nodes still waiting on unresolved dependencies. A
+// non-zero count once HasPendingWork reports false means the graph can never
+// drain.
+func (this *HostOrchestrator) BlockedNodes() int {
+	if this == nil {
+		return 0
+	}
+	blocked := 0
+	for _, deps := range this.remainingDeps {
+		if deps > 0 {
+			blocked++
+		}
+	}
+	return blocked
+}
+
+// SnapshotRemaining returns the commands of every node that has not completed
+// (blocked on dependencies, ready, or issued but not yet executed) in node-ID
+// order. Dependencies on completed nodes are dropped; dependencies on nodes
+// that are missing from the graph are kept so a dangling edge still blocks on
+// replay. Nodes without a CommandDescriptor payload cannot be serialized and
+// are only counted in the second return value.
+func (this *HostOrchestrator) SnapshotRemaining() ([]CommandDescriptor, int) {
+	if this == nil || this.graph == nil {
+		return nil, 0
+	}
+
+	remaining := make(map[int]bool)
+	for id, deps := range this.remainingDeps {
+		if deps > 0 {
+			remaining[id] = true
+		}
+	}
+	for _, id := range this.readyQueue {
+		remaining[id] = true
+	}
+	for id := range this.inFlight {
+		remaining[id] = true
+	}
+
+	ids := make([]int, 0, len(remaining))
+	for id := range remaining {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	commands := make([]CommandDescriptor, 0, len(ids))
+	skipped := 0
+	for _, id := range ids {
+		node := this.graph.Nodes[id]
+		if node == nil {
+			continue
+		}
+		cmd, ok := node.Payload.(*CommandDescriptor)
+		if !ok || cmd == nil {
+			skipped++
+			continue
+		}
+		snapshot := *cmd
+		snapshot.ID = int32(id)
+		snapshot.Dependencies = nil
+		for _, dep := range node.Deps {
+			if _, known := this.graph.Nodes[dep]; remaining[dep] || !known {
+				snapshot.Dependencies = append(snapshot.Dependencies, int32(dep))
+			}
+		}
+		commands = append(commands, snapshot)
+	}
+	return commands, skipped
+}
+
+// GraphNodeSnapshot describes one node of the command graph as it stands after
+// MoE expansion, stream cloning and splitting, with the chiplet resolved at
+// issue time.
+type GraphNodeSnapshot struct {
+	ID        int    `json:"id"`
+	Kind      string `json:"kind"`
+	Target    string `json:"target"`
+	ChipletID int    `json:"chiplet_id"`
+	Queue     int    `json:"queue"`
+	Batch     int    `json:"batch"`
+	Latency   int    `json:"latency"`
+	State     string `json:"state"`
+	Deps      []int  `json:"deps"`
+}
+
+// SnapshotGraph returns every node of the current graph in node-ID order.
+// State is "completed", "in_flight", "ready" or "blocked"; nodes without a
+// command payload (the synthetic bootstrap pipeline) report their payload
+// string as the kind and -1 for chiplet and queue.
+func (this *HostOrchestrator) SnapshotGraph() []GraphNodeSnapshot {
+	if this == nil || this.graph == nil {
+		return nil
+	}
+
+	ready := make(map[int]bool, len(this.readyQueue))
+	for _, id := range this.readyQueue {
+		ready[id] = true
+	}
+
+	ids := make([]int, 0, len(this.graph.Nodes))
+	for id := range this.graph.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	nodes := make([]GraphNodeSnapshot, 0, len(ids))
+	for _, id := range ids {
+		node := this.graph.Nodes[id]
+		if node == nil {
+			continue
+		}
+		snapshot := GraphNodeSnapshot{
+			ID:        id,
+			Target:    node.Target.String(),
+			ChipletID: -1,
+			Queue:     -1,
+			Batch:     node.Batch,
+			Latency:   node.Latency,
+			Deps:      append([]int{}, node.Deps...),
+		}
+		switch payload := node.Payload.(type) {
+		case *CommandDescriptor:
+			if payload != nil {
+				snapshot.Kind = payload.Kind.String()
+				snapshot.ChipletID = int(payload.ChipletID)
+				snapshot.Queue = int(payload.Queue)
+				if payload.Latency > 0 {
+					snapshot.Latency = int(payload.Latency)
+				}
+			}
+		case string:
+			snapshot.Kind = payload
+		}
+		switch {
+		case this.inFlight[id]:
+			snapshot.State = "in_flight"
+		case ready[id]:
+			snapshot.State = "ready"
+		case this.remainingDeps[id] > 0:
+			snapshot.State = "blocked"
+		default:
+			snapshot.State = "completed"
+		}
+		nodes = append(nodes, snapshot)
+	}
+	return nodes
+}
+
 func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 	latency := node.Latency
 	var payload interface{}
 
+	this.applyWeightLayout(node)
 	if cmd, ok := node.Payload.(*CommandDescriptor); ok && cmd != nil {
 		payload = cmd
 		if cmd.Latency > 0 {
@@ -1539,7 +2068,7 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 				}
 			}
 			if cmd.PayloadBytes > 0 && bandwidth > 0 {
-				bytes := int64(cmd.PayloadBytes)
+				bytes := EccWireBytes(this.config, int64(cmd.PayloadBytes))
 				cycles := int((bytes + bandwidth - 1) / bandwidth)
 				if cycles <= 0 {
 					cycles = 1
@@ -1592,6 +2121,7 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 				}
 				payloadMap["transfer_bandwidth_bytes"] = bandwidth
 				payloadMap["bytes"] = int(bytes)
+				wireBytes := EccWireBytes(this.config, bytes)
 				stageLower := strings.ToLower(stage)
 				if stageLower == "transfer_to_rram" {
 					if this.lastDigitalID < 0 && this.topology.Digital.NumChiplets > 0 {
@@ -1611,11 +2141,11 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 						payloadMap["transfer_hops"] = hops
 						latency = this.applyTransferLatencyEstimator(&TransferLatencyQuery{
 							Stage:      stageLower,
-							Bytes:      bytes,
+							Bytes:      wireBytes,
 							SrcDigital: src,
 							DstRram:    chipletID,
 							Metadata:   payloadMap,
-						}, transferLatencyFromBandwidth(bytes, int64(bandwidth), hops))
+						}, transferLatencyFromBandwidth(wireBytes, int64(bandwidth), hops))
 					}
 				} else if stageLower == "transfer_to_digital" {
 					if this.lastRramID < 0 && this.topology.Rram.NumChiplets > 0 {
@@ -1635,11 +2165,11 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 					payloadMap["transfer_hops"] = hops
 					latency = this.applyTransferLatencyEstimator(&TransferLatencyQuery{
 						Stage:      stageLower,
-						Bytes:      bytes,
+						Bytes:      wireBytes,
 						SrcRram:    src,
 						DstDigital: dst,
 						Metadata:   payloadMap,
-					}, transferLatencyFromBandwidth(bytes, int64(bandwidth), hops))
+					}, transferLatencyFromBandwidth(wireBytes, int64(bandwidth), hops))
 				}
 			}
 		}
@@ -1647,6 +2177,10 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 		payload = payloadMap
 	}
 
+	overlapWindow := 0
+	if node.Target == TaskTargetTransfer && isDigitalToRramPayload(payload) {
+		overlapWindow = this.producerOverlapWindow(node)
+	}
 	if latency <= 0 {
 		latency = 1
 	}
@@ -1657,6 +2191,11 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 		Type:    node.Type,
 		Latency: latency,
 		Payload: payload,
+		Batch:   node.Batch,
+	}
+	task.OverlapWindow = overlapWindow
+	if batchID, ok := this.nodeBatch[node.ID]; ok {
+		task.Batch = batchID
 	}
 	if cmd, ok := payload.(*CommandDescriptor); ok && cmd != nil {
 		task.Opcode = cmd.Kind
@@ -1679,6 +2218,53 @@ func (this *HostOrchestrator) createTaskFromNode(node *OpNode) *Task {
 	return task
 }
 
+func (this *HostOrchestrator) transferPipelineChunks() int {
+	if this.config == nil {
+		return 0
+	}
+	return this.config.TransferPipelineChunks
+}
+
+// RecordProducerCycles 在流水化传输模式下记录数字节点在 chiplet 上的计算周期，
+// 供依赖它的 digital->RRAM 传输计算可重叠窗口。
+func (this *HostOrchestrator) RecordProducerCycles(nodeID int, cycles int) {
+	if this == nil || this.transferPipelineChunks() <= 1 || cycles <= 0 {
+		return
+	}
+	if this.producerSpan == nil {
+		this.producerSpan = make(map[int]int)
+	}
+	this.producerSpan[nodeID] = cycles
+}
+
+// producerOverlapWindow 返回 digital->RRAM 传输可与之流水重叠的生产者计算时长（取依赖中
+// 最长者）；平台据此按 --chiplet_transfer_pipeline_chunks 把前 N-1 块的搬运隐藏在计算之下。
+// 依赖传输一旦生成即删除生产者记录，避免长图运行时 producerSpan 无限增长。
+func (this *HostOrchestrator) producerOverlapWindow(node *OpNode) int {
+	if this.transferPipelineChunks() <= 1 {
+		return 0
+	}
+	window := 0
+	for _, dep := range node.Deps {
+		if span := this.producerSpan[dep]; span > window {
+			window = span
+		}
+		delete(this.producerSpan, dep)
+	}
+	return window
+}
+
+func isDigitalToRramPayload(payload interface{}) bool {
+	switch v := payload.(type) {
+	case *CommandDescriptor:
+		return v != nil && v.Flags&TransferFlagDirectionMask == TransferFlagDigitalToRram
+	case map[string]interface{}:
+		stage, _ := v["stage"].(string)
+		return strings.EqualFold(stage, "transfer_to_rram")
+	}
+	return false
+}
+
 func transferLatencyFromBandwidth(bytes int64, bandwidth int64, hops int) int {
 	if bandwidth <= 0 {
 		bandwidth = 4096
@@ -1753,6 +2339,296 @@ func (this *HostOrchestrator) finalizeMoeSession(owner int, session *moeDispatch
 	if session != nil && debugIssueCounter < debugMaxDebugEvents {
 		fmt.Printf("[chiplet-debug] host_moe_complete node=%d experts=%d\n", owner, len(session.expertIDs))
 	}
+	this.expandDeferredGatingFetches()
+}
+
+// deferredGatingFetch 记录因会话数达到 --chiplet_max_moe_sessions 而暂缓展开的 gating fetch。
+type deferredGatingFetch struct {
+	nodeID int
+	event  *HostEvent
+}
+
+func (this *HostOrchestrator) moeSessionLimitReached() bool {
+	return this.config != nil && this.config.MaxMoeSessions > 0 && len(this.moeSessions) >= this.config.MaxMoeSessions
+}
+
+// deferGatingFetch 暂缓展开 nodeID 的专家分发；对其后继各加一个占位依赖，
+// 抵消本次完成时的递减，使后继在展开前保持阻塞。
+func (this *HostOrchestrator) deferGatingFetch(nodeID int, event *HostEvent) {
+	this.moeDeferredFetches = append(this.moeDeferredFetches, deferredGatingFetch{nodeID: nodeID, event: event})
+	this.moeSessionDeferrals++
+	if this.graph == nil {
+		return
+	}
+	for _, succ := range this.graph.Successors(nodeID) {
+		if _, exists := this.remainingDeps[succ]; exists {
+			this.remainingDeps[succ]++
+		}
+	}
+}
+
+// expandDeferredGatingFetches 在会话数低于上限时按 FIFO 展开暂缓的 gating fetch。
+// 展开后原后继已改挂到 merge/barrier 节点，剩余挂在 gating 节点上的（新专家组及
+// 未生成会话时的原后继）按该节点刚完成的方式释放。
+func (this *HostOrchestrator) expandDeferredGatingFetches() {
+	if this.moeExpanding {
+		return
+	}
+	this.moeExpanding = true
+	defer func() { this.moeExpanding = false }()
+	for len(this.moeDeferredFetches) > 0 && !this.moeSessionLimitReached() {
+		pending := this.moeDeferredFetches[0]
+		this.moeDeferredFetches = this.moeDeferredFetches[1:]
+		this.handleGatingFetchEvent(pending.nodeID, pending.event)
+		this.releaseSuccessors(pending.nodeID)
+	}
+}
+
+func (this *HostOrchestrator) releaseSuccessors(nodeID int) {
+	if this.graph == nil {
+		return
+	}
+	for _, succ := range this.graph.Successors(nodeID) {
+		if _, exists := this.remainingDeps[succ]; exists {
+			if this.remainingDeps[succ] > 0 {
+				this.remainingDeps[succ]--
+			}
+			if this.remainingDeps[succ] == 0 {
+				this.readyQueue = append(this.readyQueue, succ)
+			}
+		}
+	}
+}
+
+// expertIsEmpty 判断 selected 中第 index 个专家是否未分到 token。expert_tokens 与
+// selected_experts 按位置对齐；缺省或长度不足时视为非空，保持整组发射。
+func (this *HostOrchestrator) expertIsEmpty(expertTokens []int, index int) bool {
+	if this.config != nil && this.config.MoeSkipEmptyExperts == 0 {
+		return false
+	}
+	return index < len(expertTokens) && expertTokens[index] <= 0
+}
+
+// recordExpertLoad 累计每个专家分到的 token 数。expert_tokens 与 selected 按位置对齐，
+// 缺省时每个被选专家计 event.Tokens；未被选中的候选专家计 0，使其计入负载分布。
+func (this *HostOrchestrator) recordExpertLoad(event *HostEvent, selected []int, expertTokens []int) {
+	if this.moeExpertTokens == nil {
+		this.moeExpertTokens = make(map[int]int64)
+	}
+	for _, expertID := range event.CandidateExperts {
+		if _, seen := this.moeExpertTokens[expertID]; !seen {
+			this.moeExpertTokens[expertID] = 0
+		}
+	}
+	for index, expertID := range selected {
+		tokens := event.Tokens
+		if index < len(expertTokens) {
+			tokens = expertTokens[index]
+		}
+		if tokens < 0 {
+			tokens = 0
+		}
+		this.moeExpertTokens[expertID] += int64(tokens)
+	}
+}
+
+// applyMoeAlltoall 在设置 --chiplet_moe_alltoall_bw 时把一次 gating 的全部专家传输
+// 视为一次 all-to-all 集合通信：dispatch（token 送往专家）与 combine（结果送回）两阶段
+// 各自共享源端口带宽，每多一个目标 chiplet 增加一个仲裁周期。每个专家的传入/传出
+// 延迟替换为所在阶段的集合延迟，而不是彼此独立的点对点估计。
+// 设置 --chiplet_collective_broadcast_cycles 时，host 还需先把集合命令广播给每个参与
+// chiplet，这部分控制面开销按参与者数计入首个阶段的启动延迟。
+func (this *HostOrchestrator) applyMoeAlltoall(groups [][]CommandDescriptor, expertTokens []int) {
+	if this.config == nil || this.config.MoeAlltoallBw <= 0 {
+		return
+	}
+
+	dispatchBytes, combineBytes := int64(0), int64(0)
+	dispatchDsts := make(map[int32]struct{})
+	combineSrcs := make(map[int32]struct{})
+	digitalPeers := make(map[int32]struct{})
+	for index, group := range groups {
+		if this.expertIsEmpty(expertTokens, index) {
+			continue
+		}
+		for _, cmd := range group {
+			if cmd.Kind != CommandKindTransferSchedule {
+				continue
+			}
+			switch cmd.Flags {
+			case TransferFlagDigitalToRram:
+				dispatchBytes += int64(cmd.PayloadBytes)
+				dispatchDsts[cmd.ChipletID] = struct{}{}
+				digitalPeers[cmd.Queue] = struct{}{}
+			case TransferFlagRramToDigital:
+				combineBytes += int64(cmd.PayloadBytes)
+				combineSrcs[cmd.Queue] = struct{}{}
+				digitalPeers[cmd.ChipletID] = struct{}{}
+			}
+		}
+	}
+	if len(dispatchDsts) == 0 && len(combineSrcs) == 0 {
+		return
+	}
+
+	dispatchCycles := alltoallPhaseCycles(dispatchBytes, len(dispatchDsts), this.config.MoeAlltoallBw)
+	combineCycles := alltoallPhaseCycles(combineBytes, len(combineSrcs), this.config.MoeAlltoallBw)
+	rramPeers := len(dispatchDsts)
+	for src := range combineSrcs {
+		if _, ok := dispatchDsts[src]; !ok {
+			rramPeers++
+		}
+	}
+	broadcastCycles := this.collectiveBroadcastCost(len(digitalPeers) + rramPeers)
+	if len(dispatchDsts) > 0 {
+		dispatchCycles += broadcastCycles
+	} else {
+		combineCycles += broadcastCycles
+	}
+	// 未分到 token 的专家不会发射，不计入阶段成员。
+	members := make(map[string]int)
+	annotated := make([]*CommandDescriptor, 0)
+	for index, group := range groups {
+		if this.expertIsEmpty(expertTokens, index) {
+			continue
+		}
+		for i := range group {
+			if group[i].Kind != CommandKindTransferSchedule {
+				continue
+			}
+			phase, phaseCycles := "", 0
+			switch group[i].Flags {
+			case TransferFlagDigitalToRram:
+				phase, phaseCycles = "dispatch", dispatchCycles
+			case TransferFlagRramToDigital:
+				phase, phaseCycles = "combine", combineCycles
+			default:
+				continue
+			}
+			group[i].Latency = int32(phaseCycles)
+			if group[i].Metadata == nil {
+				group[i].Metadata = make(map[string]interface{})
+			}
+			// 平台按 (collective_id, phase) 只占用一次链路，时长为整个阶段的集合延迟。
+			group[i].Metadata["moe_alltoall"] = true
+			group[i].Metadata[MetadataKeyCollectiveID] = this.moeAlltoallCollectives
+			group[i].Metadata[MetadataKeyCollectivePhase] = phase
+			group[i].Metadata[MetadataKeyCollectiveCycles] = phaseCycles
+			members[phase]++
+			annotated = append(annotated, &group[i])
+		}
+	}
+	for _, cmd := range annotated {
+		cmd.Metadata[MetadataKeyCollectiveMembers] = members[cmd.Metadata[MetadataKeyCollectivePhase].(string)]
+	}
+
+	this.moeAlltoallCollectives++
+	this.moeAlltoallBytes += dispatchBytes + combineBytes
+	this.moeAlltoallCycles += int64(dispatchCycles + combineCycles)
+	this.collectiveBroadcastCycles += int64(broadcastCycles)
+}
+
+// collectiveBroadcastCost 返回 host 向 participants 个 chiplet 逐一下发集合命令的控制面周期数。
+func (this *HostOrchestrator) collectiveBroadcastCost(participants int) int {
+	if this.config == nil || this.config.BroadcastCycles <= 0 || participants <= 0 {
+		return 0
+	}
+	return participants * this.config.BroadcastCycles
+}
+
+// alltoallPhaseCycles 返回一个 all-to-all 阶段的延迟：peers 个对端同时交换的 bytes 串行
+// 通过带宽为 bw 的端口，外加 peers-1 个仲裁周期表示同时交换的链路争用。
+func alltoallPhaseCycles(bytes int64, peers int, bw int) int {
+	if bytes <= 0 || peers <= 0 {
+		return 0
+	}
+	cycles := int((bytes + int64(bw) - 1) / int64(bw))
+	return cycles + peers - 1
+}
+
+// hostDispatchLimit 返回 --chiplet_host_dispatch_per_cycle 限定的每次 Advance 最大派发数（0 表示不限）。
+func (this *HostOrchestrator) hostDispatchLimit() int {
+	if this.config == nil || this.config.HostDispatchPerCycle <= 0 {
+		return 0
+	}
+	return this.config.HostDispatchPerCycle
+}
+
+// HostDispatchBoundCycles 返回 host 派发预算成为瓶颈（仍有就绪节点却被截断）的 Advance 次数。
+func (this *HostOrchestrator) HostDispatchBoundCycles() int {
+	if this == nil {
+		return 0
+	}
+	return this.hostDispatchBoundCycles
+}
+
+// MoeExpertTokens 返回每个专家累计分到的 token 数（副本）。
+func (this *HostOrchestrator) MoeExpertTokens() map[int]int64 {
+	if this == nil {
+		return nil
+	}
+	tokens := make(map[int]int64, len(this.moeExpertTokens))
+	for expertID, count := range this.moeExpertTokens {
+		tokens[expertID] = count
+	}
+	return tokens
+}
+
+// MoeAlltoallCollectives 返回按 all-to-all 建模的 gating 次数。
+func (this *HostOrchestrator) MoeAlltoallCollectives() int {
+	if this == nil {
+		return 0
+	}
+	return this.moeAlltoallCollectives
+}
+
+// MoeAlltoallBytes 返回 all-to-all dispatch 与 combine 两阶段的总字节数。
+func (this *HostOrchestrator) MoeAlltoallBytes() int64 {
+	if this == nil {
+		return 0
+	}
+	return this.moeAlltoallBytes
+}
+
+// MoeAlltoallCycles 返回 all-to-all 集合通信的总延迟周期。
+func (this *HostOrchestrator) MoeAlltoallCycles() int64 {
+	if this == nil {
+		return 0
+	}
+	return this.moeAlltoallCycles
+}
+
+// CollectiveBroadcastCycles 返回集合通信启动时广播命令累计的控制面周期，已包含在
+// MoeAlltoallCycles 中。
+func (this *HostOrchestrator) CollectiveBroadcastCycles() int64 {
+	if this == nil {
+		return 0
+	}
+	return this.collectiveBroadcastCycles
+}
+
+// MoeSkippedExpertCommands 返回因专家未分到 token 而省略的命令数。
+func (this *HostOrchestrator) MoeSkippedExpertCommands() int {
+	if this == nil {
+		return 0
+	}
+	return this.moeSkippedExpertCommands
+}
+
+// MoeSessionDeferrals 返回因会话上限而暂缓展开的 gating fetch 次数。
+func (this *HostOrchestrator) MoeSessionDeferrals() int {
+	if this == nil {
+		return 0
+	}
+	return this.moeSessionDeferrals
+}
+
+// MoeSessionPeak 返回同时在途的 MoE 会话数峰值。
+func (this *HostOrchestrator) MoeSessionPeak() int {
+	if this == nil {
+		return 0
+	}
+	return this.moeSessionPeak
 }
 
 func (this *HostOrchestrator) SetTransferLatencyEstimator(estimator TransferLatencyEstimator) {
@@ -113,3 +113,138 @@ func asInt(value interface{}) int {
 		panic("unexpected type for int conversion")
 	}
 }
+
+func TestHostOrchestratorPrefetchesNextBatchWeights(t *testing.T) {
+	commands := []CommandDescriptor{
+		{ID: 0, Kind: CommandKindRramWeightLoad, Target: TaskTargetRram, Latency: 4,
+			Metadata: map[string]interface{}{"weight_tag": "layer0"}},
+		{ID: 1, Kind: CommandKindRramStageAct, Target: TaskTargetRram, Latency: 4, Dependencies: []int32{0}},
+		{ID: 2, Kind: CommandKindRramWeightLoad, Target: TaskTargetRram, Latency: 4, Dependencies: []int32{1},
+			Metadata: map[string]interface{}{"weight_tag": "layer1"}},
+		{ID: 3, Kind: CommandKindRramStageAct, Target: TaskTargetRram, Latency: 4, Dependencies: []int32{2}},
+	}
+
+	tempDir := t.TempDir()
+	commandPath := filepath.Join(tempDir, "stream_commands.json")
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(commandPath, data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	config := &Config{
+		NumDigitalChiplets:      1,
+		NumRramChiplets:         1,
+		DigitalPeRows:           128,
+		DigitalPeCols:           128,
+		TransferBandwidthDr:     4096,
+		TransferBandwidthRd:     4096,
+		HostDmaBandwidth:        8192,
+		DigitalActivationBuffer: 1 << 30,
+		DigitalScratchBuffer:    1 << 30,
+		RramInputBuffer:         1 << 30,
+		RramOutputBuffer:        1 << 30,
+		HostStreamTotalBatches:  3,
+		HostStreamLowWatermark:  0,
+		HostStreamHighWatermark: 1,
+		WeightPrefetch:          true,
+	}
+	topology := BuildTopology(config)
+
+	orchestrator := new(HostOrchestrator)
+	orchestrator.Init(config, topology, commandPath)
+	t.Cleanup(func() { orchestrator.Fini() })
+
+	// prefetchedBy maps each prefetched batch to the demand batch in flight
+	// when its speculative load was issued.
+	prefetchedBy := make(map[int]int)
+	currentBatch := -1
+	for iter := 0; iter < 100 && orchestrator.HasPendingWork(); iter++ {
+		for _, task := range orchestrator.Advance() {
+			cmd := task.Payload.(*CommandDescriptor)
+			batchID := asInt(cmd.Metadata["stream_batch_id"])
+			if prefetch, _ := cmd.Metadata["prefetch"].(bool); prefetch {
+				if cmd.Kind != CommandKindRramWeightLoad || cmd.Metadata["weight_tag"] != "layer0" {
+					t.Fatalf("only the first-layer weight load should be prefetched, got %s %v", cmd.Kind, cmd.Metadata["weight_tag"])
+				}
+				if _, dup := prefetchedBy[batchID]; dup {
+					t.Fatalf("batch %d prefetched twice", batchID)
+				}
+				prefetchedBy[batchID] = currentBatch
+			} else {
+				currentBatch = batchID
+			}
+			orchestrator.NotifyTaskCompletion(task.NodeID)
+		}
+	}
+
+	if len(prefetchedBy) != 2 || prefetchedBy[1] != 0 || prefetchedBy[2] != 1 {
+		t.Fatalf("expected batches 1 and 2 prefetched during batches 0 and 1, got %v", prefetchedBy)
+	}
+	if got := orchestrator.PrefetchedWeightBatches(); got != 2 {
+		t.Fatalf("expected 2 prefetched batches, got %d", got)
+	}
+	if orchestrator.streamBatchesCompleted != 3 {
+		t.Fatalf("prefetch loads must not disturb batch accounting, completed %d", orchestrator.streamBatchesCompleted)
+	}
+}
+
+func TestHostOrchestratorAverageActiveBatches(t *testing.T) {
+	commands := []CommandDescriptor{
+		{ID: 0, Kind: CommandKindPeElementwise, Target: TaskTargetDigital, ChipletID: 0, Latency: 4},
+	}
+	commandPath := filepath.Join(t.TempDir(), "stream_commands.json")
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(commandPath, data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	run := func(low, high int) (float64, int) {
+		config := &Config{
+			NumDigitalChiplets:      1,
+			NumRramChiplets:         1,
+			DigitalPeRows:           128,
+			DigitalPeCols:           128,
+			DigitalActivationBuffer: 1 << 30,
+			DigitalScratchBuffer:    1 << 30,
+			RramInputBuffer:         1 << 30,
+			RramOutputBuffer:        1 << 30,
+			HostStreamTotalBatches:  8,
+			HostStreamLowWatermark:  low,
+			HostStreamHighWatermark: high,
+		}
+		orchestrator := new(HostOrchestrator)
+		orchestrator.Init(config, BuildTopology(config), commandPath)
+		defer orchestrator.Fini()
+
+		// 每个任务在途 4 个节拍后完成，模拟设备延迟。
+		pending := map[int]int{}
+		for tick := 0; tick < 40; tick++ {
+			for _, task := range orchestrator.Advance() {
+				pending[task.NodeID] = tick + 4
+			}
+			for nodeID, done := range pending {
+				if done <= tick {
+					delete(pending, nodeID)
+					orchestrator.NotifyTaskCompletion(nodeID)
+				}
+			}
+		}
+		return orchestrator.AverageActiveBatches(), orchestrator.PeakActiveBatches()
+	}
+
+	shallowAvg, shallowPeak := run(0, 1)
+	deepAvg, deepPeak := run(2, 3)
+	if shallowAvg <= 0 || shallowPeak != 1 {
+		t.Fatalf("expected a single active batch with high watermark 1: avg=%.3f peak=%d", shallowAvg, shallowPeak)
+	}
+	if deepAvg <= shallowAvg || deepPeak <= shallowPeak {
+		t.Fatalf("deeper watermarks should sustain more batches: avg %.3f -> %.3f, peak %d -> %d",
+			shallowAvg, deepAvg, shallowPeak, deepPeak)
+	}
+}
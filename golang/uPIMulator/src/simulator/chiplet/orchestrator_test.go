@@ -372,3 +372,239 @@ func TestHostOrchestratorWatermarkComparison(t *testing.T) {
 
 	t.Logf("no_stream_first_wave=%d stream_first_wave=%d", len(firstWave), len(streamWave))
 }
+
+func TestBootstrapItersChainsPipeline(t *testing.T) {
+	t.Parallel()
+
+	for _, iters := range []int{1, 3} {
+		config := &Config{
+			NumDigitalChiplets: 1,
+			NumRramChiplets:    1,
+			DigitalPeCols:      64,
+			RramSaRows:         32,
+			BootstrapIters:     iters,
+		}
+		orch := new(HostOrchestrator)
+		orch.Init(config, BuildTopology(config), "")
+
+		if orch.BootstrapIters() != iters || len(orch.graph.Nodes) != 6*iters {
+			t.Fatalf("iters=%d: expected %d nodes, got %d (reported %d)", iters, 6*iters, len(orch.graph.Nodes), orch.BootstrapIters())
+		}
+		if len(orch.readyQueue) != 1 || orch.readyQueue[0] != 0 {
+			t.Fatalf("iters=%d: expected only node 0 ready, got %v", iters, orch.readyQueue)
+		}
+		for iter := 1; iter < iters; iter++ {
+			deps := orch.graph.Nodes[6*iter].Deps
+			if len(deps) != 1 || deps[0] != 6*iter-1 {
+				t.Fatalf("iteration %d should chain on node %d, got %v", iter, 6*iter-1, deps)
+			}
+		}
+		orch.Fini()
+	}
+}
+
+func TestHostDispatchBudgetCapsAdvance(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		NumDigitalChiplets:   4,
+		NumRramChiplets:      1,
+		HostDispatchPerCycle: 2,
+	}
+	orch := new(HostOrchestrator)
+	orch.Init(config, BuildTopology(config), "")
+	defer orch.Fini()
+
+	orch.maxIssuePerCycle = 8
+	graph := NewOpGraph()
+	for id := 0; id < 5; id++ {
+		graph.AddNode(&OpNode{ID: id, Type: TaskTypeCompute, Target: TaskTargetDigital, Latency: 4})
+	}
+	orch.setGraph(graph)
+
+	issued := make([]int, 0)
+	for len(orch.readyQueue) > 0 {
+		issued = append(issued, len(orch.Advance()))
+	}
+	if len(issued) != 3 || issued[0] != 2 || issued[1] != 2 || issued[2] != 1 {
+		t.Fatalf("expected host budget to issue 2,2,1 tasks, got %v", issued)
+	}
+	if orch.HostDispatchBoundCycles() != 2 {
+		t.Fatalf("expected host dispatch to bind in 2 advances, got %d", orch.HostDispatchBoundCycles())
+	}
+}
+
+func TestSnapshotGraphReportsNodeStates(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{NumDigitalChiplets: 2, NumRramChiplets: 1}
+	orch := new(HostOrchestrator)
+	orch.Init(config, BuildTopology(config), "")
+	defer orch.Fini()
+
+	graph := NewOpGraph()
+	graph.AddNode(&OpNode{ID: 0, Target: TaskTargetDigital, Latency: 2, Payload: &CommandDescriptor{Kind: CommandKindPeGemm, Target: TaskTargetDigital, ChipletID: -1}})
+	graph.AddNode(&OpNode{ID: 1, Target: TaskTargetDigital, Latency: 2, Deps: []int{0}, Payload: &CommandDescriptor{Kind: CommandKindPeReduce, Target: TaskTargetDigital, ChipletID: 1}})
+	graph.AddNode(&OpNode{ID: 2, Target: TaskTargetDigital, Latency: 2, Deps: []int{1}, Payload: "postprocess"})
+	orch.setGraph(graph)
+
+	orch.Advance()
+	states := func() []string {
+		out := make([]string, 0, 3)
+		for _, node := range orch.SnapshotGraph() {
+			out = append(out, node.State)
+		}
+		return out
+	}
+	if got := states(); len(got) != 3 || got[0] != "in_flight" || got[1] != "blocked" || got[2] != "blocked" {
+		t.Fatalf("after issue: states %v", got)
+	}
+
+	orch.NotifyTaskCompletion(0)
+	nodes := orch.SnapshotGraph()
+	if nodes[0].State != "completed" || nodes[0].ChipletID < 0 || nodes[0].Kind != CommandKindPeGemm.String() {
+		t.Fatalf("node 0 should be completed on a resolved chiplet: %+v", nodes[0])
+	}
+	if nodes[1].State != "ready" || nodes[1].ChipletID != 1 || len(nodes[1].Deps) != 1 || nodes[1].Deps[0] != 0 {
+		t.Fatalf("node 1 should be ready with its dependency: %+v", nodes[1])
+	}
+	if nodes[2].State != "blocked" || nodes[2].Kind != "postprocess" || nodes[2].ChipletID != -1 {
+		t.Fatalf("bootstrap-style node should report its payload string: %+v", nodes[2])
+	}
+}
+
+func TestPipelinedTransferCarriesProducerWindow(t *testing.T) {
+	t.Parallel()
+
+	overlapWindow := func(chunks int, computeCycles int) int {
+		config := &Config{
+			NumDigitalChiplets:     1,
+			NumRramChiplets:        1,
+			TransferBandwidthDr:    1024,
+			TransferBandwidthRd:    1024,
+			TransferPipelineChunks: chunks,
+		}
+		orch := new(HostOrchestrator)
+		orch.Init(config, BuildTopology(config), "")
+		t.Cleanup(orch.Fini)
+
+		graph := NewOpGraph()
+		graph.AddNode(&OpNode{ID: 0, Type: TaskTypeCompute, Target: TaskTargetDigital, Latency: 1, Payload: "producer"})
+		graph.AddNode(&OpNode{
+			ID:     1,
+			Type:   TaskTypeDataMove,
+			Target: TaskTargetTransfer,
+			Deps:   []int{0},
+			Payload: &CommandDescriptor{
+				Kind:         CommandKindTransferC2D,
+				Target:       TaskTargetTransfer,
+				Flags:        TransferFlagDigitalToRram,
+				PayloadBytes: 32 * 1024,
+			},
+		})
+		orch.setGraph(graph)
+
+		if tasks := orch.Advance(); len(tasks) != 1 {
+			t.Fatalf("expected the producer to issue first, got %d tasks", len(tasks))
+		}
+		orch.RecordProducerCycles(0, computeCycles)
+		orch.NotifyTaskCompletion(0)
+		tasks := orch.Advance()
+		if len(tasks) != 1 || tasks[0].Target != TaskTargetTransfer {
+			t.Fatalf("expected the dependent transfer, got %d tasks", len(tasks))
+		}
+		if len(orch.producerSpan) != 0 {
+			t.Fatalf("producer span should be dropped once its transfer is created, got %v", orch.producerSpan)
+		}
+		return tasks[0].OverlapWindow
+	}
+
+	if window := overlapWindow(0, 16); window != 0 {
+		t.Fatalf("pipelining disabled should leave no overlap window, got %d", window)
+	}
+	if window := overlapWindow(4, 16); window != 16 {
+		t.Fatalf("expected the producer's 16 compute cycles as overlap window, got %d", window)
+	}
+}
+
+func TestMaxInflightTransfersThrottlesIssue(t *testing.T) {
+	t.Parallel()
+
+	run := func(limit int) (*HostOrchestrator, int) {
+		config := &Config{
+			NumDigitalChiplets:   1,
+			NumRramChiplets:      1,
+			TransferBandwidthDr:  1 << 20,
+			TransferBandwidthRd:  1 << 20,
+			MaxInflightTransfers: limit,
+		}
+		orch := new(HostOrchestrator)
+		orch.Init(config, BuildTopology(config), "")
+		t.Cleanup(orch.Fini)
+		orch.maxIssuePerCycle = 8
+
+		graph := NewOpGraph()
+		for id := 0; id < 6; id++ {
+			graph.AddNode(&OpNode{ID: id, Type: TaskTypeDataMove, Target: TaskTargetTransfer, Payload: &CommandDescriptor{
+				Kind:         CommandKindTransferC2D,
+				Target:       TaskTargetTransfer,
+				Flags:        TransferFlagDigitalToRram,
+				PayloadBytes: 64,
+			}})
+		}
+		orch.setGraph(graph)
+		return orch, len(orch.Advance())
+	}
+
+	if _, issued := run(0); issued != 6 {
+		t.Fatalf("unlimited: expected all 6 small transfers to issue, got %d", issued)
+	}
+
+	orch, issued := run(2)
+	if issued != 2 || orch.TransferInflightLimitedCycles() != 1 {
+		t.Fatalf("limit 2: issued=%d limited=%d, want 2/1", issued, orch.TransferInflightLimitedCycles())
+	}
+	if next := orch.Advance(); len(next) != 0 || orch.TransferInflightLimitedCycles() != 2 {
+		t.Fatalf("no slot free: issued=%d limited=%d", len(next), orch.TransferInflightLimitedCycles())
+	}
+	orch.NotifyTaskCompletion(0)
+	if next := orch.Advance(); len(next) != 1 {
+		t.Fatalf("one completion should free exactly one slot, issued %d", len(next))
+	}
+}
+
+func TestEccOverheadStretchesTransferLatency(t *testing.T) {
+	t.Parallel()
+
+	latency := func(eccPct int) int {
+		config := &Config{
+			NumDigitalChiplets:  1,
+			NumRramChiplets:     1,
+			TransferBandwidthDr: 1024,
+			TransferBandwidthRd: 1024,
+			EccOverheadPct:      eccPct,
+		}
+		orch := new(HostOrchestrator)
+		orch.Init(config, BuildTopology(config), "")
+		t.Cleanup(orch.Fini)
+
+		graph := NewOpGraph()
+		graph.AddNode(&OpNode{ID: 0, Type: TaskTypeDataMove, Target: TaskTargetTransfer, Payload: &CommandDescriptor{
+			Kind:         CommandKindTransferC2D,
+			Target:       TaskTargetTransfer,
+			Flags:        TransferFlagDigitalToRram,
+			PayloadBytes: 32 * 1024,
+		}})
+		orch.setGraph(graph)
+		tasks := orch.Advance()
+		if len(tasks) != 1 {
+			t.Fatalf("expected the transfer to issue, got %d tasks", len(tasks))
+		}
+		return tasks[0].Latency
+	}
+
+	// 32 KiB 在 1024 B/cycle 下需 32 周期，附加 25% ECC 后线上为 40 KiB，多 8 周期。
+	if plain, ecc := latency(0), latency(25); ecc-plain != 8 {
+		t.Fatalf("expected ECC to add 8 transfer cycles, got %d -> %d", plain, ecc)
+	}
+}
@@ -1,6 +1,11 @@
 package rram
 
-import "strings"
+import (
+	"math/rand"
+	"strings"
+
+	"uPIMulator/src/misc"
+)
 
 // Chiplet groups together multiple tiles belonging to the same RRAM die. It
 // wires the controller, pre/post processing modules and buffer accounting.
@@ -29,6 +34,7 @@ type Chiplet struct {
 	WeightLoads          int64
 	WeightLoadHits       int64
 	WeightLoadEnergyPJ   float64
+	ProgramEnergyPJ      float64
 	StageEnergyPJ        float64
 	ExecuteEnergyPJ      float64
 	PostEnergyPJ         float64
@@ -39,6 +45,24 @@ type Chiplet struct {
 	StaticEnergyPJ       float64
 	AreaMm2              float64
 	bufferPeak           map[string]int64
+	programRng           *rand.Rand
+	// Input-path availability in platform cycles. serialPathReady shadows a
+	// single shared path so the latency hidden by overlapping can be reported.
+	weightPathReady     int
+	activationPathReady int
+	serialPathReady     int
+	OverlapHiddenCycles int64
+	// PartialSumCombines 统计因深度超过单 tile 而需要跨 tile 合并部分和的 CIM 任务数。
+	PartialSumCombines      int64
+	PartialSumCombineCycles int64
+	PartialSumBytes         int64
+	// DequantOps/DequantCycles/DequantEnergyPJ 统计读出权重的反量化缩放工作量。
+	DequantOps      int64
+	DequantCycles   int64
+	DequantEnergyPJ float64
+	// WeightLoadBytes/WeightLoadCycles 统计经权重加载通路搬运的字节与传输周期（不含编程脉冲）。
+	WeightLoadBytes  int64
+	WeightLoadCycles int64
 }
 
 type weightLoadTask struct {
@@ -48,6 +72,14 @@ type weightLoadTask struct {
 	Bytes     int64
 	Remaining int
 	StartTick int
+	Pulses    int
+}
+
+// newProgramRng 为每个 chiplet 派生独立的编程早退随机流。
+func newProgramRng(seed int64, id int) *rand.Rand {
+	streams := new(misc.RNGStreams)
+	streams.Init(seed)
+	return streams.Fork(misc.RNGStreamRramProgram, int64(id))
 }
 
 // NewChiplet constructs an RRAM chiplet with uniform tile/array configuration.
@@ -83,6 +115,7 @@ func NewChiplet(
 	}
 
 	controller := NewController(tiles)
+	controller.WeightDirectory().SetCapacity(params.WeightCapacityBytes)
 
 	chip := &Chiplet{
 		ID:                   id,
@@ -107,6 +140,7 @@ func NewChiplet(
 		},
 		weightLoadQueue: make([]*weightLoadTask, 0),
 		params:          params,
+		programRng:      newProgramRng(params.ProgramSeed, id),
 	}
 
 	areaPerTile := params.Tile.SenseArrayAreaMm2 + params.Tile.ControllerAreaMm2
@@ -137,6 +171,40 @@ func (c *Chiplet) AddWeightLoadEnergy(bytes int64) {
 	c.WeightLoadEnergyPJ += float64(bytes) * c.params.WeightReadEnergyPJPerByte
 }
 
+// AddProgramEnergy charges program-and-verify energy for writing bytes with the
+// given number of pulses.
+func (c *Chiplet) AddProgramEnergy(bytes int64, pulses int) {
+	if bytes <= 0 || pulses <= 0 {
+		return
+	}
+	energy := float64(bytes) * float64(pulses) * c.params.ProgramEnergyPJPerBytePulse
+	c.DynamicEnergyPJ += energy
+	c.ProgramEnergyPJ += energy
+}
+
+// samplePulsesToConverge draws how many program pulses a write needs before
+// verify succeeds; every pulse converges with ProgramEarlyExitProb, capped at
+// ProgramPulses.
+func (c *Chiplet) samplePulsesToConverge() int {
+	maxPulses := c.params.ProgramPulses
+	if maxPulses <= 0 {
+		return 0
+	}
+	prob := c.params.ProgramEarlyExitProb
+	if prob <= 0 {
+		return maxPulses
+	}
+	if prob >= 1 {
+		return 1
+	}
+	for pulse := 1; pulse < maxPulses; pulse++ {
+		if c.programRng.Float64() < prob {
+			return pulse
+		}
+	}
+	return maxPulses
+}
+
 // RegisterWeights records residency for a tile/array weight chunk. Returns true on cache hit.
 func (c *Chiplet) RegisterWeights(tileID, arrayID int, tag string, bytes int64, tick int) bool {
 	if c == nil || c.Controller == nil {
@@ -153,10 +221,13 @@ func (c *Chiplet) RegisterWeights(tileID, arrayID int, tag string, bytes int64,
 	return hit
 }
 
-// ScheduleWeightLoad enqueues a DMA-style weight transfer to the chiplet.
-func (c *Chiplet) ScheduleWeightLoad(tileID, arrayID int, tag string, bytes int64, latency int, startTick int) {
+// ScheduleWeightLoad enqueues a DMA-style weight transfer to the chiplet and
+// books the weight path starting at startTick. It returns the booked cycles and
+// the energy the load will charge when it completes, so callers can attribute
+// the cost of loads whose result is later discarded.
+func (c *Chiplet) ScheduleWeightLoad(tileID, arrayID int, tag string, bytes int64, latency int, startTick int) (int, float64) {
 	if c == nil {
-		return
+		return 0, 0
 	}
 	if bytes < 0 {
 		bytes = 0
@@ -168,6 +239,12 @@ func (c *Chiplet) ScheduleWeightLoad(tileID, arrayID int, tag string, bytes int6
 		}
 		latency = int((bytes + bandwidth - 1) / bandwidth)
 	}
+	c.WeightLoadBytes += bytes
+	c.WeightLoadCycles += int64(latency)
+	pulses := c.samplePulsesToConverge()
+	if pulses > 0 && c.params.ProgramPulseCycles > 0 {
+		latency += pulses * c.params.ProgramPulseCycles
+	}
 	if latency < 1 {
 		latency = 1
 	}
@@ -178,11 +255,84 @@ func (c *Chiplet) ScheduleWeightLoad(tileID, arrayID int, tag string, bytes int6
 		Bytes:     bytes,
 		Remaining: latency,
 		StartTick: startTick,
+		Pulses:    pulses,
 	}
 	c.weightLoadQueue = append(c.weightLoadQueue, task)
 	c.PendingTasks++
 	c.PendingCycles += latency
 	c.WeightLoads++
+	c.ReserveWeightPath(startTick, latency)
+
+	energy := float64(bytes) * c.params.WeightReadEnergyPJPerByte
+	if pulses > 0 {
+		energy += float64(bytes) * float64(pulses) * c.params.ProgramEnergyPJPerBytePulse
+	}
+	return latency, energy
+}
+
+func (c *Chiplet) reservePath(ready *int, now, cycles int) int {
+	if cycles < 0 {
+		cycles = 0
+	}
+	serialStart := now
+	if c.serialPathReady > serialStart {
+		serialStart = c.serialPathReady
+	}
+	c.serialPathReady = serialStart + cycles
+	if !c.params.OverlapWeightActivation {
+		*ready = c.serialPathReady
+		return *ready
+	}
+	start := now
+	if *ready > start {
+		start = *ready
+	}
+	*ready = start + cycles
+	return *ready
+}
+
+// ReserveWeightPath books the weight-load path for cycles starting no earlier
+// than now and returns the cycle at which the weights are in place.
+func (c *Chiplet) ReserveWeightPath(now, cycles int) int {
+	if c == nil {
+		return now
+	}
+	return c.reservePath(&c.weightPathReady, now, cycles)
+}
+
+// ReserveActivationPath books the activation input path for cycles starting no
+// earlier than now and returns the cycle at which the activations are in place.
+func (c *Chiplet) ReserveActivationPath(now, cycles int) int {
+	if c == nil {
+		return now
+	}
+	return c.reservePath(&c.activationPathReady, now, cycles)
+}
+
+// InputsReadyAt returns the cycle at which both the weight and activation
+// paths have drained, i.e. the earliest cycle a stage phase may start.
+func (c *Chiplet) InputsReadyAt() int {
+	if c == nil {
+		return 0
+	}
+	if c.weightPathReady > c.activationPathReady {
+		return c.weightPathReady
+	}
+	return c.activationPathReady
+}
+
+// ConsumeInputs is called when a stage phase starts. It accumulates the cycles
+// the separate paths saved against the shared-path timeline and resyncs that
+// timeline to the actual one.
+func (c *Chiplet) ConsumeInputs() {
+	if c == nil {
+		return
+	}
+	ready := c.InputsReadyAt()
+	if c.serialPathReady > ready {
+		c.OverlapHiddenCycles += int64(c.serialPathReady - ready)
+	}
+	c.serialPathReady = ready
 }
 
 // LookupWeights returns the directory record for the provided key.
@@ -193,6 +343,72 @@ func (c *Chiplet) LookupWeights(tileID, arrayID int, tag string) (*WeightRecord,
 	return c.Controller.LookupWeights(tileID, arrayID, tag)
 }
 
+// TouchWeights marks a resident weight chunk as used at tick.
+func (c *Chiplet) TouchWeights(tileID, arrayID int, tag string, tick int) {
+	if c == nil || c.WeightDirectory == nil {
+		return
+	}
+	c.WeightDirectory.Touch(tileID, arrayID, tag, tick)
+}
+
+// WeightLoadPending reports whether a load for the key is queued or in flight.
+func (c *Chiplet) WeightLoadPending(tileID, arrayID int, tag string) bool {
+	if c == nil {
+		return false
+	}
+	tag = strings.ToLower(tag)
+	matches := func(task *weightLoadTask) bool {
+		return task != nil && task.TileID == tileID && task.ArrayID == arrayID && task.Tag == tag
+	}
+	if matches(c.weightLoadActive) {
+		return true
+	}
+	for _, task := range c.weightLoadQueue {
+		if matches(task) {
+			return true
+		}
+	}
+	return false
+}
+
+// WeightCapacityBytes returns the residency bound (0 when unbounded).
+func (c *Chiplet) WeightCapacityBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.params.WeightCapacityBytes
+}
+
+// WeightHeadroomBytes returns the weight capacity not yet taken by resident
+// chunks or by loads still queued or in flight. It is meaningless when the
+// capacity is unbounded.
+func (c *Chiplet) WeightHeadroomBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	used := int64(0)
+	if c.WeightDirectory != nil {
+		used = c.WeightDirectory.TotalBytes()
+	}
+	if c.weightLoadActive != nil {
+		used += c.weightLoadActive.Bytes
+	}
+	for _, task := range c.weightLoadQueue {
+		if task != nil {
+			used += task.Bytes
+		}
+	}
+	return c.params.WeightCapacityBytes - used
+}
+
+// WeightEvictions reports capacity-driven weight evictions.
+func (c *Chiplet) WeightEvictions() int64 {
+	if c == nil || c.WeightDirectory == nil {
+		return 0
+	}
+	return c.WeightDirectory.Evictions()
+}
+
 // EvictWeights removes tracked residency metadata.
 func (c *Chiplet) EvictWeights(tileID, arrayID int, tag string) {
 	if c == nil || c.Controller == nil {
@@ -227,11 +443,109 @@ func (c *Chiplet) ScheduleTask(latency int, spec *TaskSpec) {
 	}
 
 	task := c.buildTask(latency, spec)
+	c.applyPartialSumCombine(task)
+	c.applyDequant(task)
 	cycles := c.Controller.Reserve(latency, task)
 	c.PendingCycles += cycles
 	c.PendingTasks++
 }
 
+// TileDepth 返回单个 tile 可容纳的归约深度（K 维）。
+func (c *Chiplet) TileDepth() int {
+	if c.params.TileDepth > 0 {
+		return c.params.TileDepth
+	}
+	if len(c.Tiles) == 0 || len(c.Tiles[0].Arrays) == 0 || c.Tiles[0].Arrays[0] == nil {
+		return 0
+	}
+	return c.Tiles[0].Arrays[0].Rows * c.Tiles[0].ArraysPerDim
+}
+
+// applyPartialSumCombine 为深度超过单 tile 的 execute/复合任务追加部分和合并：
+// 各 tile 的部分 MAC 并行执行，脉冲阶段不变；合并在专用累加器上串行进行，
+// 每多一个 tile 需要 ceil(cols/lanes) 个后处理周期，并搬运 rows×cols 个 32-bit 部分和。
+func (c *Chiplet) applyPartialSumCombine(task *Task) {
+	if task == nil || task.Spec == nil || c.params.PartialSumLanes <= 0 {
+		return
+	}
+	if task.Phase != TaskPhaseExecute && task.Phase != TaskPhaseUnknown {
+		return
+	}
+	spec := task.Spec
+	tileDepth := c.TileDepth()
+	if tileDepth <= 0 || spec.Depth <= tileDepth {
+		return
+	}
+	parts := (spec.Depth + tileDepth - 1) / tileDepth
+	cols := spec.Cols
+	if cols <= 0 && len(c.Tiles) > 0 && len(c.Tiles[0].Arrays) > 0 {
+		cols = c.Tiles[0].Arrays[0].Cols
+	}
+	if cols <= 0 {
+		cols = 1
+	}
+	rows := spec.Rows
+	if rows <= 0 {
+		rows = 1
+	}
+	lanes := c.params.PartialSumLanes
+	combineCycles := (parts - 1) * ((cols + lanes - 1) / lanes)
+	psumBytes := int64(parts-1) * int64(rows) * int64(cols) * 4
+
+	task.PostprocessCycles += combineCycles
+	task.EstimatedCycles += combineCycles
+	task.resetProgress()
+	c.PartialSumCombines++
+	c.PartialSumCombineCycles += int64(combineCycles)
+	c.PartialSumBytes += psumBytes
+	c.AddOutputTransferEnergy(psumBytes)
+	if task.Phase == TaskPhaseExecute {
+		// 复合任务的后处理周期在 Tick 中按 TotalPostprocessCycles 计能；execute 任务不计，需在此补上。
+		combineEnergy := float64(combineCycles) * c.params.PostprocessEnergyPJPerCycle
+		c.DynamicEnergyPJ += combineEnergy
+		c.PostEnergyPJ += combineEnergy
+	}
+}
+
+// applyDequant 为读取权重的 execute/复合任务追加反量化：rows×cols 个输出各需
+// ceil(16/WeightBits) 次缩放，按 DequantLanes 并行，权重精度越低开销越大。
+func (c *Chiplet) applyDequant(task *Task) {
+	if task == nil || task.Spec == nil || c.params.DequantLanes <= 0 {
+		return
+	}
+	if task.Phase != TaskPhaseExecute && task.Phase != TaskPhaseUnknown {
+		return
+	}
+	weightBits := c.params.WeightBits
+	if weightBits <= 0 || weightBits > 16 {
+		weightBits = 16
+	}
+	rows := task.Spec.Rows
+	if rows <= 0 {
+		rows = 1
+	}
+	cols := task.Spec.Cols
+	if cols <= 0 && len(c.Tiles) > 0 && len(c.Tiles[0].Arrays) > 0 {
+		cols = c.Tiles[0].Arrays[0].Cols
+	}
+	if cols <= 0 {
+		cols = 1
+	}
+	ops := int64(rows) * int64(cols) * int64((16+weightBits-1)/weightBits)
+	lanes := int64(c.params.DequantLanes)
+	cycles := int((ops + lanes - 1) / lanes)
+
+	task.PostprocessCycles += cycles
+	task.DequantCycles += cycles
+	task.EstimatedCycles += cycles
+	task.resetProgress()
+	energy := float64(ops) * c.params.DequantEnergyPJPerOp
+	c.DequantOps += ops
+	c.DequantCycles += int64(cycles)
+	c.DequantEnergyPJ += energy
+	c.DynamicEnergyPJ += energy
+}
+
 func (c *Chiplet) processWeightLoads() {
 	if c.weightLoadActive == nil && len(c.weightLoadQueue) > 0 {
 		c.weightLoadActive = c.weightLoadQueue[0]
@@ -251,6 +565,12 @@ func (c *Chiplet) processWeightLoads() {
 	if task.Bytes > 0 {
 		c.AddWeightLoadEnergy(task.Bytes)
 	}
+	if task.Pulses > 0 {
+		c.AddProgramEnergy(task.Bytes, task.Pulses)
+		c.stats.ProgramTasks++
+		c.stats.PulseCountWrite += int64(task.Pulses)
+		c.stats.TotalWriteLatency += int64(task.Pulses * c.params.ProgramPulseCycles)
+	}
 	c.RegisterWeights(task.TileID, task.ArrayID, task.Tag, task.Bytes, task.StartTick)
 	c.PendingTasks--
 	if c.PendingTasks < 0 {
@@ -292,6 +612,7 @@ func (c *Chiplet) Tick() {
 			c.stats.LastSummary = delta.LastSummary
 			c.lastResult = delta.LastSummary
 		}
+		c.stats.mergeDigitalChecks(delta)
 		if delta.CimTasks > 0 {
 			c.ExecutedTasks += int(delta.CimTasks)
 			c.PendingTasks -= int(delta.CimTasks)
@@ -307,8 +628,13 @@ func (c *Chiplet) Tick() {
 		c.BusyCycles++
 	}
 
+	c.StaticEnergyPJ += c.StaticEnergyPerCyclePJ()
+}
+
+// StaticEnergyPerCyclePJ returns the leakage energy charged for one chiplet cycle.
+func (c *Chiplet) StaticEnergyPerCyclePJ() float64 {
 	totalMw := c.params.StaticPowerMw + float64(len(c.Tiles))*c.params.Tile.LeakagePowerMw
-	c.StaticEnergyPJ += c.energyPerCyclePJ(totalMw)
+	return c.energyPerCyclePJ(totalMw)
 }
 
 // Busy reports whether the chiplet is still processing a scheduled task.
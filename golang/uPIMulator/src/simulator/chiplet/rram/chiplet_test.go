@@ -0,0 +1,213 @@
+package rram
+
+import "testing"
+
+func runProgramWeightLoads(t *testing.T, earlyExit float64) (cycles int, energy float64, stats Stats) {
+	t.Helper()
+	params := DefaultParameters()
+	params.ProgramPulses = 16
+	params.ProgramPulseCycles = 80
+	params.ProgramEarlyExitProb = earlyExit
+	chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)
+
+	for i := 0; i < 32; i++ {
+		chip.ScheduleWeightLoad(0, i, "w", 4096, 0, 0)
+	}
+	for chip.PendingTasks > 0 {
+		chip.Tick()
+		cycles++
+		if cycles > 1_000_000 {
+			t.Fatalf("weight loads did not drain")
+		}
+	}
+	return cycles, chip.ProgramEnergyPJ, chip.Stats()
+}
+
+func TestProgramEarlyExitReducesWeightLoadCost(t *testing.T) {
+	slowCycles, slowEnergy, slowStats := runProgramWeightLoads(t, 0.0)
+	fastCycles, fastEnergy, fastStats := runProgramWeightLoads(t, 0.5)
+
+	if slowStats.ProgramTasks != 32 || fastStats.ProgramTasks != 32 {
+		t.Fatalf("expected 32 program tasks, got %d and %d", slowStats.ProgramTasks, fastStats.ProgramTasks)
+	}
+	if slowStats.PulseCountWrite != 32*16 {
+		t.Fatalf("without early exit every write should use all pulses, got %d", slowStats.PulseCountWrite)
+	}
+	if fastStats.PulseCountWrite >= slowStats.PulseCountWrite {
+		t.Fatalf("early exit should reduce pulses: %d >= %d", fastStats.PulseCountWrite, slowStats.PulseCountWrite)
+	}
+	if fastCycles >= slowCycles {
+		t.Fatalf("early exit should reduce weight-load latency: %d >= %d", fastCycles, slowCycles)
+	}
+	if fastEnergy >= slowEnergy {
+		t.Fatalf("early exit should reduce program energy: %.3f >= %.3f", fastEnergy, slowEnergy)
+	}
+}
+
+func TestInputPathsOverlapHidesShorterLoad(t *testing.T) {
+	for _, overlap := range []bool{false, true} {
+		params := DefaultParameters()
+		params.OverlapWeightActivation = overlap
+		chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)
+
+		chip.ReserveWeightPath(100, 40)
+		chip.ReserveActivationPath(100, 25)
+
+		wantReady := 165
+		wantHidden := int64(0)
+		if overlap {
+			wantReady = 140
+			wantHidden = 25
+		}
+		if got := chip.InputsReadyAt(); got != wantReady {
+			t.Fatalf("overlap=%v: inputs ready at %d, want %d", overlap, got, wantReady)
+		}
+		chip.ConsumeInputs()
+		if chip.OverlapHiddenCycles != wantHidden {
+			t.Fatalf("overlap=%v: hidden cycles %d, want %d", overlap, chip.OverlapHiddenCycles, wantHidden)
+		}
+	}
+}
+
+func TestWeightCapacityEvictsLeastRecentlyUsed(t *testing.T) {
+	params := DefaultParameters()
+	params.WeightCapacityBytes = 200
+	chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)
+
+	chip.RegisterWeights(0, 0, "a", 100, 1)
+	chip.RegisterWeights(0, 0, "b", 100, 2)
+	chip.TouchWeights(0, 0, "a", 3)
+	chip.RegisterWeights(0, 0, "c", 100, 4)
+
+	if _, ok := chip.LookupWeights(0, 0, "b"); ok {
+		t.Fatalf("least recently used chunk should be evicted")
+	}
+	for _, tag := range []string{"a", "c"} {
+		if _, ok := chip.LookupWeights(0, 0, tag); !ok {
+			t.Fatalf("chunk %s should stay resident", tag)
+		}
+	}
+	if chip.WeightBytesResident != 200 || chip.WeightEvictions() != 1 {
+		t.Fatalf("resident=%d evictions=%d, want 200 and 1", chip.WeightBytesResident, chip.WeightEvictions())
+	}
+}
+
+func TestDeepReductionPaysPartialSumCombine(t *testing.T) {
+	run := func(depth int, lanes int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.PartialSumLanes = lanes
+		// 2x2 tile，每 tile 1x1 个 128 行阵列：单 tile 深度 128。
+		chip := NewChiplet(0, 2, 1, 128, 128, 2, 2, 12, 0, 0, params)
+		chip.ScheduleTask(0, &TaskSpec{
+			Rows:       16,
+			Cols:       128,
+			Depth:      depth,
+			PulseCount: depth,
+			AdcSamples: depth,
+			PreCycles:  16,
+			PostCycles: 16,
+			Phase:      TaskPhaseExecute,
+		})
+		cycles := 0
+		for chip.Busy() {
+			chip.Tick()
+			cycles++
+			if cycles > 100_000 {
+				t.Fatalf("task did not drain")
+			}
+		}
+		return chip, cycles
+	}
+
+	shallow, shallowCycles := run(128, 32)
+	if shallow.PartialSumCombines != 0 || shallow.PartialSumBytes != 0 {
+		t.Fatalf("a reduction that fits one tile needs no combine, got %d", shallow.PartialSumCombines)
+	}
+
+	deep, deepCycles := run(512, 32)
+	flat, flatCycles := run(512, 0)
+	// 512/128 = 4 个 tile，3 次合并，每次 ceil(128/32)=4 个周期。
+	if deep.PartialSumCombines != 1 || deep.PartialSumCombineCycles != 12 {
+		t.Fatalf("expected one combine of 12 cycles, got %d/%d", deep.PartialSumCombines, deep.PartialSumCombineCycles)
+	}
+	if deep.PartialSumBytes != 3*16*128*4 {
+		t.Fatalf("expected %d partial-sum bytes, got %d", 3*16*128*4, deep.PartialSumBytes)
+	}
+	if deepCycles != flatCycles+12 || deepCycles <= shallowCycles {
+		t.Fatalf("expected combine to add 12 cycles: shallow=%d flat=%d deep=%d", shallowCycles, flatCycles, deepCycles)
+	}
+	if deep.DynamicEnergyPJ <= flat.DynamicEnergyPJ {
+		t.Fatalf("expected combine to cost energy: flat=%.3f deep=%.3f", flat.DynamicEnergyPJ, deep.DynamicEnergyPJ)
+	}
+}
+
+func TestWeightLoadBandwidthIsSeparateFromActivationPath(t *testing.T) {
+	run := func(bandwidth int64) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.ProgramPulses = 0
+		params.OverlapWeightActivation = true
+		params.WeightLoadBytesPerCycle = bandwidth
+		chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)
+		chip.ScheduleWeightLoad(0, 0, "w", 8192, 0, 0)
+		return chip, chip.ReserveActivationPath(0, 30)
+	}
+
+	narrow, narrowActReady := run(1024)
+	wide, wideActReady := run(4096)
+
+	if narrow.WeightLoadCycles != 8 || wide.WeightLoadCycles != 2 {
+		t.Fatalf("expected 8 and 2 weight-load cycles, got %d and %d", narrow.WeightLoadCycles, wide.WeightLoadCycles)
+	}
+	if narrow.WeightLoadBytes != 8192 || wide.WeightLoadBytes != 8192 {
+		t.Fatalf("expected 8192 weight-load bytes, got %d and %d", narrow.WeightLoadBytes, wide.WeightLoadBytes)
+	}
+	if narrowActReady != wideActReady {
+		t.Fatalf("weight-load bandwidth must not change activation timing: %d != %d", narrowActReady, wideActReady)
+	}
+}
+
+func TestLowerWeightPrecisionCostsMoreDequant(t *testing.T) {
+	run := func(weightBits int, lanes int) (*Chiplet, int) {
+		params := DefaultParameters()
+		params.WeightBits = weightBits
+		params.DequantLanes = lanes
+		chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)
+		chip.ScheduleTask(0, &TaskSpec{
+			Rows:       16,
+			Cols:       128,
+			Depth:      128,
+			PulseCount: 64,
+			AdcSamples: 64,
+			PreCycles:  16,
+			PostCycles: 16,
+			Phase:      TaskPhaseExecute,
+		})
+		cycles := 0
+		for chip.Busy() {
+			chip.Tick()
+			cycles++
+			if cycles > 100_000 {
+				t.Fatalf("task did not drain")
+			}
+		}
+		return chip, cycles
+	}
+
+	_, foldedCycles := run(8, 0)
+	int8Chip, int8Cycles := run(8, 64)
+	int2Chip, int2Cycles := run(2, 64)
+
+	// 16×128 个输出：8-bit 权重每个 2 次缩放，2-bit 权重每个 8 次，64 路并行。
+	if int8Chip.DequantOps != 16*128*2 || int8Chip.DequantCycles != 64 {
+		t.Fatalf("8-bit weights: expected 4096 ops in 64 cycles, got %d/%d", int8Chip.DequantOps, int8Chip.DequantCycles)
+	}
+	if int2Chip.DequantOps != 16*128*8 || int2Chip.DequantCycles != 256 {
+		t.Fatalf("2-bit weights: expected 16384 ops in 256 cycles, got %d/%d", int2Chip.DequantOps, int2Chip.DequantCycles)
+	}
+	if int8Cycles != foldedCycles+64 || int2Cycles != foldedCycles+256 {
+		t.Fatalf("dequant should extend the task: folded=%d int8=%d int2=%d", foldedCycles, int8Cycles, int2Cycles)
+	}
+	if int2Chip.DequantEnergyPJ <= int8Chip.DequantEnergyPJ || int2Chip.DynamicEnergyPJ <= int8Chip.DynamicEnergyPJ {
+		t.Fatalf("lower precision should cost more dequant energy: int8=%.3f int2=%.3f", int8Chip.DequantEnergyPJ, int2Chip.DequantEnergyPJ)
+	}
+}
@@ -0,0 +1,68 @@
+package rram
+
+import "math"
+
+// DigitalReference 以浮点精确重算一次 CIM 点积，作为 RRAM 数值通路的交叉校验基准：
+// 不做指数对齐截断，权重按有符号值直接参与乘累加，最后套用与 FinalizeResult 相同的
+// scale/zero_point 修正。spec 未携带完整的激活分量与权重时返回 false。
+func DigitalReference(spec *TaskSpec) (float64, bool) {
+	if spec == nil {
+		return 0, false
+	}
+	count := len(spec.Mantissas)
+	if count == 0 || len(spec.Signs) != count || len(spec.Exponents) != count || len(spec.Weights) != count {
+		return 0, false
+	}
+
+	maxExponent := spec.Exponents[0]
+	for _, e := range spec.Exponents {
+		if e > maxExponent {
+			maxExponent = e
+		}
+	}
+
+	o := 0.0
+	aSum := 0.0
+	for i := 0; i < count; i++ {
+		sign := 1.0
+		if spec.Signs[i] != 0 {
+			sign = -1.0
+		}
+		fullMantissa := float64((1 << 10) | (spec.Mantissas[i] & 0x3FF))
+		// fp16: value = ±1.m × 2^(e-15)，尾数按 10 位整数计入。
+		o += sign * fullMantissa * math.Pow(2.0, float64(spec.Exponents[i]-25)) * float64(spec.Weights[i])
+		relative := math.Pow(2.0, float64(spec.Exponents[i]-maxExponent))
+		aSum += sign * fullMantissa * relative * relative
+	}
+
+	scale := 1.0
+	if spec.Scale != 0 {
+		scale = spec.Scale
+	}
+	return o*scale - aSum*float64(spec.ZeroPoint)*scale, true
+}
+
+// recordDigitalCheck 比较后处理结果与数字重算值，只累计超出 spec.ErrorAbs
+// （配置的量化误差）的那部分偏差。
+func recordDigitalCheck(stats *Stats, spec *TaskSpec, summary ResultSummary) {
+	if stats == nil || spec == nil || !spec.CrossCheck {
+		return
+	}
+	reference, ok := DigitalReference(spec)
+	if !ok {
+		stats.DigitalCheckSkipped++
+		return
+	}
+	excess := math.Abs(summary.Final-reference) - math.Abs(spec.ErrorAbs)
+	if excess < 0 {
+		excess = 0
+	}
+	stats.DigitalChecks++
+	stats.DigitalDiscrepancyAccum += excess
+	if excess > stats.DigitalDiscrepancyMax {
+		stats.DigitalDiscrepancyMax = excess
+	}
+	if excess > 0 {
+		stats.DigitalCheckViolations++
+	}
+}
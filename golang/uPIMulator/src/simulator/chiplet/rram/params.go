@@ -18,6 +18,29 @@ type Parameters struct {
 	WeightLoadBytesPerCycle     int64
 	IdleLeakEnergyPJPerCycle    float64
 	WeightControllerEnergyPJ    float64
+	// Program-and-verify 写入模型：每次权重写入最多 ProgramPulses 个脉冲，
+	// 每个脉冲耗时 ProgramPulseCycles，并以 ProgramEarlyExitProb 的概率提前收敛。
+	ProgramPulses               int
+	ProgramPulseCycles          int
+	ProgramEarlyExitProb        float64
+	ProgramEnergyPJPerBytePulse float64
+	ProgramSeed                 int64
+	// OverlapWeightActivation 为 true 时权重加载与激活输入走独立通路，可并行；
+	// 否则二者共享同一输入通路，依次占用。
+	OverlapWeightActivation bool
+	// WeightCapacityBytes 为单个 chiplet 可驻留的权重字节数，超出时按 LRU 淘汰；0 表示不限。
+	WeightCapacityBytes int64
+	// 部分和累加模型：归约深度超过单个 tile 的 TileDepth 时，MAC 被拆到多个 tile 上
+	// 并行执行，再由专用累加器以 PartialSumLanes 路宽合并各 tile 的 32-bit 部分和。
+	// TileDepth 为 0 时取 SaRows×每维阵列数；PartialSumLanes 为 0 时不建模合并。
+	TileDepth       int
+	PartialSumLanes int
+	// 反量化模型：以 WeightBits 精度存储的权重在读出后需按 16-bit 有效精度逐片缩放，
+	// 每个输出需要 ceil(16/WeightBits) 次缩放累加，由 DequantLanes 路宽的专用单元执行。
+	// DequantLanes 为 0 时不单独建模（计入后处理）。
+	WeightBits           int
+	DequantLanes         int
+	DequantEnergyPJPerOp float64
 }
 
 // TileParameters describes the geometry/properties of a single tile.
@@ -49,5 +72,14 @@ func DefaultParameters() Parameters {
 		OutputWriteEnergyPJPerByte:  0.52,
 		WeightReadEnergyPJPerByte:   0.38,
 		WeightLoadBytesPerCycle:     4096,
+		ProgramPulses:               0, // 0 disables program-and-verify modelling
+		ProgramPulseCycles:          0,
+		ProgramEarlyExitProb:        0.0,
+		ProgramEnergyPJPerBytePulse: 0.12, // per programmed byte per SET/RESET pulse
+		ProgramSeed:                 1,
+		PartialSumLanes:             0,
+		WeightBits:                  8,
+		DequantLanes:                0,
+		DequantEnergyPJPerOp:        0.15,
 	}
 }
@@ -77,3 +77,16 @@ func (p *Preprocessor) Prepare(signs []int, exponents []int, mantissas []int) (a
 
 	return aligned, maxExponent, pSum, aSum
 }
+
+// AccumulateISum 模拟阵列上的乘累加：有符号 4-bit 权重以 +8 偏移存为无符号
+// 电导，因此 I_Sum = Σ aligned·(w+8)，后处理再以 P_Sum·8 扣除偏移。
+func AccumulateISum(aligned []int, weights []int) int64 {
+	if len(aligned) != len(weights) {
+		return 0
+	}
+	iSum := int64(0)
+	for i, value := range aligned {
+		iSum += int64(value) * int64(weights[i]+8)
+	}
+	return iSum
+}
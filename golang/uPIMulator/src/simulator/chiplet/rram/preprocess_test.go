@@ -1,6 +1,9 @@
 package rram
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestPreprocessorPrepare(t *testing.T) {
 	pre := NewPreprocessor(12, 2)
@@ -44,3 +47,74 @@ func TestPostprocessorFinalize(t *testing.T) {
 		t.Fatalf("reference mismatch: want 0.4, got %f", summary.Reference)
 	}
 }
+
+func TestDigitalReferenceMatchesAlignedAccumulation(t *testing.T) {
+	pre := NewPreprocessor(12, 2)
+	post := NewPostprocessor(24)
+	finalize := func(spec *TaskSpec) float64 {
+		aligned, maxExp, pSum, aSum := pre.Prepare(spec.Signs, spec.Exponents, spec.Mantissas)
+		spec.MaxExponent = maxExp
+		spec.PSum = int64(pSum)
+		spec.ASum = aSum
+		spec.ISum = AccumulateISum(aligned, spec.Weights)
+		return post.FinalizeResult(spec.ISum, spec.PSum, spec.MaxExponent, spec, spec.ASum).Final
+	}
+
+	// 指数相同时对齐不截断，RRAM 结果应与数字重算完全一致：1·3 - 1.5·(-2) + 1.25·5 = 12.25。
+	exact := &TaskSpec{Signs: []int{0, 1, 0}, Exponents: []int{15, 15, 15}, Mantissas: []int{0, 512, 256}, Weights: []int{3, -2, 5}}
+	reference, ok := DigitalReference(exact)
+	if !ok || reference != 12.25 || finalize(exact) != reference {
+		t.Fatalf("expected exact agreement at 12.25, got rram=%f digital=%f ok=%v", finalize(exact), reference, ok)
+	}
+
+	// 指数差 5 时对齐右移截断尾数，偏差应很小但非零。
+	shifted := &TaskSpec{Signs: []int{0, 0}, Exponents: []int{15, 10}, Mantissas: []int{0, 1}, Weights: []int{1, 7}}
+	reference, _ = DigitalReference(shifted)
+	diff := math.Abs(finalize(shifted) - reference)
+	if diff == 0 || diff > 0.01 {
+		t.Fatalf("expected a small alignment discrepancy, got %f", diff)
+	}
+
+	if _, ok := DigitalReference(&TaskSpec{Signs: []int{0}, Exponents: []int{15}, Mantissas: []int{0}}); ok {
+		t.Fatalf("a spec without weights cannot be recomputed")
+	}
+}
+
+func TestCrossCheckFlagsCorruptedAccumulator(t *testing.T) {
+	run := func(corrupt int64, errorAbs float64) Stats {
+		chip := NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, DefaultParameters())
+		spec := &TaskSpec{
+			Signs:      []int{0, 1, 0},
+			Exponents:  []int{15, 15, 15},
+			Mantissas:  []int{0, 512, 256},
+			Weights:    []int{3, -2, 5},
+			ErrorAbs:   errorAbs,
+			PostCycles: 4,
+			Phase:      TaskPhasePost,
+			CrossCheck: true,
+		}
+		aligned, maxExp, pSum, aSum := NewPreprocessor(12, 2).Prepare(spec.Signs, spec.Exponents, spec.Mantissas)
+		spec.MaxExponent = maxExp
+		spec.PSum = int64(pSum)
+		spec.ASum = aSum
+		spec.ISum = AccumulateISum(aligned, spec.Weights) + corrupt
+		chip.ScheduleTask(0, spec)
+		for cycles := 0; chip.Busy(); cycles++ {
+			chip.Tick()
+			if cycles > 10_000 {
+				t.Fatalf("task did not drain")
+			}
+		}
+		return chip.Stats()
+	}
+
+	clean := run(0, 0)
+	if clean.DigitalChecks != 1 || clean.DigitalCheckViolations != 0 || clean.DigitalDiscrepancyMax != 0 {
+		t.Fatalf("expected a clean check, got %+v", clean)
+	}
+	// I_Sum 多出 1024 → O 偏 1.0；其中 0.25 在配置的量化误差内。
+	corrupted := run(1024, 0.25)
+	if corrupted.DigitalCheckViolations != 1 || corrupted.DigitalDiscrepancyMax != 0.75 {
+		t.Fatalf("expected the corrupted accumulator to exceed tolerance by 0.75, got %+v", corrupted)
+	}
+}
@@ -18,6 +18,13 @@ type Stats struct {
 	AccumulatedErrorAbs    float64
 	ErrorSamples           int64
 	LastSummary            ResultSummary
+	// DigitalChecks 等字段记录 RRAM 结果与数字重算的交叉校验：偏差只计超出
+	// 配置量化误差的部分，DigitalCheckSkipped 为缺少激活/权重而无法重算的任务数。
+	DigitalChecks           int64
+	DigitalCheckSkipped     int64
+	DigitalCheckViolations  int64
+	DigitalDiscrepancyMax   float64
+	DigitalDiscrepancyAccum float64
 }
 
 func (s *Stats) Reset() {
@@ -48,4 +55,15 @@ func (s *Stats) Accumulate(other Stats) {
 	if other.LastSummary.Valid {
 		s.LastSummary = other.LastSummary
 	}
+	s.mergeDigitalChecks(other)
+}
+
+func (s *Stats) mergeDigitalChecks(other Stats) {
+	s.DigitalChecks += other.DigitalChecks
+	s.DigitalCheckSkipped += other.DigitalCheckSkipped
+	s.DigitalCheckViolations += other.DigitalCheckViolations
+	s.DigitalDiscrepancyAccum += other.DigitalDiscrepancyAccum
+	if other.DigitalDiscrepancyMax > s.DigitalDiscrepancyMax {
+		s.DigitalDiscrepancyMax = other.DigitalDiscrepancyMax
+	}
 }
@@ -18,6 +18,8 @@ type Task struct {
 	PulsesCompleted      int
 	AdcSamplesCompleted  int
 	Phase                TaskPhase
+	// DequantCycles 为 PostprocessCycles 中属于权重反量化的部分，能耗单独计入。
+	DequantCycles int
 }
 
 func (t *Task) clone() *Task {
@@ -85,6 +87,13 @@ type TaskSpec struct {
 	Expected       float64
 	HasExpected    bool
 	Phase          TaskPhase
+	// 交叉校验输入：fp16 激活分量与有符号权重，CrossCheck 打开时在后处理阶段
+	// 以数字方式重算结果并与 Final 比较。
+	Signs      []int
+	Exponents  []int
+	Mantissas  []int
+	Weights    []int
+	CrossCheck bool
 }
 
 // TaskPhase identifies the pipeline stage for a task.
@@ -178,6 +178,7 @@ func (t *Tile) Tick() Stats {
 					t.activeTask.ErrorSampled = true
 					t.activeTask.ErrorAbs = err
 				}
+				recordDigitalCheck(&stats, spec, summary)
 				stats.LastSummary = summary
 			}
 			if t.activeTask.ErrorSampled {
@@ -197,6 +198,7 @@ func (t *Tile) Tick() Stats {
 					t.activeTask.ErrorSampled = true
 					t.activeTask.ErrorAbs = err
 				}
+				recordDigitalCheck(&stats, spec, summary)
 				stats.LastSummary = summary
 			}
 			pulses := t.activeTask.PulsesCompleted
@@ -223,7 +225,7 @@ func (t *Tile) Tick() Stats {
 			stats.PulseCountCim += int64(pulses)
 			stats.TotalAdcSamples += int64(samples)
 			stats.TotalPreprocessCycles += int64(t.activeTask.PreprocessCycles)
-			stats.TotalPostprocessCycles += int64(t.activeTask.PostprocessCycles)
+			stats.TotalPostprocessCycles += int64(t.activeTask.PostprocessCycles - t.activeTask.DequantCycles)
 			stats.CimTasks++
 			if t.activeTask.ErrorSampled {
 				stats.LastErrorAbs = t.activeTask.ErrorAbs
@@ -22,6 +22,8 @@ type WeightDirectory struct {
 	entries   map[WeightKey]*WeightRecord
 	total     int64
 	peakTotal int64
+	capacity  int64
+	evictions int64
 }
 
 // NewWeightDirectory creates an empty directory.
@@ -36,6 +38,34 @@ func (wd *WeightDirectory) TotalBytes() int64 {
 	return wd.total
 }
 
+// SetCapacity bounds the resident bytes; registrations beyond it evict the
+// least recently used chunks. Zero or negative disables the bound.
+func (wd *WeightDirectory) SetCapacity(bytes int64) {
+	if wd == nil {
+		return
+	}
+	if bytes < 0 {
+		bytes = 0
+	}
+	wd.capacity = bytes
+}
+
+// Capacity returns the configured residency bound (0 when unbounded).
+func (wd *WeightDirectory) Capacity() int64 {
+	if wd == nil {
+		return 0
+	}
+	return wd.capacity
+}
+
+// Evictions reports how many chunks were evicted to honour the capacity.
+func (wd *WeightDirectory) Evictions() int64 {
+	if wd == nil {
+		return 0
+	}
+	return wd.evictions
+}
+
 // PeakBytes returns the historical peak bytes tracked by the directory.
 func (wd *WeightDirectory) PeakBytes() int64 {
 	return wd.peakTotal
@@ -82,12 +112,56 @@ func (wd *WeightDirectory) Register(tileID, arrayID int, tag string, bytes int64
 	}
 	wd.entries[key] = rec
 	wd.total += bytes
+	wd.evictToCapacity(key)
 	if wd.total > wd.peakTotal {
 		wd.peakTotal = wd.total
 	}
 	return false
 }
 
+// Touch refreshes the recency of a resident chunk so LRU eviction spares it.
+func (wd *WeightDirectory) Touch(tileID, arrayID int, tag string, tick int) {
+	if wd == nil {
+		return
+	}
+	if rec, ok := wd.entries[wd.makeKey(tileID, arrayID, tag)]; ok && tick > rec.LastLoadTick {
+		rec.LastLoadTick = tick
+	}
+}
+
+// evictToCapacity drops least recently used chunks, never the one just
+// registered, until the resident bytes fit the capacity.
+func (wd *WeightDirectory) evictToCapacity(keep WeightKey) {
+	for wd.capacity > 0 && wd.total > wd.capacity {
+		var victim *WeightRecord
+		for key, rec := range wd.entries {
+			if key == keep {
+				continue
+			}
+			if victim == nil || rec.LastLoadTick < victim.LastLoadTick ||
+				(rec.LastLoadTick == victim.LastLoadTick && lessWeightKey(key, victim.Key)) {
+				victim = rec
+			}
+		}
+		if victim == nil {
+			return
+		}
+		wd.total -= victim.Bytes
+		delete(wd.entries, victim.Key)
+		wd.evictions++
+	}
+}
+
+func lessWeightKey(a, b WeightKey) bool {
+	if a.TileID != b.TileID {
+		return a.TileID < b.TileID
+	}
+	if a.ArrayID != b.ArrayID {
+		return a.ArrayID < b.ArrayID
+	}
+	return a.Tag < b.Tag
+}
+
 // Evict removes the tracked weights (e.g., during explicit unload).
 func (wd *WeightDirectory) Evict(tileID, arrayID int, tag string) {
 	if wd == nil {
@@ -108,4 +182,5 @@ func (wd *WeightDirectory) Reset() {
 	wd.entries = make(map[WeightKey]*WeightRecord)
 	wd.total = 0
 	wd.peakTotal = 0
+	wd.evictions = 0
 }
@@ -1,5 +1,10 @@
 package chiplet
 
+import (
+	"sort"
+	"strings"
+)
+
 // Scheduler captures host-side orchestration logic for the chiplet platform.
 // Concrete implementations will manage task graphs, resource allocation, and
 // cross-chiplet synchronization.
@@ -92,3 +97,71 @@ func (this *BasicScheduler) Tick() {
 func (this *BasicScheduler) IsIdle() bool {
 	return this.queue.isEmpty()
 }
+
+// OldestBatchScheduler dispatches, among queued tasks, the one belonging to the
+// lowest (oldest) batch ID, falling back to arrival order within a batch. It
+// bounds tail latency on bursty streams where FIFO would let newer batches
+// starve an earlier one.
+type OldestBatchScheduler struct {
+	BasicScheduler
+}
+
+func (this *OldestBatchScheduler) Tick() {
+	items := this.queue.items
+	if len(items) == 0 {
+		return
+	}
+
+	best := 0
+	for idx := 1; idx < len(items); idx++ {
+		if items[idx].Batch < items[best].Batch {
+			best = idx
+		}
+	}
+	task := items[best]
+	copy(items[best:], items[best+1:])
+	items[len(items)-1] = nil
+	this.queue.items = items[:len(items)-1]
+
+	if this.executor != nil && task != nil {
+		this.executor.ExecuteTask(task)
+	}
+}
+
+// schedulerRegistry maps --chiplet_scheduler names to scheduler constructors.
+var schedulerRegistry = map[string]func() Scheduler{
+	"fifo":         func() Scheduler { return new(BasicScheduler) },
+	"oldest_batch": func() Scheduler { return new(OldestBatchScheduler) },
+}
+
+// NewScheduler builds the scheduler registered under name. An empty name
+// selects FIFO; unknown names also fall back to FIFO but report false.
+func NewScheduler(name string) (Scheduler, bool) {
+	if factory, ok := schedulerRegistry[name]; ok {
+		return factory(), true
+	}
+	return new(BasicScheduler), name == ""
+}
+
+// SchedulerNames lists the registered scheduler names in sorted order.
+func SchedulerNames() []string {
+	names := make([]string, 0, len(schedulerRegistry))
+	for name := range schedulerRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSchedulerCompare splits a --chiplet_scheduler_compare value ("a,b")
+// into scheduler names. An empty value disables comparison and yields nil.
+func ParseSchedulerCompare(text string) []string {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	names := make([]string, 0, 2)
+	for _, part := range strings.Split(text, ",") {
+		names = append(names, strings.ToLower(strings.TrimSpace(part)))
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package chiplet
+
+import "testing"
+
+type batchLatencyRecorder struct {
+	tick    int
+	arrival map[int]int
+	pending map[int]int
+	latency map[int]int
+}
+
+func (this *batchLatencyRecorder) ExecuteTask(task *Task) {
+	this.pending[task.Batch]--
+	if this.pending[task.Batch] == 0 {
+		this.latency[task.Batch] = this.tick - this.arrival[task.Batch] + 1
+	}
+}
+
+// runBurstyStream feeds a steady trickle of small batches, then a burst of
+// large ones, and returns each batch's arrival-to-completion latency.
+func runBurstyStream(t *testing.T, name string) map[int]int {
+	t.Helper()
+	scheduler, ok := NewScheduler(name)
+	if !ok {
+		t.Fatalf("scheduler %q not registered", name)
+	}
+	recorder := &batchLatencyRecorder{
+		arrival: make(map[int]int),
+		pending: make(map[int]int),
+		latency: make(map[int]int),
+	}
+	scheduler.Init(&Config{}, nil, recorder)
+	defer scheduler.Fini()
+
+	// Batch 0 issues its second half only after the burst of batches 2-3 has
+	// been queued, as a later layer would once its first layer drains.
+	arrivals := map[int][][2]int{
+		0: {{0, 2}, {1, 2}},
+		1: {{2, 4}, {3, 4}},
+		2: {{0, 2}},
+	}
+	for tick := 0; tick < 100; tick++ {
+		recorder.tick = tick
+		for _, arrival := range arrivals[tick] {
+			batch, count := arrival[0], arrival[1]
+			if _, seen := recorder.arrival[batch]; !seen {
+				recorder.arrival[batch] = tick
+			}
+			for i := 0; i < count; i++ {
+				recorder.pending[batch]++
+				scheduler.EnqueueTask(&Task{Batch: batch})
+			}
+		}
+		scheduler.Tick()
+	}
+	if !scheduler.IsIdle() || len(recorder.latency) != 4 {
+		t.Fatalf("%s: stream did not drain, completed %d batches", name, len(recorder.latency))
+	}
+	return recorder.latency
+}
+
+func TestOldestBatchSchedulerReducesWorstCaseLatency(t *testing.T) {
+	fifo := runBurstyStream(t, "fifo")
+	oldest := runBurstyStream(t, "oldest_batch")
+
+	worst := func(latency map[int]int) int {
+		result := 0
+		for _, value := range latency {
+			if value > result {
+				result = value
+			}
+		}
+		return result
+	}
+	if worst(oldest) >= worst(fifo) {
+		t.Fatalf("oldest_batch should cut worst-case latency: %d >= %d", worst(oldest), worst(fifo))
+	}
+	if oldest[0] >= fifo[0] {
+		t.Fatalf("oldest batch should finish sooner: %d >= %d", oldest[0], fifo[0])
+	}
+	// The gain comes out of the newest batch, which now waits behind older work.
+	if oldest[3] <= fifo[3] {
+		t.Fatalf("newest batch should pay for the reordering: %d <= %d", oldest[3], fifo[3])
+	}
+}
+
+func TestNewSchedulerFallsBackToFifo(t *testing.T) {
+	scheduler, ok := NewScheduler("does_not_exist")
+	if ok {
+		t.Fatalf("unknown scheduler name should report false")
+	}
+	if _, isFifo := scheduler.(*BasicScheduler); !isFifo {
+		t.Fatalf("expected fallback to BasicScheduler, got %T", scheduler)
+	}
+}
@@ -31,3 +31,8 @@ func (this *HostTaskStager) HasPending() bool {
 func (this *HostTaskStager) Pop() (*Task, bool) {
 	return this.queue.dequeue()
 }
+
+// Len returns the number of tasks waiting in the stager.
+func (this *HostTaskStager) Len() int {
+	return len(this.queue.items)
+}
@@ -58,6 +58,8 @@ type Task struct {
 	SubOperation  uint32
 	RequestBytes  int64
 	ResponseBytes int64
+	Batch         int
+	OverlapWindow int
 }
 
 // OpNode represents a node in a workload DAG that will be mapped onto chiplet tasks.
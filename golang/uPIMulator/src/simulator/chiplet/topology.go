@@ -1,6 +1,9 @@
 package chiplet
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 // MeshCoordinate identifies a chiplet position on the 2D mesh interconnect.
 type MeshCoordinate struct {
@@ -125,6 +128,25 @@ func (topology *Topology) RramHopDistance(src, dst int) int {
 	return ManhattanDistance(a, b)
 }
 
+// RramNeighbors returns the RRAM chiplets that sit one mesh hop away from the
+// requested chiplet, ordered by chiplet ID.
+func (topology *Topology) RramNeighbors(id int) []int {
+	origin, ok := topology.RramCoord(id)
+	if !ok {
+		return nil
+	}
+	neighbors := make([]int, 0, 4)
+	for idx, coord := range topology.Rram.MeshCoords {
+		if idx == id {
+			continue
+		}
+		if ManhattanDistance(origin, coord) == 1 {
+			neighbors = append(neighbors, idx)
+		}
+	}
+	return neighbors
+}
+
 // DigitalToRramHopDistance estimates the hop distance between a digital source and RRAM destination.
 func (topology *Topology) DigitalToRramHopDistance(srcDigital, dstRram int) int {
 	if topology == nil {
@@ -170,3 +192,103 @@ func buildMesh(count int, offsetX, offsetY int) (rows int, cols int, coords []Me
 	}
 	return rows, cols, coords
 }
+
+// HopDistanceStats summarises the digital↔RRAM hop distances over every pair.
+type HopDistanceStats struct {
+	Pairs int
+	Min   int
+	Max   int
+	Avg   float64
+}
+
+// DigitalRramHopStats returns min/avg/max of DigitalToRramHopDistance across all
+// digital/RRAM pairs, i.e. the communication diameter between the two meshes.
+func (topology *Topology) DigitalRramHopStats() HopDistanceStats {
+	stats := HopDistanceStats{}
+	if topology == nil {
+		return stats
+	}
+	total := 0
+	for d := range topology.Digital.MeshCoords {
+		for r := range topology.Rram.MeshCoords {
+			hops := topology.DigitalToRramHopDistance(d, r)
+			if stats.Pairs == 0 || hops < stats.Min {
+				stats.Min = hops
+			}
+			if stats.Pairs == 0 || hops > stats.Max {
+				stats.Max = hops
+			}
+			total += hops
+			stats.Pairs++
+		}
+	}
+	if stats.Pairs > 0 {
+		stats.Avg = float64(total) / float64(stats.Pairs)
+	}
+	return stats
+}
+
+// CheckHopDistances self-checks the mesh placement and the hop distances derived
+// from it: coordinate counts match the chiplet counts, no two chiplets share a
+// mesh node, every chiplet sits at the row-major grid position its index and
+// the mesh's column count and offset call for, and digital↔RRAM hops equal the
+// Manhattan distance between those grid positions in the shared network space
+// (RRAM rows sit below the digital mesh, shifted by Digital.MeshRows+1). The
+// grid positions are computed from the chiplet index alone, so a stale or
+// edited MeshCoords entry shows up as a hop mismatch. It returns one message
+// per anomaly; an empty result means the topology is consistent.
+func (topology *Topology) CheckHopDistances() []string {
+	if topology == nil {
+		return nil
+	}
+	anomalies := make([]string, 0)
+	if n := len(topology.Digital.MeshCoords); n != topology.Digital.NumChiplets {
+		anomalies = append(anomalies, fmt.Sprintf("digital mesh has %d coordinates for %d chiplets", n, topology.Digital.NumChiplets))
+	}
+	if n := len(topology.Rram.MeshCoords); n != topology.Rram.NumChiplets {
+		anomalies = append(anomalies, fmt.Sprintf("rram mesh has %d coordinates for %d chiplets", n, topology.Rram.NumChiplets))
+	}
+
+	offset := topology.Digital.MeshRows + 1
+	occupied := make(map[MeshCoordinate]string)
+	place := func(coord MeshCoordinate, name string) {
+		if coord.X < 0 || coord.Y < 0 {
+			anomalies = append(anomalies, fmt.Sprintf("%s has negative mesh coordinate (%d,%d)", name, coord.X, coord.Y))
+		}
+		if other, taken := occupied[coord]; taken {
+			anomalies = append(anomalies, fmt.Sprintf("%s shares mesh node (%d,%d) with %s", name, coord.X, coord.Y, other))
+			return
+		}
+		occupied[coord] = name
+	}
+	for d, coord := range topology.Digital.MeshCoords {
+		place(coord, fmt.Sprintf("digital[%d]", d))
+	}
+	for r, coord := range topology.Rram.MeshCoords {
+		place(MeshCoordinate{X: coord.X, Y: coord.Y + offset}, fmt.Sprintf("rram[%d]", r))
+	}
+
+	for d := 0; d < topology.Digital.NumChiplets && d < len(topology.Digital.MeshCoords); d++ {
+		dGrid := gridCoordinate(d, topology.Digital.MeshCols, topology.Digital.MeshOffsetX, topology.Digital.MeshOffsetY)
+		for r := 0; r < topology.Rram.NumChiplets && r < len(topology.Rram.MeshCoords); r++ {
+			rGrid := gridCoordinate(r, topology.Rram.MeshCols, topology.Rram.MeshOffsetX, topology.Rram.MeshOffsetY)
+			hops := topology.DigitalToRramHopDistance(d, r)
+			expected := ManhattanDistance(dGrid, MeshCoordinate{X: rGrid.X, Y: rGrid.Y + offset})
+			if hops != expected {
+				anomalies = append(anomalies, fmt.Sprintf("hop distance digital[%d]↔rram[%d]=%d disagrees with the grid placement (%d)", d, r, hops, expected))
+			} else if hops == 0 {
+				anomalies = append(anomalies, fmt.Sprintf("digital[%d] and rram[%d] are co-located (0 hops)", d, r))
+			}
+		}
+	}
+	return anomalies
+}
+
+// gridCoordinate returns the row-major position of chiplet idx in a mesh with
+// cols columns placed at (offsetX, offsetY).
+func gridCoordinate(idx, cols, offsetX, offsetY int) MeshCoordinate {
+	if cols <= 0 {
+		cols = 1
+	}
+	return MeshCoordinate{X: idx%cols + offsetX, Y: idx/cols + offsetY}
+}
@@ -1,6 +1,9 @@
 package chiplet
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestBuildMeshTopology(t *testing.T) {
 	cfg := &Config{
@@ -52,3 +55,75 @@ func TestBuildMeshTopology(t *testing.T) {
 		t.Fatalf("digital hop distance mismatch: got %d, want %d", dist, ManhattanDistance(a, b))
 	}
 }
+
+func TestRramNeighbors(t *testing.T) {
+	cfg := &Config{
+		NumDigitalChiplets: 4,
+		NumRramChiplets:    8,
+	}
+
+	topology := BuildTopology(cfg)
+
+	// 8 RRAM chiplets form a 3x3 mesh with the last slot empty:
+	// 0 1 2
+	// 3 4 5
+	// 6 7
+	cases := map[int][]int{
+		0: {1, 3},
+		4: {1, 3, 5, 7},
+		5: {2, 4},
+		7: {4, 6},
+	}
+	for id, want := range cases {
+		got := topology.RramNeighbors(id)
+		if len(got) != len(want) {
+			t.Fatalf("rram %d neighbors = %v, want %v", id, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("rram %d neighbors = %v, want %v", id, got, want)
+			}
+		}
+	}
+
+	if got := topology.RramNeighbors(-1); got != nil {
+		t.Fatalf("expected nil neighbors for invalid id, got %v", got)
+	}
+}
+
+func TestCheckHopDistancesFlagsMisplacedChiplets(t *testing.T) {
+	cfg := &Config{
+		NumDigitalChiplets: 4,
+		NumRramChiplets:    4,
+	}
+	topology := BuildTopology(cfg)
+	if anomalies := topology.CheckHopDistances(); len(anomalies) != 0 {
+		t.Fatalf("expected generated mesh to be consistent, got %v", anomalies)
+	}
+	stats := topology.DigitalRramHopStats()
+	if stats.Pairs != 16 || stats.Min <= 0 || stats.Max < stats.Min || stats.Avg < float64(stats.Min) || stats.Avg > float64(stats.Max) {
+		t.Fatalf("unexpected hop stats %+v", stats)
+	}
+
+	topology.Rram.MeshCoords[1] = topology.Rram.MeshCoords[0]
+	topology.Digital.MeshCoords = append(topology.Digital.MeshCoords, MeshCoordinate{X: -1, Y: 0})
+	anomalies := topology.CheckHopDistances()
+	want := []string{
+		"digital mesh has 5 coordinates for 4 chiplets",
+		"digital[4] has negative mesh coordinate",
+		"rram[1] shares mesh node",
+		"hop distance digital[0]↔rram[1]=",
+	}
+	for _, prefix := range want {
+		found := false
+		for _, anomaly := range anomalies {
+			if strings.HasPrefix(anomaly, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("expected anomaly %q, got %v", prefix, anomalies)
+		}
+	}
+}
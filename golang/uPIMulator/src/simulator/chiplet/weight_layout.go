@@ -0,0 +1,88 @@
+package chiplet
+
+// Weight layout policies for --chiplet_rram_weight_layout. A command opts in by
+// carrying a logical weight tile index in its "logical_tile" metadata; the
+// orchestrator then derives the physical RRAM chiplet and local tile from it.
+const (
+	WeightLayoutNone   = "none"
+	WeightLayoutBlock  = "block"
+	WeightLayoutCyclic = "cyclic"
+)
+
+const metadataKeyLogicalTile = "logical_tile"
+
+// weightLayoutPlacement maps a logical tile to (chiplet, local tile).
+//
+//   - block fills every tile of one chiplet before moving to the next, keeping
+//     a layer's consecutive tiles together;
+//   - cyclic deals consecutive tiles round-robin across chiplets so residency
+//     stays balanced even when a layer only covers a few tiles.
+func weightLayoutPlacement(policy string, logical int, numChiplets int, tilesPerChiplet int) (int, int, bool) {
+	if logical < 0 || numChiplets <= 0 {
+		return 0, 0, false
+	}
+	if tilesPerChiplet <= 0 {
+		tilesPerChiplet = 1
+	}
+	switch policy {
+	case WeightLayoutBlock:
+		return (logical / tilesPerChiplet) % numChiplets, logical % tilesPerChiplet, true
+	case WeightLayoutCyclic:
+		return logical % numChiplets, (logical / numChiplets) % tilesPerChiplet, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// applyWeightLayout rewrites the RRAM placement of cmd according to the
+// configured layout policy. RRAM commands get their chiplet and tile_id;
+// transfers get the RRAM endpoint on their side of the link.
+func (this *HostOrchestrator) applyWeightLayout(node *OpNode) {
+	if this.config == nil || this.topology == nil || node == nil {
+		return
+	}
+	policy := this.config.RramWeightLayout
+	if policy == "" || policy == WeightLayoutNone {
+		return
+	}
+	cmd, ok := node.Payload.(*CommandDescriptor)
+	if !ok || cmd == nil || cmd.Metadata == nil {
+		return
+	}
+	if _, exists := cmd.Metadata[metadataKeyLogicalTile]; !exists {
+		return
+	}
+	logical := metadataInt(cmd.Metadata, metadataKeyLogicalTile, -1)
+	tilesPerChiplet := this.topology.Rram.TilesPerDim * this.topology.Rram.TilesPerDim
+	chipletID, tileID, ok := weightLayoutPlacement(policy, logical, this.topology.Rram.NumChiplets, tilesPerChiplet)
+	if !ok {
+		return
+	}
+
+	switch node.Target {
+	case TaskTargetRram:
+		cmd.ChipletID = int32(chipletID)
+		cmd.Metadata["tile_id"] = tileID
+	case TaskTargetTransfer:
+		switch cmd.Flags & TransferFlagDirectionMask {
+		case TransferFlagDigitalToRram:
+			cmd.ChipletID = int32(chipletID)
+		case TransferFlagRramToDigital:
+			cmd.Queue = int32(chipletID)
+		default:
+			return
+		}
+	default:
+		return
+	}
+	this.weightLayoutPlaced++
+}
+
+// WeightLayoutPlaced returns how many commands were placed by the weight
+// layout policy.
+func (this *HostOrchestrator) WeightLayoutPlaced() int {
+	if this == nil {
+		return 0
+	}
+	return this.weightLayoutPlaced
+}
@@ -0,0 +1,69 @@
+package chiplet
+
+import "testing"
+
+func TestWeightLayoutPlacement(t *testing.T) {
+	t.Parallel()
+
+	// 3 chiplets with 4 tiles each.
+	cases := []struct {
+		policy  string
+		logical int
+		chiplet int
+		tile    int
+	}{
+		{WeightLayoutBlock, 0, 0, 0},
+		{WeightLayoutBlock, 3, 0, 3},
+		{WeightLayoutBlock, 4, 1, 0},
+		{WeightLayoutBlock, 13, 0, 1},
+		{WeightLayoutCyclic, 0, 0, 0},
+		{WeightLayoutCyclic, 1, 1, 0},
+		{WeightLayoutCyclic, 4, 1, 1},
+		{WeightLayoutCyclic, 12, 0, 0},
+	}
+	for _, tc := range cases {
+		chiplet, tile, ok := weightLayoutPlacement(tc.policy, tc.logical, 3, 4)
+		if !ok || chiplet != tc.chiplet || tile != tc.tile {
+			t.Fatalf("%s logical=%d: got (%d,%d,%v), want (%d,%d)", tc.policy, tc.logical, chiplet, tile, ok, tc.chiplet, tc.tile)
+		}
+	}
+	if _, _, ok := weightLayoutPlacement(WeightLayoutNone, 1, 3, 4); ok {
+		t.Fatalf("none policy should not place tiles")
+	}
+}
+
+func TestApplyWeightLayoutRewritesRramAndTransferEndpoints(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{NumDigitalChiplets: 1, NumRramChiplets: 2, RramTilesPerDim: 2, RramWeightLayout: WeightLayoutCyclic}
+	orch := &HostOrchestrator{config: config, topology: BuildTopology(config)}
+
+	load := &OpNode{Target: TaskTargetRram, Payload: &CommandDescriptor{
+		Kind:      CommandKindRramWeightLoad,
+		ChipletID: 0,
+		Metadata:  map[string]interface{}{"logical_tile": 3},
+	}}
+	orch.applyWeightLayout(load)
+	cmd := load.Payload.(*CommandDescriptor)
+	if cmd.ChipletID != 1 || cmd.Metadata["tile_id"] != 1 {
+		t.Fatalf("expected chiplet 1 tile 1, got chiplet %d tile %v", cmd.ChipletID, cmd.Metadata["tile_id"])
+	}
+
+	out := &OpNode{Target: TaskTargetTransfer, Payload: &CommandDescriptor{
+		Kind:      CommandKindTransferD2C,
+		Flags:     TransferFlagRramToDigital,
+		Queue:     0,
+		ChipletID: 0,
+		Metadata:  map[string]interface{}{"logical_tile": 3},
+	}}
+	orch.applyWeightLayout(out)
+	if got := out.Payload.(*CommandDescriptor); got.Queue != 1 || got.ChipletID != 0 {
+		t.Fatalf("expected source rram 1 and digital 0, got queue=%d chiplet=%d", got.Queue, got.ChipletID)
+	}
+
+	untouched := &OpNode{Target: TaskTargetRram, Payload: &CommandDescriptor{Kind: CommandKindRramExecute, ChipletID: 0}}
+	orch.applyWeightLayout(untouched)
+	if orch.WeightLayoutPlaced() != 2 {
+		t.Fatalf("expected 2 placed commands, got %d", orch.WeightLayoutPlaced())
+	}
+}
@@ -0,0 +1,251 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// dvfsState 记录单个 chiplet 的频率档位与利用率窗口。phase 以百分比累加，
+// 每累计满 100 才执行一次域时钟 tick，从而在低档位上按比例跳过 tick。
+type dvfsState struct {
+	level         int
+	phase         int
+	windowTicks   int
+	windowBusy    int
+	ticksAtLevel  []int64
+	tickedInCycle int
+	skippedTicks  int64
+}
+
+// dvfsController 按最近窗口内的利用率为每个 chiplet 升降频：利用率低于
+// lowUtil 时降一档，高于 highUtil 时升一档。被跳过的 tick 不计静态能耗，
+// 动态能耗按当前档位的 V² 缩放（见 dynamicEnergyScale），两者节省的部分都累加到
+// energySavedPJ。
+type dvfsController struct {
+	levels        []int
+	window        int
+	lowUtil       int
+	highUtil      int
+	digital       []*dvfsState
+	rram          []*dvfsState
+	transitions   int64
+	energySavedPJ float64
+	// frequencyLog 是 chiplet_dvfs_frequency.csv 的内容：每周期采样各 chiplet 的
+	// 有效频率，只在任一 chiplet 换档时追加一行，文件按阶梯函数解读。
+	frequencyLog  []string
+	lastFrequency []int
+}
+
+func newDvfsController(config *chiplet.Config, numDigital, numRram int) *dvfsController {
+	if config == nil || !config.Dvfs {
+		return nil
+	}
+
+	levels := config.DvfsLevels
+	if len(levels) == 0 {
+		levels = []int{100}
+	}
+	window := config.DvfsWindow
+	if window <= 0 {
+		window = 1
+	}
+
+	controller := &dvfsController{
+		levels:   append([]int(nil), levels...),
+		window:   window,
+		lowUtil:  config.DvfsLowUtil,
+		highUtil: config.DvfsHighUtil,
+		digital:  make([]*dvfsState, numDigital),
+		rram:     make([]*dvfsState, numRram),
+	}
+	for i := range controller.digital {
+		controller.digital[i] = &dvfsState{ticksAtLevel: make([]int64, len(levels))}
+	}
+	for i := range controller.rram {
+		controller.rram[i] = &dvfsState{ticksAtLevel: make([]int64, len(levels))}
+	}
+	header := []string{"cycle"}
+	for i := range controller.digital {
+		header = append(header, fmt.Sprintf("digital%d_mhz", i))
+	}
+	for i := range controller.rram {
+		header = append(header, fmt.Sprintf("rram%d_mhz", i))
+	}
+	controller.frequencyLog = []string{strings.Join(header, ",")}
+	return controller
+}
+
+// effectiveMhz 返回 state 当前档位下的有效频率。
+func (this *dvfsController) effectiveMhz(state *dvfsState, nominalMhz int) int {
+	if this == nil || state == nil {
+		return nominalMhz
+	}
+	return nominalMhz * this.levels[state.level] / 100
+}
+
+// averageMhz 按各档位停留的名义 tick 数加权，给出整个运行期间的平均有效频率。
+func (this *dvfsController) averageMhz(state *dvfsState, nominalMhz int) float64 {
+	if this == nil || state == nil {
+		return float64(nominalMhz)
+	}
+	ticks := int64(0)
+	weighted := int64(0)
+	for idx, level := range this.levels {
+		ticks += state.ticksAtLevel[idx]
+		weighted += state.ticksAtLevel[idx] * int64(level)
+	}
+	if ticks == 0 {
+		return float64(this.effectiveMhz(state, nominalMhz))
+	}
+	return float64(nominalMhz) * float64(weighted) / float64(ticks) / 100
+}
+
+func (this *dvfsController) currentFrequencies(digitalMhz, rramMhz int) []int {
+	frequencies := make([]int, 0, len(this.digital)+len(this.rram))
+	for _, state := range this.digital {
+		frequencies = append(frequencies, this.effectiveMhz(state, digitalMhz))
+	}
+	for _, state := range this.rram {
+		frequencies = append(frequencies, this.effectiveMhz(state, rramMhz))
+	}
+	return frequencies
+}
+
+func frequencyRow(cycle int, frequencies []int) string {
+	fields := make([]string, 0, len(frequencies)+1)
+	fields = append(fields, strconv.Itoa(cycle))
+	for _, mhz := range frequencies {
+		fields = append(fields, strconv.Itoa(mhz))
+	}
+	return strings.Join(fields, ",")
+}
+
+// sampleFrequencies 在周期末采样各 chiplet 的有效频率，与上次记录不同时追加一行。
+func (this *dvfsController) sampleFrequencies(cycle, digitalMhz, rramMhz int) {
+	if this == nil {
+		return
+	}
+	frequencies := this.currentFrequencies(digitalMhz, rramMhz)
+	changed := this.lastFrequency == nil
+	for i := 0; !changed && i < len(frequencies); i++ {
+		changed = frequencies[i] != this.lastFrequency[i]
+	}
+	if !changed {
+		return
+	}
+	this.lastFrequency = frequencies
+	this.frequencyLog = append(this.frequencyLog, frequencyRow(cycle, frequencies))
+}
+
+// frequencyChanges 返回首行采样之后记录的换档次数。
+func (this *dvfsController) frequencyChanges() int {
+	if this == nil || len(this.frequencyLog) <= 2 {
+		return 0
+	}
+	return len(this.frequencyLog) - 2
+}
+
+// frequencyLines 返回 frequencyLog，并在最后一次换档之后补一行 cycle 时刻的
+// 状态，使阶梯覆盖到运行结束。
+func (this *dvfsController) frequencyLines(cycle, digitalMhz, rramMhz int) []string {
+	if this == nil || len(this.frequencyLog) <= 1 {
+		return nil
+	}
+	lines := append([]string(nil), this.frequencyLog...)
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, strconv.Itoa(cycle)+",") {
+		lines = append(lines, frequencyRow(cycle, this.currentFrequencies(digitalMhz, rramMhz)))
+	}
+	return lines
+}
+
+func (this *dvfsController) digitalState(id int) *dvfsState {
+	if this == nil || id < 0 || id >= len(this.digital) {
+		return nil
+	}
+	return this.digital[id]
+}
+
+func (this *dvfsController) rramState(id int) *dvfsState {
+	if this == nil || id < 0 || id >= len(this.rram) {
+		return nil
+	}
+	return this.rram[id]
+}
+
+// dynamicEnergyScale 返回 state 当前档位下动态能耗相对名义值的比例。电压随频率
+// 线性缩放，动态功耗为 C·V²·f；每个操作持续 1/f，因此单次操作的能耗按 V² 缩放。
+func (this *dvfsController) dynamicEnergyScale(state *dvfsState) float64 {
+	if this == nil || state == nil {
+		return 1
+	}
+	voltage := float64(this.levels[state.level]) / 100
+	return voltage * voltage
+}
+
+// scaleDynamicEnergy 把一次 tick 新增的动态能耗（*energy 相对 before 的增量）按当前
+// 档位缩放，并把省下的部分计入 energySavedPJ。
+func (this *dvfsController) scaleDynamicEnergy(state *dvfsState, energy *float64, before float64) {
+	if this == nil || state == nil {
+		return
+	}
+	delta := *energy - before
+	if delta <= 0 {
+		return
+	}
+	scaled := delta * this.dynamicEnergyScale(state)
+	*energy = before + scaled
+	this.energySavedPJ += delta - scaled
+}
+
+// step 在一个名义域 tick 上推进 state，并返回该 chiplet 此 tick 是否执行。
+// busy 用于统计利用率；窗口结束时根据利用率调整档位。
+func (this *dvfsController) step(state *dvfsState, busy bool) bool {
+	if state == nil {
+		return true
+	}
+
+	state.ticksAtLevel[state.level]++
+	state.windowTicks++
+	if busy {
+		state.windowBusy++
+	}
+
+	state.phase += this.levels[state.level]
+	run := false
+	if state.phase >= 100 {
+		state.phase -= 100
+		run = true
+		state.tickedInCycle++
+	} else {
+		state.skippedTicks++
+	}
+
+	if state.windowTicks >= this.window {
+		util := state.windowBusy * 100 / state.windowTicks
+		if util < this.lowUtil && state.level < len(this.levels)-1 {
+			state.level++
+			this.transitions++
+		} else if util > this.highUtil && state.level > 0 {
+			state.level--
+			this.transitions++
+		}
+		state.windowTicks = 0
+		state.windowBusy = 0
+	}
+
+	return run
+}
+
+// consumeTicked 返回本平台周期内 state 实际执行的 tick 数并清零。
+func (this *dvfsController) consumeTicked(state *dvfsState) int {
+	if state == nil {
+		return 0
+	}
+	ticks := state.tickedInCycle
+	state.tickedInCycle = 0
+	return ticks
+}
@@ -1,8 +1,12 @@
 package simulator
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"math"
+	"math/rand"
+	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -25,11 +29,41 @@ import (
 type ChipletPlatform struct {
 	config                        *chiplet.Config
 	topology                      *chiplet.Topology
+	topologyAnomalies             []string
+	undersizedBufferWarnings      []string
+	analyticalTransferCycles      int64
+	eccOverheadBytes              int64
+	pipelinedTransfers            int64
+	collectivePhases              map[string]int
+	collectiveChargedCycles       int64
+	pipelinedHiddenCycles         int64
+	duplexLinks                   map[duplexLinkKey]*duplexLinkState
+	duplexContentionEvents        int64
+	duplexContentionCycles        int64
+	retirementQueue               []pendingRetirement
+	retirementStallCycles         int64
+	retiredTasks                  int64
+	barriers                      int64
+	barrierProducers              int64
+	barrierWaitCyclesTotal        int64
+	rramCrossCheckWarned          bool
+	unrecognizedCommands          int64
+	unrecognizedCommandKeys       map[string]bool
+	transferIssuedBytes           int64
+	transferCompletedBytes        int64
+	transferDroppedBytes          int64
+	transferInflightPeakBytes     int64
+	cycleCapReached               bool
+	profiler                      *cycleProfiler
+	wallStart                     time.Time
 	digitalChiplets               []*digital.Chiplet
 	rramChiplets                  []*rram.Chiplet
 	orchestrator                  *chiplet.HostOrchestrator
 	stager                        *chiplet.HostTaskStager
 	scheduler                     chiplet.Scheduler
+	schedulerOverride             string
+	commandGraph                  []chiplet.CommandDescriptor
+	outputDirpath                 string
 	executedDigitalTasks          int
 	executedRramTasks             int
 	executedHostTasks             int
@@ -49,6 +83,10 @@ type ChipletPlatform struct {
 	transferThrottleEvents        int
 	hostDmaController             *host.DMAController
 	booksimClient                 *booksim.Client
+	booksimCache                  *booksim.EstimateCache
+	booksimCalls                  int64
+	booksimEstimated              int64
+	booksimFallbacks              int64
 	digitalBytesLoaded            int64
 	digitalBytesStored            int64
 	digitalScalarOps              int64
@@ -64,6 +102,11 @@ type ChipletPlatform struct {
 	kvCacheMissBytes              int64
 	kvCacheEvictedBytes           int64
 	kvCachePeakBytes              int64
+	kvHeadCaches                  map[int]*host.KVCache
+	kvHeadStats                   map[int]*kvHeadCounter
+	kvShadowCache                 *host.KVCache
+	kvShadowHits                  int64
+	kvShadowMisses                int64
 	digitalClockMhz               int
 	rramClockMhz                  int
 	interconnectClockMhz          int
@@ -111,28 +154,99 @@ type ChipletPlatform struct {
 	hostDmaLoadBytesTotal         int64
 	hostDmaStoreBytesTotal        int64
 
-	transferAdaptiveCycles int
-	tokenizer              tokenizer.Tokenizer
-	progressInterval       int
-	nextProgressCycle      int
-	statsFlushInterval     int
-	nextStatsFlushCycle    int
-	rramInputBuffered      []int64
-	rramProcessingBytes    []int64
-	rramOutputBuffered     []int64
-	gatingQueues           map[gatingKey][]*moeGatingSnapshot
-	moeEventMetrics        map[int]*moeEventMetrics
-	moeEventsTotal         int64
-	moeTokensTotal         int64
-	moeExpertsTotal        int64
-	moeLatencyTotal        int64
-	moeLatencySamples      int64
-	moeLatencyMax          int
-	moeSnapshotHits        int64
-	moeSnapshotMisses      int64
-	moeFallbackEvents      int64
-	moeSessionsCompleted   int64
-	moeSummaryAppended     bool
+	transferAdaptiveCycles  int
+	tokenizer               tokenizer.Tokenizer
+	progressInterval        int
+	nextProgressCycle       int
+	statsFlushInterval      int
+	nextStatsFlushCycle     int
+	snapshotInterval        int
+	nextSnapshotCycle       int
+	timeseriesLog           []string
+	timeseriesRows          int
+	timeseriesFile          *os.File
+	wastedTasks             int
+	wastedEnergyPJ          float64
+	wastedCycles            int64
+	transferPreemptions     int64
+	transferPriorityLatency map[string]*latencyCounter
+	inflightTransfers       []*inflightTransfer
+	preemptedTransfers      map[*chiplet.Task]*inflightTransfer
+	digitalReserveBlocked   []bool
+	rramReserveBlocked      []bool
+	idleReasonCycles        map[string]*idleReasonCounter
+	lastSnapshotCycle       int
+	rramInputBuffered       []int64
+	rramProcessingBytes     []int64
+	rramOutputBuffered      []int64
+	rramOutputPending       []int64
+	rramOutputLimited       []int64
+	rramInputConsumed       []int64
+	rramOutputProduced      []int64
+	gatingQueues            map[gatingKey][]*moeGatingSnapshot
+	moeEventMetrics         map[int]*moeEventMetrics
+	moeEventsTotal          int64
+	moeTokensTotal          int64
+	moeExpertsTotal         int64
+	moeLatencyTotal         int64
+	moeLatencySamples       int64
+	moeLatencyMax           int
+	moeSnapshotHits         int64
+	moeSnapshotMisses       int64
+	moeFallbackEvents       int64
+	moeSessionsCompleted    int64
+	moeGatingSoftmaxCycles  int64
+	transposeTasks          int64
+	transposeCycles         int64
+	transposePenaltyCycles  int64
+	moeSummaryAppended      bool
+	weightNeighborHits      int64
+	weightNeighborBytes     int64
+	weightPrefetchPending   map[weightPrefetchKey]weightPrefetchLoad
+	weightPrefetchLoads     int64
+	weightPrefetchHits      int64
+	weightPrefetchWasted    int64
+	weightPrefetchSkipped   int64
+	activeChipletCycles     int64
+	busyPlatformCycles      int64
+	serialPlatformCycles    int64
+	activationSpilled       []int64
+	activationResidents     [][]*activationResident
+	activationSpillBytes    int64
+	activationReloadBytes   int64
+	activationSpillEvents   int64
+	activationOffloadDma    int64
+	dvfs                    *dvfsController
+	powerdown               *powerdownController
+	transferFloorHits       int64
+	cmdFetchCycles          int64
+	cmdFetchCommands        int64
+	interconnectStagePJ     [interconnectStageCount]float64
+	stagerPeakDepth         int
+	lmHeadTokens            int64
+	rngStreams              *misc.RNGStreams
+	resultSampler           *rand.Rand
+	resultSeen              []bool
+	resultTail              []string
+	resultsTotal            int64
+	resultsSampled          int64
+	rooflineStats           map[string]*rooflineEntry
+	transferScheduleCycles  int64
+	transferSizeHist        map[transferSizeKey]int64
+	dispatchedTasks         int64
+	lastDispatchedTasks     int64
+	stallCycles             int
+	aborted                 bool
+	abortReason             string
+	runArgs                 string
+	runOptions              string
+}
+
+// transferSizeKey buckets transfers by stage and the next power of two of
+// their size in bytes.
+type transferSizeKey struct {
+	stage  string
+	bucket int64
 }
 
 type gatingKey struct {
@@ -154,6 +268,28 @@ type moeGatingSnapshot struct {
 	metadata        map[string]interface{}
 }
 
+// latencyCounter accumulates wait-cycle samples for an average.
+type latencyCounter struct {
+	samples int64
+	total   int64
+}
+
+func (counter *latencyCounter) average() float64 {
+	if counter.samples == 0 {
+		return 0
+	}
+	return float64(counter.total) / float64(counter.samples)
+}
+
+// rooflineEntry accumulates the work and traffic of one layer (stage name or
+// command kind) for the roofline report.
+type rooflineEntry struct {
+	tasks int64
+	macs  int64
+	flops int64
+	bytes int64
+}
+
 type moeEventMetrics struct {
 	startCycle int
 	tokens     int
@@ -166,8 +302,21 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 	config_loader.Init()
 
 	config := chiplet.LoadConfig(config_loader)
+	if config.ReproPath != "" {
+		config = restoreReproConfig(config)
+	}
+	if this.schedulerOverride != "" {
+		config.SchedulerMode = this.schedulerOverride
+	}
 	topology := chiplet.BuildTopology(config)
 	binDirpath := command_line_parser.StringParameter("bin_dirpath")
+	if config.EnergyCalibrationPath != "" {
+		factors, err := chiplet.LoadEnergyCalibration(config.EnergyCalibrationPath)
+		if err != nil {
+			panic(err)
+		}
+		config.EnergyCalibration = factors
+	}
 
 	digitalChiplets := make([]*digital.Chiplet, 0, topology.Digital.NumChiplets)
 	digitalParams := digital.DefaultParameters()
@@ -180,12 +329,30 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 	if config.DigitalScratchBuffer > 0 {
 		digitalParams.Buffer.ScratchBytes = config.DigitalScratchBuffer
 	}
+	if config.DigitalL2Bytes > 0 {
+		digitalParams.Buffer.L2Bytes = config.DigitalL2Bytes
+	}
+	if config.DigitalL2Bandwidth > 0 {
+		digitalParams.Buffer.L2BandwidthBytesPerCycle = config.DigitalL2Bandwidth
+	}
+	digitalParams.Buffer.ActivationBanks = config.ActivationBanks
+	digitalParams.Buffer.RawLatencyCycles = config.DigitalRawLatency
+	digitalParams.Buffer.PrefetchDistance = config.PrefetchDistance
+	digitalParams.Spu.IssueWidth = config.DigitalIssueWidth
 	if config.TransferBandwidthDr > 0 {
 		digitalParams.Interconnect.BytesPerCycle = config.TransferBandwidthDr
 	}
 	if config.TransferBandwidthRd > 0 && config.TransferBandwidthRd < digitalParams.Interconnect.BytesPerCycle {
 		digitalParams.Interconnect.BytesPerCycle = config.TransferBandwidthRd
 	}
+	applyDigitalEnergyCalibration(&digitalParams, config.EnergyCalibration)
+	if config.ModelPath != "" {
+		overrides, err := chiplet.LoadBufferBandwidths(config.ModelPath, topology.Digital.NumChiplets)
+		if err != nil {
+			panic(err)
+		}
+		config.BufferBandwidths = overrides
+	}
 	for i := 0; i < topology.Digital.NumChiplets; i++ {
 		digitalChiplets = append(digitalChiplets, digital.NewChiplet(
 			i,
@@ -195,7 +362,7 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 			topology.Digital.SpusPerChiplet,
 			config.DigitalActivationBuffer,
 			config.DigitalScratchBuffer,
-			digitalParams,
+			digitalParamsForChiplet(digitalParams, config.BufferBandwidths, i),
 		))
 	}
 
@@ -204,6 +371,25 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 	if config.RramClockMhz > 0 {
 		rramParams.ClockMHz = config.RramClockMhz
 	}
+	rramParams.ProgramSeed = config.RngSeed
+	if config.RramProgramVerify {
+		// program-and-verify 需显式开启；否则权重加载不计编程脉冲，保持既有结果。
+		rramParams.ProgramPulses = config.RramProgramPulses
+		rramParams.ProgramPulseCycles = config.RramWriteLatency
+		rramParams.ProgramEarlyExitProb = config.RramProgramEarlyExit
+	}
+	rramParams.OverlapWeightActivation = config.RramLoadPathMode == "overlap"
+	rramParams.WeightCapacityBytes = config.RramWeightCapacity
+	rramParams.TileDepth = config.RramTileDepth
+	rramParams.PartialSumLanes = config.RramPsumLanes
+	rramParams.DequantLanes = config.RramDequantLanes
+	if config.RramWeightBits > 0 {
+		rramParams.WeightBits = config.RramWeightBits
+	}
+	if config.RramWeightLoadBw > 0 {
+		rramParams.WeightLoadBytesPerCycle = config.RramWeightLoadBw
+	}
+	applyRramEnergyCalibration(&rramParams, config.EnergyCalibration)
 	for i := 0; i < topology.Rram.NumChiplets; i++ {
 		rramChiplets = append(rramChiplets, rram.NewChiplet(
 			i,
@@ -228,9 +414,20 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 	if binDirpath != "" {
 		commandFile = filepath.Join(binDirpath, "chiplet_commands.json")
 	}
+	if config.ReproPath != "" {
+		commandFile = filepath.Join(config.ReproPath, "chiplet_commands.json")
+		fmt.Printf("[chiplet] 从复现目录 %s 重放剩余命令图。\n", config.ReproPath)
+	}
+	if this.commandGraph != nil {
+		orchestrator.SetCommandGraph(this.commandGraph)
+	}
 	orchestrator.Init(config, topology, commandFile)
 
-	scheduler := new(chiplet.BasicScheduler)
+	scheduler, ok := chiplet.NewScheduler(config.SchedulerMode)
+	if !ok {
+		fmt.Printf("[chiplet] warning: unknown scheduler %q (available: %s); falling back to fifo\n",
+			config.SchedulerMode, strings.Join(chiplet.SchedulerNames(), ", "))
+	}
 	statFactory := new(misc.StatFactory)
 	statFactory.Init("ChipletPlatform")
 
@@ -256,14 +453,28 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 
 	this.config = config
 	this.topology = topology
+	this.topologyAnomalies = topology.CheckHopDistances()
+	for _, anomaly := range this.topologyAnomalies {
+		fmt.Printf("[chiplet] warning: topology %s\n", anomaly)
+	}
 	this.digitalChiplets = digitalChiplets
 	this.rramChiplets = rramChiplets
+	if config.BufferWarnings != 0 {
+		this.undersizedBufferWarnings = this.checkBufferWorkingSets()
+		for _, warning := range this.undersizedBufferWarnings {
+			fmt.Printf("[chiplet] warning: %s\n", warning)
+		}
+	}
 	this.orchestrator = orchestrator
 	this.stager = stager
 	this.scheduler = scheduler
 	this.executedDigitalTasks = 0
 	this.executedRramTasks = 0
 	this.binDirpath = binDirpath
+	if this.outputDirpath != "" {
+		this.binDirpath = this.outputDirpath
+	}
+	this.wallStart = time.Now()
 	this.statFactory = statFactory
 	var ramulatorClient *ramulator.Client
 	if config.HostDmaUseRamulator {
@@ -297,8 +508,20 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 		}
 	}
 	this.booksimClient = booksimClient
+	this.booksimCache = nil
+	this.booksimCalls = 0
+	this.booksimEstimated = 0
+	this.booksimFallbacks = 0
+	if booksimClient != nil {
+		this.booksimCache = booksim.NewEstimateCache(config.NocBooksimCacheSize, config.NocBooksimCacheBucket, config.NocBooksimCacheTtl)
+	}
 	orchestrator.SetTransferLatencyEstimator(this.buildTransferLatencyEstimator())
 	this.kvCache = host.NewKVCache(config.KvCacheBytes)
+	this.kvHeadCaches = make(map[int]*host.KVCache)
+	this.kvHeadStats = make(map[int]*kvHeadCounter)
+	this.kvShadowCache = nil
+	this.kvShadowHits = 0
+	this.kvShadowMisses = 0
 	this.currentCycle = 0
 	this.maxWaitCycles = 0
 	this.maxDigitalThroughput = 0
@@ -338,18 +561,48 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 	this.rramPhase = 0
 	this.interconnectPhase = 0
 	this.digitalDeferrals = make([]int, len(digitalChiplets))
+	this.activationSpilled = make([]int64, len(digitalChiplets))
+	this.activationResidents = make([][]*activationResident, len(digitalChiplets))
+	this.dvfs = newDvfsController(config, len(digitalChiplets), len(rramChiplets))
+	this.powerdown = newPowerdownController(config, len(digitalChiplets), len(rramChiplets))
+	this.profiler = newCycleProfiler(config)
 	this.digitalSaturation = make([]int, len(digitalChiplets))
 	this.rramDeferrals = make([]int, len(rramChiplets))
 	this.rramSaturation = make([]int, len(rramChiplets))
+	this.digitalReserveBlocked = make([]bool, len(digitalChiplets))
+	this.rramReserveBlocked = make([]bool, len(rramChiplets))
+	this.idleReasonCycles = make(map[string]*idleReasonCounter)
 	this.rramInputBuffered = make([]int64, len(rramChiplets))
 	this.rramProcessingBytes = make([]int64, len(rramChiplets))
 	this.rramOutputBuffered = make([]int64, len(rramChiplets))
+	this.rramOutputPending = make([]int64, len(rramChiplets))
+	this.rramInputConsumed = make([]int64, len(rramChiplets))
+	this.rramOutputProduced = make([]int64, len(rramChiplets))
+	this.rramOutputLimited = make([]int64, len(rramChiplets))
 	this.gatingQueues = make(map[gatingKey][]*moeGatingSnapshot)
 	this.moeEventMetrics = make(map[int]*moeEventMetrics)
-	this.cycleLog = []string{"cycle,digital_exec,digital_completed,rram_exec,transfer_exec,transfer_bytes,transfer_hops,host_dma_load_bytes,host_dma_store_bytes,kv_hits,kv_misses,kv_load_bytes,kv_store_bytes,digital_load_bytes,digital_store_bytes,digital_pe_active,digital_spu_active,digital_vpu_active,throttle_until,throttle_events,deferrals,avg_wait,digital_util,rram_util,digital_ticks,rram_ticks,interconnect_ticks,host_tasks,outstanding_digital,outstanding_rram,outstanding_transfer,outstanding_dma,transfer_to_rram_bytes,transfer_to_digital_bytes,transfer_host_load_bytes,transfer_host_store_bytes,transfer_throttle_events_total,transfer_throttle_cycles_total"}
+	this.cycleLog = []string{"cycle,digital_exec,digital_completed,rram_exec,transfer_exec,transfer_bytes,transfer_hops,host_dma_load_bytes,host_dma_store_bytes,kv_hits,kv_misses,kv_load_bytes,kv_store_bytes,digital_load_bytes,digital_store_bytes,digital_pe_active,digital_spu_active,digital_vpu_active,throttle_until,throttle_events,deferrals,avg_wait,digital_util,rram_util,digital_ticks,rram_ticks,interconnect_ticks,host_tasks,outstanding_digital,outstanding_rram,outstanding_transfer,outstanding_dma,transfer_to_rram_bytes,transfer_to_digital_bytes,transfer_host_load_bytes,transfer_host_store_bytes,transfer_throttle_events_total,transfer_throttle_cycles_total,stager_depth,transfer_issued_bytes_total,transfer_completed_bytes_total,transfer_inflight_bytes"}
 	this.resultLog = []string{"cycle,chiplet_id,raw_om,final,reference,scale,zero_point,moe_events_total,moe_avg_latency,moe_latency_max,moe_snapshot_hit_rate,moe_fallback_rate"}
+	this.rngStreams = new(misc.RNGStreams)
+	this.rngStreams.Init(config.RngSeed)
+	if config.ResultSampleSeed != 0 {
+		this.resultSampler = rand.New(rand.NewSource(config.ResultSampleSeed))
+	} else {
+		this.resultSampler = this.rngStreams.Fork(misc.RNGStreamResultSample, config.ResultSampleStreamKey)
+	}
+	this.resultSeen = make([]bool, len(rramChiplets))
+	this.resultTail = make([]string, len(rramChiplets))
+	this.rooflineStats = make(map[string]*rooflineEntry)
+	this.transferSizeHist = make(map[transferSizeKey]int64)
 	this.transferAdaptiveCycles = 0
 	this.tokenizer = tokenizer.NewStaticTokenizer(nil)
+	this.dispatchedTasks = 0
+	this.lastDispatchedTasks = 0
+	this.stallCycles = 0
+	this.aborted = false
+	this.abortReason = ""
+	this.runArgs = command_line_parser.StringifyArgs()
+	this.runOptions = command_line_parser.StringifyOptions()
 
 	progressInterval := int(command_line_parser.IntParameter("chiplet_progress_interval"))
 	if progressInterval < 0 {
@@ -375,6 +628,21 @@ func (this *ChipletPlatform) Init(command_line_parser *misc.CommandLineParser) {
 		fmt.Println("[chiplet] 统计快照将仅在仿真结束时写入。")
 	}
 
+	snapshotInterval := config.SnapshotInterval
+	if snapshotInterval < 0 {
+		snapshotInterval = 0
+	}
+	this.snapshotInterval = snapshotInterval
+	this.timeseriesLog = nil
+	this.timeseriesRows = 0
+	this.timeseriesFile = nil
+	this.lastSnapshotCycle = -1
+	if snapshotInterval > 0 {
+		this.nextSnapshotCycle = snapshotInterval
+		this.timeseriesLog = []string{timeseriesHeader}
+		fmt.Printf("[chiplet] 时间序列快照将每 %d 个周期追加一行。\n", snapshotInterval)
+	}
+
 	if this.scheduler != nil {
 		this.scheduler.Init(config, topology, this)
 	}
@@ -397,6 +665,11 @@ func (this *ChipletPlatform) Fini() {
 		_ = this.booksimClient.Close()
 		this.booksimClient = nil
 	}
+
+	if this.timeseriesFile != nil {
+		_ = this.timeseriesFile.Close()
+		this.timeseriesFile = nil
+	}
 }
 
 // SetTokenizer allows the host runtime to replace the default tokenizer.
@@ -408,7 +681,7 @@ func (this *ChipletPlatform) SetTokenizer(tok tokenizer.Tokenizer) {
 }
 
 func (this *ChipletPlatform) IsFinished() bool {
-	if this.scheduler == nil {
+	if this.scheduler == nil || this.aborted || this.cycleCapReached {
 		return true
 	}
 
@@ -424,6 +697,10 @@ func (this *ChipletPlatform) IsFinished() bool {
 		return false
 	}
 
+	if len(this.retirementQueue) > 0 || len(this.inflightTransfers) > 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -431,6 +708,7 @@ func (this *ChipletPlatform) Cycle() {
 	if this.scheduler == nil {
 		return
 	}
+	defer this.profiler.addTotal(this.profiler.start())
 
 	digitalTicks := this.advanceDomainTicks(this.digitalClockMhz, &this.digitalPhase)
 	rramTicks := this.advanceDomainTicks(this.rramClockMhz, &this.rramPhase)
@@ -465,17 +743,25 @@ func (this *ChipletPlatform) Cycle() {
 		this.statFactory.Increment("cycles", 1)
 	}
 
-	for i := 0; i < digitalTicks; i++ {
-		cycleDeferrals += this.runDigitalTick()
-	}
-
-	for i := 0; i < rramTicks; i++ {
-		this.runRramTick()
-	}
-
+	// 周期内各时钟域按 --chiplet_cycle_order 依次推进（默认 digital→rram→transfer）。
+	// 先推进的域在本周期内看到的是其余域上一周期末的缓冲/完成状态，
+	// 后推进的域则能看到本周期内已释放的缓冲；顺序固定，因此每种顺序下结果确定。
 	throttleActive := this.transferThrottleUntil > 0
-	for i := 0; i < interconnectTicks; i++ {
-		this.runInterconnectTick()
+	for _, domain := range this.cycleOrder() {
+		switch domain {
+		case "digital":
+			for i := 0; i < digitalTicks; i++ {
+				cycleDeferrals += this.runDigitalTick()
+			}
+		case "rram":
+			for i := 0; i < rramTicks; i++ {
+				this.runRramTick()
+			}
+		case "transfer":
+			for i := 0; i < interconnectTicks; i++ {
+				this.runInterconnectTick()
+			}
+		}
 	}
 
 	if throttleActive || this.transferThrottleUntil > 0 {
@@ -484,14 +770,28 @@ func (this *ChipletPlatform) Cycle() {
 			this.statFactory.Increment("transfer_throttle_cycles_total", 1)
 		}
 	}
+	this.recordIdleReasons(throttleActive || this.transferThrottleUntil > 0)
 
 	if digitalTicks > 0 {
 		for _, chip := range this.digitalChiplets {
-			if chip != nil {
-				chip.AccumulateStaticEnergy(digitalTicks)
+			if chip == nil {
+				continue
+			}
+			ticks := digitalTicks
+			if this.dvfs != nil {
+				ticks = this.dvfs.consumeTicked(this.dvfs.digitalState(chip.ID))
+			}
+			if this.powerdown.asleep(this.powerdown.digitalState(chip.ID)) {
+				chip.StaticEnergyPJ += this.powerdown.retain(ticks, chip.StaticEnergyPerCyclePJ())
+				continue
 			}
+			chip.AccumulateStaticEnergy(ticks)
 		}
 	}
+	this.observePowerdown()
+	this.completeInflightTransfers()
+	this.drainRetirements()
+	this.dvfs.sampleFrequencies(this.currentCycle, this.digitalClockMhz, this.rramClockMhz)
 
 	if this.cycleDigitalExec > this.maxDigitalThroughput {
 		this.maxDigitalThroughput = this.cycleDigitalExec
@@ -523,221 +823,1253 @@ func (this *ChipletPlatform) Cycle() {
 		}
 	}
 
+	this.accumulateParallelism()
 	this.logCycleMetrics(cycleDeferrals)
 	this.emitProgress(cycleDeferrals)
 	this.maybeFlushStats()
+	this.maybeSnapshot()
+	this.checkDeadlock()
+	this.remindUnlimitedStream()
+	this.checkCycleCap()
 }
 
-func (this *ChipletPlatform) runDigitalTick() int {
-	deferrals := 0
+// unlimitedStreamReminderCycles 是无限流式运行时打印提醒的间隔（平台周期）。
+const unlimitedStreamReminderCycles = 1000000
+
+func (this *ChipletPlatform) unlimitedStreaming() bool {
+	return this.config != nil && this.config.HostStreamTotalBatches <= 0
+}
 
+// remindUnlimitedStream 在无限流式运行中周期性提醒，避免无意中启动的无界运行
+// 悄悄消耗数小时。
+func (this *ChipletPlatform) remindUnlimitedStream() {
+	if !this.unlimitedStreaming() || this.currentCycle%unlimitedStreamReminderCycles != 0 {
+		return
+	}
+	batches := 0
 	if this.orchestrator != nil {
-		if tasks := this.orchestrator.Advance(); tasks != nil {
-			for _, task := range tasks {
-				this.SubmitTask(task)
-			}
-		}
+		batches = len(this.orchestrator.BatchLatencies())
 	}
+	limit := "无上限"
+	if this.config.MaxCycles > 0 {
+		limit = fmt.Sprintf("%d", this.config.MaxCycles)
+	}
+	fmt.Printf("[chiplet] 提醒：无限流式运行中（--chiplet_host_stream_total_batches<=0），周期=%d 已完成批次=%d 周期上限=%s。\n",
+		this.currentCycle, batches, limit)
+}
 
-	if this.stager != nil {
-		deferred := make([]*chiplet.Task, 0)
-
-		for this.stager.HasPending() {
-			task, ok := this.stager.Pop()
-			if !ok || task == nil {
-				continue
-			}
-
-			if this.transferThrottleUntil > 0 && task.Target == chiplet.TaskTargetTransfer {
-				deferred = append(deferred, task)
-				if this.statFactory != nil {
-					this.statFactory.Increment("transfer_throttle_deferred", 1)
-				}
-				continue
-			}
+// checkCycleCap 在达到 --chiplet_max_cycles 时停止仿真；这是预期的停止而非错误。
+func (this *ChipletPlatform) checkCycleCap() {
+	if this.cycleCapReached || this.config == nil || this.config.MaxCycles <= 0 {
+		return
+	}
+	if this.currentCycle < this.config.MaxCycles || this.IsFinished() {
+		return
+	}
+	this.cycleCapReached = true
+	fmt.Printf("[chiplet] 已达到 --chiplet_max_cycles=%d，停止仿真。\n", this.config.MaxCycles)
+}
 
-			if this.isTargetBusy(task) {
-				deferred = append(deferred, task)
-				if this.statFactory != nil {
-					this.statFactory.Increment("task_deferrals", 1)
-				}
-				deferrals++
-				this.recordDeferral(task)
-				continue
-			}
+// defaultCycleOrder is the historical intra-cycle order: digital, then RRAM,
+// then the interconnect draining transfers.
+var defaultCycleOrder = []string{"digital", "rram", "transfer"}
 
-			this.scheduler.EnqueueTask(task)
-		}
+func (this *ChipletPlatform) cycleOrder() []string {
+	if this.config == nil || len(this.config.CycleOrder) != len(defaultCycleOrder) {
+		return defaultCycleOrder
+	}
+	return this.config.CycleOrder
+}
 
-		for _, task := range deferred {
-			this.stager.Enqueue(task)
+// checkDeadlock aborts the run once work is still pending but no task has been
+// dispatched and no chiplet has been busy for DeadlockCycles consecutive cycles,
+// or when the run drains while graph nodes are still blocked on dependencies.
+func (this *ChipletPlatform) checkDeadlock() {
+	if this.aborted || this.config == nil || this.config.DeadlockCycles <= 0 {
+		return
+	}
+	if this.IsFinished() {
+		if blocked := this.orchestrator.BlockedNodes(); blocked > 0 {
+			this.reportError(fmt.Sprintf("deadlock: %d nodes blocked on unresolved dependencies", blocked))
 		}
+		return
 	}
-
-	this.scheduler.Tick()
-
-	for _, chiplet := range this.digitalChiplets {
-		chiplet.Tick()
-		this.cycleDigitalLoadBytes += chiplet.CycleLoadBytes
-		this.cycleDigitalStoreBytes += chiplet.CycleStoreBytes
-		this.cycleDigitalPeActive += chiplet.CyclePeActive
-		this.cycleDigitalSpuActive += chiplet.CycleSpuActive
-		this.cycleDigitalVpuActive += chiplet.CycleVpuActive
-		this.cycleDigitalCompleted += chiplet.CycleTasksCompleted
-		this.totalDigitalLoadBytesRuntime += chiplet.CycleLoadBytes
-		this.totalDigitalStoreBytesRuntime += chiplet.CycleStoreBytes
-		this.totalDigitalCompleted += chiplet.CycleTasksCompleted
+	if this.dispatchedTasks != this.lastDispatchedTasks || this.anyChipletBusy() || len(this.retirementQueue) > 0 || len(this.inflightTransfers) > 0 {
+		this.lastDispatchedTasks = this.dispatchedTasks
+		this.stallCycles = 0
+		return
+	}
+	this.stallCycles++
+	if this.stallCycles >= this.config.DeadlockCycles {
+		this.reportError(fmt.Sprintf("deadlock: no task dispatched for %d cycles with work pending", this.stallCycles))
 	}
+}
 
-	return deferrals
+func (this *ChipletPlatform) anyChipletBusy() bool {
+	return this.activeChipletCount() > 0
 }
 
-func (this *ChipletPlatform) runRramTick() {
-	for _, chiplet := range this.rramChiplets {
-		chiplet.Tick()
-		if summary, ok := chiplet.ConsumeLastResult(); ok {
-			this.recordRramResult(chiplet.ID, summary)
+// activeChipletCount returns how many digital and RRAM chiplets are busy.
+func (this *ChipletPlatform) activeChipletCount() int {
+	active := 0
+	for _, chip := range this.digitalChiplets {
+		if chip != nil && chip.Busy() {
+			active++
+		}
+	}
+	for _, chip := range this.rramChiplets {
+		if chip != nil && chip.Busy() {
+			active++
 		}
 	}
+	return active
 }
 
-func (this *ChipletPlatform) runInterconnectTick() {
-	if this.transferThrottleUntil > 0 {
-		this.transferThrottleUntil--
+// accumulateParallelism samples the busy-chiplet count once per platform cycle.
+func (this *ChipletPlatform) accumulateParallelism() {
+	active := this.activeChipletCount()
+	this.activeChipletCycles += int64(active)
+	if active > 0 {
+		this.busyPlatformCycles++
+	}
+	if active == 1 {
+		this.serialPlatformCycles++
 	}
 }
 
-func (this *ChipletPlatform) advanceDomainTicks(freq int, phase *int) int {
-	if freq <= 0 {
-		return 0
-	}
-	if this.clockBaseMhz <= 0 {
-		return 1
+// dvfsLines 输出单个 chiplet 在各 DVFS 档位上停留的名义域周期数、被跳过的 tick 数
+// 及按停留时间加权的平均有效频率。
+func (this *ChipletPlatform) dvfsLines(prefix string, id int, state *dvfsState, nominalMhz int) []string {
+	if this.dvfs == nil || state == nil {
+		return nil
 	}
-	*phase += freq
-	ticks := 0
-	for *phase >= this.clockBaseMhz {
-		*phase -= this.clockBaseMhz
-		ticks++
+	lines := make([]string, 0, len(this.dvfs.levels)+1)
+	for idx, level := range this.dvfs.levels {
+		lines = append(lines, fmt.Sprintf("%s[%d]_dvfs_cycles_at_%dpct: %d", prefix, id, level, state.ticksAtLevel[idx]))
 	}
-	return ticks
+	lines = append(lines, fmt.Sprintf("%s[%d]_dvfs_skipped_ticks: %d", prefix, id, state.skippedTicks))
+	lines = append(lines, fmt.Sprintf("%s[%d]_dvfs_avg_freq_mhz: %.2f", prefix, id, this.dvfs.averageMhz(state, nominalMhz)))
+	return lines
 }
 
-func (this *ChipletPlatform) emitProgress(cycleDeferrals int) {
-	if this.progressInterval <= 0 {
+func (this *ChipletPlatform) writeDvfsFrequencyFile() {
+	if this.binDirpath == "" {
 		return
 	}
-
-	if this.currentCycle < this.nextProgressCycle {
+	lines := this.dvfs.frequencyLines(this.currentCycle, this.digitalClockMhz, this.rramClockMhz)
+	if lines == nil {
 		return
 	}
+	frequencyLogger := new(misc.FileDumper)
+	frequencyLogger.Init(filepath.Join(this.binDirpath, "chiplet_dvfs_frequency.csv"))
+	frequencyLogger.WriteLines(lines)
+}
 
-	this.nextProgressCycle += this.progressInterval
-
-	digitalPending := 0
-	for _, chiplet := range this.digitalChiplets {
-		if chiplet != nil {
-			digitalPending += chiplet.PendingTasks
-		}
+// parallelismLines 给出 Amdahl 风格的并行度指标：平均活跃 chiplet 数、
+// 其占 chiplet 总数的比例、仅一个 chiplet 活跃的周期占比，以及去掉这部分
+// 串行周期（按忙碌周期计）后的理论加速比上界。
+func (this *ChipletPlatform) parallelismLines() []string {
+	avgActive := 0.0
+	serialFraction := 0.0
+	if this.currentCycle > 0 {
+		avgActive = float64(this.activeChipletCycles) / float64(this.currentCycle)
+		serialFraction = float64(this.serialPlatformCycles) / float64(this.currentCycle)
 	}
-
-	rramPending := 0
-	for _, chiplet := range this.rramChiplets {
-		if chiplet != nil {
-			rramPending += chiplet.PendingTasks
-		}
+	effective := 0.0
+	if total := len(this.digitalChiplets) + len(this.rramChiplets); total > 0 {
+		effective = avgActive / float64(total)
+	}
+	speedup := 0.0
+	if parallel := this.busyPlatformCycles - this.serialPlatformCycles; parallel > 0 {
+		speedup = float64(this.busyPlatformCycles) / float64(parallel)
+	}
+	return []string{
+		fmt.Sprintf("ChipletPlatform_avg_active_chiplets: %.4f", avgActive),
+		fmt.Sprintf("ChipletPlatform_effective_parallelism: %.4f", effective),
+		fmt.Sprintf("ChipletPlatform_serial_cycle_fraction: %.4f", serialFraction),
+		fmt.Sprintf("ChipletPlatform_serial_removed_speedup: %.4f", speedup),
 	}
+}
 
-	stagerState := "空"
-	if this.stager != nil && this.stager.HasPending() {
-		stagerState = "等待"
+// reportError records the first fatal condition of the run, stops the
+// simulation loop via IsFinished, and writes a repro snapshot when
+// DumpOnError is enabled.
+func (this *ChipletPlatform) reportError(reason string) {
+	if this.aborted {
+		return
+	}
+	this.aborted = true
+	this.abortReason = reason
+	fmt.Printf("[chiplet] 错误：周期=%d %s，终止仿真。\n", this.currentCycle, reason)
+	if this.statFactory != nil {
+		this.statFactory.Increment("aborts", 1)
+	}
+	if this.config != nil && this.config.DumpOnError {
+		this.writeReproDir()
 	}
+}
 
-	orchestratorState := "空闲"
-	if this.orchestrator != nil && this.orchestrator.HasPendingWork() {
-		orchestratorState = "活跃"
+// reportUndeliverable aborts the run on a task no chiplet can execute (unknown
+// target or out-of-range chiplet id) when DumpOnError is enabled, so the
+// failure leaves a repro snapshot. Otherwise the task is dropped as before.
+// It reports whether the run was aborted.
+func (this *ChipletPlatform) reportUndeliverable(task *chiplet.Task, detail string) bool {
+	if this.config == nil || !this.config.DumpOnError {
+		return false
 	}
+	this.reportError(fmt.Sprintf("undeliverable task node=%d target=%s%s", task.NodeID, task.Target.String(), detail))
+	return true
+}
 
-	fmt.Printf(
-		"[chiplet] 周期=%d | 数字任务 累计=%d 待处理=%d | RRAM任务 累计=%d 待处理=%d | 传输累计=%d | 本周期调度 数字=%d RRAM=%d 传输=%d | 本周期传输字节=%d | 回压=%d | Stager=%s | Orchestrator=%s\n",
-		this.currentCycle,
-		this.executedDigitalTasks,
-		digitalPending,
-		this.executedRramTasks,
-		rramPending,
-		this.executedTransferTasks,
-		this.cycleDigitalExec,
-		this.cycleRramExec,
-		this.cycleTransferExec,
-		this.cycleTransferBytes,
-		cycleDeferrals,
-		stagerState,
-		orchestratorState,
-	)
+// restoreReproConfig replaces the command-line config with the chiplet_config.json
+// saved next to the repro commands, so a replay runs under the settings of the
+// failed run. Only ReproPath is kept from the current command line.
+func restoreReproConfig(config *chiplet.Config) *chiplet.Config {
+	path := filepath.Join(config.ReproPath, "chiplet_config.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("[chiplet] warning: failed to read %s: %v; replaying with the current config\n", path, err)
+		return config
+	}
+	restored := new(chiplet.Config)
+	if err := json.Unmarshal(data, restored); err != nil {
+		fmt.Printf("[chiplet] warning: failed to decode %s: %v; replaying with the current config\n", path, err)
+		return config
+	}
+	restored.ReproPath = config.ReproPath
+	return restored
 }
 
-func (this *ChipletPlatform) maybeFlushStats() {
-	if this.statsFlushInterval <= 0 {
+// writeReproDir dumps the remaining command graph, the resolved config and the
+// cycle reached into <bin_dirpath>/chiplet_repro. Passing that directory back
+// via --chiplet_repro_path replays the remaining commands.
+func (this *ChipletPlatform) writeReproDir() {
+	if this.binDirpath == "" {
 		return
 	}
-	if this.currentCycle < this.nextStatsFlushCycle {
+	dir := filepath.Join(this.binDirpath, "chiplet_repro")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Printf("[chiplet] warning: failed to create %s: %v\n", dir, err)
 		return
 	}
-	this.nextStatsFlushCycle += this.statsFlushInterval
-	this.writeStatsFiles(false)
-}
 
-func (this *ChipletPlatform) recordRramResult(chipletID int, summary rram.ResultSummary) {
-	if !summary.Valid {
+	var commands []chiplet.CommandDescriptor
+	skipped := 0
+	if this.orchestrator != nil {
+		commands, skipped = this.orchestrator.SnapshotRemaining()
+	}
+	if commands == nil {
+		commands = []chiplet.CommandDescriptor{}
+	}
+	commandData, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		fmt.Printf("[chiplet] warning: failed to encode repro commands: %v\n", err)
 		return
 	}
-	line := fmt.Sprintf("%d,%d,%d,%.6f,%.6f,%.6f,%d,0,0,0,0,0",
-		this.currentCycle,
-		chipletID,
-		summary.RawOM,
-		summary.Final,
-		summary.Reference,
-		summary.Scale,
-		summary.ZeroPt,
-	)
-	this.resultLog = append(this.resultLog, line)
-	if this.statFactory != nil {
-		this.statFactory.Increment("rram_results_recorded", 1)
+	configData, err := json.MarshalIndent(this.config, "", "  ")
+	if err != nil {
+		fmt.Printf("[chiplet] warning: failed to encode repro config: %v\n", err)
+		return
 	}
-}
 
-func (this *ChipletPlatform) Dump() {
-	this.writeStatsFiles(true)
+	commandDumper := new(misc.FileDumper)
+	commandDumper.Init(filepath.Join(dir, "chiplet_commands.json"))
+	commandDumper.WriteLines([]string{string(commandData)})
+
+	configDumper := new(misc.FileDumper)
+	configDumper.Init(filepath.Join(dir, "chiplet_config.json"))
+	configDumper.WriteLines([]string{string(configData)})
+
+	optionsDumper := new(misc.FileDumper)
+	optionsDumper.Init(filepath.Join(dir, "options.txt"))
+	optionsDumper.WriteLines([]string{this.runOptions})
+
+	reproDumper := new(misc.FileDumper)
+	reproDumper.Init(filepath.Join(dir, "repro.txt"))
+	reproDumper.WriteLines([]string{
+		fmt.Sprintf("reason: %s", this.abortReason),
+		fmt.Sprintf("cycle: %d", this.currentCycle),
+		fmt.Sprintf("remaining_commands: %d", len(commands)),
+		fmt.Sprintf("skipped_nodes: %d", skipped),
+		fmt.Sprintf("cycle_order: %s", strings.Join(this.cycleOrder(), ",")),
+		fmt.Sprintf("args: %s", this.runArgs),
+		fmt.Sprintf("replay: rerun with the args above plus --chiplet_repro_path %s", dir),
+	})
+	fmt.Printf("[chiplet] 复现快照已写入 %s（剩余命令=%d）。\n", dir, len(commands))
 }
 
-func (this *ChipletPlatform) writeStatsFiles(final bool) {
-	if this.binDirpath == "" {
-		return
+func (this *ChipletPlatform) runDigitalTick() int {
+	deferrals := 0
+
+	schedulerStart := this.profiler.start()
+	if this.orchestrator != nil {
+		if tasks := this.orchestrator.Advance(); tasks != nil {
+			for _, task := range tasks {
+				this.SubmitTask(task)
+			}
+		}
 	}
 
-	file_dumper := new(misc.FileDumper)
-	file_dumper.Init(filepath.Join(this.binDirpath, "chiplet_log.txt"))
+	deferrals = this.drainStager()
 
-	lines := make([]string, 0)
+	this.scheduler.Tick()
+	this.profiler.addScheduler(schedulerStart)
 
-	if this.statFactory != nil {
-		this.statFactory.Increment("digital_tasks_total", 0)
-		this.statFactory.Increment("rram_tasks_total", 0)
-		lines = append(lines, this.statFactory.ToLines()...)
+	defer this.profiler.addDevice(this.profiler.start())
+	for _, chiplet := range this.digitalChiplets {
+		if this.powerdown.asleep(this.powerdown.digitalState(chiplet.ID)) {
+			continue
+		}
+		if this.dvfs != nil && !this.dvfs.step(this.dvfs.digitalState(chiplet.ID), chiplet.Busy()) {
+			this.dvfs.energySavedPJ += chiplet.StaticEnergyPerCyclePJ()
+			continue
+		}
+		dynamicBefore := chiplet.DynamicEnergyPJ
+		chiplet.Tick()
+		this.dvfs.scaleDynamicEnergy(this.dvfs.digitalState(chiplet.ID), &chiplet.DynamicEnergyPJ, dynamicBefore)
+		this.cycleDigitalLoadBytes += chiplet.CycleLoadBytes
+		this.cycleDigitalStoreBytes += chiplet.CycleStoreBytes
+		this.cycleDigitalPeActive += chiplet.CyclePeActive
+		this.cycleDigitalSpuActive += chiplet.CycleSpuActive
+		this.cycleDigitalVpuActive += chiplet.CycleVpuActive
+		this.cycleDigitalCompleted += chiplet.CycleTasksCompleted
+		this.totalDigitalLoadBytesRuntime += chiplet.CycleLoadBytes
+		this.totalDigitalStoreBytesRuntime += chiplet.CycleStoreBytes
+		this.totalDigitalCompleted += chiplet.CycleTasksCompleted
 	}
 
-	lines = append(lines,
-		fmt.Sprintf("ChipletPlatform_digital_tasks_total: %d", this.executedDigitalTasks),
-		fmt.Sprintf("ChipletPlatform_rram_tasks_total: %d", this.executedRramTasks),
-		fmt.Sprintf("ChipletPlatform_transfer_tasks_total: %d", this.executedTransferTasks),
-		fmt.Sprintf("ChipletPlatform_transfer_bytes_total: %d", this.totalTransferBytes),
-		fmt.Sprintf("ChipletPlatform_transfer_hops_total: %d", this.totalTransferHops),
-		fmt.Sprintf("ChipletPlatform_transfer_to_rram_bytes_total: %d", this.totalTransferToRramBytes),
-		fmt.Sprintf("ChipletPlatform_transfer_to_digital_bytes_total: %d", this.totalTransferToDigitalBytes),
-		fmt.Sprintf("ChipletPlatform_transfer_host_load_bytes_total: %d", this.totalTransferHostLoadBytes),
+	return deferrals
+}
+
+// drainStager hands every staged task whose target can accept it to the
+// scheduler and re-queues the rest, returning the number of busy-target
+// deferrals. With --chiplet_transfer_priority, a staged critical transfer
+// preempts in-flight bulk transfers, which give up the link and re-queue here
+// with their remaining bytes.
+func (this *ChipletPlatform) drainStager() int {
+	if this.stager == nil {
+		return 0
+	}
+
+	deferrals := 0
+	staged := make([]*chiplet.Task, 0, this.stager.Len())
+	criticalPending := false
+	for this.stager.HasPending() {
+		task, ok := this.stager.Pop()
+		if !ok || task == nil {
+			continue
+		}
+		if task.Target == chiplet.TaskTargetTransfer && this.transferPriority(task) == transferPriorityCritical {
+			criticalPending = true
+		}
+		staged = append(staged, task)
+	}
+
+	deferred := make([]*chiplet.Task, 0)
+	if criticalPending && this.config != nil && this.config.TransferPriority != 0 {
+		deferred = append(deferred, this.preemptBulkTransfers()...)
+	}
+	for _, task := range staged {
+		if task.Target == chiplet.TaskTargetTransfer && this.transferYields(task, criticalPending) {
+			deferred = append(deferred, task)
+			if this.statFactory != nil {
+				this.statFactory.Increment("transfer_throttle_deferred", 1)
+			}
+			continue
+		}
+
+		if inflight, ok := this.preemptedTransfers[task]; ok {
+			delete(this.preemptedTransfers, task)
+			this.resumeTransfer(inflight)
+			continue
+		}
+
+		if this.cmdFetchPending(task) {
+			deferred = append(deferred, task)
+			continue
+		}
+
+		if this.isTargetBusy(task) {
+			deferred = append(deferred, task)
+			if this.statFactory != nil {
+				this.statFactory.Increment("task_deferrals", 1)
+			}
+			deferrals++
+			this.recordDeferral(task)
+			continue
+		}
+
+		this.recordCmdFetch(task)
+		this.scheduler.EnqueueTask(task)
+	}
+
+	for _, task := range deferred {
+		this.stager.Enqueue(task)
+	}
+	return deferrals
+}
+
+// Transfer priority classes carried in the "priority" metadata of a transfer.
+const (
+	transferPriorityCritical = "critical"
+	transferPriorityNormal   = "normal"
+	transferPriorityBulk     = "bulk"
+)
+
+var transferPriorityClasses = []string{transferPriorityCritical, transferPriorityNormal, transferPriorityBulk}
+
+// transferPriority returns the priority class of a transfer task; anything
+// other than critical or bulk is treated as normal.
+func (this *ChipletPlatform) transferPriority(task *chiplet.Task) string {
+	cmd, ok := task.Payload.(*chiplet.CommandDescriptor)
+	if !ok || cmd == nil {
+		return transferPriorityNormal
+	}
+	switch strings.ToLower(metadataString(cmd.Metadata, "priority", transferPriorityNormal)) {
+	case transferPriorityCritical:
+		return transferPriorityCritical
+	case transferPriorityBulk:
+		return transferPriorityBulk
+	}
+	return transferPriorityNormal
+}
+
+// transferYields reports whether a transfer must wait this cycle. Without
+// priorities every transfer waits out the throttle in FIFO order. With them a
+// critical transfer issues immediately, queueing behind whatever occupancy is
+// left once bulk transfers have been preempted, and bulk transfers give way to
+// any critical transfer still queued.
+func (this *ChipletPlatform) transferYields(task *chiplet.Task, criticalPending bool) bool {
+	if this.config == nil || this.config.TransferPriority == 0 {
+		return this.transferThrottleUntil > 0
+	}
+	switch this.transferPriority(task) {
+	case transferPriorityCritical:
+		return false
+	case transferPriorityBulk:
+		return this.transferThrottleUntil > 0 || criticalPending
+	}
+	return this.transferThrottleUntil > 0
+}
+
+// inflightTransfer 是已占用互连、尚未传完的传输。bytes/cycles 是仍需在链路上
+// 传输的部分，被抢占后只剩未传完的字节；finishCycle 是其最后一个字节离开链路的周期。
+type inflightTransfer struct {
+	task        *chiplet.Task
+	class       string
+	bytes       int64
+	cycles      int
+	finishCycle int
+}
+
+// issueTransfer 在传输占用链路 cycles 个周期后登记其完成时刻。链路按发出顺序
+// 串行排空，因此完成时刻是当前周期加上链路上累计的占用。未启用优先级或未占用
+// 链路时直接按该时刻记录延迟并返回 false，由调用方照常退休；否则传输保持
+// in-flight 直到传完才退休，返回 true。
+func (this *ChipletPlatform) issueTransfer(task *chiplet.Task, bytes int64, cycles int) bool {
+	finish := this.currentCycle + this.transferThrottleUntil
+	if cycles <= 0 || this.config == nil || this.config.TransferPriority == 0 {
+		this.recordTransferPriorityLatency(task, finish)
+		return false
+	}
+	this.inflightTransfers = append(this.inflightTransfers, &inflightTransfer{
+		task:        task,
+		class:       this.transferPriority(task),
+		bytes:       bytes,
+		cycles:      cycles,
+		finishCycle: finish,
+	})
+	return true
+}
+
+// preemptBulkTransfers 让所有尚未传完的 bulk 传输让出链路：其剩余占用从链路上
+// 扣除，排在其后的传输相应提前完成；剩余字节按未传完的周期比例折算。返回需要
+// 重新排队的任务。
+func (this *ChipletPlatform) preemptBulkTransfers() []*chiplet.Task {
+	requeued := make([]*chiplet.Task, 0)
+	kept := this.inflightTransfers[:0]
+	shift := 0
+	for _, inflight := range this.inflightTransfers {
+		inflight.finishCycle -= shift
+		remaining := inflight.finishCycle - this.currentCycle
+		if inflight.class != transferPriorityBulk || remaining <= 0 {
+			kept = append(kept, inflight)
+			continue
+		}
+		if remaining > inflight.cycles {
+			// 尚未开始传输：整段占用都让出。
+			remaining = inflight.cycles
+		}
+		inflight.bytes = (inflight.bytes*int64(remaining) + int64(inflight.cycles) - 1) / int64(inflight.cycles)
+		inflight.cycles = remaining
+		shift += remaining
+		this.transferThrottleUntil -= remaining
+		if this.transferThrottleUntil < 0 {
+			this.transferThrottleUntil = 0
+		}
+
+		if this.preemptedTransfers == nil {
+			this.preemptedTransfers = make(map[*chiplet.Task]*inflightTransfer)
+		}
+		this.preemptedTransfers[inflight.task] = inflight
+		requeued = append(requeued, inflight.task)
+		this.transferPreemptions++
+		if this.statFactory != nil {
+			this.statFactory.Increment("transfer_preemptions", 1)
+		}
+	}
+	for idx := len(kept); idx < len(this.inflightTransfers); idx++ {
+		this.inflightTransfers[idx] = nil
+	}
+	this.inflightTransfers = kept
+	return requeued
+}
+
+// resumeTransfer 让被抢占的传输重新占用链路，只传输其剩余字节。字节数与能耗
+// 在首次发出时已经计入，这里只补回链路占用。
+func (this *ChipletPlatform) resumeTransfer(inflight *inflightTransfer) {
+	this.occupyInterconnect(inflight.cycles)
+	inflight.finishCycle = this.currentCycle + this.transferThrottleUntil
+	this.inflightTransfers = append(this.inflightTransfers, inflight)
+}
+
+// completeInflightTransfers 在周期末退休所有已传完的 in-flight 传输，并按优先级
+// 记录其从进入 stager 到传输完成的延迟。
+func (this *ChipletPlatform) completeInflightTransfers() {
+	if len(this.inflightTransfers) == 0 {
+		return
+	}
+	remaining := this.inflightTransfers[:0]
+	for _, inflight := range this.inflightTransfers {
+		if inflight.finishCycle > this.currentCycle {
+			remaining = append(remaining, inflight)
+			continue
+		}
+		this.recordTransferPriorityLatency(inflight.task, this.currentCycle)
+		this.retireTask(inflight.task.NodeID)
+	}
+	for idx := len(remaining); idx < len(this.inflightTransfers); idx++ {
+		this.inflightTransfers[idx] = nil
+	}
+	this.inflightTransfers = remaining
+}
+
+// recordTransferPriorityLatency 记录传输从进入 stager 到 completeCycle 传输完成的
+// 周期数，按优先级分类。
+func (this *ChipletPlatform) recordTransferPriorityLatency(task *chiplet.Task, completeCycle int) {
+	if this.transferPriorityLatency == nil {
+		this.transferPriorityLatency = make(map[string]*latencyCounter)
+	}
+	class := this.transferPriority(task)
+	counter, ok := this.transferPriorityLatency[class]
+	if !ok {
+		counter = &latencyCounter{}
+		this.transferPriorityLatency[class] = counter
+	}
+	latency := completeCycle - task.EnqueueCycle
+	if latency < 0 {
+		latency = 0
+	}
+	counter.samples++
+	counter.total += int64(latency)
+}
+
+func (this *ChipletPlatform) transferPriorityLines() []string {
+	lines := []string{fmt.Sprintf("ChipletPlatform_transfer_preemptions: %d", this.transferPreemptions)}
+	for _, class := range transferPriorityClasses {
+		counter := this.transferPriorityLatency[class]
+		if counter == nil {
+			continue
+		}
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_transfer_priority[%s]_transfers: %d", class, counter.samples),
+			fmt.Sprintf("ChipletPlatform_transfer_priority[%s]_avg_latency: %.4f", class, counter.average()),
+		)
+	}
+	return lines
+}
+
+// workingSetTileDim 是启动检查所用代表性 GEMM tile 的边长，元素按 2 字节计，与
+// orchestrator 生成命令时的默认尺寸一致。
+const workingSetTileDim = 128
+
+// checkBufferWorkingSets compares one representative tile's working set with
+// the configured buffers and returns a warning, with the recommended minimum
+// option value, for every buffer that could never hold it.
+func (this *ChipletPlatform) checkBufferWorkingSets() []string {
+	tileBytes := int64(workingSetTileDim * workingSetTileDim * 2)
+	tile := fmt.Sprintf("%dx%d tile", workingSetTileDim, workingSetTileDim)
+	warnings := make([]string, 0)
+
+	if len(this.digitalChiplets) > 0 && this.digitalChiplets[0] != nil {
+		desc := &digital.TaskDescriptor{InputBytes: tileBytes, WeightBytes: tileBytes, OutputBytes: tileBytes}
+		for _, shortfall := range this.digitalChiplets[0].WorkingSetShortfalls(desc) {
+			contents, option := "activations", "chiplet_digital_activation_buffer"
+			switch shortfall.Buffer {
+			case "weights":
+				contents = "weights"
+			case "scratch":
+				contents, option = "outputs", "chiplet_digital_scratch_buffer"
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"digital %s buffer (%d bytes per cluster) cannot hold a single %s's %s (%d bytes); recommend --%s >= %d",
+				shortfall.Buffer, shortfall.CapacityBytes, tile, contents, shortfall.RequiredBytes, option, shortfall.RecommendedBytes))
+		}
+	}
+
+	if len(this.rramChiplets) > 0 && this.rramChiplets[0] != nil {
+		chip := this.rramChiplets[0]
+		if chip.InputBufferCapacity > 0 && chip.InputBufferCapacity < tileBytes {
+			warnings = append(warnings, fmt.Sprintf(
+				"rram input buffer (%d bytes) cannot hold a single %s's activations (%d bytes); recommend --chiplet_rram_input_buffer >= %d",
+				chip.InputBufferCapacity, tile, tileBytes, tileBytes))
+		}
+		if chip.OutputBufferCapacity > 0 && chip.OutputBufferCapacity < tileBytes {
+			warnings = append(warnings, fmt.Sprintf(
+				"rram output buffer (%d bytes) cannot hold a single %s's outputs (%d bytes); recommend --chiplet_rram_output_buffer >= %d",
+				chip.OutputBufferCapacity, tile, tileBytes, tileBytes))
+		}
+	}
+	return warnings
+}
+
+// Idle-cycle reasons, in classification priority order.
+const (
+	idleReasonBufferBlocked = "buffer_blocked"
+	idleReasonThrottled     = "throttled"
+	idleReasonNoReadyWork   = "no_ready_work"
+)
+
+type idleReasonCounter struct {
+	bufferBlocked int64
+	throttled     int64
+	noReadyWork   int64
+}
+
+func (counter *idleReasonCounter) add(reason string) {
+	switch reason {
+	case idleReasonBufferBlocked:
+		counter.bufferBlocked++
+	case idleReasonThrottled:
+		counter.throttled++
+	default:
+		counter.noReadyWork++
+	}
+}
+
+// idleReason 给空闲 chiplet 的本周期归因：输入缓冲预留失败优先，其次是互连背压，
+// 其余视为没有就绪任务（依赖未满足）。
+func idleReason(reserveBlocked bool, throttled bool) string {
+	if reserveBlocked {
+		return idleReasonBufferBlocked
+	}
+	if throttled {
+		return idleReasonThrottled
+	}
+	return idleReasonNoReadyWork
+}
+
+func (this *ChipletPlatform) idleReasonCounterFor(kind string) *idleReasonCounter {
+	if this.idleReasonCycles == nil {
+		this.idleReasonCycles = make(map[string]*idleReasonCounter)
+	}
+	counter, ok := this.idleReasonCycles[kind]
+	if !ok {
+		counter = &idleReasonCounter{}
+		this.idleReasonCycles[kind] = counter
+	}
+	return counter
+}
+
+// recordIdleReasons classifies every chiplet that ended the cycle without
+// pending work and accumulates the reason per chiplet type.
+func (this *ChipletPlatform) recordIdleReasons(throttled bool) {
+	for i, chip := range this.digitalChiplets {
+		if chip == nil || chip.Busy() {
+			continue
+		}
+		blocked := i < len(this.digitalReserveBlocked) && this.digitalReserveBlocked[i]
+		this.idleReasonCounterFor("digital").add(idleReason(blocked, throttled))
+	}
+	for i, chip := range this.rramChiplets {
+		if chip == nil || chip.Busy() {
+			continue
+		}
+		blocked := i < len(this.rramReserveBlocked) && this.rramReserveBlocked[i]
+		this.idleReasonCounterFor("rram").add(idleReason(blocked, throttled))
+	}
+}
+
+func (this *ChipletPlatform) idleReasonLines() []string {
+	lines := make([]string, 0, 6)
+	for _, kind := range []string{"digital", "rram"} {
+		counter := this.idleReasonCycles[kind]
+		if counter == nil {
+			counter = &idleReasonCounter{}
+		}
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_%s_idle_cycles[%s]: %d", kind, idleReasonNoReadyWork, counter.noReadyWork),
+			fmt.Sprintf("ChipletPlatform_%s_idle_cycles[%s]: %d", kind, idleReasonThrottled, counter.throttled),
+			fmt.Sprintf("ChipletPlatform_%s_idle_cycles[%s]: %d", kind, idleReasonBufferBlocked, counter.bufferBlocked),
+		)
+	}
+	return lines
+}
+
+// observePowerdown 在周期末按各 chiplet 是否忙碌推进掉电状态。
+func (this *ChipletPlatform) observePowerdown() {
+	if this.powerdown == nil {
+		return
+	}
+	for _, chip := range this.digitalChiplets {
+		if chip != nil {
+			this.powerdown.observe(this.powerdown.digitalState(chip.ID), chip.Busy())
+		}
+	}
+	for _, chip := range this.rramChiplets {
+		if chip != nil {
+			this.powerdown.observe(this.powerdown.rramState(chip.ID), chip.Busy())
+		}
+	}
+}
+
+func (this *ChipletPlatform) powerdownLines() []string {
+	if this.powerdown == nil {
+		return nil
+	}
+	asleepCycles := int64(0)
+	for _, state := range append(append([]*powerdownState(nil), this.powerdown.digital...), this.powerdown.rram...) {
+		asleepCycles += state.asleepCycles
+	}
+	return []string{
+		fmt.Sprintf("ChipletPlatform_powerdown_events: %d", this.powerdown.powerdowns),
+		fmt.Sprintf("ChipletPlatform_powerdown_asleep_cycles: %d", asleepCycles),
+		fmt.Sprintf("ChipletPlatform_powerdown_wakeups: %d", this.powerdown.wakeups),
+		fmt.Sprintf("ChipletPlatform_powerdown_wake_latency_cycles: %d", this.powerdown.wakeLatencyCycles),
+		fmt.Sprintf("ChipletPlatform_powerdown_energy_saved_pj: %.6f", this.powerdown.energySavedPJ),
+	}
+}
+
+func (this *ChipletPlatform) runRramTick() {
+	defer this.profiler.addDevice(this.profiler.start())
+	for _, chiplet := range this.rramChiplets {
+		if this.powerdown.asleep(this.powerdown.rramState(chiplet.ID)) {
+			chiplet.StaticEnergyPJ += this.powerdown.retain(1, chiplet.StaticEnergyPerCyclePJ())
+			continue
+		}
+		if this.dvfs != nil && !this.dvfs.step(this.dvfs.rramState(chiplet.ID), chiplet.Busy()) {
+			this.dvfs.energySavedPJ += chiplet.StaticEnergyPerCyclePJ()
+			continue
+		}
+		dynamicBefore := chiplet.DynamicEnergyPJ
+		chiplet.Tick()
+		this.dvfs.scaleDynamicEnergy(this.dvfs.rramState(chiplet.ID), &chiplet.DynamicEnergyPJ, dynamicBefore)
+		this.drainRramOutputWrites(chiplet.ID)
+		if summary, ok := chiplet.ConsumeLastResult(); ok {
+			this.recordRramResult(chiplet.ID, summary)
+		}
+	}
+}
+
+// analyticalTransfers reports whether --chiplet_transfer_mode=analytical is in
+// effect.
+func (this *ChipletPlatform) analyticalTransfers() bool {
+	return this.config != nil && this.config.TransferMode == "analytical"
+}
+
+func (this *ChipletPlatform) transferMode() string {
+	if this.analyticalTransfers() {
+		return "analytical"
+	}
+	return "cycle_accurate"
+}
+
+// occupyInterconnect charges the estimated cycles of a transfer. In
+// cycle-accurate mode the link stays busy for that long and later transfers
+// contend for it; in analytical mode the cost only accumulates in a latency
+// budget and the link is immediately free again.
+func (this *ChipletPlatform) occupyInterconnect(cycles int) {
+	if cycles <= 0 {
+		return
+	}
+	if this.analyticalTransfers() {
+		this.analyticalTransferCycles += int64(cycles)
+		return
+	}
+	this.transferThrottleUntil += cycles
+}
+
+func (this *ChipletPlatform) runInterconnectTick() {
+	defer this.profiler.addDevice(this.profiler.start())
+	if this.transferThrottleUntil > 0 {
+		this.transferThrottleUntil--
+	}
+}
+
+func (this *ChipletPlatform) advanceDomainTicks(freq int, phase *int) int {
+	if freq <= 0 {
+		return 0
+	}
+	if this.clockBaseMhz <= 0 {
+		return 1
+	}
+	*phase += freq
+	ticks := 0
+	for *phase >= this.clockBaseMhz {
+		*phase -= this.clockBaseMhz
+		ticks++
+	}
+	return ticks
+}
+
+func (this *ChipletPlatform) emitProgress(cycleDeferrals int) {
+	if this.progressInterval <= 0 {
+		return
+	}
+
+	if this.currentCycle < this.nextProgressCycle {
+		return
+	}
+
+	this.nextProgressCycle += this.progressInterval
+
+	digitalPending := 0
+	for _, chiplet := range this.digitalChiplets {
+		if chiplet != nil {
+			digitalPending += chiplet.PendingTasks
+		}
+	}
+
+	rramPending := 0
+	for _, chiplet := range this.rramChiplets {
+		if chiplet != nil {
+			rramPending += chiplet.PendingTasks
+		}
+	}
+
+	stagerState := "空"
+	if this.stager != nil && this.stager.HasPending() {
+		stagerState = "等待"
+	}
+
+	orchestratorState := "空闲"
+	if this.orchestrator != nil && this.orchestrator.HasPendingWork() {
+		orchestratorState = "活跃"
+	}
+
+	fmt.Printf(
+		"[chiplet] 周期=%d | 数字任务 累计=%d 待处理=%d | RRAM任务 累计=%d 待处理=%d | 传输累计=%d | 本周期调度 数字=%d RRAM=%d 传输=%d | 本周期传输字节=%d | 回压=%d | Stager=%s | Orchestrator=%s\n",
+		this.currentCycle,
+		this.executedDigitalTasks,
+		digitalPending,
+		this.executedRramTasks,
+		rramPending,
+		this.executedTransferTasks,
+		this.cycleDigitalExec,
+		this.cycleRramExec,
+		this.cycleTransferExec,
+		this.cycleTransferBytes,
+		cycleDeferrals,
+		stagerState,
+		orchestratorState,
+	)
+}
+
+func (this *ChipletPlatform) maybeFlushStats() {
+	if this.statsFlushInterval <= 0 {
+		return
+	}
+	if this.currentCycle < this.nextStatsFlushCycle {
+		return
+	}
+	this.nextStatsFlushCycle += this.statsFlushInterval
+	this.writeStatsFiles(false)
+}
+
+const timeseriesHeader = "cycle,digital_tasks,rram_tasks,transfer_tasks,tokens,transfer_bytes,host_dma_load_bytes,host_dma_store_bytes,kv_hits,kv_misses,deferrals,digital_util,rram_util,energy_total_pj"
+
+// maybeSnapshot 每 snapshotInterval 个周期向 chiplet_timeseries.csv 追加一行累计指标。
+// 与 stats flush 不同，历史行会保留下来，用于观察长时间运行中的阶段变化（如 prefill→decode）。
+func (this *ChipletPlatform) maybeSnapshot() {
+	if this.snapshotInterval <= 0 {
+		return
+	}
+	if this.currentCycle < this.nextSnapshotCycle {
+		return
+	}
+	this.nextSnapshotCycle += this.snapshotInterval
+	this.recordSnapshot()
+	this.writeTimeseriesFile()
+}
+
+// recordSnapshot 记录当前周期的累计指标。
+func (this *ChipletPlatform) recordSnapshot() {
+	deferrals := 0
+	for _, count := range this.digitalDeferrals {
+		deferrals += count
+	}
+	for _, count := range this.rramDeferrals {
+		deferrals += count
+	}
+	energy := 0.0
+	for _, chip := range this.digitalChiplets {
+		energy += chip.DynamicEnergyPJ + chip.StaticEnergyPJ + chip.InterconnectEnergyPJ
+	}
+	for _, chip := range this.rramChiplets {
+		energy += chip.DynamicEnergyPJ + chip.StaticEnergyPJ
+	}
+	digitalUtil, rramUtil := this.utilization()
+	this.lastSnapshotCycle = this.currentCycle
+	this.timeseriesRows++
+	this.timeseriesLog = append(this.timeseriesLog, fmt.Sprintf("%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%.4f,%.4f,%.6e",
+		this.currentCycle,
+		this.executedDigitalTasks,
+		this.executedRramTasks,
+		this.executedTransferTasks,
+		this.lmHeadTokens,
+		this.totalTransferBytes,
+		this.hostDmaLoadBytesTotal,
+		this.hostDmaStoreBytesTotal,
+		this.kvCacheHits,
+		this.kvCacheMisses,
+		deferrals,
+		digitalUtil,
+		rramUtil,
+		energy,
+	))
+}
+
+// snapshotCount 返回已记录的时间序列快照数（不含表头）。
+func (this *ChipletPlatform) snapshotCount() int {
+	return this.timeseriesRows
+}
+
+// writeFinalGraphFile 在 --chiplet_dump_final_graph 下把经过 MoE 展开、流式克隆、
+// 融合与拆分后的完整 DAG 写入 chiplet_graph_final.json，反映实际执行的图结构。
+func (this *ChipletPlatform) writeFinalGraphFile() {
+	if this.binDirpath == "" || this.config == nil || this.config.DumpFinalGraph == 0 || this.orchestrator == nil {
+		return
+	}
+	nodes := this.orchestrator.SnapshotGraph()
+	if nodes == nil {
+		nodes = []chiplet.GraphNodeSnapshot{}
+	}
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		fmt.Printf("[chiplet] warning: failed to encode final graph: %v\n", err)
+		return
+	}
+	graphDumper := new(misc.FileDumper)
+	graphDumper.Init(filepath.Join(this.binDirpath, "chiplet_graph_final.json"))
+	graphDumper.WriteLines([]string{string(data)})
+}
+
+// writeTimeseriesFile 把尚未落盘的时间序列行（首次包括表头）追加到
+// chiplet_timeseries.csv。文件在第一次写入时创建并保持打开，Fini 时关闭。
+func (this *ChipletPlatform) writeTimeseriesFile() {
+	if this.binDirpath == "" || len(this.timeseriesLog) == 0 {
+		return
+	}
+	if this.timeseriesFile == nil {
+		file, err := os.Create(filepath.Join(this.binDirpath, "chiplet_timeseries.csv"))
+		if err != nil {
+			panic(err)
+		}
+		this.timeseriesFile = file
+	}
+	if _, err := this.timeseriesFile.WriteString(strings.Join(this.timeseriesLog, "\n") + "\n"); err != nil {
+		panic(err)
+	}
+	this.timeseriesLog = this.timeseriesLog[:0]
+}
+
+func (this *ChipletPlatform) recordRramResult(chipletID int, summary rram.ResultSummary) {
+	if !summary.Valid {
+		return
+	}
+	line := fmt.Sprintf("%d,%d,%d,%.6f,%.6f,%.6f,%d,0,0,0,0,0",
+		this.currentCycle,
+		chipletID,
+		summary.RawOM,
+		summary.Final,
+		summary.Reference,
+		summary.Scale,
+		summary.ZeroPt,
+	)
+	this.resultsTotal++
+	if this.statFactory != nil {
+		this.statFactory.Increment("rram_results_recorded", 1)
+	}
+	if !this.shouldKeepResult(chipletID, summary) {
+		// 保留被丢弃的最新一条，结束时补写每个 chiplet 的最后结果。
+		if chipletID >= 0 && chipletID < len(this.resultTail) {
+			this.resultTail[chipletID] = line
+		}
+		return
+	}
+	if chipletID >= 0 && chipletID < len(this.resultTail) {
+		this.resultTail[chipletID] = ""
+	}
+	this.resultLog = append(this.resultLog, line)
+	this.resultsSampled++
+}
+
+// shouldKeepResult applies --chiplet_result_sample_rate. The first result of
+// each chiplet and any result whose absolute error exceeds the configured
+// threshold are always kept so anomalies never disappear from the log.
+func (this *ChipletPlatform) shouldKeepResult(chipletID int, summary rram.ResultSummary) bool {
+	if this.config == nil || this.config.ResultSampleRate >= 1 {
+		return true
+	}
+	if chipletID < 0 || chipletID >= len(this.resultSeen) {
+		return true
+	}
+	if !this.resultSeen[chipletID] {
+		this.resultSeen[chipletID] = true
+		return true
+	}
+	if summary.HasReference && math.Abs(summary.Final-summary.Reference) > this.config.ResultErrorThreshold {
+		return true
+	}
+	if this.config.ResultSampleRate <= 0 || this.resultSampler == nil {
+		return false
+	}
+	return this.resultSampler.Float64() < this.config.ResultSampleRate
+}
+
+// flushResultTails appends the last result of every chiplet that was dropped
+// by sampling, so each chiplet's final state is present in the result log.
+func (this *ChipletPlatform) flushResultTails() {
+	for idx, line := range this.resultTail {
+		if line == "" {
+			continue
+		}
+		this.resultLog = append(this.resultLog, line)
+		this.resultsSampled++
+		this.resultTail[idx] = ""
+	}
+}
+
+func (this *ChipletPlatform) Dump() {
+	this.writeStatsFiles(true)
+	if this.summaryEnabled() {
+		this.printSummary(os.Stdout)
+	}
+}
+
+// summaryEnabled resolves --chiplet_summary: 1 always prints, 0 never, and a
+// negative value prints only when stdout is a terminal.
+func (this *ChipletPlatform) summaryEnabled() bool {
+	if this.config == nil || this.config.Summary == 0 {
+		return false
+	}
+	if this.config.Summary > 0 {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printSummary 在运行结束时向 out（通常为控制台）输出关键指标，完整统计仍写入 chiplet_log.txt。
+func (this *ChipletPlatform) printSummary(out io.Writer) {
+	digitalEnergy := 0.0
+	for _, chip := range this.digitalChiplets {
+		digitalEnergy += chip.DynamicEnergyPJ + chip.StaticEnergyPJ + chip.InterconnectEnergyPJ
+	}
+	rramEnergy := 0.0
+	for _, chip := range this.rramChiplets {
+		rramEnergy += chip.DynamicEnergyPJ + chip.StaticEnergyPJ
+	}
+	digitalUtil, rramUtil := this.utilization()
+	totalEnergy := digitalEnergy + rramEnergy
+	energyPerToken := 0.0
+	if this.lmHeadTokens > 0 {
+		energyPerToken = totalEnergy / float64(this.lmHeadTokens)
+	}
+
+	fmt.Fprintln(out, "[chiplet] ===== 运行摘要 =====")
+	rows := [][2]string{
+		{"cycles", fmt.Sprintf("%d", this.totalCycles())},
+		{"digital_util", fmt.Sprintf("%.4f", digitalUtil)},
+		{"rram_util", fmt.Sprintf("%.4f", rramUtil)},
+		{"energy_total_pj", fmt.Sprintf("%.6e", totalEnergy)},
+		{"tokens", fmt.Sprintf("%d", this.lmHeadTokens)},
+		{"energy_per_token_pj", fmt.Sprintf("%.6e", energyPerToken)},
+		{"transfer_bytes", fmt.Sprintf("%d", this.totalTransferBytes)},
+		{"transfer_mode", this.transferMode()},
+		{"cycles_per_wall_sec", fmt.Sprintf("%.1f", this.cyclesPerWallSecond())},
+	}
+	if this.profiler != nil {
+		scheduler, device, _ := this.profiler.split()
+		rows = append(rows, [2]string{"scheduler_wall_pct", fmt.Sprintf("%.1f%% (device %.1f%%)",
+			this.profiler.percent(scheduler), this.profiler.percent(device))})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(out, "  %-22s %s\n", row[0], row[1])
+	}
+
+	// info 级别只写入 chiplet_warnings.json，摘要中只列出需要关注的告警。
+	printed := 0
+	for _, warning := range this.collectWarnings() {
+		if warning.Severity == warningSeverityInfo {
+			continue
+		}
+		fmt.Fprintf(out, "  warning                %s\n", warning.Message)
+		printed++
+	}
+	if printed == 0 {
+		fmt.Fprintln(out, "  warnings               none")
+	}
+}
+
+// cyclesPerWallSecond 返回仿真吞吐（模拟周期/墙钟秒），用于比较不同传输模式的仿真速度。
+// 该值依赖墙钟，只出现在终端摘要与 --chiplet_profile 输出中，不写入 chiplet_log.txt，以保持其可复现。
+func (this *ChipletPlatform) cyclesPerWallSecond() float64 {
+	if this.wallStart.IsZero() {
+		return 0
+	}
+	elapsed := time.Since(this.wallStart).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(this.totalCycles()) / elapsed
+}
+
+// totalCycles 返回运行的总周期：analytical 模式下传输不占用链路，其延迟预算按基准时钟
+// 换算后累加到仿真周期上。
+func (this *ChipletPlatform) totalCycles() int64 {
+	total := int64(this.currentCycle)
+	if !this.analyticalTransfers() {
+		return total
+	}
+	return total + this.interconnectToBaseCycles(this.analyticalTransferCycles)
+}
+
+// interconnectToBaseCycles 把互连域周期换算为基准时钟周期。
+func (this *ChipletPlatform) interconnectToBaseCycles(cycles int64) int64 {
+	if cycles <= 0 || this.clockBaseMhz <= 0 || this.interconnectClockMhz <= 0 {
+		return cycles
+	}
+	return (cycles*int64(this.clockBaseMhz) + int64(this.interconnectClockMhz) - 1) / int64(this.interconnectClockMhz)
+}
+
+// utilization 返回 digital/RRAM chiplet 的平均忙碌占比（忙碌周期 / (chiplet 数 × 总周期)）。
+func (this *ChipletPlatform) utilization() (float64, float64) {
+	digitalBusy := 0
+	for _, chip := range this.digitalChiplets {
+		digitalBusy += chip.BusyCycles
+	}
+	rramBusy := 0
+	for _, chip := range this.rramChiplets {
+		rramBusy += chip.BusyCycles
+	}
+	digitalUtil := 0.0
+	if this.currentCycle > 0 && len(this.digitalChiplets) > 0 {
+		digitalUtil = float64(digitalBusy) / float64(len(this.digitalChiplets)*this.currentCycle)
+	}
+	rramUtil := 0.0
+	if this.currentCycle > 0 && len(this.rramChiplets) > 0 {
+		rramUtil = float64(rramBusy) / float64(len(this.rramChiplets)*this.currentCycle)
+	}
+	return digitalUtil, rramUtil
+}
+
+// batchLatencyPercentiles 返回批次延迟的样本数、p50、p99 与最大值（节拍）。
+func (this *ChipletPlatform) batchLatencyPercentiles() (int, int, int, int) {
+	var latencies []int
+	if this.orchestrator != nil {
+		latencies = this.orchestrator.BatchLatencies()
+	}
+	p50, p99, maxLatency := 0, 0, 0
+	if len(latencies) > 0 {
+		sort.Ints(latencies)
+		p50 = latencies[(len(latencies)-1)*50/100]
+		p99 = latencies[(len(latencies)-1)*99/100]
+		maxLatency = latencies[len(latencies)-1]
+	}
+	return len(latencies), p50, p99, maxLatency
+}
+
+// batchLatencyLines 汇总 streaming batch 从实例化到全部节点完成的延迟分布，用于比较调度策略的尾延迟。
+func (this *ChipletPlatform) batchLatencyLines() []string {
+	samples, p50, p99, maxLatency := this.batchLatencyPercentiles()
+	return []string{
+		fmt.Sprintf("ChipletPlatform_batch_latency_samples: %d", samples),
+		fmt.Sprintf("ChipletPlatform_batch_latency_p50: %d", p50),
+		fmt.Sprintf("ChipletPlatform_batch_latency_p99: %d", p99),
+		fmt.Sprintf("ChipletPlatform_batch_latency_max: %d", maxLatency),
+		fmt.Sprintf("ChipletPlatform_avg_active_batches: %.4f", this.orchestrator.AverageActiveBatches()),
+		fmt.Sprintf("ChipletPlatform_max_active_batches: %d", this.orchestrator.PeakActiveBatches()),
+	}
+}
+
+func (this *ChipletPlatform) writeStatsFiles(final bool) {
+	if this.binDirpath == "" {
+		return
+	}
+
+	if final {
+		this.flushResultTails()
+		// 结束时补一行最终累计值，保证时间序列覆盖到最后一个周期。
+		if this.snapshotInterval > 0 && this.lastSnapshotCycle != this.currentCycle {
+			this.recordSnapshot()
+		}
+	}
+
+	file_dumper := new(misc.FileDumper)
+	file_dumper.Init(filepath.Join(this.binDirpath, "chiplet_log.txt"))
+
+	lines := make([]string, 0)
+
+	if this.statFactory != nil {
+		this.statFactory.Increment("digital_tasks_total", 0)
+		this.statFactory.Increment("rram_tasks_total", 0)
+		lines = append(lines, this.statFactory.ToLines()...)
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("ChipletPlatform_digital_tasks_total: %d", this.executedDigitalTasks),
+		fmt.Sprintf("ChipletPlatform_rram_tasks_total: %d", this.executedRramTasks),
+		fmt.Sprintf("ChipletPlatform_transfer_tasks_total: %d", this.executedTransferTasks),
+		fmt.Sprintf("ChipletPlatform_transfer_bytes_total: %d", this.totalTransferBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_hops_total: %d", this.totalTransferHops),
+		fmt.Sprintf("ChipletPlatform_transfer_to_rram_bytes_total: %d", this.totalTransferToRramBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_to_digital_bytes_total: %d", this.totalTransferToDigitalBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_host_load_bytes_total: %d", this.totalTransferHostLoadBytes),
 		fmt.Sprintf("ChipletPlatform_transfer_host_store_bytes_total: %d", this.totalTransferHostStoreBytes),
 		fmt.Sprintf("ChipletPlatform_transfer_throttle_events_total: %d", this.transferThrottleEventsTotal),
+		fmt.Sprintf("ChipletPlatform_cross_target_missing_transfer: %d", this.orchestrator.CrossTargetMissingTransfers()),
+		fmt.Sprintf("ChipletPlatform_pipeline_checksum_verified: %d", this.orchestrator.PipelineChecksumVerified()),
+		fmt.Sprintf("ChipletPlatform_pipeline_checksum_mismatches: %d", this.orchestrator.PipelineChecksumMismatches()),
 		fmt.Sprintf("ChipletPlatform_transfer_throttle_cycles_total: %d", this.transferThrottleCyclesTotal),
+		fmt.Sprintf("ChipletPlatform_transfer_mode[%s]: 1", this.transferMode()),
+		fmt.Sprintf("ChipletPlatform_transfer_analytical_cycles: %d", this.analyticalTransferCycles),
+		fmt.Sprintf("ChipletPlatform_total_cycles: %d", this.totalCycles()),
+		fmt.Sprintf("ChipletPlatform_link_duplex[%s]: 1", this.linkDuplexMode()),
+		fmt.Sprintf("ChipletPlatform_half_duplex_contention_events: %d", this.duplexContentionEvents),
+		fmt.Sprintf("ChipletPlatform_half_duplex_contention_cycles: %d", this.duplexContentionCycles),
+		fmt.Sprintf("ChipletPlatform_transfer_issued_bytes_total: %d", this.transferIssuedBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_completed_bytes_total: %d", this.transferCompletedBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_ecc_overhead_bytes: %d", this.eccOverheadBytes),
+		fmt.Sprintf("ChipletPlatform_pipelined_transfers: %d", this.pipelinedTransfers),
+		fmt.Sprintf("ChipletPlatform_pipelined_transfer_hidden_cycles: %d", this.pipelinedHiddenCycles),
+		fmt.Sprintf("ChipletPlatform_transfer_dropped_bytes_total: %d", this.transferDroppedBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_inflight_peak_bytes: %d", this.transferInflightPeakBytes),
+		fmt.Sprintf("ChipletPlatform_transfer_inflight_limited_cycles: %d", this.orchestrator.TransferInflightLimitedCycles()),
+		fmt.Sprintf("ChipletPlatform_transfer_schedule_overhead_cycles: %d", this.transferScheduleCycles),
+		fmt.Sprintf("ChipletPlatform_transfer_min_latency_floor_hits: %d", this.transferFloorHits),
+		fmt.Sprintf("ChipletPlatform_cmd_fetch_cycles_total: %d", this.cmdFetchCycles),
+		fmt.Sprintf("ChipletPlatform_cmd_fetch_commands: %d", this.cmdFetchCommands),
+		fmt.Sprintf("ChipletPlatform_stager_peak_depth: %d", this.stagerPeakDepth),
+		fmt.Sprintf("ChipletPlatform_lm_head_tokens: %d", this.lmHeadTokens),
+		fmt.Sprintf("ChipletPlatform_booksim_calls: %d", this.booksimCalls),
+		fmt.Sprintf("ChipletPlatform_booksim_cache_hits: %d", this.booksimCache.Hits()),
+		fmt.Sprintf("ChipletPlatform_booksim_cache_misses: %d", this.booksimCache.Misses()),
+		fmt.Sprintf("ChipletPlatform_booksim_cache_expired: %d", this.booksimCache.Expired()),
+		fmt.Sprintf("ChipletPlatform_booksim_call_reduction: %.4f", booksimCallReduction(this.booksimCache.Hits(), this.booksimCalls)),
+		fmt.Sprintf("ChipletPlatform_booksim_estimated_transfers: %d", this.booksimEstimated),
+		fmt.Sprintf("ChipletPlatform_booksim_fallback_transfers: %d", this.booksimFallbacks),
+		fmt.Sprintf("ChipletPlatform_booksim_coverage: %.4f", booksimCoverage(this.booksimEstimated, this.booksimFallbacks)),
 		fmt.Sprintf("ChipletPlatform_host_dma_load_bytes_total: %d", this.hostDmaLoadBytesTotal),
 		fmt.Sprintf("ChipletPlatform_host_dma_store_bytes_total: %d", this.hostDmaStoreBytesTotal),
 		fmt.Sprintf("ChipletPlatform_kv_cache_loads_total: %d", this.kvCacheLoads),
@@ -753,6 +2085,9 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		fmt.Sprintf("ChipletPlatform_digital_load_bytes_runtime_total: %d", this.totalDigitalLoadBytesRuntime),
 		fmt.Sprintf("ChipletPlatform_digital_store_bytes_runtime_total: %d", this.totalDigitalStoreBytesRuntime),
 		fmt.Sprintf("ChipletPlatform_digital_tasks_completed_total: %d", this.totalDigitalCompleted),
+		fmt.Sprintf("ChipletPlatform_retirement_latency: %d", this.retirementLatency()),
+		fmt.Sprintf("ChipletPlatform_retired_tasks_total: %d", this.retiredTasks),
+		fmt.Sprintf("ChipletPlatform_retirement_stall_cycles: %d", this.retirementStallCycles),
 		fmt.Sprintf("ChipletPlatform_digital_load_bytes_total: %d", this.digitalBytesLoaded),
 		fmt.Sprintf("ChipletPlatform_digital_store_bytes_total: %d", this.digitalBytesStored),
 		fmt.Sprintf("ChipletPlatform_digital_scalar_ops_total: %d", this.digitalScalarOps),
@@ -774,10 +2109,26 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		fmt.Sprintf("ChipletPlatform_moe_snapshot_misses_total: %d", this.moeSnapshotMisses),
 		fmt.Sprintf("ChipletPlatform_moe_fallback_events_total: %d", this.moeFallbackEvents),
 		fmt.Sprintf("ChipletPlatform_moe_sessions_completed_total: %d", this.moeSessionsCompleted),
+		fmt.Sprintf("ChipletPlatform_moe_session_deferrals: %d", this.orchestrator.MoeSessionDeferrals()),
+		fmt.Sprintf("ChipletPlatform_moe_session_peak: %d", this.orchestrator.MoeSessionPeak()),
+		fmt.Sprintf("ChipletPlatform_moe_skipped_expert_commands: %d", this.orchestrator.MoeSkippedExpertCommands()),
+		fmt.Sprintf("ChipletPlatform_moe_alltoall_collectives: %d", this.orchestrator.MoeAlltoallCollectives()),
+		fmt.Sprintf("ChipletPlatform_moe_alltoall_bytes: %d", this.orchestrator.MoeAlltoallBytes()),
+		fmt.Sprintf("ChipletPlatform_moe_alltoall_cycles: %d", this.orchestrator.MoeAlltoallCycles()),
+		fmt.Sprintf("ChipletPlatform_collective_charged_cycles: %d", this.collectiveChargedCycles),
+		fmt.Sprintf("ChipletPlatform_collective_broadcast_cycles: %d", this.orchestrator.CollectiveBroadcastCycles()),
+		fmt.Sprintf("ChipletPlatform_moe_gating_softmax_cycles: %d", this.moeGatingSoftmaxCycles),
+	)
+	lines = append(lines, moeExpertLoadLines(this.orchestrator.MoeExpertTokens())...)
+	lines = append(lines,
+		fmt.Sprintf("ChipletPlatform_host_dispatch_bound_cycles: %d", this.orchestrator.HostDispatchBoundCycles()),
 		fmt.Sprintf("ChipletPlatform_moe_latency_samples: %d", this.moeLatencySamples),
 		fmt.Sprintf("ChipletPlatform_moe_latency_total_cycles: %d", this.moeLatencyTotal),
 		fmt.Sprintf("ChipletPlatform_moe_latency_max_cycles: %d", this.moeLatencyMax),
+		fmt.Sprintf("ChipletPlatform_rram_results_total: %d", this.resultsTotal),
+		fmt.Sprintf("ChipletPlatform_rram_results_sampled: %d", this.resultsSampled),
 	)
+	lines = append(lines, this.transferSizeHistLines()...)
 
 	if this.statFactory != nil {
 		waitSamples := this.statFactory.Value("task_wait_samples")
@@ -807,10 +2158,22 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 	totalRramExecuteEnergy := 0.0
 	totalRramPostEnergy := 0.0
 	totalRramWeightEnergy := 0.0
+	totalRramProgramEnergy := 0.0
 	totalWeightResident := int64(0)
 	totalWeightPeak := int64(0)
 	totalWeightLoads := int64(0)
 	totalWeightHits := int64(0)
+	totalWeightEvictions := int64(0)
+	maxWeightResident := int64(0)
+	totalOutputLimited := int64(0)
+	totalPartialSumCombines := int64(0)
+	totalDequantCycles := int64(0)
+	totalDequantEnergy := 0.0
+	totalWeightLoadBytes := int64(0)
+	totalWeightLoadCycles := int64(0)
+	totalProgramTasks := int64(0)
+	totalProgramPulses := int64(0)
+	totalOverlapHidden := int64(0)
 	totalRramPulses := int64(0)
 	totalRramAdcSamples := int64(0)
 	totalRramPreCycles := int64(0)
@@ -819,6 +2182,14 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 	totalRramErrorAccum := 0.0
 	maxRramError := 0.0
 	lastRramError := 0.0
+	totalDigitalEnergy := 0.0
+	totalScratchSpill := int64(0)
+	totalSpillStall := int64(0)
+	totalConversionOps := int64(0)
+	totalConversionCycles := int64(0)
+	totalRramEnergy := 0.0
+	totalDigitalOps := int64(0)
+	totalRramOps := int64(0)
 
 	for _, chiplet := range this.digitalChiplets {
 		line := fmt.Sprintf("DigitalChiplet[%d]_executed_tasks: %d", chiplet.ID, chiplet.ExecutedTasks)
@@ -827,6 +2198,24 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		lines = append(lines, line)
 		line = fmt.Sprintf("DigitalChiplet[%d]_busy_cycles: %d", chiplet.ID, chiplet.BusyCycles)
 		lines = append(lines, line)
+		lines = append(lines,
+			fmt.Sprintf("DigitalChiplet[%d]_useful_busy_cycles: %d", chiplet.ID, chiplet.UsefulBusyCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_overhead_busy_cycles: %d", chiplet.ID, chiplet.OverheadBusyCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_useful_cycle_fraction: %.4f", chiplet.ID, usefulCycleFraction(chiplet)),
+			fmt.Sprintf("DigitalChiplet[%d]_load_compute_overlap_cycles: %d", chiplet.ID, chiplet.LoadComputeOverlapCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_load_stall_cycles: %d", chiplet.ID, chiplet.LoadStallCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_activation_bank_overflows: %d", chiplet.ID, chiplet.ActivationBankOverflows),
+			fmt.Sprintf("DigitalChiplet[%d]_raw_hazard_stall_cycles: %d", chiplet.ID, chiplet.RawHazardStallCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_prefetch_hits: %d", chiplet.ID, chiplet.PrefetchHits),
+			fmt.Sprintf("DigitalChiplet[%d]_prefetch_bytes: %d", chiplet.ID, chiplet.PrefetchBytes),
+			fmt.Sprintf("DigitalChiplet[%d]_prefetch_overlap_cycles: %d", chiplet.ID, chiplet.PrefetchOverlapCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_dispatch_limited_cycles: %d", chiplet.ID, chiplet.DispatchLimitedCycles),
+		)
+		loadBW, storeBW := chiplet.BufferBandwidths()
+		lines = append(lines,
+			fmt.Sprintf("DigitalChiplet[%d]_buffer_load_bw: %d", chiplet.ID, loadBW),
+			fmt.Sprintf("DigitalChiplet[%d]_buffer_store_bw: %d", chiplet.ID, storeBW),
+		)
 		line = fmt.Sprintf("DigitalChiplet[%d]_deferrals: %d", chiplet.ID, this.digitalDeferrals[chiplet.ID])
 		lines = append(lines, line)
 		line = fmt.Sprintf("DigitalChiplet[%d]_saturation: %d", chiplet.ID, this.digitalSaturation[chiplet.ID])
@@ -841,12 +2230,19 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		lines = append(lines, line)
 		line = fmt.Sprintf("DigitalChiplet[%d]_spu_busy_cycles: %d", chiplet.ID, chiplet.SpuBusyCycles)
 		lines = append(lines, line)
+		lines = append(lines,
+			fmt.Sprintf("DigitalChiplet[%d]_scratch_spill_bytes: %d", chiplet.ID, chiplet.ScratchSpillBytes),
+			fmt.Sprintf("DigitalChiplet[%d]_spill_stall_cycles: %d", chiplet.ID, chiplet.SpillStallCycles),
+			fmt.Sprintf("DigitalChiplet[%d]_dtype_conversion_ops: %d", chiplet.ID, chiplet.DtypeConversionOps),
+			fmt.Sprintf("DigitalChiplet[%d]_dtype_conversion_cycles: %d", chiplet.ID, chiplet.DtypeConversionCycles),
+		)
 		lines = append(lines,
 			fmt.Sprintf("DigitalChiplet[%d]_energy_pe_pj: %.6f", chiplet.ID, chiplet.PeEnergyPJ),
 			fmt.Sprintf("DigitalChiplet[%d]_energy_spu_pj: %.6f", chiplet.ID, chiplet.SpuEnergyPJ),
 			fmt.Sprintf("DigitalChiplet[%d]_energy_reduce_pj: %.6f", chiplet.ID, chiplet.ReduceEnergyPJ),
 			fmt.Sprintf("DigitalChiplet[%d]_energy_vpu_pj: %.6f", chiplet.ID, chiplet.VpuEnergyPJ),
 			fmt.Sprintf("DigitalChiplet[%d]_energy_dynamic_pj: %.6f", chiplet.ID, chiplet.DynamicEnergyPJ),
+			fmt.Sprintf("DigitalChiplet[%d]_ops_per_pj: %.6f", chiplet.ID, opsPerPJ(digitalOps(chiplet), digitalEnergyPJ(chiplet))),
 		)
 		for idx, cycles := range chiplet.PeBusyCycles {
 			lines = append(lines, fmt.Sprintf("DigitalChiplet[%d]_pe[%d]_busy_cycles: %d", chiplet.ID, idx, cycles))
@@ -854,6 +2250,7 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		for idx, cycles := range chiplet.SpuClusterBusy {
 			lines = append(lines, fmt.Sprintf("DigitalChiplet[%d]_spu_cluster[%d]_busy_cycles: %d", chiplet.ID, idx, cycles))
 		}
+		lines = append(lines, this.dvfsLines("DigitalChiplet", chiplet.ID, this.dvfs.digitalState(chiplet.ID), this.digitalClockMhz)...)
 		for name, occ := range chiplet.BufferOccupancy {
 			lines = append(lines, fmt.Sprintf("DigitalChiplet[%d]_buffer_%s: %d", chiplet.ID, name, occ))
 			if peak := chiplet.BufferPeakUsage[name]; peak > 0 {
@@ -872,6 +2269,12 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		totalSpuEnergy += chiplet.SpuEnergyPJ
 		totalVpuEnergy += chiplet.VpuEnergyPJ
 		totalReduceEnergy += chiplet.ReduceEnergyPJ
+		totalDigitalEnergy += digitalEnergyPJ(chiplet)
+		totalDigitalOps += digitalOps(chiplet)
+		totalScratchSpill += chiplet.ScratchSpillBytes
+		totalSpillStall += chiplet.SpillStallCycles
+		totalConversionOps += chiplet.DtypeConversionOps
+		totalConversionCycles += chiplet.DtypeConversionCycles
 	}
 
 	totalInputPeak := int64(0)
@@ -899,15 +2302,41 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 			fmt.Sprintf("RramChiplet[%d]_execute_energy_pj: %.6f", chiplet.ID, chiplet.ExecuteEnergyPJ),
 			fmt.Sprintf("RramChiplet[%d]_post_energy_pj: %.6f", chiplet.ID, chiplet.PostEnergyPJ),
 			fmt.Sprintf("RramChiplet[%d]_weight_load_energy_pj: %.6f", chiplet.ID, chiplet.WeightLoadEnergyPJ),
+			fmt.Sprintf("RramChiplet[%d]_program_energy_pj: %.6f", chiplet.ID, chiplet.ProgramEnergyPJ),
 			fmt.Sprintf("RramChiplet[%d]_dynamic_energy_pj: %.6f", chiplet.ID, chiplet.DynamicEnergyPJ),
 			fmt.Sprintf("RramChiplet[%d]_static_energy_pj: %.6f", chiplet.ID, chiplet.StaticEnergyPJ),
 		)
+		rramMacs := this.rramMacEstimate(stats)
+		lines = append(lines,
+			fmt.Sprintf("RramChiplet[%d]_macs_estimate: %d", chiplet.ID, rramMacs),
+			fmt.Sprintf("RramChiplet[%d]_ops_per_pj: %.6f", chiplet.ID, opsPerPJ(2*rramMacs, chiplet.DynamicEnergyPJ+chiplet.StaticEnergyPJ)),
+		)
+		totalRramOps += 2 * rramMacs
 		lines = append(lines,
 			fmt.Sprintf("RramChiplet[%d]_weights_resident_bytes: %d", chiplet.ID, chiplet.WeightBytesResident),
 			fmt.Sprintf("RramChiplet[%d]_weights_peak_bytes: %d", chiplet.ID, chiplet.WeightBytesPeak),
 			fmt.Sprintf("RramChiplet[%d]_weights_loads: %d", chiplet.ID, chiplet.WeightLoads),
 			fmt.Sprintf("RramChiplet[%d]_weights_hits: %d", chiplet.ID, chiplet.WeightLoadHits),
+			fmt.Sprintf("RramChiplet[%d]_weight_load_bytes: %d", chiplet.ID, chiplet.WeightLoadBytes),
+			fmt.Sprintf("RramChiplet[%d]_weight_load_bw: %.4f", chiplet.ID, weightLoadBandwidth(chiplet.WeightLoadBytes, chiplet.WeightLoadCycles)),
+			fmt.Sprintf("RramChiplet[%d]_partial_sum_combines: %d", chiplet.ID, chiplet.PartialSumCombines),
+			fmt.Sprintf("RramChiplet[%d]_partial_sum_combine_cycles: %d", chiplet.ID, chiplet.PartialSumCombineCycles),
+			fmt.Sprintf("RramChiplet[%d]_partial_sum_bytes: %d", chiplet.ID, chiplet.PartialSumBytes),
+			fmt.Sprintf("RramChiplet[%d]_dequant_ops: %d", chiplet.ID, chiplet.DequantOps),
+			fmt.Sprintf("RramChiplet[%d]_dequant_cycles: %d", chiplet.ID, chiplet.DequantCycles),
+			fmt.Sprintf("RramChiplet[%d]_dequant_energy_pj: %.6f", chiplet.ID, chiplet.DequantEnergyPJ),
 		)
+		totalPartialSumCombines += chiplet.PartialSumCombines
+		totalDequantCycles += chiplet.DequantCycles
+		totalDequantEnergy += chiplet.DequantEnergyPJ
+		totalWeightLoadBytes += chiplet.WeightLoadBytes
+		totalWeightLoadCycles += chiplet.WeightLoadCycles
+		lines = append(lines, this.dvfsLines("RramChiplet", chiplet.ID, this.dvfs.rramState(chiplet.ID), this.rramClockMhz)...)
+		if chiplet.ID < len(this.rramOutputLimited) {
+			lines = append(lines, fmt.Sprintf("RramChiplet[%d]_output_write_limited_cycles: %d", chiplet.ID, this.rramOutputLimited[chiplet.ID]))
+			totalOutputLimited += this.rramOutputLimited[chiplet.ID]
+		}
+		lines = append(lines, this.rramBandwidthLines(chiplet.ID, chiplet.BusyCycles)...)
 		if stats.ErrorSamples > 0 {
 			avgError := stats.AccumulatedErrorAbs / float64(stats.ErrorSamples)
 			lines = append(lines, fmt.Sprintf("RramChiplet[%d]_error_last: %.6f", chiplet.ID, stats.LastErrorAbs))
@@ -951,12 +2380,21 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		totalRramExecuteEnergy += chiplet.ExecuteEnergyPJ
 		totalRramPostEnergy += chiplet.PostEnergyPJ
 		totalRramWeightEnergy += chiplet.WeightLoadEnergyPJ
+		totalRramProgramEnergy += chiplet.ProgramEnergyPJ
+		totalProgramTasks += stats.ProgramTasks
+		totalProgramPulses += stats.PulseCountWrite
+		totalOverlapHidden += chiplet.OverlapHiddenCycles
+		totalRramEnergy += chiplet.DynamicEnergyPJ + chiplet.StaticEnergyPJ
 		totalWeightResident += chiplet.WeightBytesResident
+		if chiplet.WeightBytesResident > maxWeightResident {
+			maxWeightResident = chiplet.WeightBytesResident
+		}
 		if chiplet.WeightBytesPeak > totalWeightPeak {
 			totalWeightPeak = chiplet.WeightBytesPeak
 		}
 		totalWeightLoads += chiplet.WeightLoads
 		totalWeightHits += chiplet.WeightLoadHits
+		totalWeightEvictions += chiplet.WeightEvictions()
 		if chiplet.InputBufferPeak > totalInputPeak {
 			totalInputPeak = chiplet.InputBufferPeak
 		}
@@ -965,6 +2403,16 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		}
 	}
 
+	// Demand hit rate: speculative prefetch loads are excluded from the denominator.
+	weightHitRate := 0.0
+	if demandLoads := totalWeightLoads - this.weightPrefetchLoads; demandLoads > 0 {
+		weightHitRate = float64(totalWeightHits) / float64(demandLoads)
+	}
+	avgProgramPulses := 0.0
+	if totalProgramTasks > 0 {
+		avgProgramPulses = float64(totalProgramPulses) / float64(totalProgramTasks)
+	}
+
 	if this.currentCycle > 0 {
 		lines = append(lines,
 			fmt.Sprintf("ChipletPlatform_avg_digital_throughput: %.4f", float64(this.executedDigitalTasks)/float64(this.currentCycle)),
@@ -980,6 +2428,13 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 			fmt.Sprintf("ChipletPlatform_spu_vector_ops_total: %d", totalSpuVector),
 			fmt.Sprintf("ChipletPlatform_spu_special_ops_total: %d", totalSpuSpecial),
 			fmt.Sprintf("ChipletPlatform_spu_busy_cycles_total: %d", totalSpuBusy),
+			fmt.Sprintf("ChipletPlatform_scratch_spill_bytes: %d", totalScratchSpill),
+			fmt.Sprintf("ChipletPlatform_spill_stall_cycles: %d", totalSpillStall),
+			fmt.Sprintf("ChipletPlatform_dtype_conversion_ops_total: %d", totalConversionOps),
+			fmt.Sprintf("ChipletPlatform_dtype_conversion_cycles_total: %d", totalConversionCycles),
+			fmt.Sprintf("ChipletPlatform_transpose_tasks: %d", this.transposeTasks),
+			fmt.Sprintf("ChipletPlatform_transpose_cycles: %d", this.transposeCycles),
+			fmt.Sprintf("ChipletPlatform_transpose_stride_penalty_cycles: %d", this.transposePenaltyCycles),
 			fmt.Sprintf("ChipletPlatform_energy_pe_pj_total: %.6f", totalPeEnergy),
 			fmt.Sprintf("ChipletPlatform_energy_spu_pj_total: %.6f", totalSpuEnergy),
 			fmt.Sprintf("ChipletPlatform_energy_reduce_pj_total: %.6f", totalReduceEnergy),
@@ -988,6 +2443,12 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 			fmt.Sprintf("ChipletPlatform_energy_rram_execute_pj_total: %.6f", totalRramExecuteEnergy),
 			fmt.Sprintf("ChipletPlatform_energy_rram_post_pj_total: %.6f", totalRramPostEnergy),
 			fmt.Sprintf("ChipletPlatform_energy_rram_weight_load_pj_total: %.6f", totalRramWeightEnergy),
+			fmt.Sprintf("ChipletPlatform_energy_rram_program_pj_total: %.6f", totalRramProgramEnergy),
+			fmt.Sprintf("ChipletPlatform_energy_digital_pj_total: %.6f", totalDigitalEnergy),
+			fmt.Sprintf("ChipletPlatform_energy_rram_pj_total: %.6f", totalRramEnergy),
+			fmt.Sprintf("ChipletPlatform_digital_ops_per_pj: %.6f", opsPerPJ(totalDigitalOps, totalDigitalEnergy)),
+			fmt.Sprintf("ChipletPlatform_rram_ops_per_pj: %.6f", opsPerPJ(totalRramOps, totalRramEnergy)),
+			fmt.Sprintf("ChipletPlatform_ops_per_pj: %.6f", opsPerPJ(totalDigitalOps+totalRramOps, totalDigitalEnergy+totalRramEnergy)),
 			fmt.Sprintf("ChipletPlatform_rram_pulse_count_total: %d", totalRramPulses),
 			fmt.Sprintf("ChipletPlatform_rram_adc_samples_total: %d", totalRramAdcSamples),
 			fmt.Sprintf("ChipletPlatform_rram_preprocess_cycles_total: %d", totalRramPreCycles),
@@ -998,8 +2459,89 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 			fmt.Sprintf("ChipletPlatform_rram_weight_peak_bytes: %d", totalWeightPeak),
 			fmt.Sprintf("ChipletPlatform_rram_weight_loads_total: %d", totalWeightLoads),
 			fmt.Sprintf("ChipletPlatform_rram_weight_hits_total: %d", totalWeightHits),
+			fmt.Sprintf("ChipletPlatform_rram_weight_load_bytes_total: %d", totalWeightLoadBytes),
+			fmt.Sprintf("ChipletPlatform_rram_weight_load_bw: %.4f", weightLoadBandwidth(totalWeightLoadBytes, totalWeightLoadCycles)),
+			fmt.Sprintf("ChipletPlatform_rram_program_tasks_total: %d", totalProgramTasks),
+			fmt.Sprintf("ChipletPlatform_rram_program_pulses_avg: %.4f", avgProgramPulses),
+			fmt.Sprintf("ChipletPlatform_weight_neighbor_hits: %d", this.weightNeighborHits),
+			fmt.Sprintf("ChipletPlatform_weight_neighbor_interconnect_bytes: %d", this.weightNeighborBytes),
+			fmt.Sprintf("ChipletPlatform_rram_weight_hit_rate: %.4f", weightHitRate),
+			fmt.Sprintf("ChipletPlatform_rram_weight_evictions_total: %d", totalWeightEvictions),
+			fmt.Sprintf("ChipletPlatform_rram_weight_layout_placed: %d", this.orchestrator.WeightLayoutPlaced()),
+			fmt.Sprintf("ChipletPlatform_rram_weight_resident_imbalance: %.4f", weightResidentImbalance(maxWeightResident, totalWeightResident, len(this.rramChiplets))),
+			fmt.Sprintf("ChipletPlatform_prefetched_weight_batches: %d", this.orchestrator.PrefetchedWeightBatches()),
+			fmt.Sprintf("ChipletPlatform_weight_prefetch_loads: %d", this.weightPrefetchLoads),
+			fmt.Sprintf("ChipletPlatform_weight_prefetch_hits: %d", this.weightPrefetchHits),
+			fmt.Sprintf("ChipletPlatform_weight_prefetch_wasted: %d", this.weightPrefetchWasted),
+			fmt.Sprintf("ChipletPlatform_weight_prefetch_unused: %d", len(this.weightPrefetchPending)),
+			fmt.Sprintf("ChipletPlatform_weight_prefetch_skipped: %d", this.weightPrefetchSkipped),
+			fmt.Sprintf("ChipletPlatform_activation_spill_bytes_total: %d", this.activationSpillBytes),
+			fmt.Sprintf("ChipletPlatform_activation_reload_bytes_total: %d", this.activationReloadBytes),
+			fmt.Sprintf("ChipletPlatform_activation_spill_events: %d", this.activationSpillEvents),
+			fmt.Sprintf("ChipletPlatform_activation_offload_dma_cycles: %d", this.activationOffloadDma),
+			fmt.Sprintf("ChipletPlatform_weight_act_overlap_hidden_cycles: %d", totalOverlapHidden),
 			fmt.Sprintf("ChipletPlatform_rram_input_buffer_peak_bytes: %d", totalInputPeak),
 			fmt.Sprintf("ChipletPlatform_rram_output_buffer_peak_bytes: %d", totalOutputPeak),
+			fmt.Sprintf("ChipletPlatform_rram_output_write_limited_cycles: %d", totalOutputLimited),
+			fmt.Sprintf("ChipletPlatform_rram_partial_sum_combines: %d", totalPartialSumCombines),
+			fmt.Sprintf("ChipletPlatform_rram_dequant_cycles: %d", totalDequantCycles),
+			fmt.Sprintf("ChipletPlatform_rram_dequant_energy_pj: %.6f", totalDequantEnergy),
+		)
+		lines = append(lines, this.batchLatencyLines()...)
+		lines = append(lines, this.kvHeadLines()...)
+		lines = append(lines, this.interconnectEfficiencyLines()...)
+		lines = append(lines, this.parallelismLines()...)
+		lines = append(lines, this.rooflineLines()...)
+		lines = append(lines, this.transferPriorityLines()...)
+		lines = append(lines, this.idleReasonLines()...)
+		lines = append(lines, this.powerdownLines()...)
+		lines = append(lines, this.taskReconciliationLines()...)
+		lines = append(lines, this.profileLines()...)
+		lines = append(lines, this.barrierLines()...)
+		lines = append(lines, this.rramCrossCheckLines()...)
+		lines = append(lines, fmt.Sprintf("ChipletPlatform_unrecognized_command_count: %d", this.unrecognizedCommands))
+		lines = append(lines, fmt.Sprintf("ChipletPlatform_cycle_order[%s]: 1", strings.Join(this.cycleOrder(), ",")))
+		hopStats := this.topology.DigitalRramHopStats()
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_hop_distance_min: %d", hopStats.Min),
+			fmt.Sprintf("ChipletPlatform_hop_distance_avg: %.4f", hopStats.Avg),
+			fmt.Sprintf("ChipletPlatform_hop_distance_max: %d", hopStats.Max),
+			fmt.Sprintf("ChipletPlatform_topology_anomalies: %d", len(this.topologyAnomalies)),
+			fmt.Sprintf("ChipletPlatform_undersized_buffer_warnings: %d", len(this.undersizedBufferWarnings)),
+		)
+		if this.snapshotInterval > 0 {
+			lines = append(lines, fmt.Sprintf("ChipletPlatform_timeseries_snapshots: %d", this.snapshotCount()))
+		}
+		if iters := this.orchestrator.BootstrapIters(); iters > 0 {
+			lines = append(lines, fmt.Sprintf("ChipletPlatform_bootstrap_iters: %d", iters))
+		}
+		if this.config != nil && this.config.ReductionCostModel != "" {
+			lines = append(lines, fmt.Sprintf("ChipletPlatform_reduction_cost_model[%s]: 1", this.config.ReductionCostModel))
+		}
+		if this.dvfs != nil {
+			lines = append(lines,
+				fmt.Sprintf("ChipletPlatform_dvfs_transitions: %d", this.dvfs.transitions),
+				fmt.Sprintf("ChipletPlatform_dvfs_energy_saved_pj: %.6f", this.dvfs.energySavedPJ),
+				fmt.Sprintf("ChipletPlatform_dvfs_frequency_changes: %d", this.dvfs.frequencyChanges()),
+			)
+		}
+		if this.config != nil {
+			for _, key := range chiplet.EnergyCalibrationKeys(this.config.EnergyCalibration) {
+				lines = append(lines, fmt.Sprintf("ChipletPlatform_energy_calibration[%s]: %.4f", key, this.config.EnergyCalibration[key]))
+			}
+		}
+		totalEnergy := totalDigitalEnergy + totalRramEnergy
+		lines = append(lines, this.edpLines(totalDigitalEnergy, totalRramEnergy)...)
+		wastedEnergy, wastedCycles := this.wastedWork()
+		wastedFraction := 0.0
+		if totalEnergy > 0 {
+			wastedFraction = wastedEnergy / totalEnergy
+		}
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_wasted_tasks: %d", this.wastedTasks),
+			fmt.Sprintf("ChipletPlatform_wasted_energy_pj: %.6f", wastedEnergy),
+			fmt.Sprintf("ChipletPlatform_wasted_cycles: %d", wastedCycles),
+			fmt.Sprintf("ChipletPlatform_wasted_energy_fraction: %.4f", wastedFraction),
 		)
 		if totalRramErrorSamples > 0 {
 			avgErr := totalRramErrorAccum / float64(totalRramErrorSamples)
@@ -1039,8 +2581,14 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 		cycle_logger.WriteLines(this.cycleLog)
 	}
 
+	this.writeRooflineFile()
+	this.writeTimeseriesFile()
+	this.writeDvfsFrequencyFile()
+
 	if final {
 		this.appendMoeSummaryRow()
+		this.writeFinalGraphFile()
+		this.writeWarningsFile()
 	}
 	if len(this.resultLog) > 1 {
 		resultLogger := new(misc.FileDumper)
@@ -1049,77 +2597,474 @@ func (this *ChipletPlatform) writeStatsFiles(final bool) {
 	}
 }
 
-// SubmitTask enqueues a chiplet task for execution. Future host orchestration
-// logic will call this to drive workload execution.
-func (this *ChipletPlatform) SubmitTask(task *chiplet.Task) {
-	if this.stager == nil {
+// recordRoofline attributes a submitted digital descriptor to its layer. The
+// layer is the stage name attached by the assembler, falling back to the
+// command kind (or descriptor description for string payloads).
+func (this *ChipletPlatform) recordRoofline(task *chiplet.Task, descriptor *digital.TaskDescriptor) {
+	if this.rooflineStats == nil || descriptor == nil {
+		return
+	}
+	layer := descriptor.Description
+	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
+		layer = metadataString(cmd.Metadata, "stage_name", cmd.Kind.String())
+	}
+	layer = strings.ReplaceAll(layer, ",", "_")
+	if layer == "" {
+		layer = "unknown"
+	}
+
+	macs := int64(0)
+	if descriptor.RequiresPe && descriptor.ProblemM > 0 && descriptor.ProblemN > 0 && descriptor.ProblemK > 0 {
+		macs = int64(descriptor.ProblemM) * int64(descriptor.ProblemN) * int64(descriptor.ProblemK)
+	}
+	flops := 2*macs + int64(descriptor.ScalarOps+descriptor.VectorOps+descriptor.SpecialOps+descriptor.VpuOps)
+
+	entry, ok := this.rooflineStats[layer]
+	if !ok {
+		entry = &rooflineEntry{}
+		this.rooflineStats[layer] = entry
+	}
+	entry.tasks++
+	entry.macs += macs
+	entry.flops += flops
+	entry.bytes += descriptor.InputBytes + descriptor.WeightBytes + descriptor.OutputBytes
+}
+
+// writeRooflineFile emits chiplet_roofline.csv. The compute roof is the peak
+// PE throughput summed over all digital chiplets, in flops per cycle (2 flops
+// per MAC) like the per-layer numerator; the memory roof uses the
+// digital<->RRAM interconnect bandwidth. A layer whose intensity
+// exceeds the ridge point is reported as compute-bound.
+func (this *ChipletPlatform) writeRooflineFile() {
+	if len(this.rooflineStats) == 0 || this.binDirpath == "" {
+		return
+	}
+
+	peakFlops, bandwidth, ridge := this.rooflineRoofs()
+	lines := []string{"layer_id,tasks,macs,flops,bytes,intensity,attainable_flops_per_cycle,bound"}
+	for _, layer := range this.rooflineLayers() {
+		entry := this.rooflineStats[layer]
+		intensity := entry.intensity()
+		attainable := intensity * bandwidth
+		if attainable > peakFlops {
+			attainable = peakFlops
+		}
+		bound := rooflineBound(intensity, ridge)
+		lines = append(lines, fmt.Sprintf("%s,%d,%d,%d,%d,%.6f,%.2f,%s",
+			layer,
+			entry.tasks,
+			entry.macs,
+			entry.flops,
+			entry.bytes,
+			intensity,
+			attainable,
+			bound,
+		))
+	}
+
+	rooflineLogger := new(misc.FileDumper)
+	rooflineLogger.Init(filepath.Join(this.binDirpath, "chiplet_roofline.csv"))
+	rooflineLogger.WriteLines(lines)
+}
+
+// rooflineRoofs returns the compute roof (flops/cycle), the memory roof
+// (bytes/cycle) and the ridge intensity where a layer turns compute-bound.
+func (this *ChipletPlatform) rooflineRoofs() (float64, float64, float64) {
+	peakFlops := 2 * float64(this.rooflinePeakMacs())
+	bandwidth := 0.0
+	if this.config != nil {
+		bandwidth = float64(this.config.TransferBandwidthDr)
+	}
+	ridge := 0.0
+	if bandwidth > 0 {
+		ridge = peakFlops / bandwidth
+	}
+	return peakFlops, bandwidth, ridge
+}
+
+// rooflinePeakMacs 返回所有数字芯粒合计的每周期 MAC 数。
+func (this *ChipletPlatform) rooflinePeakMacs() int64 {
+	if this.topology == nil {
+		return 0
+	}
+	digital := this.topology.Digital
+	return int64(digital.NumChiplets) * int64(digital.PesPerChiplet) * int64(digital.PeRows) * int64(digital.PeCols)
+}
+
+func (this *ChipletPlatform) rooflineLayers() []string {
+	layers := make([]string, 0, len(this.rooflineStats))
+	for layer := range this.rooflineStats {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	return layers
+}
+
+func (entry *rooflineEntry) intensity() float64 {
+	if entry.bytes <= 0 {
+		return 0
+	}
+	return float64(entry.flops) / float64(entry.bytes)
+}
+
+func rooflineBound(intensity, ridge float64) string {
+	if ridge > 0 && intensity >= ridge {
+		return "compute"
+	}
+	return "memory"
+}
+
+// edpLines 输出 EDP/ED²P，单位为 pJ×cycles、pJ×cycles²；各域使用自身时钟域周期，
+// 合计使用平台基准周期（含 analytical 模式的传输预算）。
+func (this *ChipletPlatform) edpLines(digitalEnergy, rramEnergy float64) []string {
+	digitalDelay := float64(this.digitalDomainCycles)
+	rramDelay := float64(this.rramDomainCycles)
+	totalDelay := float64(this.totalCycles())
+	totalEnergy := digitalEnergy + rramEnergy
+	return []string{
+		fmt.Sprintf("ChipletPlatform_edp_digital: %.6e", digitalEnergy*digitalDelay),
+		fmt.Sprintf("ChipletPlatform_edp_rram: %.6e", rramEnergy*rramDelay),
+		fmt.Sprintf("ChipletPlatform_edp_total: %.6e", totalEnergy*totalDelay),
+		fmt.Sprintf("ChipletPlatform_ed2p_digital: %.6e", digitalEnergy*digitalDelay*digitalDelay),
+		fmt.Sprintf("ChipletPlatform_ed2p_rram: %.6e", rramEnergy*rramDelay*rramDelay),
+		fmt.Sprintf("ChipletPlatform_ed2p_total: %.6e", totalEnergy*totalDelay*totalDelay),
+	}
+}
+
+// rooflineLines 输出 ridge 点及每个 layer 相对 ridge 的位置，便于直接从 chiplet_log.txt 判断瓶颈。
+func (this *ChipletPlatform) rooflineLines() []string {
+	peakFlops, bandwidth, ridge := this.rooflineRoofs()
+	lines := []string{
+		fmt.Sprintf("ChipletPlatform_roofline_peak_macs_per_cycle: %d", this.rooflinePeakMacs()),
+		fmt.Sprintf("ChipletPlatform_roofline_peak_flops_per_cycle: %.2f", peakFlops),
+		fmt.Sprintf("ChipletPlatform_roofline_bandwidth_bytes_per_cycle: %.2f", bandwidth),
+		fmt.Sprintf("ChipletPlatform_roofline_ridge_intensity: %.6f", ridge),
+	}
+	for _, layer := range this.rooflineLayers() {
+		intensity := this.rooflineStats[layer].intensity()
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_roofline[%s]_intensity: %.6f", layer, intensity),
+			fmt.Sprintf("ChipletPlatform_roofline[%s]_bound[%s]: 1", layer, rooflineBound(intensity, ridge)),
+		)
+	}
+	return lines
+}
+
+// SubmitTask enqueues a chiplet task for execution. Future host orchestration
+// logic will call this to drive workload execution.
+func (this *ChipletPlatform) SubmitTask(task *chiplet.Task) {
+	if this.stager == nil {
+		return
+	}
+
+	if task == nil {
+		return
+	}
+
+	if task.EnqueueCycle == 0 {
+		task.EnqueueCycle = this.currentCycle
+	}
+
+	if task.Target == chiplet.TaskTargetTransfer {
+		this.transferIssuedBytes += transferTaskBytes(task)
+		if inflight := this.transferInflightBytes(); inflight > this.transferInflightPeakBytes {
+			this.transferInflightPeakBytes = inflight
+		}
+	}
+
+	this.stager.Enqueue(task)
+}
+
+// ExecuteTask satisfies chiplet.TaskExecutor and dispatches tasks to the
+// appropriate subsystem.
+func (this *ChipletPlatform) ExecuteTask(task *chiplet.Task) {
+	if task == nil {
+		return
+	}
+	defer this.profiler.addExecute(this.profiler.start())
+
+	waitCycles := this.currentCycle - task.EnqueueCycle
+	if waitCycles < 0 {
+		waitCycles = 0
+	}
+
+	if this.statFactory != nil {
+		this.statFactory.Increment("task_wait_cycles_total", int64(waitCycles))
+		this.statFactory.Increment("task_wait_samples", 1)
+	}
+
+	if waitCycles > this.maxWaitCycles {
+		this.maxWaitCycles = waitCycles
+	}
+
+	if this.orchestrator != nil {
+		this.orchestrator.NotifyBackpressure(waitCycles)
+	}
+
+	this.dispatchedTasks++
+
+	wasted := isWastedWork(task)
+	energyBefore, cyclesBefore := 0.0, 0
+	if wasted {
+		this.wastedTasks++
+		energyBefore, cyclesBefore = this.platformDynamicEnergy(), this.rramPendingCycles(task)
+	}
+
+	inflight := false
+	switch task.Target {
+	case chiplet.TaskTargetDigital:
+		this.handleDigitalTask(task)
+		this.cycleDigitalExec++
+	case chiplet.TaskTargetRram:
+		this.handleRramTask(task)
+		this.cycleRramExec++
+	case chiplet.TaskTargetTransfer:
+		inflight = this.handleTransferTask(task)
+		this.cycleTransferExec++
+	case chiplet.TaskTargetHost:
+		this.handleHostTask(task)
+		this.executedHostTasks++
+		if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil && cmd.Kind == chiplet.CommandKindHostLmHead {
+			this.lmHeadTokens += int64(firstPositive(metadataInt(cmd.Metadata, "tokens", 0), int(cmd.Aux0), 1))
+		}
+		if this.statFactory != nil {
+			this.statFactory.Increment("host_tasks_total", 1)
+		}
+	default:
+		if this.reportUndeliverable(task, "") {
+			return
+		}
+	}
+
+	if wasted && task.Target != chiplet.TaskTargetDigital {
+		// digital 任务在完成时由 chiplet 自行累计；其余目标的能耗在派发时即已计入。
+		this.wastedEnergyPJ += this.platformDynamicEnergy() - energyBefore
+		if task.Target == chiplet.TaskTargetRram {
+			this.wastedCycles += int64(this.rramPendingCycles(task) - cyclesBefore)
+		} else {
+			this.wastedCycles += int64(task.Latency)
+		}
+	}
+
+	chiplet.AdvancePipelineChecksum(task.Payload)
+
+	if inflight {
+		// 传输在链路上传完后由 completeInflightTransfers 退休。
+		return
+	}
+	this.retireTaskAfter(task.NodeID, this.barrierWaitCycles(task))
+}
+
+// barrierWaitCycles 返回 PE barrier 收集所有生产者完成所需的 host 周期：
+// barrier 自身延迟加上 --chiplet_barrier_cycles_per_producer × 生产者数（扇入）。
+// 选项为 0 时 barrier 不产生额外代价。
+func (this *ChipletPlatform) barrierWaitCycles(task *chiplet.Task) int {
+	if this.config == nil || this.config.BarrierProducerCycles <= 0 {
+		return 0
+	}
+	cmd, ok := task.Payload.(*chiplet.CommandDescriptor)
+	if !ok || cmd == nil || cmd.Kind != chiplet.CommandKindPeBarrier {
+		return 0
+	}
+	producers := metadataInt(cmd.Metadata, "producers", 1)
+	if producers < 1 {
+		producers = 1
+	}
+	wait := int(cmd.Latency) + producers*this.config.BarrierProducerCycles
+	if wait < 0 {
+		wait = 0
+	}
+	this.barriers++
+	this.barrierProducers += int64(producers)
+	this.barrierWaitCyclesTotal += int64(wait)
+	return wait
+}
+
+func (this *ChipletPlatform) barrierLines() []string {
+	avgProducers := 0.0
+	if this.barriers > 0 {
+		avgProducers = float64(this.barrierProducers) / float64(this.barriers)
+	}
+	return []string{
+		fmt.Sprintf("ChipletPlatform_barriers_total: %d", this.barriers),
+		fmt.Sprintf("ChipletPlatform_barrier_wait_cycles_total: %d", this.barrierWaitCyclesTotal),
+		fmt.Sprintf("ChipletPlatform_barrier_avg_producers: %.4f", avgProducers),
+	}
+}
+
+// rramCrossCheckLines 汇总 RRAM 结果与数字重算的交叉校验；未开启时不输出。
+func (this *ChipletPlatform) rramCrossCheckLines() []string {
+	if this.config == nil || this.config.RramCrossCheck == 0 {
+		return nil
+	}
+	total := rram.Stats{}
+	for _, chiplet := range this.rramChiplets {
+		total.Accumulate(chiplet.Stats())
+	}
+	avg := 0.0
+	if total.DigitalChecks > 0 {
+		avg = total.DigitalDiscrepancyAccum / float64(total.DigitalChecks)
+	}
+	return []string{
+		fmt.Sprintf("ChipletPlatform_rram_vs_digital_checks: %d", total.DigitalChecks),
+		fmt.Sprintf("ChipletPlatform_rram_vs_digital_skipped: %d", total.DigitalCheckSkipped),
+		fmt.Sprintf("ChipletPlatform_rram_vs_digital_violations: %d", total.DigitalCheckViolations),
+		fmt.Sprintf("ChipletPlatform_rram_vs_digital_discrepancy_max: %.6f", total.DigitalDiscrepancyMax),
+		fmt.Sprintf("ChipletPlatform_rram_vs_digital_discrepancy_avg: %.6f", avg),
+	}
+}
+
+// pendingRetirement 是已完成计算、等待退休（结果对后继可见）的任务。
+// stall 是其中计入 retirement 延迟的部分，不含 barrier 等待。
+type pendingRetirement struct {
+	nodeID     int
+	readyCycle int
+	stall      int
+}
+
+func (this *ChipletPlatform) retirementLatency() int {
+	if this.config == nil || this.config.RetirementLatency < 0 {
+		return 0
+	}
+	return this.config.RetirementLatency
+}
+
+// retireTask 让任务进入退休阶段。--chiplet_retirement_latency 为 0 时立即通知
+// orchestrator 释放后继；否则结果要再经过 retirement 周期才对后继可见，用于
+// 建模结果转发延迟。
+func (this *ChipletPlatform) retireTask(nodeID int) {
+	this.retireTaskAfter(nodeID, 0)
+}
+
+// retireTaskAfter 与 retireTask 相同，但在 retirement 延迟之外再推迟 extra 个周期
+// （如 barrier 收集生产者完成的等待）。
+func (this *ChipletPlatform) retireTaskAfter(nodeID int, extra int) {
+	latency := this.retirementLatency()
+	if extra < 0 {
+		extra = 0
+	}
+	if latency+extra == 0 {
+		this.retiredTasks++
+		if this.orchestrator != nil {
+			this.orchestrator.NotifyTaskCompletion(nodeID)
+		}
+		return
+	}
+	this.retirementQueue = append(this.retirementQueue, pendingRetirement{
+		nodeID:     nodeID,
+		readyCycle: this.currentCycle + latency + extra,
+		stall:      latency,
+	})
+}
+
+// drainRetirements 在周期末退休所有到期的任务，并按入队顺序通知 orchestrator。
+func (this *ChipletPlatform) drainRetirements() {
+	if len(this.retirementQueue) == 0 {
 		return
 	}
-
-	if task == nil {
-		return
+	remaining := this.retirementQueue[:0]
+	for _, entry := range this.retirementQueue {
+		if entry.readyCycle > this.currentCycle {
+			remaining = append(remaining, entry)
+			continue
+		}
+		this.retiredTasks++
+		this.retirementStallCycles += int64(entry.stall)
+		if this.orchestrator != nil {
+			this.orchestrator.NotifyTaskCompletion(entry.nodeID)
+		}
 	}
+	this.retirementQueue = remaining
+}
 
-	if task.EnqueueCycle == 0 {
-		task.EnqueueCycle = this.currentCycle
+// isWastedWork reports whether a command is tagged as speculative or wasted
+// work (metadata "speculative" or "wasted"), i.e. work whose result is
+// ultimately discarded.
+func isWastedWork(task *chiplet.Task) bool {
+	cmd, ok := task.Payload.(*chiplet.CommandDescriptor)
+	if !ok || cmd == nil || cmd.Metadata == nil {
+		return false
 	}
-
-	this.stager.Enqueue(task)
+	if speculative, _ := cmd.Metadata["speculative"].(bool); speculative {
+		return true
+	}
+	wasted, _ := cmd.Metadata["wasted"].(bool)
+	return wasted
 }
 
-// ExecuteTask satisfies chiplet.TaskExecutor and dispatches tasks to the
-// appropriate subsystem.
-func (this *ChipletPlatform) ExecuteTask(task *chiplet.Task) {
-	if task == nil {
-		return
+// platformDynamicEnergy sums the dynamic and interconnect energy charged so far
+// on every chiplet; static energy is excluded since it does not depend on work.
+func (this *ChipletPlatform) platformDynamicEnergy() float64 {
+	energy := 0.0
+	for _, chip := range this.digitalChiplets {
+		energy += chip.DynamicEnergyPJ + chip.InterconnectEnergyPJ
 	}
-
-	waitCycles := this.currentCycle - task.EnqueueCycle
-	if waitCycles < 0 {
-		waitCycles = 0
+	for _, chip := range this.rramChiplets {
+		energy += chip.DynamicEnergyPJ
 	}
+	return energy
+}
 
-	if this.statFactory != nil {
-		this.statFactory.Increment("task_wait_cycles_total", int64(waitCycles))
-		this.statFactory.Increment("task_wait_samples", 1)
+// rramPendingCycles returns the pending cycles of the RRAM chiplet a task targets.
+func (this *ChipletPlatform) rramPendingCycles(task *chiplet.Task) int {
+	if task.Target != chiplet.TaskTargetRram {
+		return 0
 	}
-
-	if waitCycles > this.maxWaitCycles {
-		this.maxWaitCycles = waitCycles
+	chipletID, ok := extractChipletID(task.Payload)
+	if !ok || chipletID < 0 || chipletID >= len(this.rramChiplets) {
+		return 0
 	}
+	return this.rramChiplets[chipletID].PendingCycles
+}
 
-	if this.orchestrator != nil {
-		this.orchestrator.NotifyBackpressure(waitCycles)
-	}
+// wastedWork returns the energy and cycles spent on speculative/wasted work:
+// digital chiplets account tagged descriptor tasks when they finish; the
+// legacy digital fallback and other targets are measured by the platform at
+// dispatch, and weight prefetches are charged once they are found evicted
+// before use.
+func (this *ChipletPlatform) wastedWork() (float64, int64) {
+	energy := this.wastedEnergyPJ
+	cycles := this.wastedCycles
+	for _, chip := range this.digitalChiplets {
+		energy += chip.WastedEnergyPJ
+		cycles += chip.WastedCycles
+	}
+	return energy, cycles
+}
 
-	switch task.Target {
-	case chiplet.TaskTargetDigital:
-		this.handleDigitalTask(task)
-		this.cycleDigitalExec++
-	case chiplet.TaskTargetRram:
-		this.handleRramTask(task)
-		this.cycleRramExec++
-	case chiplet.TaskTargetTransfer:
-		this.handleTransferTask(task)
-		this.cycleTransferExec++
-	case chiplet.TaskTargetHost:
-		this.handleHostTask(task)
-		this.executedHostTasks++
-		if this.statFactory != nil {
-			this.statFactory.Increment("host_tasks_total", 1)
+// taskReconciliationLines compares the platform's executed-task counters with
+// the sum of per-chiplet ExecutedTasks. Chiplets count a task when it
+// finishes, so tasks still in flight show up as a positive delta until they
+// drain; a non-zero delta after the run points at a path that bumps only one
+// of the two counters.
+func (this *ChipletPlatform) taskReconciliationLines() []string {
+	digitalExecuted := 0
+	for _, chip := range this.digitalChiplets {
+		if chip != nil {
+			digitalExecuted += chip.ExecutedTasks
 		}
-	default:
-		// Transfers and other task types are not yet modeled.
 	}
-
-	if this.orchestrator != nil {
-		this.orchestrator.NotifyTaskCompletion(task.NodeID)
+	rramExecuted := 0
+	for _, chip := range this.rramChiplets {
+		if chip != nil {
+			rramExecuted += chip.ExecutedTasks
+		}
+	}
+	digitalDelta := this.executedDigitalTasks - digitalExecuted
+	rramDelta := this.executedRramTasks - rramExecuted
+	return []string{
+		fmt.Sprintf("ChipletPlatform_task_count_reconciliation_delta[digital]: %d", digitalDelta),
+		fmt.Sprintf("ChipletPlatform_task_count_reconciliation_delta[rram]: %d", rramDelta),
+		fmt.Sprintf("ChipletPlatform_task_count_reconciliation_delta: %d", digitalDelta+rramDelta),
 	}
 }
 
 func (this *ChipletPlatform) handleDigitalTask(task *chiplet.Task) {
 	chipletID, ok := extractChipletID(task.Payload)
 	if !ok || chipletID < 0 || chipletID >= len(this.digitalChiplets) {
+		if ok {
+			this.reportUndeliverable(task, fmt.Sprintf(" chiplet=%d", chipletID))
+		}
 		return
 	}
 
@@ -1149,130 +3094,570 @@ func (this *ChipletPlatform) handleDigitalTask(task *chiplet.Task) {
 			if descriptor.Description == "" {
 				descriptor.Description = cmd.Kind.String()
 			}
+			if this.config != nil && this.config.EnergyCalibration != nil {
+				descriptor.EnergyScale = chiplet.EnergyScale(this.config.EnergyCalibration, cmd.Kind.String())
+			}
+		}
+		descriptor.Wasted = isWastedWork(task)
+		pendingBefore := this.digitalChiplets[chipletID].PendingCycles
+		if this.digitalChiplets[chipletID].SubmitDescriptor(descriptor) {
+			this.orchestrator.RecordProducerCycles(task.NodeID, this.digitalToInterconnectCycles(this.digitalChiplets[chipletID].PendingCycles-pendingBefore))
+			if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
+				this.recordGatingSnapshotFromCommand(chipletID, cmd)
+				this.recordMoeBarrierMetrics(cmd)
+			}
+			this.executedDigitalTasks++
+			if this.statFactory != nil {
+				this.statFactory.Increment("digital_tasks_total", 1)
+				this.statFactory.Increment("digital_load_bytes_total", descriptor.InputBytes+descriptor.WeightBytes)
+				this.statFactory.Increment("digital_store_bytes_total", descriptor.OutputBytes)
+				this.statFactory.Increment("digital_scalar_ops_total", int64(descriptor.ScalarOps))
+				this.statFactory.Increment("digital_vector_ops_total", int64(descriptor.VectorOps))
+			}
+			this.digitalBytesLoaded += descriptor.InputBytes + descriptor.WeightBytes
+			this.digitalBytesStored += descriptor.OutputBytes
+			this.digitalScalarOps += int64(descriptor.ScalarOps)
+			this.digitalVectorOps += int64(descriptor.VectorOps)
+			this.recordRoofline(task, descriptor)
+			if descriptor.Description == "moe_gating_softmax" {
+				this.moeGatingSoftmaxCycles += int64(this.digitalChiplets[chipletID].EstimateSpuCycles(descriptor))
+			}
+			if descriptor.Description == "transpose" {
+				this.recordTranspose(this.digitalChiplets[chipletID], descriptor)
+			}
+			return
+		}
+	}
+
+	this.scheduleLegacyDigitalTask(this.digitalChiplets[chipletID], task)
+	this.executedDigitalTasks++
+	if this.statFactory != nil {
+		this.statFactory.Increment("digital_tasks_total", 1)
+	}
+}
+
+// scheduleLegacyDigitalTask runs a task that has no descriptor on the
+// latency-only path. A wasted task is charged here since the chiplet only
+// accounts tagged descriptor tasks: legacy tasks carry no op counts, so their
+// energy is the chiplet's per-cycle energy over the scheduled cycles.
+func (this *ChipletPlatform) scheduleLegacyDigitalTask(chip *digital.Chiplet, task *chiplet.Task) {
+	pendingBefore := chip.PendingCycles
+	chip.ScheduleTask(task.Latency)
+	if isWastedWork(task) {
+		cycles := chip.PendingCycles - pendingBefore
+		this.wastedCycles += int64(cycles)
+		this.wastedEnergyPJ += float64(cycles) * chip.StaticEnergyPerCyclePJ()
+	}
+}
+
+func (this *ChipletPlatform) handleRramTask(task *chiplet.Task) {
+	chipletID, ok := extractChipletID(task.Payload)
+	if !ok || chipletID < 0 || chipletID >= len(this.rramChiplets) {
+		if ok {
+			this.reportUndeliverable(task, fmt.Sprintf(" chiplet=%d", chipletID))
+		}
+		return
+	}
+
+	spec := this.buildRramTaskSpec(task)
+	this.rramChiplets[chipletID].ScheduleTask(task.Latency, spec)
+	this.executedRramTasks++
+	if this.statFactory != nil {
+		this.statFactory.Increment("rram_tasks_total", 1)
+		if spec != nil {
+			if spec.PulseCount > 0 {
+				this.statFactory.Increment("rram_pulse_count_total", int64(spec.PulseCount))
+			}
+			if spec.AdcSamples > 0 {
+				this.statFactory.Increment("rram_adc_samples_total", int64(spec.AdcSamples))
+			}
+			if spec.PreCycles > 0 {
+				this.statFactory.Increment("rram_preprocess_cycles_total", int64(spec.PreCycles))
+			}
+			if spec.PostCycles > 0 {
+				this.statFactory.Increment("rram_postprocess_cycles_total", int64(spec.PostCycles))
+			}
+			if spec.ErrorAbs != 0 {
+				this.statFactory.Increment("rram_error_samples", 1)
+			}
+		}
+	}
+
+	var cmdKind chiplet.CommandKind
+	var stageLabel string
+	var cmdDescriptor *chiplet.CommandDescriptor
+	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
+		cmdKind = cmd.Kind
+		cmdDescriptor = cmd
+	} else if payloadMap, ok := task.Payload.(map[string]interface{}); ok {
+		if s, ok := payloadMap["stage"].(string); ok {
+			stageLabel = strings.ToLower(s)
+		}
+	}
+
+	if cmdKind == chiplet.CommandKindRramStageAct || stageLabel == "stage_act" || stageLabel == "stage" {
+		if this.waitsForInputPaths(task) {
+			this.rramChiplets[chipletID].ConsumeInputs()
+		}
+		expectedBytes := int64(spec.ActivationSize)
+		if expectedBytes <= 0 {
+			expectedBytes = this.rramInputBuffered[chipletID]
+		}
+		this.consumeRramInput(chipletID, expectedBytes)
+		outputBytes := int64(spec.OutputSize)
+		if outputBytes <= 0 {
+			outputBytes = expectedBytes
+		}
+		if chipletID >= 0 && chipletID < len(this.rramProcessingBytes) {
+			this.rramProcessingBytes[chipletID] = outputBytes
+		}
+		if chipletID >= 0 && chipletID < len(this.rramChiplets) && spec != nil {
+			if chip := this.rramChiplets[chipletID]; chip != nil {
+				tileID, arrayID, weightTag := deriveWeightKey(cmdDescriptor, spec)
+				if _, ok := chip.LookupWeights(tileID, arrayID, weightTag); !ok {
+					chip.RegisterWeights(tileID, arrayID, weightTag, estimateWeightBytes(spec), this.currentCycle)
+				} else {
+					chip.TouchWeights(tileID, arrayID, weightTag, this.currentCycle)
+				}
+			}
+		}
+	}
+	if cmdKind == chiplet.CommandKindRramPost || stageLabel == "post" || stageLabel == "rram_post" {
+		outputBytes := int64(spec.OutputSize)
+		if outputBytes <= 0 {
+			outputBytes = this.rramProcessingBytes[chipletID]
+		}
+		this.releaseRramOutputForChiplet(chipletID, outputBytes)
+	}
+	if cmdKind == chiplet.CommandKindRramWeightLoad && chipletID >= 0 && chipletID < len(this.rramChiplets) && spec != nil {
+		this.serveWeightLoad(chipletID, cmdDescriptor, spec)
+	}
+}
+
+// weightPrefetchKey identifies a speculatively loaded weight chunk on a chiplet.
+type weightPrefetchKey struct {
+	chipletID int
+	key       rram.WeightKey
+}
+
+// weightPrefetchLoad records the cost of an issued prefetch so it can be
+// charged as wasted work if the chunk is evicted before a demand load uses it.
+type weightPrefetchLoad struct {
+	cycles   int
+	energyPJ float64
+}
+
+// serveWeightLoad handles an RRAM weight-load command: a resident chunk is a
+// hit, otherwise it is copied from a neighbor or loaded from the host.
+// Commands tagged "prefetch" are speculative loads for the next streaming
+// batch; they are dropped when the chunk is already resident or when it would
+// not fit the free weight capacity, so a prefetch never evicts a resident
+// chunk. Issued prefetches are tracked until a demand load consumes them or
+// finds them evicted (wasted).
+func (this *ChipletPlatform) serveWeightLoad(chipletID int, cmd *chiplet.CommandDescriptor, spec *rram.TaskSpec) {
+	chip := this.rramChiplets[chipletID]
+	if chip == nil {
+		return
+	}
+	tileID, arrayID, weightTag := deriveWeightKey(cmd, spec)
+	weightBytes := estimateWeightBytes(spec)
+	prefetchKey := weightPrefetchKey{
+		chipletID: chipletID,
+		key:       rram.WeightKey{TileID: tileID, ArrayID: arrayID, Tag: strings.ToLower(weightTag)},
+	}
+
+	if cmd != nil && cmd.Metadata != nil {
+		if prefetch, _ := cmd.Metadata["prefetch"].(bool); prefetch {
+			if _, ok := chip.LookupWeights(tileID, arrayID, weightTag); ok || chip.WeightLoadPending(tileID, arrayID, weightTag) {
+				return
+			}
+			if capacity := chip.WeightCapacityBytes(); capacity > 0 && weightBytes > chip.WeightHeadroomBytes() {
+				this.weightPrefetchSkipped++
+				return
+			}
+			cycles, energy := chip.ScheduleWeightLoad(tileID, arrayID, weightTag, weightBytes, this.weightLoadLatency(cmd), this.currentCycle)
+			if this.weightPrefetchPending == nil {
+				this.weightPrefetchPending = make(map[weightPrefetchKey]weightPrefetchLoad)
+			}
+			this.weightPrefetchPending[prefetchKey] = weightPrefetchLoad{cycles: cycles, energyPJ: energy}
+			this.weightPrefetchLoads++
+			if this.statFactory != nil {
+				this.statFactory.Increment("rram_weight_bytes_total", weightBytes)
+			}
+			return
+		}
+	}
+
+	prefetch, prefetched := this.weightPrefetchPending[prefetchKey]
+	delete(this.weightPrefetchPending, prefetchKey)
+
+	_, resident := chip.LookupWeights(tileID, arrayID, weightTag)
+	if resident || (prefetched && chip.WeightLoadPending(tileID, arrayID, weightTag)) {
+		if prefetched {
+			this.weightPrefetchHits++
+		}
+		chip.TouchWeights(tileID, arrayID, weightTag, this.currentCycle)
+		chip.WeightLoads++
+		chip.WeightLoadHits++
+		if this.statFactory != nil {
+			this.statFactory.Increment("rram_weight_loads_total", 1)
+			this.statFactory.Increment("rram_weight_hits_total", 1)
+		}
+		return
+	}
+	if prefetched {
+		// 预取的块在使用前被逐出，其搬运与编程开销计入 wasted work。
+		this.weightPrefetchWasted++
+		this.wastedCycles += int64(prefetch.cycles)
+		this.wastedEnergyPJ += prefetch.energyPJ
+	}
+
+	if this.loadWeightsFromNeighbor(chipletID, tileID, arrayID, weightTag, weightBytes) {
+		if this.statFactory != nil {
+			this.statFactory.Increment("rram_weight_loads_total", 1)
+		}
+		return
+	}
+	chip.ScheduleWeightLoad(tileID, arrayID, weightTag, weightBytes, this.weightLoadLatency(cmd), this.currentCycle)
+	if this.statFactory != nil {
+		this.statFactory.Increment("rram_weight_loads_total", 1)
+		this.statFactory.Increment("rram_weight_bytes_total", weightBytes)
+	}
+}
+
+// weightLoadLatency returns the latency to book for a host weight load. With
+// --chiplet_rram_weight_load_bw set, the command's own latency is ignored and
+// the chiplet times the load on its dedicated weight path instead.
+func (this *ChipletPlatform) weightLoadLatency(cmd *chiplet.CommandDescriptor) int {
+	if cmd == nil || (this.config != nil && this.config.RramWeightLoadBw > 0) {
+		return 0
+	}
+	return int(cmd.Latency)
+}
+
+// weightLoadBandwidth returns the effective weight-load bandwidth in bytes per cycle.
+func weightLoadBandwidth(bytes, cycles int64) float64 {
+	if cycles <= 0 {
+		return 0
+	}
+	return float64(bytes) / float64(cycles)
+}
+
+// digitalParamsForChiplet 返回第 id 个 digital chiplet 的参数：在共享参数上叠加
+// 模型 JSON topology 段中该 chiplet 的 load/store 带宽覆盖。
+func digitalParamsForChiplet(base digital.Parameters, overrides map[int]chiplet.BufferBandwidth, id int) digital.Parameters {
+	params := base
+	bw, ok := overrides[id]
+	if !ok {
+		return params
+	}
+	if bw.LoadBytesPerCycle != nil {
+		params.PeArray.LoadBandwidthBytesPerCycle = *bw.LoadBytesPerCycle
+	}
+	if bw.StoreBytesPerCycle != nil {
+		params.PeArray.StoreBandwidthBytesPerCycle = *bw.StoreBytesPerCycle
+	}
+	return params
+}
+
+// applyDigitalEnergyCalibration scales the per-operation energy of the PE,
+// SPU and VPU components; per-command-kind factors are applied per task.
+func applyDigitalEnergyCalibration(params *digital.Parameters, factors map[string]float64) {
+	if len(factors) == 0 {
+		return
+	}
+	params.PeArray.MacEnergyPJ *= chiplet.EnergyScale(factors, "pe")
+	spu := chiplet.EnergyScale(factors, "spu")
+	params.Spu.ScalarEnergyPJ *= spu
+	params.Spu.VectorEnergyPJ *= spu
+	params.Spu.SpecialEnergyPJ *= spu
+	params.Vpu.VectorEnergyPJ *= chiplet.EnergyScale(factors, "vpu")
+}
+
+// applyRramEnergyCalibration scales the ADC/DAC components and, because each
+// RRAM pipeline phase is issued by its own command kind, folds the kind
+// factors into the matching phase coefficients.
+func applyRramEnergyCalibration(params *rram.Parameters, factors map[string]float64) {
+	if len(factors) == 0 {
+		return
+	}
+	params.AdcEnergyPJ *= chiplet.EnergyScale(factors, "adc")
+	params.DacEnergyPJ *= chiplet.EnergyScale(factors, "dac")
+
+	params.PreprocessEnergyPJPerCycle *= chiplet.EnergyScale(factors, chiplet.CommandKindRramStageAct.String())
+	execute := chiplet.EnergyScale(factors, chiplet.CommandKindRramExecute.String())
+	params.PulseEnergyPJ *= execute
+	params.DacEnergyPJ *= execute
+	params.AdcEnergyPJ *= execute
+	params.PostprocessEnergyPJPerCycle *= chiplet.EnergyScale(factors, chiplet.CommandKindRramPost.String())
+	load := chiplet.EnergyScale(factors, chiplet.CommandKindRramWeightLoad.String())
+	params.WeightReadEnergyPJPerByte *= load
+	params.ProgramEnergyPJPerBytePulse *= load
+}
+
+// activationResident records activation bytes a host2d transfer placed in a
+// digital chiplet's buffer, and how many of them currently sit on the host
+// after a spill. Spill, reload and drain only ever move bytes against these
+// records, never against reservations made by the chiplet's own tasks.
+type activationResident struct {
+	owner   int
+	bytes   int64
+	spilled int64
+}
+
+// trackActivationResident records bytes of activations owned by owner as
+// resident in chipletID's buffer. Records are only kept while activation
+// offload is enabled, since nothing else reads them.
+func (this *ChipletPlatform) trackActivationResident(chipletID int, owner int, bytes int64) {
+	if this.config == nil || !this.config.ActivationOffload {
+		return
+	}
+	if bytes <= 0 || chipletID < 0 || chipletID >= len(this.activationResidents) {
+		return
+	}
+	this.activationResidents[chipletID] = append(this.activationResidents[chipletID], &activationResident{owner: owner, bytes: bytes})
+}
+
+// spillActivations makes room for an activation reservation of bytes that did
+// not fit by offloading resident activations to host memory over d2host DMA,
+// then retries the reservation. Only bytes owned by earlier host2d transfers
+// are spilled, oldest owner first. It returns false, leaving the buffer as it
+// was, when offload is disabled or the request exceeds what can be spilled.
+func (this *ChipletPlatform) spillActivations(chipletID int, bytes int64) bool {
+	if this.config == nil || !this.config.ActivationOffload {
+		return false
+	}
+	if chipletID < 0 || chipletID >= len(this.digitalChiplets) || chipletID >= len(this.activationSpilled) {
+		return false
+	}
+	chip := this.digitalChiplets[chipletID]
+	if chip == nil {
+		return false
+	}
+	spill := chip.BufferUsage("activation") + bytes - chip.BufferCapacity("activation")
+	if spill <= 0 {
+		// Enough space in total but split across clusters; spill a full request.
+		spill = bytes
+	}
+	resident := int64(0)
+	for _, entry := range this.activationResidents[chipletID] {
+		resident += entry.bytes - entry.spilled
+	}
+	if spill > resident {
+		spill = resident
+	}
+	if spill <= 0 || !chip.AdjustBuffer("Activation", -spill) {
+		return false
+	}
+	if !chip.AdjustBuffer("Activation", bytes) {
+		chip.AdjustBuffer("Activation", spill)
+		return false
+	}
+
+	remaining := spill
+	for _, entry := range this.activationResidents[chipletID] {
+		if remaining <= 0 {
+			break
 		}
-		if this.digitalChiplets[chipletID].SubmitDescriptor(descriptor) {
-			if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
-				this.recordGatingSnapshotFromCommand(chipletID, cmd)
-				this.recordMoeBarrierMetrics(cmd)
-			}
-			this.executedDigitalTasks++
-			if this.statFactory != nil {
-				this.statFactory.Increment("digital_tasks_total", 1)
-				this.statFactory.Increment("digital_load_bytes_total", descriptor.InputBytes+descriptor.WeightBytes)
-				this.statFactory.Increment("digital_store_bytes_total", descriptor.OutputBytes)
-				this.statFactory.Increment("digital_scalar_ops_total", int64(descriptor.ScalarOps))
-				this.statFactory.Increment("digital_vector_ops_total", int64(descriptor.VectorOps))
-			}
-			this.digitalBytesLoaded += descriptor.InputBytes + descriptor.WeightBytes
-			this.digitalBytesStored += descriptor.OutputBytes
-			this.digitalScalarOps += int64(descriptor.ScalarOps)
-			this.digitalVectorOps += int64(descriptor.VectorOps)
-			return
+		moved := entry.bytes - entry.spilled
+		if moved > remaining {
+			moved = remaining
 		}
+		entry.spilled += moved
+		remaining -= moved
 	}
-
-	this.digitalChiplets[chipletID].ScheduleTask(task.Latency)
-	this.executedDigitalTasks++
+	this.activationSpilled[chipletID] += spill
+	this.activationSpillBytes += spill
+	this.activationSpillEvents++
+	this.chargeActivationOffload(host.DMATransferDigitalToHost, spill)
 	if this.statFactory != nil {
-		this.statFactory.Increment("digital_tasks_total", 1)
+		this.statFactory.Increment("activation_spill_bytes_total", spill)
 	}
+	return true
 }
 
-func (this *ChipletPlatform) handleRramTask(task *chiplet.Task) {
-	chipletID, ok := extractChipletID(task.Payload)
-	if !ok || chipletID < 0 || chipletID >= len(this.rramChiplets) {
+// reloadActivations brings previously spilled activations back over host2d
+// DMA once the buffer has room again, oldest owner first.
+func (this *ChipletPlatform) reloadActivations(chipletID int) {
+	if chipletID < 0 || chipletID >= len(this.activationSpilled) || this.activationSpilled[chipletID] <= 0 {
+		return
+	}
+	chip := this.digitalChiplets[chipletID]
+	if chip == nil {
+		return
+	}
+	reload := chip.BufferCapacity("activation") - chip.BufferUsage("activation")
+	if reload > this.activationSpilled[chipletID] {
+		reload = this.activationSpilled[chipletID]
+	}
+	if reload <= 0 || !chip.AdjustBuffer("Activation", reload) {
 		return
 	}
 
-	spec := this.buildRramTaskSpec(task)
-	this.rramChiplets[chipletID].ScheduleTask(task.Latency, spec)
-	this.executedRramTasks++
-	if this.statFactory != nil {
-		this.statFactory.Increment("rram_tasks_total", 1)
-		if spec != nil {
-			if spec.PulseCount > 0 {
-				this.statFactory.Increment("rram_pulse_count_total", int64(spec.PulseCount))
-			}
-			if spec.AdcSamples > 0 {
-				this.statFactory.Increment("rram_adc_samples_total", int64(spec.AdcSamples))
-			}
-			if spec.PreCycles > 0 {
-				this.statFactory.Increment("rram_preprocess_cycles_total", int64(spec.PreCycles))
-			}
-			if spec.PostCycles > 0 {
-				this.statFactory.Increment("rram_postprocess_cycles_total", int64(spec.PostCycles))
-			}
-			if spec.ErrorAbs != 0 {
-				this.statFactory.Increment("rram_error_samples", 1)
-			}
+	remaining := reload
+	for _, entry := range this.activationResidents[chipletID] {
+		if remaining <= 0 {
+			break
 		}
+		moved := entry.spilled
+		if moved > remaining {
+			moved = remaining
+		}
+		entry.spilled -= moved
+		remaining -= moved
+	}
+	this.activationSpilled[chipletID] -= reload
+	this.activationReloadBytes += reload
+	this.chargeActivationOffload(host.DMATransferHostToDigital, reload)
+	if this.statFactory != nil {
+		this.statFactory.Increment("activation_reload_bytes_total", reload)
 	}
+}
 
-	var cmdKind chiplet.CommandKind
-	var stageLabel string
-	var cmdDescriptor *chiplet.CommandDescriptor
-	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
-		cmdKind = cmd.Kind
-		cmdDescriptor = cmd
-	} else if payloadMap, ok := task.Payload.(map[string]interface{}); ok {
-		if s, ok := payloadMap["stage"].(string); ok {
-			stageLabel = strings.ToLower(s)
-		}
+// spilledActivationShare returns how many of bytes a d2host transfer would
+// take from activations already spilled to the host, without touching the
+// resident records. It mirrors the order used by drainActivationResidents.
+func (this *ChipletPlatform) spilledActivationShare(chipletID int, bytes int64) int64 {
+	if chipletID < 0 || chipletID >= len(this.activationResidents) {
+		return 0
+	}
+	onChip, spilled := int64(0), int64(0)
+	for _, entry := range this.activationResidents[chipletID] {
+		onChip += entry.bytes - entry.spilled
+		spilled += entry.spilled
+	}
+	share := bytes - onChip
+	if share > spilled {
+		share = spilled
+	}
+	if share < 0 {
+		return 0
 	}
+	return share
+}
 
-	if cmdKind == chiplet.CommandKindRramStageAct || stageLabel == "stage_act" || stageLabel == "stage" {
-		expectedBytes := int64(spec.ActivationSize)
-		if expectedBytes <= 0 {
-			expectedBytes = this.rramInputBuffered[chipletID]
+// drainActivationResidents retires up to bytes of host2d-owned activations
+// for a d2host transfer. Bytes still in the buffer go first, oldest owner
+// first; only then are spilled bytes, which already live on the host, dropped
+// from the spill record. It returns both amounts.
+func (this *ChipletPlatform) drainActivationResidents(chipletID int, bytes int64) (int64, int64) {
+	if chipletID < 0 || chipletID >= len(this.activationResidents) {
+		return 0, 0
+	}
+	remaining := bytes
+	onChip := int64(0)
+	for _, entry := range this.activationResidents[chipletID] {
+		if remaining <= 0 {
+			break
 		}
-		this.consumeRramInput(chipletID, expectedBytes)
-		outputBytes := int64(spec.OutputSize)
-		if outputBytes <= 0 {
-			outputBytes = expectedBytes
+		take := entry.bytes - entry.spilled
+		if take > remaining {
+			take = remaining
 		}
-		if chipletID >= 0 && chipletID < len(this.rramProcessingBytes) {
-			this.rramProcessingBytes[chipletID] = outputBytes
+		entry.bytes -= take
+		onChip += take
+		remaining -= take
+	}
+	onHost := int64(0)
+	for _, entry := range this.activationResidents[chipletID] {
+		if remaining <= 0 {
+			break
 		}
-		if chipletID >= 0 && chipletID < len(this.rramChiplets) && spec != nil {
-			if chip := this.rramChiplets[chipletID]; chip != nil {
-				tileID, arrayID, weightTag := deriveWeightKey(cmdDescriptor, spec)
-				if _, ok := chip.LookupWeights(tileID, arrayID, weightTag); !ok {
-					chip.RegisterWeights(tileID, arrayID, weightTag, estimateWeightBytes(spec), this.currentCycle)
-				}
-			}
+		take := entry.spilled
+		if take > remaining {
+			take = remaining
 		}
+		entry.spilled -= take
+		entry.bytes -= take
+		onHost += take
+		remaining -= take
 	}
-	if cmdKind == chiplet.CommandKindRramPost || stageLabel == "post" || stageLabel == "rram_post" {
-		outputBytes := int64(spec.OutputSize)
-		if outputBytes <= 0 {
-			outputBytes = this.rramProcessingBytes[chipletID]
+	kept := this.activationResidents[chipletID][:0]
+	for _, entry := range this.activationResidents[chipletID] {
+		if entry.bytes > 0 {
+			kept = append(kept, entry)
 		}
-		this.releaseRramOutputForChiplet(chipletID, outputBytes)
 	}
-	if cmdKind == chiplet.CommandKindRramWeightLoad && chipletID >= 0 && chipletID < len(this.rramChiplets) && spec != nil {
-		if chip := this.rramChiplets[chipletID]; chip != nil {
-			tileID, arrayID, weightTag := deriveWeightKey(cmdDescriptor, spec)
-			weightBytes := estimateWeightBytes(spec)
-			if _, ok := chip.LookupWeights(tileID, arrayID, weightTag); ok {
-				chip.WeightLoads++
-				chip.WeightLoadHits++
-				if this.statFactory != nil {
-					this.statFactory.Increment("rram_weight_loads_total", 1)
-					this.statFactory.Increment("rram_weight_hits_total", 1)
-				}
-			} else {
-				latency := 0
-				if cmdDescriptor != nil {
-					latency = int(cmdDescriptor.Latency)
-				}
-				chip.ScheduleWeightLoad(tileID, arrayID, weightTag, weightBytes, latency, this.currentCycle)
-				if this.statFactory != nil {
-					this.statFactory.Increment("rram_weight_loads_total", 1)
-					this.statFactory.Increment("rram_weight_bytes_total", weightBytes)
-				}
-			}
+	this.activationResidents[chipletID] = kept
+	this.activationSpilled[chipletID] -= onHost
+	return onChip, onHost
+}
+
+// chargeActivationOffload books a spill or reload on the host DMA engine and
+// stalls the interconnect for its estimated duration.
+func (this *ChipletPlatform) chargeActivationOffload(direction host.DMATransferKind, bytes int64) {
+	if direction == host.DMATransferHostToDigital {
+		this.cycleHostDmaLoadBytes += bytes
+		this.hostDmaLoadBytesTotal += bytes
+	} else {
+		this.cycleHostDmaStoreBytes += bytes
+		this.hostDmaStoreBytesTotal += bytes
+	}
+	if this.hostDmaController == nil {
+		return
+	}
+	this.hostDmaController.Record(direction, bytes, 1)
+	if estimated := this.hostDmaController.EstimateCycles(this.eccWireBytes(bytes), 1, nil); estimated > 0 {
+		this.occupyInterconnect(estimated)
+		this.activationOffloadDma += int64(estimated)
+	}
+}
+
+// loadWeightsFromNeighbor serves a weight-load miss from an adjacent RRAM
+// chiplet that already holds the tag. The copy is charged as an RRAM-to-RRAM
+// interconnect transfer instead of a host load; returns false when no
+// neighbor holds the weights or sharing is disabled.
+func (this *ChipletPlatform) loadWeightsFromNeighbor(chipletID, tileID, arrayID int, tag string, bytes int64) bool {
+	if this.config == nil || !this.config.RramWeightNeighborShare || this.topology == nil {
+		return false
+	}
+	if chipletID < 0 || chipletID >= len(this.rramChiplets) {
+		return false
+	}
+	dst := this.rramChiplets[chipletID]
+	if dst == nil {
+		return false
+	}
+
+	for _, neighborID := range this.topology.RramNeighbors(chipletID) {
+		if neighborID < 0 || neighborID >= len(this.rramChiplets) {
+			continue
+		}
+		src := this.rramChiplets[neighborID]
+		if src == nil {
+			continue
+		}
+		record, ok := src.LookupWeights(tileID, arrayID, tag)
+		if !ok {
+			continue
+		}
+		if record != nil && record.Bytes > 0 {
+			bytes = record.Bytes
+		}
+		hops := this.topology.RramHopDistance(neighborID, chipletID)
+		if hops <= 0 {
+			hops = 1
+		}
+		energyBytes := hopWeightedBytes(bytes, hops)
+		src.AddOutputTransferEnergy(energyBytes)
+		dst.AddInputTransferEnergy(energyBytes)
+
+		bandwidth := int64(0)
+		if this.config != nil {
+			bandwidth = this.config.TransferBandwidthRd
 		}
+		latency := this.applyTransferFloor(estimateTransferCycles(bytes, bandwidth, hops))
+		dst.ScheduleWeightLoad(tileID, arrayID, tag, bytes, latency, this.currentCycle)
+
+		this.weightNeighborHits++
+		this.weightNeighborBytes += bytes
+		if this.statFactory != nil {
+			this.statFactory.Increment("rram_weight_neighbor_hits", 1)
+			this.statFactory.Increment("rram_weight_neighbor_bytes", bytes)
+		}
+		return true
 	}
+	return false
 }
 
 func (this *ChipletPlatform) handleHostTask(task *chiplet.Task) {
@@ -1436,12 +3821,39 @@ func (t *transferAdjustmentTracker) addState(kind stateKind, index int, delta in
 	})
 }
 
-func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
+// transferTaskBytes 返回传输任务搬运的字节数：命令的 PayloadBytes 或 map 负载的
+// "bytes"，缺省 1024。
+func transferTaskBytes(task *chiplet.Task) int64 {
+	bytes := int64(1024)
 	if task == nil {
-		return
+		return bytes
+	}
+	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
+		if cmd.PayloadBytes > 0 {
+			bytes = int64(cmd.PayloadBytes)
+		}
+	} else if payload, ok := task.Payload.(map[string]interface{}); ok {
+		if value, ok := payload["bytes"]; ok {
+			if iv, ok := toInt(value); ok && iv > 0 {
+				bytes = int64(iv)
+			} else if fv, ok := value.(float64); ok && fv > 0 {
+				bytes = int64(fv)
+			}
+		}
+	}
+	if bytes < 0 {
+		bytes = 0
+	}
+	return bytes
+}
+
+// handleTransferTask 执行一次传输，返回其退休是否推迟到链路上传完为止（见 issueTransfer）。
+func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) bool {
+	if task == nil {
+		return false
 	}
 
-	bytes := int64(1024)
+	bytes := transferTaskBytes(task)
 	stage := "transfer_to_rram"
 	var payloadMap map[string]interface{}
 
@@ -1453,9 +3865,6 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 	meta := cmdMetadata(task.Payload)
 
 	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil {
-		if cmd.PayloadBytes > 0 {
-			bytes = int64(cmd.PayloadBytes)
-		}
 		switch cmd.Kind {
 		case chiplet.CommandKindTransferHost2D:
 			stage = "transfer_host2d"
@@ -1492,13 +3901,6 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 		}
 	} else if payload, ok := task.Payload.(map[string]interface{}); ok {
 		payloadMap = payload
-		if value, ok := payload["bytes"]; ok {
-			if iv, ok := toInt(value); ok && iv > 0 {
-				bytes = int64(iv)
-			} else if fv, ok := value.(float64); ok && fv > 0 {
-				bytes = int64(fv)
-			}
-		}
 		if s, ok := payload["stage"].(string); ok && s != "" {
 			stage = strings.ToLower(s)
 		}
@@ -1529,10 +3931,6 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 		}
 	}
 
-	if bytes < 0 {
-		bytes = 0
-	}
-
 	stageLower := strings.ToLower(stage)
 	adjustments := transferAdjustmentTracker{}
 	success := true
@@ -1600,7 +3998,9 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 				success = false
 				this.rramDeferrals[dstRramIndex]++
 				this.rramSaturation[dstRramIndex]++
+				this.rramReserveBlocked[dstRramIndex] = true
 			} else {
+				this.rramReserveBlocked[dstRramIndex] = false
 				adjustments.addBuffer(bufferKindRram, dstRramIndex, "input", bytes)
 				if dstRramIndex >= 0 && dstRramIndex < len(this.rramInputBuffered) {
 					this.rramInputBuffered[dstRramIndex] += bytes
@@ -1671,7 +4071,9 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 			success = false
 			this.digitalDeferrals[dstDigitalIndex]++
 			this.digitalSaturation[dstDigitalIndex]++
+			this.digitalReserveBlocked[dstDigitalIndex] = true
 		} else {
+			this.digitalReserveBlocked[dstDigitalIndex] = false
 			adjustments.addBuffer(bufferKindDigital, dstDigitalIndex, "scratch", bytes)
 		}
 	case "transfer_host2d":
@@ -1690,14 +4092,17 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 			failureReason = fmt.Sprintf("digital_target_missing chiplet=%d", dstDigitalIndex)
 			break
 		}
-		if !this.digitalChiplets[dstDigitalIndex].AdjustBuffer("Activation", bytes) {
+		if !this.digitalChiplets[dstDigitalIndex].AdjustBuffer("Activation", bytes) && !this.spillActivations(dstDigitalIndex, bytes) {
 			usage := this.digitalChiplets[dstDigitalIndex].BufferUsage("activation")
 			failureReason = fmt.Sprintf("digital_activation_reserve_fail chiplet=%d bytes=%d usage=%d", dstDigitalIndex, bytes, usage)
 			success = false
 			this.digitalDeferrals[dstDigitalIndex]++
 			this.digitalSaturation[dstDigitalIndex]++
+			this.digitalReserveBlocked[dstDigitalIndex] = true
 		} else {
+			this.digitalReserveBlocked[dstDigitalIndex] = false
 			adjustments.addBuffer(bufferKindDigital, dstDigitalIndex, "activation", bytes)
+			this.trackActivationResident(dstDigitalIndex, task.NodeID, bytes)
 		}
 	case "transfer_d2host":
 		if payloadMap != nil {
@@ -1717,16 +4122,20 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 		}
 		if chip := this.digitalChiplets[srcDigitalIndex]; chip != nil {
 			usage := chip.BufferUsage("activation")
-			release := bytes
+			// 已溢出到主机的数据不在 buffer 中，只释放仍驻留片上的字节；
+			// 驻留记录在释放成功后才更新，失败重试时记录保持原样。
+			release := bytes - this.spilledActivationShare(srcDigitalIndex, bytes)
 			if usage < release {
 				release = usage
 			}
-			if release > 0 {
-				if !chip.AdjustBuffer("Activation", -release) {
-					failureReason = fmt.Sprintf("digital_activation_release_fail chiplet=%d release=%d usage=%d", srcDigitalIndex, release, usage)
-					success = false
-				} else {
+			if release > 0 && !chip.AdjustBuffer("Activation", -release) {
+				failureReason = fmt.Sprintf("digital_activation_release_fail chiplet=%d release=%d usage=%d", srcDigitalIndex, release, usage)
+				success = false
+			} else {
+				this.drainActivationResidents(srcDigitalIndex, bytes)
+				if release > 0 {
 					adjustments.addBuffer(bufferKindDigital, srcDigitalIndex, "activation", -release)
+					this.reloadActivations(srcDigitalIndex)
 				}
 			}
 		}
@@ -1740,6 +4149,7 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 			failureReason = "unspecified"
 		}
 		fmt.Printf("[chiplet-debug] transfer stage=%s failed bytes=%d reason=%s\n", stageLower, bytes, failureReason)
+		this.transferDroppedBytes += bytes
 		this.transferThrottleUntil += 2
 		this.transferThrottleEvents++
 		this.cycleThrottleEvents++
@@ -1748,7 +4158,7 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 			this.statFactory.Increment("transfer_buffer_saturation", 1)
 			this.statFactory.Increment("transfer_throttle_events_total", 1)
 		}
-		return
+		return false
 	}
 
 	this.handleKvAccess(stageLower, bytes, meta)
@@ -1780,14 +4190,18 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 		this.totalTransferHostStoreBytes += bytes
 	}
 
-	energyBytes := hopWeightedBytes(bytes, hopCount)
+	linkBefore := this.transferThrottleUntil
+	// 链路上额外携带的 ECC/校验字节只影响带宽与能耗，缓冲区占用仍按有效载荷计。
+	wireBytes := this.eccWireBytes(bytes)
+	this.eccOverheadBytes += wireBytes - bytes
+	energyBytes := hopWeightedBytes(wireBytes, hopCount)
 	fmt.Printf("[chiplet-debug] transfer stage=%s bytes=%d hops=%d srcDigital=%d dstDigital=%d srcRram=%d dstRram=%d\n",
 		stageLower, bytes, hopCount, srcDigitalIndex, dstDigitalIndex, srcRramIndex, dstRramIndex)
 	switch stageLower {
 	case "transfer_to_rram":
 		if srcDigitalIndex >= 0 && srcDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[srcDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		if dstRramIndex >= 0 && dstRramIndex < len(this.rramChiplets) {
@@ -1795,14 +4209,20 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 				chip.AddInputTransferEnergy(energyBytes)
 			}
 		}
-		estimated := this.estimateNocCycles(stageLower, bytes, hopCount, srcDigitalIndex, dstRramIndex, srcRramIndex, dstDigitalIndex, meta)
-		if estimated > 0 {
-			this.transferThrottleUntil += estimated
+		estimated := this.estimateNocCycles(stageLower, wireBytes, hopCount, srcDigitalIndex, dstRramIndex, srcRramIndex, dstDigitalIndex, meta)
+		estimated = this.shareDuplexLink(stageLower, srcDigitalIndex, dstRramIndex, estimated)
+		estimated = this.pipelineTransferCycles(task, estimated)
+		estimated = this.collectiveTransferCycles(meta, estimated)
+		this.occupyInterconnect(estimated)
+		if dstRramIndex >= 0 && dstRramIndex < len(this.rramChiplets) {
+			if chip := this.rramChiplets[dstRramIndex]; chip != nil {
+				chip.ReserveActivationPath(this.currentCycle, estimated)
+			}
 		}
 	case "transfer_to_digital":
 		if dstDigitalIndex >= 0 && dstDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[dstDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		if srcRramIndex >= 0 && srcRramIndex < len(this.rramChiplets) {
@@ -1810,27 +4230,41 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 				chip.AddOutputTransferEnergy(energyBytes)
 			}
 		}
-		estimated := this.estimateNocCycles(stageLower, bytes, hopCount, srcDigitalIndex, dstRramIndex, srcRramIndex, dstDigitalIndex, meta)
-		if estimated > 0 {
-			this.transferThrottleUntil += estimated
-		}
+		estimated := this.estimateNocCycles(stageLower, wireBytes, hopCount, srcDigitalIndex, dstRramIndex, srcRramIndex, dstDigitalIndex, meta)
+		estimated = this.shareDuplexLink(stageLower, dstDigitalIndex, srcRramIndex, estimated)
+		estimated = this.collectiveTransferCycles(meta, estimated)
+		this.occupyInterconnect(estimated)
 	case "transfer_host2d":
 		if dstDigitalIndex >= 0 && dstDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[dstDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 	case "transfer_d2host":
 		if srcDigitalIndex >= 0 && srcDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[srcDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 	}
 
+	if cmd, ok := task.Payload.(*chiplet.CommandDescriptor); ok && cmd != nil && cmd.Kind == chiplet.CommandKindTransferSchedule {
+		if this.statFactory != nil {
+			this.statFactory.Increment("transfer_schedule_commands", 1)
+		}
+		// 描述符编程开销：DMA 引擎在数据搬运之外额外占用的固定周期。
+		if this.config != nil && this.config.TransferScheduleCycles > 0 {
+			overhead := this.config.TransferScheduleCycles
+			this.occupyInterconnect(overhead)
+			this.transferScheduleCycles += int64(overhead)
+		}
+	}
+
+	this.recordTransferSize(stageLower, bytes)
 	this.executedTransferTasks++
 	this.cycleTransferBytes += bytes
 	this.totalTransferBytes += bytes
+	this.transferCompletedBytes += bytes
 	this.cycleTransferHops += hopCount
 	this.totalTransferHops += int64(hopCount)
 
@@ -1838,7 +4272,7 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 	case "transfer_to_rram":
 		if srcDigitalIndex >= 0 && srcDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[srcDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		if dstRramIndex >= 0 && dstRramIndex < len(this.rramChiplets) {
@@ -1849,7 +4283,7 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 	case "transfer_to_digital":
 		if dstDigitalIndex >= 0 && dstDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[dstDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		if srcRramIndex >= 0 && srcRramIndex < len(this.rramChiplets) {
@@ -1860,35 +4294,31 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 	case "transfer_host2d":
 		if dstDigitalIndex >= 0 && dstDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[dstDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		this.cycleHostDmaLoadBytes += bytes
 		this.hostDmaLoadBytesTotal += bytes
 		estimated := 0
 		if this.hostDmaController != nil {
-			this.hostDmaController.Record(host.DMATransferHostToDigital, bytes, hopCount)
-			estimated = this.hostDmaController.EstimateCycles(bytes, hopCount, meta)
-		}
-		if estimated > 0 {
-			this.transferThrottleUntil += estimated
+			this.hostDmaController.Record(host.DMATransferHostToDigital, wireBytes, hopCount)
+			estimated = this.hostDmaController.EstimateCycles(wireBytes, hopCount, meta)
 		}
+		this.occupyInterconnect(estimated)
 	case "transfer_d2host":
 		if srcDigitalIndex >= 0 && srcDigitalIndex < len(this.digitalChiplets) {
 			if chip := this.digitalChiplets[srcDigitalIndex]; chip != nil {
-				chip.AddInterconnectEnergy(energyBytes)
+				this.chargeInterconnectEnergy(stageLower, chip, energyBytes)
 			}
 		}
 		this.cycleHostDmaStoreBytes += bytes
 		this.hostDmaStoreBytesTotal += bytes
 		estimated := 0
 		if this.hostDmaController != nil {
-			this.hostDmaController.Record(host.DMATransferDigitalToHost, bytes, hopCount)
-			estimated = this.hostDmaController.EstimateCycles(bytes, hopCount, meta)
-		}
-		if estimated > 0 {
-			this.transferThrottleUntil += estimated
+			this.hostDmaController.Record(host.DMATransferDigitalToHost, wireBytes, hopCount)
+			estimated = this.hostDmaController.EstimateCycles(wireBytes, hopCount, meta)
 		}
+		this.occupyInterconnect(estimated)
 	}
 
 	if this.statFactory != nil {
@@ -1910,12 +4340,81 @@ func (this *ChipletPlatform) handleTransferTask(task *chiplet.Task) {
 			this.statFactory.Increment("transfer_d2host_hops_total", int64(hopCount))
 		}
 	}
+
+	return this.issueTransfer(task, bytes, this.transferThrottleUntil-linkBefore)
+}
+
+// transferInflightBytes 是已提交但尚未被 handleTransferTask 完成（或因缓冲饱和
+// 丢弃）的传输字节数；持续增长说明互连跟不上发射速度。
+func (this *ChipletPlatform) transferInflightBytes() int64 {
+	inflight := this.transferIssuedBytes - this.transferCompletedBytes - this.transferDroppedBytes
+	if inflight < 0 {
+		return 0
+	}
+	return inflight
+}
+
+// duplexLinkKey 标识一条 digital↔RRAM 链路，两个方向共用同一个 key。
+type duplexLinkKey struct {
+	digital int
+	rram    int
+}
+
+type duplexLinkState struct {
+	busyUntil int
+	stage     string
+}
+
+func (this *ChipletPlatform) linkDuplexMode() string {
+	if this.config != nil && this.config.HalfDuplex != 0 {
+		return "half"
+	}
+	return "full"
+}
+
+// shareDuplexLink returns the cycles a digital↔RRAM transfer occupies its
+// link. Full-duplex links give each direction its own bandwidth, so the
+// estimate is returned unchanged. With --chiplet_half_duplex both directions
+// share one budget: a transfer that finds the link busy in the opposite
+// direction waits for it to turn around, and that wait is counted as
+// upstream/downstream contention.
+func (this *ChipletPlatform) shareDuplexLink(stage string, digitalID int, rramID int, cycles int) int {
+	if this.config == nil || this.config.HalfDuplex == 0 || cycles <= 0 || digitalID < 0 || rramID < 0 {
+		return cycles
+	}
+	if this.duplexLinks == nil {
+		this.duplexLinks = make(map[duplexLinkKey]*duplexLinkState)
+	}
+	key := duplexLinkKey{digital: digitalID, rram: rramID}
+	link, ok := this.duplexLinks[key]
+	if !ok {
+		link = &duplexLinkState{}
+		this.duplexLinks[key] = link
+	}
+
+	wait := 0
+	if link.busyUntil > this.currentCycle && link.stage != stage {
+		wait = link.busyUntil - this.currentCycle
+		this.duplexContentionEvents++
+		this.duplexContentionCycles += int64(wait)
+	}
+	end := this.currentCycle + wait + cycles
+	if link.stage != stage || end > link.busyUntil {
+		link.busyUntil = end
+	}
+	link.stage = stage
+	return wait + cycles
 }
 
 func (this *ChipletPlatform) estimateNocCycles(stage string, bytes int64, hops int, srcDigital int, dstRram int, srcRram int, dstDigital int, meta map[string]interface{}) int {
 	if bytes <= 0 {
 		return 0
 	}
+	return this.applyTransferFloor(this.estimateRawNocCycles(stage, bytes, hops, srcDigital, dstRram, srcRram, dstDigital, meta))
+}
+
+// estimateRawNocCycles 优先使用 BookSim 估计，失败时退回带宽模型；结果未施加最小延迟下限。
+func (this *ChipletPlatform) estimateRawNocCycles(stage string, bytes int64, hops int, srcDigital int, dstRram int, srcRram int, dstDigital int, meta map[string]interface{}) int {
 
 	stageLower := strings.ToLower(stage)
 	bandwidth := int64(0)
@@ -1930,36 +4429,113 @@ func (this *ChipletPlatform) estimateNocCycles(stage string, bytes int64, hops i
 
 	fallback := estimateTransferCycles(bytes, bandwidth, hops)
 
+	if cycles, ok := this.booksimNocCycles(stageLower, bytes, srcDigital, dstRram, srcRram, dstDigital, meta); ok {
+		this.booksimEstimated++
+		return cycles
+	}
+	// 请求了 BookSim 却落回带宽模型（节点映射失败、超时、失败后被禁用）计入 fallback。
+	if this.config != nil && this.config.NocUseBooksim {
+		this.booksimFallbacks++
+	}
+	return fallback
+}
+
+// booksimNocCycles 返回 BookSim 对该传输的估计；客户端不可用、节点映射失败或估计无效时 ok 为 false。
+func (this *ChipletPlatform) booksimNocCycles(stageLower string, bytes int64, srcDigital int, dstRram int, srcRram int, dstDigital int, meta map[string]interface{}) (int, bool) {
 	client := this.booksimClient
 	if client == nil || !client.Enabled() {
-		return fallback
+		return 0, false
 	}
 
 	totalDigital := len(this.digitalChiplets)
 	totalRram := len(this.rramChiplets)
 
+	srcNode, dstNode := -1, -1
 	switch stageLower {
 	case "transfer_to_rram":
-		srcNode := this.nocDigitalNodeID(srcDigital, totalDigital)
-		dstNode := this.nocRramNodeID(dstRram, totalDigital, totalRram)
-		if srcNode < 0 || dstNode < 0 {
-			return fallback
-		}
-		if cycles, ok := client.Estimate(srcNode, dstNode, bytes, meta); ok && cycles > 0 {
-			return cycles
-		}
+		srcNode = this.nocDigitalNodeID(srcDigital, totalDigital)
+		dstNode = this.nocRramNodeID(dstRram, totalDigital, totalRram)
 	case "transfer_to_digital":
-		srcNode := this.nocRramNodeID(srcRram, totalDigital, totalRram)
-		dstNode := this.nocDigitalNodeID(dstDigital, totalDigital)
-		if srcNode < 0 || dstNode < 0 {
-			return fallback
-		}
-		if cycles, ok := client.Estimate(srcNode, dstNode, bytes, meta); ok && cycles > 0 {
-			return cycles
+		srcNode = this.nocRramNodeID(srcRram, totalDigital, totalRram)
+		dstNode = this.nocDigitalNodeID(dstDigital, totalDigital)
+	}
+	if srcNode < 0 || dstNode < 0 {
+		return 0, false
+	}
+	if cycles, ok := this.booksimEstimate(client, srcNode, dstNode, bytes, meta); ok && cycles > 0 {
+		return cycles, true
+	}
+	return 0, false
+}
+
+// booksimEstimate 经由估算缓存调用 BookSim：相同 (src,dst,字节桶) 的查询在 TTL
+// 内直接复用缓存结果，只有未命中时才真正访问外部服务。
+func (this *ChipletPlatform) booksimEstimate(client *booksim.Client, src, dst int, bytes int64, meta map[string]interface{}) (int, bool) {
+	if this.booksimCache == nil {
+		this.booksimCalls++
+		return client.Estimate(src, dst, bytes, meta)
+	}
+	key, rounded := this.booksimCache.Key(src, dst, bytes)
+	if cycles, ok := this.booksimCache.Get(key, this.currentCycle); ok {
+		return cycles, true
+	}
+	this.booksimCalls++
+	cycles, ok := client.Estimate(src, dst, rounded, meta)
+	if ok && cycles > 0 {
+		this.booksimCache.Put(key, cycles, this.currentCycle)
+	}
+	return cycles, ok
+}
+
+func (this *ChipletPlatform) recordTransferSize(stage string, bytes int64) {
+	if this.transferSizeHist == nil {
+		return
+	}
+	key := transferSizeKey{
+		stage:  strings.TrimPrefix(stage, "transfer_"),
+		bucket: transferSizeBucket(bytes),
+	}
+	this.transferSizeHist[key]++
+}
+
+// transferSizeBucket rounds bytes up to the next power of two (minimum 1).
+func transferSizeBucket(bytes int64) int64 {
+	bucket := int64(1)
+	for bucket < bytes {
+		bucket <<= 1
+	}
+	return bucket
+}
+
+func (this *ChipletPlatform) transferSizeHistLines() []string {
+	keys := make([]transferSizeKey, 0, len(this.transferSizeHist))
+	for key := range this.transferSizeHist {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].stage != keys[j].stage {
+			return keys[i].stage < keys[j].stage
 		}
+		return keys[i].bucket < keys[j].bucket
+	})
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("ChipletPlatform_transfer_size_hist_%s_%d: %d", key.stage, key.bucket, this.transferSizeHist[key]))
 	}
+	return lines
+}
 
-	return fallback
+// applyTransferFloor 将传输周期抬高到 --chiplet_transfer_min_latency，
+// 并统计下限生效的次数，以便判断该下限是否真正约束了结果。
+func (this *ChipletPlatform) applyTransferFloor(cycles int) int {
+	if this.config == nil || this.config.TransferMinLatency <= 0 {
+		return cycles
+	}
+	if cycles >= this.config.TransferMinLatency {
+		return cycles
+	}
+	this.transferFloorHits++
+	return this.config.TransferMinLatency
 }
 
 func estimateTransferCycles(bytes int64, bandwidth int64, hops int) int {
@@ -1986,7 +4562,20 @@ func (this *ChipletPlatform) handleKvAccess(stage string, bytes int64, meta map[
 		return
 	}
 
-	result := this.kvCache.Access(op, info, bytes)
+	groupSize := 1
+	if this.config != nil {
+		groupSize = this.config.GqaGroupSize
+	}
+	groupSize = metadataInt(meta, "gqa_group_size", groupSize)
+	if groupSize > 1 && info.Head >= 0 {
+		// GQA：同组的 query head 共享同一个 KV head。影子缓存按未分组的
+		// query head 建模，用于对比分组带来的命中率变化。
+		this.accessKvShadow(op, info, bytes)
+		info.Head /= groupSize
+	}
+
+	result := this.kvCacheForHead(info.Head).Access(op, info, bytes)
+	this.recordKvHeadAccess(info.Head, result.Hit)
 
 	switch result.Op {
 	case host.KVCacheOpLoad:
@@ -2037,6 +4626,232 @@ func (this *ChipletPlatform) handleKvAccess(stage string, bytes int64, meta map[
 	}
 }
 
+// kvHeadCounter 记录单个 KV head 的命中/未命中次数。
+type kvHeadCounter struct {
+	hits   int64
+	misses int64
+}
+
+// kvCacheForHead 返回 KV head 对应的缓存区域。配置了 --chiplet_kv_heads 时，
+// 每个 KV head 拥有容量均分的独立 LRU 区域，互不驱逐；否则共用整块缓存。
+func (this *ChipletPlatform) kvCacheForHead(head int) *host.KVCache {
+	if this.config == nil || this.config.KvHeads <= 0 || head < 0 || this.kvHeadCaches == nil {
+		return this.kvCache
+	}
+	region := head % this.config.KvHeads
+	if cache, ok := this.kvHeadCaches[region]; ok {
+		return cache
+	}
+	cache := host.NewKVCache(this.config.KvCacheBytes / int64(this.config.KvHeads))
+	if cache == nil {
+		return this.kvCache
+	}
+	this.kvHeadCaches[region] = cache
+	return cache
+}
+
+func (this *ChipletPlatform) recordKvHeadAccess(head int, hit bool) {
+	if head < 0 || this.kvHeadStats == nil {
+		return
+	}
+	counter, ok := this.kvHeadStats[head]
+	if !ok {
+		counter = &kvHeadCounter{}
+		this.kvHeadStats[head] = counter
+	}
+	if hit {
+		counter.hits++
+	} else {
+		counter.misses++
+	}
+}
+
+func (this *ChipletPlatform) accessKvShadow(op host.KVCacheOp, info host.KVAccessInfo, bytes int64) {
+	if this.kvShadowCache == nil && this.config != nil {
+		this.kvShadowCache = host.NewKVCache(this.config.KvCacheBytes)
+	}
+	if this.kvShadowCache == nil {
+		return
+	}
+	if this.kvShadowCache.Access(op, info, bytes).Hit {
+		this.kvShadowHits++
+	} else {
+		this.kvShadowMisses++
+	}
+}
+
+// kvHeadLines 输出每个 KV head 的命中率，以及 GQA 分组前后的整体命中率对比。
+func (this *ChipletPlatform) kvHeadLines() []string {
+	heads := make([]int, 0, len(this.kvHeadStats))
+	for head := range this.kvHeadStats {
+		heads = append(heads, head)
+	}
+	sort.Ints(heads)
+	lines := make([]string, 0, len(heads)+3)
+	for _, head := range heads {
+		counter := this.kvHeadStats[head]
+		lines = append(lines, fmt.Sprintf("ChipletPlatform_kv_head[%d]_hit_rate: %.4f", head, hitRate(counter.hits, counter.misses)))
+	}
+	if this.kvShadowCache != nil {
+		lines = append(lines,
+			fmt.Sprintf("ChipletPlatform_kv_gqa_hit_rate: %.4f", hitRate(this.kvCacheHits, this.kvCacheMisses)),
+			fmt.Sprintf("ChipletPlatform_kv_ungrouped_hit_rate: %.4f", hitRate(this.kvShadowHits, this.kvShadowMisses)),
+		)
+	}
+	return lines
+}
+
+// 互连能效按搬运方向分桶：digital→RRAM、RRAM→digital、host DMA（load+store）。
+const (
+	interconnectStageToRram = iota
+	interconnectStageToDigital
+	interconnectStageHost
+	interconnectStageCount
+)
+
+var interconnectStageNames = [interconnectStageCount]string{"to_rram", "to_digital", "host"}
+
+func interconnectStage(stage string) (int, bool) {
+	switch stage {
+	case "transfer_to_rram":
+		return interconnectStageToRram, true
+	case "transfer_to_digital":
+		return interconnectStageToDigital, true
+	case "transfer_host2d", "transfer_d2host":
+		return interconnectStageHost, true
+	}
+	return 0, false
+}
+
+// chargeInterconnectEnergy 向 chip 记入互连能耗，并把增量归到 stage 对应的分桶。
+func (this *ChipletPlatform) chargeInterconnectEnergy(stage string, chip *digital.Chiplet, bytes int64) {
+	before := chip.InterconnectEnergyPJ
+	chip.AddInterconnectEnergy(bytes)
+	if bucket, ok := interconnectStage(stage); ok {
+		this.interconnectStagePJ[bucket] += chip.InterconnectEnergyPJ - before
+	}
+}
+
+// interconnectEfficiencyLines 输出每 pJ 互连能耗搬运的字节数，整体及按方向细分；能耗为 0 时输出 0。
+func (this *ChipletPlatform) interconnectEfficiencyLines() []string {
+	totalPJ := 0.0
+	for _, chip := range this.digitalChiplets {
+		if chip != nil {
+			totalPJ += chip.InterconnectEnergyPJ
+		}
+	}
+	stageBytes := [interconnectStageCount]int64{
+		this.totalTransferToRramBytes,
+		this.totalTransferToDigitalBytes,
+		this.totalTransferHostLoadBytes + this.totalTransferHostStoreBytes,
+	}
+	lines := []string{fmt.Sprintf("ChipletPlatform_bytes_per_interconnect_pj: %.4f", bytesPerPJ(this.totalTransferBytes, totalPJ))}
+	for bucket, name := range interconnectStageNames {
+		lines = append(lines, fmt.Sprintf("ChipletPlatform_bytes_per_interconnect_pj[%s]: %.4f", name, bytesPerPJ(stageBytes[bucket], this.interconnectStagePJ[bucket])))
+	}
+	return lines
+}
+
+func bytesPerPJ(bytes int64, energyPJ float64) float64 {
+	if energyPJ <= 0 {
+		return 0
+	}
+	return float64(bytes) / energyPJ
+}
+
+// usefulCycleFraction 返回 digital chiplet 忙碌周期中 compute/SPU/VPU 有进展的比例；
+// 比例偏低说明该 chiplet 主要耗在 load/store 上，属于访存受限。
+func usefulCycleFraction(chip *digital.Chiplet) float64 {
+	if chip == nil || chip.BusyCycles <= 0 {
+		return 0
+	}
+	return float64(chip.UsefulBusyCycles) / float64(chip.BusyCycles)
+}
+
+func (this *ChipletPlatform) transposeStridePenalty() float64 {
+	if this.config == nil {
+		return 1
+	}
+	return this.config.TransposeStridePenalty
+}
+
+// recordTranspose 记录转置的 load/store 周期，以及相对同字节连续拷贝多出的跨步折损周期。
+func (this *ChipletPlatform) recordTranspose(chip *digital.Chiplet, desc *digital.TaskDescriptor) {
+	if chip == nil || desc == nil {
+		return
+	}
+	cycles := chip.EstimateMemoryCycles(desc)
+	contiguous := *desc
+	contiguous.StrideFactor = 0
+	this.transposeTasks++
+	this.transposeCycles += int64(cycles)
+	this.transposePenaltyCycles += int64(cycles - chip.EstimateMemoryCycles(&contiguous))
+}
+
+// digitalOps 返回 digital chiplet 完成的运算数：每个 MAC 计 2 次，再加 SPU 标量/向量/特殊运算。
+func digitalOps(chip *digital.Chiplet) int64 {
+	if chip == nil {
+		return 0
+	}
+	return 2*chip.TotalMacs + chip.SpuScalarOps + chip.SpuVectorOps + chip.SpuSpecialOps
+}
+
+// digitalEnergyPJ 返回 digital chiplet 的动态、静态与互连能耗之和。
+func digitalEnergyPJ(chip *digital.Chiplet) float64 {
+	if chip == nil {
+		return 0
+	}
+	return chip.DynamicEnergyPJ + chip.StaticEnergyPJ + chip.InterconnectEnergyPJ
+}
+
+// rramMacEstimate 由 ADC 采样数估算 RRAM chiplet 完成的 MAC：每次采样读出一列上
+// SaRows 行的累加结果。
+func (this *ChipletPlatform) rramMacEstimate(stats rram.Stats) int64 {
+	rows := 0
+	if this.config != nil {
+		rows = this.config.RramSaRows
+	}
+	if rows <= 0 || stats.TotalAdcSamples <= 0 {
+		return 0
+	}
+	return stats.TotalAdcSamples * int64(rows)
+}
+
+func opsPerPJ(ops int64, energyPJ float64) float64 {
+	if ops <= 0 || energyPJ <= 0 {
+		return 0
+	}
+	return float64(ops) / energyPJ
+}
+
+// rramBandwidthLines 输出 RRAM chiplet 在忙碌周期内实际达到的输入消耗/输出产出带宽
+// （字节/周期），用于区分 ADC 受限与缓冲传输受限。
+func (this *ChipletPlatform) rramBandwidthLines(chipletID int, busyCycles int) []string {
+	if chipletID < 0 || chipletID >= len(this.rramInputConsumed) || chipletID >= len(this.rramOutputProduced) {
+		return nil
+	}
+	input := this.rramInputConsumed[chipletID]
+	output := this.rramOutputProduced[chipletID]
+	inputBw, outputBw := 0.0, 0.0
+	if busyCycles > 0 {
+		inputBw = float64(input) / float64(busyCycles)
+		outputBw = float64(output) / float64(busyCycles)
+	}
+	return []string{
+		fmt.Sprintf("RramChiplet[%d]_input_bytes: %d", chipletID, input),
+		fmt.Sprintf("RramChiplet[%d]_output_bytes: %d", chipletID, output),
+		fmt.Sprintf("RramChiplet[%d]_input_bw: %.4f", chipletID, inputBw),
+		fmt.Sprintf("RramChiplet[%d]_output_bw: %.4f", chipletID, outputBw),
+	}
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses <= 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
 func (this *ChipletPlatform) consumeRramInput(chipletID int, bytes int64) {
 	if chipletID < 0 || chipletID >= len(this.rramChiplets) {
 		return
@@ -2076,6 +4891,9 @@ func (this *ChipletPlatform) consumeRramInput(chipletID int, bytes int64) {
 			this.rramInputBuffered[chipletID] = 0
 		}
 		this.rramProcessingBytes[chipletID] += consumed
+		if chipletID < len(this.rramInputConsumed) {
+			this.rramInputConsumed[chipletID] += consumed
+		}
 	}
 }
 
@@ -2096,6 +4914,43 @@ func (this *ChipletPlatform) releaseRramOutputForChiplet(chipletID int, bytes in
 		return
 	}
 
+	if this.config != nil && this.config.RramOutputWriteBw > 0 && chipletID < len(this.rramOutputPending) {
+		// 输出缓冲写带宽受限：post 结果先排队，由 RRAM 域每个 tick 按带宽写入。
+		this.rramOutputPending[chipletID] += produce
+		return
+	}
+	this.writeRramOutput(chipletID, produce)
+}
+
+// drainRramOutputWrites 在一个 RRAM tick 内按 --chiplet_rram_output_write_bw
+// 把排队的 post 结果写入输出缓冲；写不完的部分留到后续 tick，并计为受限周期。
+func (this *ChipletPlatform) drainRramOutputWrites(chipletID int) {
+	if chipletID < 0 || chipletID >= len(this.rramOutputPending) || this.rramOutputPending[chipletID] <= 0 {
+		return
+	}
+	chunk := this.rramOutputPending[chipletID]
+	if this.config != nil && this.config.RramOutputWriteBw > 0 && chunk > this.config.RramOutputWriteBw {
+		chunk = this.config.RramOutputWriteBw
+	}
+	this.rramOutputPending[chipletID] -= chunk
+	this.writeRramOutput(chipletID, chunk)
+	if this.rramOutputPending[chipletID] > 0 {
+		this.rramOutputLimited[chipletID]++
+	}
+}
+
+// rramOutputWritePending reports whether chipletID still has post results
+// waiting to be written into its output buffer.
+func (this *ChipletPlatform) rramOutputWritePending(chipletID int) bool {
+	return chipletID >= 0 && chipletID < len(this.rramOutputPending) && this.rramOutputPending[chipletID] > 0
+}
+
+func (this *ChipletPlatform) writeRramOutput(chipletID int, produce int64) {
+	chiplet := this.rramChiplets[chipletID]
+	if chiplet == nil {
+		return
+	}
+
 	before := chiplet.BufferUsage("output")
 	if !chiplet.AdjustBuffer("output", produce) {
 		// Adjustment may saturate at capacity; rely on occupancy delta.
@@ -2112,6 +4967,9 @@ func (this *ChipletPlatform) releaseRramOutputForChiplet(chipletID int, bytes in
 			this.rramProcessingBytes[chipletID] -= added
 		}
 		this.rramOutputBuffered[chipletID] += added
+		if chipletID < len(this.rramOutputProduced) {
+			this.rramOutputProduced[chipletID] += added
+		}
 		current := chiplet.BufferUsage("output")
 		if this.rramOutputBuffered[chipletID] > current {
 			this.rramOutputBuffered[chipletID] = current
@@ -2215,6 +5073,18 @@ func metadataInt(meta map[string]interface{}, key string, fallback int) int {
 	return fallback
 }
 
+func metadataFloat(meta map[string]interface{}, key string, fallback float64) float64 {
+	if meta == nil {
+		return fallback
+	}
+	if value, exists := meta[key]; exists {
+		if fv, ok := toFloat(value); ok {
+			return fv
+		}
+	}
+	return fallback
+}
+
 func metadataIntSlice(meta map[string]interface{}, key string) []int {
 	if meta == nil {
 		return nil
@@ -2290,7 +5160,7 @@ func (this *ChipletPlatform) buildTransferLatencyEstimator() chiplet.TransferLat
 			return 0, false
 		}
 
-		cycles, ok := client.Estimate(src, dst, query.Bytes, query.Metadata)
+		cycles, ok := this.booksimEstimate(client, src, dst, query.Bytes, query.Metadata)
 		return cycles, ok
 	}
 }
@@ -2309,6 +5179,75 @@ func (this *ChipletPlatform) nocRramNodeID(id int, totalDigital int, totalRram i
 	return totalDigital + id
 }
 
+// pipelineTransferCycles 让依赖数字计算的 digital->RRAM 传输按 --chiplet_transfer_pipeline_chunks
+// 分块流水：前 N-1 块可在生产者计算期间边产边传，生产者完成后只剩最后一块占用链路。
+// 被隐藏的周期不超过编排器记录的生产者计算时长（task.OverlapWindow）。
+func (this *ChipletPlatform) pipelineTransferCycles(task *chiplet.Task, cycles int) int {
+	if this.config == nil || task == nil || task.OverlapWindow <= 0 || cycles <= 1 {
+		return cycles
+	}
+	chunks := this.config.TransferPipelineChunks
+	if chunks <= 1 {
+		return cycles
+	}
+	tail := (cycles + chunks - 1) / chunks
+	hidden := cycles - tail
+	if hidden > task.OverlapWindow {
+		hidden = task.OverlapWindow
+	}
+	if hidden <= 0 {
+		return cycles
+	}
+	this.pipelinedTransfers++
+	this.pipelinedHiddenCycles += int64(hidden)
+	return cycles - hidden
+}
+
+// collectiveTransferCycles 对属于 MoE all-to-all 集合通信的传输，用编排器给出的阶段集合
+// 延迟替代点对点估计：同一 (collective_id, phase) 只由首个到达的传输占用一次链路，
+// 其余成员随该次占用一并完成。阶段的最后一个成员到达后即删除记录，使 collectivePhases
+// 不随运行中的集合通信次数增长；缺少成员数时保留记录。
+func (this *ChipletPlatform) collectiveTransferCycles(meta map[string]interface{}, cycles int) int {
+	phaseCycles := metadataInt(meta, chiplet.MetadataKeyCollectiveCycles, 0)
+	if phaseCycles <= 0 {
+		return cycles
+	}
+	key := fmt.Sprintf("%d/%s", metadataInt(meta, chiplet.MetadataKeyCollectiveID, 0), metadataString(meta, chiplet.MetadataKeyCollectivePhase, ""))
+	if this.collectivePhases == nil {
+		this.collectivePhases = make(map[string]int)
+	}
+	remaining, charged := this.collectivePhases[key]
+	if !charged {
+		remaining = metadataInt(meta, chiplet.MetadataKeyCollectiveMembers, 0)
+		this.collectiveChargedCycles += int64(phaseCycles)
+	}
+	if remaining == 1 {
+		delete(this.collectivePhases, key)
+	} else {
+		if remaining > 1 {
+			remaining--
+		}
+		this.collectivePhases[key] = remaining
+	}
+	if charged {
+		return 0
+	}
+	return phaseCycles
+}
+
+// digitalToInterconnectCycles 把数字域周期换算为互连域周期，使生产者计算时长与链路占用可比。
+func (this *ChipletPlatform) digitalToInterconnectCycles(cycles int) int {
+	if cycles <= 0 || this.digitalClockMhz <= 0 || this.interconnectClockMhz <= 0 {
+		return cycles
+	}
+	return int(int64(cycles) * int64(this.interconnectClockMhz) / int64(this.digitalClockMhz))
+}
+
+// eccWireBytes 返回按 --chiplet_ecc_overhead_pct 附加 ECC/校验字节后实际上链路的字节数。
+func (this *ChipletPlatform) eccWireBytes(bytes int64) int64 {
+	return chiplet.EccWireBytes(this.config, bytes)
+}
+
 func hopWeightedBytes(bytes int64, hops int) int64 {
 	if bytes < 0 {
 		bytes = 0
@@ -2632,6 +5571,46 @@ func (this *ChipletPlatform) recordMoeBarrierMetrics(cmd *chiplet.CommandDescrip
 	}
 }
 
+// moeExpertLoadLines summarises routing balance over the per-expert token
+// counts: mean, population stddev, coefficient of variation and extremes.
+// A high CoV means a few experts carry most of the tokens.
+func moeExpertLoadLines(expertTokens map[int]int64) []string {
+	minLoad, maxLoad := int64(0), int64(0)
+	mean, stddev, cov := 0.0, 0.0, 0.0
+	if len(expertTokens) > 0 {
+		first := true
+		total := int64(0)
+		for _, tokens := range expertTokens {
+			total += tokens
+			if first || tokens < minLoad {
+				minLoad = tokens
+			}
+			if first || tokens > maxLoad {
+				maxLoad = tokens
+			}
+			first = false
+		}
+		mean = float64(total) / float64(len(expertTokens))
+		variance := 0.0
+		for _, tokens := range expertTokens {
+			diff := float64(tokens) - mean
+			variance += diff * diff
+		}
+		stddev = math.Sqrt(variance / float64(len(expertTokens)))
+		if mean > 0 {
+			cov = stddev / mean
+		}
+	}
+	return []string{
+		fmt.Sprintf("ChipletPlatform_moe_experts_seen: %d", len(expertTokens)),
+		fmt.Sprintf("ChipletPlatform_moe_expert_load_mean: %.4f", mean),
+		fmt.Sprintf("ChipletPlatform_moe_expert_load_stddev: %.4f", stddev),
+		fmt.Sprintf("ChipletPlatform_moe_expert_load_cov: %.4f", cov),
+		fmt.Sprintf("ChipletPlatform_moe_expert_load_min: %d", minLoad),
+		fmt.Sprintf("ChipletPlatform_moe_expert_load_max: %d", maxLoad),
+	}
+}
+
 func (this *ChipletPlatform) appendMoeSummaryRow() {
 	if this == nil || this.moeSummaryAppended || this.moeEventsTotal == 0 {
 		return
@@ -3046,7 +6025,7 @@ func (this *ChipletPlatform) buildDigitalDescriptorFromCommand(cmd *chiplet.Comm
 			topK = 1
 		}
 		tokens := problemM
-		totalOps := tokens * topK
+		totalOps := this.reductionOps(tokens, topK, reductionCandidates(cmd.Metadata, topK))
 		reduceOps := metadataInt(cmd.Metadata, "reduce_ops", totalOps)
 		if reduceOps > 0 {
 			totalOps = reduceOps
@@ -3107,6 +6086,10 @@ func (this *ChipletPlatform) buildDigitalDescriptorFromCommand(cmd *chiplet.Comm
 		desc.Description = "gemm"
 	default:
 		// leave defaults
+		this.noteUnrecognizedCommand(cmd, "kind")
+	}
+	if !chiplet.IsKnownCommandOp(metadataString(cmd.Metadata, "op", "")) {
+		this.noteUnrecognizedCommand(cmd, "op")
 	}
 
 	if cmd.Metadata != nil {
@@ -3164,6 +6147,57 @@ func (this *ChipletPlatform) buildDigitalDescriptorFromCommand(cmd *chiplet.Comm
 			desc.TargetBuffer = metadataString(cmd.Metadata, "target_buffer", "scratch")
 			desc.RegistersRd = problemK
 			desc.RegistersWr = problemN
+		case "transpose":
+			// 转置是一次 load/store pass：搬运字节与连续拷贝相同，但跨步访存使有效带宽折损。
+			rows := firstPositive(int(cmd.Aux0), metadataInt(cmd.Metadata, "rows", problemM))
+			cols := firstPositive(int(cmd.Aux1), metadataInt(cmd.Metadata, "cols", problemN))
+			bytes := int64(firstPositive(int(cmd.PayloadBytes), metadataInt(cmd.Metadata, "bytes", rows*cols*bytesPerF16)))
+
+			desc.Description = "transpose"
+			desc.Kind = digital.TaskKindElementwise
+			desc.RequiresPe = false
+			desc.RequiresSpu = false
+			desc.RequiresVpu = false
+			desc.ExecUnit = digital.ExecUnitUnknown
+			desc.ProblemM = rows
+			desc.ProblemN = cols
+			desc.ProblemK = 1
+			desc.InputBytes = bytes
+			desc.WeightBytes = 0
+			desc.OutputBytes = bytes
+			desc.ScalarOps = 0
+			desc.VectorOps = 0
+			desc.SpecialOps = 0
+			desc.StrideFactor = metadataFloat(cmd.Metadata, "stride_penalty", this.transposeStridePenalty())
+			desc.TargetBuffer = metadataString(cmd.Metadata, "target_buffer", "scratch")
+		case "moe_gating_softmax":
+			// softmax 的 SFU 工作量随 tokens×num_experts 线性增长。
+			tokens := firstPositive(int(cmd.Aux0), metadataInt(cmd.Metadata, "tokens", problemM))
+			experts := firstPositive(int(cmd.Aux1), metadataInt(cmd.Metadata, "num_experts", 1))
+			if tokens <= 0 {
+				tokens = 1
+			}
+			if experts <= 0 {
+				experts = 1
+			}
+			logits := tokens * experts
+
+			desc.Description = "moe_gating_softmax"
+			desc.Kind = digital.TaskKindSpuOp
+			desc.RequiresPe = false
+			desc.RequiresSpu = true
+			desc.RequiresVpu = false
+			desc.ExecUnit = digital.ExecUnitSpu
+			desc.ProblemM = tokens
+			desc.ProblemN = experts
+			desc.ProblemK = experts
+			desc.InputBytes = int64(metadataInt(cmd.Metadata, "activation_bytes", logits*bytesPerF16))
+			desc.OutputBytes = int64(metadataInt(cmd.Metadata, "output_bytes", logits*bytesPerF16))
+			desc.WeightBytes = 0
+			desc.ScalarOps = metadataInt(cmd.Metadata, "scalar_ops", tokens)
+			desc.VectorOps = metadataInt(cmd.Metadata, "vector_ops", logits)
+			desc.SpecialOps = firstPositive(metadataInt(cmd.Metadata, "special_ops", logits), logits)
+			desc.TargetBuffer = metadataString(cmd.Metadata, "target_buffer", "scratch")
 		case "topk_select":
 			desc.Description = "topk_select"
 			desc.Kind = digital.TaskKindReduction
@@ -3178,7 +6212,7 @@ func (this *ChipletPlatform) buildDigitalDescriptorFromCommand(cmd *chiplet.Comm
 			if topK <= 0 {
 				topK = 1
 			}
-			totalOps := tokens * topK
+			totalOps := this.reductionOps(tokens, topK, reductionCandidates(cmd.Metadata, firstPositive(problemN, topK)))
 			totalOps = firstPositive(metadataInt(cmd.Metadata, "reduce_ops", totalOps), totalOps)
 			if totalOps > math.MaxInt32 {
 				totalOps = math.MaxInt32
@@ -3199,10 +6233,72 @@ func (this *ChipletPlatform) buildDigitalDescriptorFromCommand(cmd *chiplet.Comm
 
 	desc.RegistersRd = problemK
 	desc.RegistersWr = problemN
+	desc.ConversionOps = dtypeConversionOps(cmd.Metadata, desc.ProblemM, desc.ProblemN)
+	desc.SourceBuffer = metadataString(cmd.Metadata, "source_buffer", "")
 
 	return desc
 }
 
+// dtypeConversionOps 在 metadata 的 input_dtype 与 output_dtype 不同时返回转换 pass
+// 的向量操作数（每个输出元素一次）；元素数默认取 M×N，可由 conversion_elements 覆盖。
+// 缺省的一侧取命令的 precision，因此只声明其中一个 dtype 也能与命令精度比较。
+func dtypeConversionOps(meta map[string]interface{}, rows, cols int) int {
+	precision := metadataString(meta, "precision", "")
+	inputDtype := strings.ToLower(metadataString(meta, "input_dtype", precision))
+	outputDtype := strings.ToLower(metadataString(meta, "output_dtype", precision))
+	if inputDtype == "" || outputDtype == "" || inputDtype == outputDtype {
+		return 0
+	}
+	elements := int64(rows) * int64(cols)
+	elements = int64(firstPositive(metadataInt(meta, "conversion_elements", int(elements)), int(elements)))
+	if elements > math.MaxInt32 {
+		elements = math.MaxInt32
+	}
+	if elements < 0 {
+		elements = 0
+	}
+	return int(elements)
+}
+
+// reductionCandidates 返回每个 token 参与 top-k 选择的候选数：优先使用
+// metadata 中的 candidates，其次是 candidate_experts 列表长度，最后退回 fallback。
+func reductionCandidates(meta map[string]interface{}, fallback int) int {
+	if candidates := metadataInt(meta, "candidates", 0); candidates > 0 {
+		return candidates
+	}
+	if experts := metadataIntSlice(meta, "candidate_experts"); len(experts) > 0 {
+		return len(experts)
+	}
+	return fallback
+}
+
+// reductionOps 按 --chiplet_reduction_cost_model 估算 top-k/归约的标量操作数。
+// linear 保持原先的 tokens*topK；nlogn 把每个 token 的选择视为对候选集的
+// 排序/堆操作，即 tokens*C*ceil(log2 C)。
+func (this *ChipletPlatform) reductionOps(tokens, topK, candidates int) int {
+	if tokens < 1 {
+		tokens = 1
+	}
+	if topK < 1 {
+		topK = 1
+	}
+	if this.config == nil || this.config.ReductionCostModel != chiplet.ReductionCostNlogn {
+		return tokens * topK
+	}
+	if candidates < topK {
+		candidates = topK
+	}
+	logFactor := 1
+	for width := 2; width < candidates; width <<= 1 {
+		logFactor++
+	}
+	ops := int64(tokens) * int64(candidates) * int64(logFactor)
+	if ops > math.MaxInt32 {
+		ops = math.MaxInt32
+	}
+	return int(ops)
+}
+
 func (this *ChipletPlatform) buildRramTaskSpec(task *chiplet.Task) *rram.TaskSpec {
 	if task == nil {
 		return nil
@@ -3223,7 +6319,9 @@ func (this *ChipletPlatform) buildRramTaskSpec(task *chiplet.Task) *rram.TaskSpe
 	var signs []int
 	var exponents []int
 	var mantissas []int
+	var weights []int
 	var hasFPComponents bool
+	hasISum := false
 
 	if value, exists := payloadMap["activation_bits"]; exists {
 		if iv, ok := toInt(value); ok {
@@ -3354,6 +6452,12 @@ func (this *ChipletPlatform) buildRramTaskSpec(task *chiplet.Task) *rram.TaskSpe
 			used = true
 		}
 	}
+	if value, exists := payloadMap["weights"]; exists {
+		if slice, ok := toIntSlice(value); ok {
+			weights = slice
+			used = true
+		}
+	}
 	phaseLabel := ""
 	if value, exists := payloadMap["phase"]; exists {
 		if sv, ok := value.(string); ok {
@@ -3372,6 +6476,7 @@ func (this *ChipletPlatform) buildRramTaskSpec(task *chiplet.Task) *rram.TaskSpe
 	if value, exists := payloadMap["i_sum"]; exists {
 		if iv, ok := toInt64(value); ok {
 			spec.ISum = iv
+			hasISum = true
 			used = true
 		}
 	}
@@ -3413,15 +6518,23 @@ func (this *ChipletPlatform) buildRramTaskSpec(task *chiplet.Task) *rram.TaskSpe
 			pre = rram.NewPreprocessor(spec.ActivationBits, spec.SliceBits)
 		}
 		if len(signs) > 0 && len(signs) == len(exponents) && len(signs) == len(mantissas) {
-			_, maxExp, pSum, aSum := pre.Prepare(signs, exponents, mantissas)
+			aligned, maxExp, pSum, aSum := pre.Prepare(signs, exponents, mantissas)
 			spec.MaxExponent = maxExp
 			spec.PSum = int64(pSum)
 			spec.ASum = aSum
+			if this.rramCrossCheckEnabled() && !hasISum && len(weights) == len(aligned) {
+				spec.ISum = rram.AccumulateISum(aligned, weights)
+			}
 			if spec.ISum == 0 {
 				spec.ISum = spec.PSum
 			}
 		}
 	}
+	spec.Signs = signs
+	spec.Exponents = exponents
+	spec.Mantissas = mantissas
+	spec.Weights = weights
+	spec.CrossCheck = this.rramCrossCheckEnabled()
 
 	switch phaseLabel {
 	case "stage", "stage_act", "rram_stage":
@@ -3513,6 +6626,10 @@ func (this *ChipletPlatform) buildRramSpecFromCommand(cmd *chiplet.CommandDescri
 	spec.ASum = 0
 	spec.Expected = 0
 	spec.HasExpected = false
+	if this.rramCrossCheckEnabled() {
+		spec.CrossCheck = true
+		this.attachCrossCheckOperands(cmd, spec)
+	}
 	switch cmd.Kind {
 	case chiplet.CommandKindRramStageAct:
 		spec.Phase = rram.TaskPhaseStage
@@ -3520,12 +6637,112 @@ func (this *ChipletPlatform) buildRramSpecFromCommand(cmd *chiplet.CommandDescri
 		spec.Phase = rram.TaskPhaseExecute
 	case chiplet.CommandKindRramPost:
 		spec.Phase = rram.TaskPhasePost
+	case chiplet.CommandKindRramWeightLoad:
+		spec.Phase = rram.TaskPhaseUnknown
 	default:
 		spec.Phase = rram.TaskPhaseUnknown
+		this.noteUnrecognizedCommand(cmd, "kind")
+	}
+	if !chiplet.IsKnownCommandOp(metadataString(cmd.Metadata, "op", "")) {
+		this.noteUnrecognizedCommand(cmd, "op")
 	}
 	return spec
 }
 
+// rramCrossCheckEnabled reports whether --chiplet_rram_cross_check is on.
+func (this *ChipletPlatform) rramCrossCheckEnabled() bool {
+	return this.config != nil && this.config.RramCrossCheck != 0
+}
+
+// attachCrossCheckOperands 从命令 metadata 的 signs/exponents/mantissas/weights 取出
+// fp16 激活分量与有符号权重，经预处理与阵列累加得到 P_Sum/A_Sum/I_Sum，供后处理阶段与
+// 数字重算比较。命令未携带完整操作数时该任务无法校验（计入 skipped），并提示一次。
+func (this *ChipletPlatform) attachCrossCheckOperands(cmd *chiplet.CommandDescriptor, spec *rram.TaskSpec) {
+	operands := make([][]int, 0, 4)
+	for _, key := range []string{"signs", "exponents", "mantissas", "weights"} {
+		var values []int
+		if cmd.Metadata != nil {
+			if raw, ok := cmd.Metadata[key]; ok {
+				values, _ = toIntSlice(raw)
+			}
+		}
+		operands = append(operands, values)
+	}
+	signs, exponents, mantissas, weights := operands[0], operands[1], operands[2], operands[3]
+	count := len(mantissas)
+	if count == 0 || len(signs) != count || len(exponents) != count || len(weights) != count {
+		if !this.rramCrossCheckWarned {
+			this.rramCrossCheckWarned = true
+			fmt.Printf("[chiplet] warning: --chiplet_rram_cross_check cannot run for RRAM command node=%d: "+
+				"metadata lacks signs/exponents/mantissas/weights; such tasks are counted as skipped\n", cmd.ID)
+		}
+		return
+	}
+
+	var pre *rram.Preprocessor
+	if len(this.rramChiplets) > 0 && this.rramChiplets[0].Preprocess != nil {
+		pre = this.rramChiplets[0].Preprocess
+	} else {
+		pre = rram.NewPreprocessor(spec.ActivationBits, spec.SliceBits)
+	}
+	aligned, maxExp, pSum, aSum := pre.Prepare(signs, exponents, mantissas)
+	spec.MaxExponent = maxExp
+	spec.PSum = int64(pSum)
+	spec.ASum = aSum
+	spec.ISum = rram.AccumulateISum(aligned, weights)
+	spec.Signs = signs
+	spec.Exponents = exponents
+	spec.Mantissas = mantissas
+	spec.Weights = weights
+}
+
+// noteUnrecognizedCommand 记录一条 kind 或 metadata "op" 未被模型显式处理、只能按
+// 默认（GEMM / 未知阶段）建模的命令。--chiplet_strict_commands 下直接报错终止；
+// 否则计数，并对每个不同的 kind/op 打印一次警告。
+func (this *ChipletPlatform) noteUnrecognizedCommand(cmd *chiplet.CommandDescriptor, field string) {
+	label := cmd.Kind.String()
+	if field == "op" {
+		label = strings.ToLower(strings.TrimSpace(metadataString(cmd.Metadata, "op", "")))
+	}
+	this.unrecognizedCommands++
+	if this.config != nil && this.config.StrictCommands != 0 {
+		this.reportError(fmt.Sprintf("unrecognized command %s %q (node=%d)", field, label, cmd.ID))
+		return
+	}
+	key := field + ":" + label
+	if this.unrecognizedCommandKeys == nil {
+		this.unrecognizedCommandKeys = make(map[string]bool)
+	}
+	if !this.unrecognizedCommandKeys[key] {
+		this.unrecognizedCommandKeys[key] = true
+		fmt.Printf("[chiplet] warning: command node=%d has unrecognized %s %q; modelled with defaults\n", cmd.ID, field, label)
+	}
+}
+
+// cmdFetchLatency 返回命令从 host 分发到目标 chiplet 的跳数代价；host 任务不经过分发。
+func (this *ChipletPlatform) cmdFetchLatency(task *chiplet.Task) int {
+	if task == nil || task.Target == chiplet.TaskTargetHost || this.config == nil || this.config.CmdFetchLatency <= 0 {
+		return 0
+	}
+	return this.config.CmdFetchLatency
+}
+
+// cmdFetchPending reports whether a task issued at EnqueueCycle is still in
+// flight from the host and therefore cannot start this cycle.
+func (this *ChipletPlatform) cmdFetchPending(task *chiplet.Task) bool {
+	latency := this.cmdFetchLatency(task)
+	return latency > 0 && this.currentCycle < task.EnqueueCycle+latency
+}
+
+func (this *ChipletPlatform) recordCmdFetch(task *chiplet.Task) {
+	latency := this.cmdFetchLatency(task)
+	if latency <= 0 {
+		return
+	}
+	this.cmdFetchCycles += int64(latency)
+	this.cmdFetchCommands++
+}
+
 func (this *ChipletPlatform) isTargetBusy(task *chiplet.Task) bool {
 	if task == nil {
 		return false
@@ -3539,6 +6756,9 @@ func (this *ChipletPlatform) isTargetBusy(task *chiplet.Task) bool {
 			if chip == nil {
 				return false
 			}
+			if this.powerdown.blocks(this.powerdown.digitalState(chipletID)) {
+				return true
+			}
 			limit := chip.PendingCapacity()
 			if limit <= 0 {
 				limit = 1
@@ -3552,17 +6772,40 @@ func (this *ChipletPlatform) isTargetBusy(task *chiplet.Task) bool {
 			if chip == nil {
 				return false
 			}
+			if this.powerdown.blocks(this.powerdown.rramState(chipletID)) {
+				return true
+			}
+			if this.waitsForInputPaths(task) && chip.InputsReadyAt() > this.currentCycle {
+				return true
+			}
 			limit := chip.PendingCapacity()
 			if limit <= 0 {
 				limit = 1
 			}
 			return chip.PendingTasks >= limit
 		}
+	case chiplet.TaskTargetTransfer:
+		// RRAM→digital 传输需等待源 chiplet 的输出缓冲写入完成。
+		cmd, ok := task.Payload.(*chiplet.CommandDescriptor)
+		if ok && cmd != nil && cmd.Flags&chiplet.TransferFlagDirectionMask == chiplet.TransferFlagRramToDigital {
+			src := metadataInt(cmd.Metadata, chiplet.MetadataKeySrcRram, int(cmd.Queue))
+			return this.rramOutputWritePending(src)
+		}
 	}
 
 	return false
 }
 
+// waitsForInputPaths reports whether an RRAM task is a stage phase that must
+// wait for its weight load and activation transfer to land.
+func (this *ChipletPlatform) waitsForInputPaths(task *chiplet.Task) bool {
+	if this.config == nil || this.config.RramLoadPathMode == "" || this.config.RramLoadPathMode == "none" {
+		return false
+	}
+	cmd, ok := task.Payload.(*chiplet.CommandDescriptor)
+	return ok && cmd != nil && cmd.Kind == chiplet.CommandKindRramStageAct
+}
+
 func (this *ChipletPlatform) recordDeferral(task *chiplet.Task) {
 	if task == nil {
 		return
@@ -3581,6 +6824,14 @@ func (this *ChipletPlatform) recordDeferral(task *chiplet.Task) {
 }
 
 func (this *ChipletPlatform) logCycleMetrics(cycleDeferrals int) {
+	stagerDepth := 0
+	if this.stager != nil {
+		stagerDepth = this.stager.Len()
+	}
+	if stagerDepth > this.stagerPeakDepth {
+		this.stagerPeakDepth = stagerDepth
+	}
+
 	if this.binDirpath == "" {
 		return
 	}
@@ -3630,7 +6881,7 @@ func (this *ChipletPlatform) logCycleMetrics(cycleDeferrals int) {
 		outstandingDma = tracker.Dma
 	}
 
-	entry := fmt.Sprintf("%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%.2f,%.4f,%.4f,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d",
+	entry := fmt.Sprintf("%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%.2f,%.4f,%.4f,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d",
 		this.currentCycle,
 		this.cycleDigitalExec,
 		this.cycleDigitalCompleted,
@@ -3669,6 +6920,10 @@ func (this *ChipletPlatform) logCycleMetrics(cycleDeferrals int) {
 		this.totalTransferHostStoreBytes,
 		this.transferThrottleEventsTotal,
 		this.transferThrottleCyclesTotal,
+		stagerDepth,
+		this.transferIssuedBytes,
+		this.transferCompletedBytes,
+		this.transferInflightBytes(),
 	)
 
 	this.cycleLog = append(this.cycleLog, entry)
@@ -3820,6 +7075,30 @@ func metadataString(meta map[string]interface{}, key, fallback string) string {
 	return fallback
 }
 
+// booksimCallReduction 返回被缓存省掉的 BookSim 调用占全部查询的比例。
+func booksimCallReduction(hits, calls int64) float64 {
+	if hits+calls <= 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+calls)
+}
+
+// booksimCoverage 返回由 BookSim（含缓存命中）给出延迟的传输占比；过低说明时序主要来自带宽模型。
+func booksimCoverage(estimated, fallbacks int64) float64 {
+	if estimated+fallbacks <= 0 {
+		return 0
+	}
+	return float64(estimated) / float64(estimated+fallbacks)
+}
+
+// weightResidentImbalance 返回驻留权重最多的 chiplet 与平均值之比；1 表示完全均衡。
+func weightResidentImbalance(maxBytes, totalBytes int64, chiplets int) float64 {
+	if totalBytes <= 0 || chiplets <= 0 {
+		return 0
+	}
+	return float64(maxBytes) * float64(chiplets) / float64(totalBytes)
+}
+
 func deriveWeightKey(cmd *chiplet.CommandDescriptor, spec *rram.TaskSpec) (int, int, string) {
 	tileID := 0
 	arrayID := 0
@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/digital"
+)
+
+func TestBufferBandwidthOverrideSpeedsUpLoadBoundTasks(t *testing.T) {
+	t.Parallel()
+
+	// 带宽覆盖写在模型 JSON 的 topology 段中，与 stage 序列同文件。
+	wide := int64(8192)
+	modelPath := filepath.Join(t.TempDir(), "model.json")
+	model := `{"name": "hetero", "sequence": [{"type": "elementwise"}],
+		"topology": {"digital": {"1": {"load_bytes_per_cycle": 8192}}}}`
+	if err := os.WriteFile(modelPath, []byte(model), 0o644); err != nil {
+		t.Fatalf("write model: %v", err)
+	}
+	overrides, err := chiplet.LoadBufferBandwidths(modelPath, 2)
+	if err != nil {
+		t.Fatalf("load model topology: %v", err)
+	}
+	base := digital.DefaultParameters()
+	base.PeArray.LoadBandwidthBytesPerCycle = 256
+
+	cycles := make([]int, 2)
+	for id := range cycles {
+		chip := digital.NewChiplet(id, 1, 4, 4, 1, 1<<20, 1<<20, digitalParamsForChiplet(base, overrides, id))
+		if !chip.SubmitDescriptor(&digital.TaskDescriptor{
+			Kind:        digital.TaskKindTileGemm,
+			Description: "load_bound_gemm",
+			ExecUnit:    digital.ExecUnitPe,
+			RequiresPe:  true,
+			ProblemM:    4,
+			ProblemN:    4,
+			ProblemK:    4,
+			InputBytes:  256 * 1024,
+			OutputBytes: 64,
+		}) {
+			t.Fatalf("chiplet %d: SubmitDescriptor failed", id)
+		}
+		for chip.Busy() || chip.PendingTasks > 0 {
+			chip.Tick()
+			cycles[id]++
+			if cycles[id] > 1<<16 {
+				t.Fatalf("chiplet %d still busy after %d cycles", id, cycles[id])
+			}
+		}
+	}
+
+	if cycles[1] >= cycles[0] {
+		t.Fatalf("expected wider buffer to finish faster: default=%d wide=%d", cycles[0], cycles[1])
+	}
+
+	chip := digital.NewChiplet(1, 1, 4, 4, 1, 0, 0, digitalParamsForChiplet(base, overrides, 1))
+	if load, store := chip.BufferBandwidths(); load != wide || store != base.PeArray.StoreBandwidthBytesPerCycle {
+		t.Fatalf("chiplet 1 buffer bandwidths = %d/%d, want %d/%d", load, store, wide, base.PeArray.StoreBandwidthBytesPerCycle)
+	}
+}
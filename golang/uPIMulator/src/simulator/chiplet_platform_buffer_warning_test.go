@@ -0,0 +1,35 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/digital"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestUndersizedBuffersWarnWithRecommendedMinimum(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	params := digital.DefaultParameters()
+	// 4 个 cluster，每个 cluster 8KiB activation/weights，scratch 足够。
+	platform.digitalChiplets = []*digital.Chiplet{digital.NewChiplet(0, 4, 4, 4, 4, 32*1024, 1024*1024, params)}
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 16*1024, 1024*1024, rram.DefaultParameters())}
+
+	warnings := platform.checkBufferWorkingSets()
+	want := []string{
+		"digital activation buffer (8192 bytes per cluster) cannot hold a single 128x128 tile's activations (32768 bytes); recommend --chiplet_digital_activation_buffer >= 131072",
+		"digital weights buffer (8192 bytes per cluster) cannot hold a single 128x128 tile's weights (32768 bytes); recommend --chiplet_digital_activation_buffer >= 131072",
+		"rram input buffer (16384 bytes) cannot hold a single 128x128 tile's activations (32768 bytes); recommend --chiplet_rram_input_buffer >= 32768",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected warnings:\n%s", strings.Join(warnings, "\n"))
+	}
+
+	platform.digitalChiplets = []*digital.Chiplet{digital.NewChiplet(0, 4, 4, 4, 4, 0, 0, params)}
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, rram.DefaultParameters())}
+	if warnings := platform.checkBufferWorkingSets(); len(warnings) != 0 {
+		t.Fatalf("default buffers should hold a tile, got %v", warnings)
+	}
+}
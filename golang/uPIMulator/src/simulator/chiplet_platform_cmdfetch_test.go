@@ -0,0 +1,61 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// runSerialChain submits tasks back to back, each only after the previous one
+// left the stager and finished its work, and returns the cycle at which the
+// last one completes.
+func runSerialChain(t *testing.T, platform *ChipletPlatform, tasks int, workPerTask int) int {
+	t.Helper()
+	platform.stager = new(chiplet.HostTaskStager)
+	platform.stager.Init()
+	scheduler := &recordingScheduler{}
+	platform.scheduler = scheduler
+	platform.currentCycle = 0
+
+	for i := 0; i < tasks; i++ {
+		platform.SubmitTask(&chiplet.Task{Target: chiplet.TaskTargetDigital, EnqueueCycle: platform.currentCycle})
+		for len(scheduler.issued) == i {
+			platform.drainStager()
+			if len(scheduler.issued) > i {
+				break
+			}
+			platform.currentCycle++
+			if platform.currentCycle > 10000 {
+				t.Fatalf("task %d never left the stager", i)
+			}
+		}
+		platform.currentCycle += workPerTask
+	}
+	return platform.currentCycle
+}
+
+func TestCmdFetchLatencyChargedPerTask(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.CmdFetchLatency = 5
+
+	// Same 80 cycles of compute: 8 tiny tasks vs one big task.
+	tiny := runSerialChain(t, platform, 8, 10)
+	if tiny != 8*(5+10) || platform.cmdFetchCycles != 40 || platform.cmdFetchCommands != 8 {
+		t.Fatalf("expected 8 fetches costing 40 cycles, got end=%d fetch=%d commands=%d", tiny, platform.cmdFetchCycles, platform.cmdFetchCommands)
+	}
+
+	platform.cmdFetchCycles, platform.cmdFetchCommands = 0, 0
+	big := runSerialChain(t, platform, 1, 80)
+	if big != 5+80 || platform.cmdFetchCycles != 5 {
+		t.Fatalf("expected one fetch of 5 cycles, got end=%d fetch=%d", big, platform.cmdFetchCycles)
+	}
+
+	scheduler := platform.scheduler.(*recordingScheduler)
+	platform.SubmitTask(&chiplet.Task{Target: chiplet.TaskTargetHost, EnqueueCycle: platform.currentCycle})
+	platform.drainStager()
+	if len(scheduler.issued) != 2 || platform.stager.HasPending() {
+		t.Fatalf("host tasks should not pay command distribution latency")
+	}
+}
@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/digital"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestUnrecognizedCommandsAreCountedOrRejected(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+
+	known := &chiplet.CommandDescriptor{Kind: chiplet.CommandKindPeSpuOp, Metadata: map[string]interface{}{"op": "softmax_exp"}}
+	platform.buildDigitalDescriptorFromCommand(known, 0)
+	platform.buildRramSpecFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindRramWeightLoad})
+	if platform.unrecognizedCommands != 0 {
+		t.Fatalf("handled commands should not be flagged, got %d", platform.unrecognizedCommands)
+	}
+
+	// 目标错误的 kind 与拼错的 op 都只能按默认建模。
+	platform.buildDigitalDescriptorFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindSync}, 0)
+	platform.buildDigitalDescriptorFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindSync}, 0)
+	platform.buildDigitalDescriptorFromCommand(&chiplet.CommandDescriptor{
+		Kind:     chiplet.CommandKindPeSpuOp,
+		Metadata: map[string]interface{}{"op": "softmx_exp"},
+	}, 0)
+	platform.buildRramSpecFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindPeGemm})
+	if platform.unrecognizedCommands != 4 || len(platform.unrecognizedCommandKeys) != 3 {
+		t.Fatalf("lenient mode: count=%d distinct=%d, want 4/3", platform.unrecognizedCommands, len(platform.unrecognizedCommandKeys))
+	}
+	if platform.aborted {
+		t.Fatalf("lenient mode must not abort")
+	}
+
+	strict := newTestPlatformForGating()
+	strict.config.StrictCommands = 1
+	strict.buildDigitalDescriptorFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindSync}, 0)
+	if !strict.aborted || strict.unrecognizedCommands != 1 {
+		t.Fatalf("strict mode should abort on the first unrecognized command: aborted=%v count=%d",
+			strict.aborted, strict.unrecognizedCommands)
+	}
+}
+
+func TestRramCrossCheckOperandsFollowTheFlag(t *testing.T) {
+	t.Parallel()
+
+	operands := map[string]interface{}{
+		"signs":     []int{0, 1, 0},
+		"exponents": []int{15, 15, 15},
+		"mantissas": []int{0, 512, 256},
+		"weights":   []int{3, -2, 5},
+	}
+	command := &chiplet.CommandDescriptor{Kind: chiplet.CommandKindRramPost, Metadata: operands}
+
+	platform := newTestPlatformForGating()
+	if spec := platform.buildRramSpecFromCommand(command); spec.CrossCheck || spec.Weights != nil || spec.ISum != 0 {
+		t.Fatalf("cross-check off should leave operands unset, got check=%v weights=%v isum=%d", spec.CrossCheck, spec.Weights, spec.ISum)
+	}
+	mapped := platform.buildRramTaskSpec(&chiplet.Task{Payload: map[string]interface{}{
+		"signs": operands["signs"], "exponents": operands["exponents"], "mantissas": operands["mantissas"], "weights": operands["weights"],
+	}})
+	if mapped.CrossCheck || mapped.ISum != mapped.PSum {
+		t.Fatalf("cross-check off should not accumulate I_Sum from weights, got isum=%d psum=%d", mapped.ISum, mapped.PSum)
+	}
+
+	platform.config.RramCrossCheck = 1
+	spec := platform.buildRramSpecFromCommand(command)
+	if !spec.CrossCheck || len(spec.Weights) != 3 || spec.ISum == 0 {
+		t.Fatalf("cross-check should carry the command operands, got check=%v weights=%v isum=%d", spec.CrossCheck, spec.Weights, spec.ISum)
+	}
+	if reference, ok := rram.DigitalReference(spec); !ok || reference != 12.25 {
+		t.Fatalf("expected a digital reference of 12.25, got %f (ok=%v)", reference, ok)
+	}
+
+	bare := platform.buildRramSpecFromCommand(&chiplet.CommandDescriptor{Kind: chiplet.CommandKindRramPost})
+	if _, ok := rram.DigitalReference(bare); ok || !platform.rramCrossCheckWarned {
+		t.Fatalf("a command without operands should be reported as uncheckable")
+	}
+}
+
+func TestWastedLegacyDigitalTaskChargesEnergy(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	chip := digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, digital.DefaultParameters())
+	run := func(wasted bool) {
+		platform.scheduleLegacyDigitalTask(chip, &chiplet.Task{
+			Target:  chiplet.TaskTargetDigital,
+			Latency: 40,
+			Payload: &chiplet.CommandDescriptor{
+				Kind:     chiplet.CommandKindPeGemm,
+				Metadata: map[string]interface{}{"wasted": wasted},
+			},
+		})
+	}
+
+	run(false)
+	if energy, cycles := platform.wastedWork(); energy != 0 || cycles != 0 {
+		t.Fatalf("useful legacy work must not count as wasted, got energy=%.6f cycles=%d", energy, cycles)
+	}
+
+	run(true)
+	energy, cycles := platform.wastedWork()
+	if chip.PendingTasks != 2 || cycles != 40 {
+		t.Fatalf("expected both tasks scheduled and 40 wasted cycles, got pending=%d cycles=%d", chip.PendingTasks, cycles)
+	}
+	if want := 40 * chip.StaticEnergyPerCyclePJ(); energy <= 0 || energy != want {
+		t.Fatalf("wasted legacy energy = %.6f, want %.6f", energy, want)
+	}
+}
@@ -0,0 +1,57 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+
+	"uPIMulator/src/misc"
+)
+
+func runWithCycleOrder(t *testing.T, order []string, cycles int) []string {
+	t.Helper()
+	tempDir := t.TempDir()
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	defer platform.Fini()
+	platform.config.CycleOrder = order
+	for i := 0; i < cycles; i++ {
+		platform.Cycle()
+	}
+	return append([]string(nil), platform.cycleLog...)
+}
+
+func TestCycleOrderIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	orders := [][]string{
+		{"digital", "rram", "transfer"},
+		{"transfer", "rram", "digital"},
+	}
+	for _, order := range orders {
+		first := runWithCycleOrder(t, order, 2000)
+		second := runWithCycleOrder(t, order, 2000)
+		if len(first) != len(second) {
+			t.Fatalf("order %v: cycle log length %d vs %d", order, len(first), len(second))
+		}
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("order %v: runs diverge at row %d:\n%s\n%s", order, i, first[i], second[i])
+			}
+		}
+	}
+
+	if got, err := misc.ParseCycleOrder(" Transfer, rram ,digital"); err != nil || strings.Join(got, ",") != "transfer,rram,digital" {
+		t.Fatalf("expected normalized permutation, got %v (%v)", got, err)
+	}
+	for _, bad := range []string{"digital,rram", "digital,rram,rram", "digital,rram,host"} {
+		if _, err := misc.ParseCycleOrder(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
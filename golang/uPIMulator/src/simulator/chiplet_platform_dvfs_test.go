@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestDvfsStepsDownWhenIdleAndBackUpWhenBusy(t *testing.T) {
+	t.Parallel()
+
+	config := &chiplet.Config{
+		Dvfs:         true,
+		DvfsLevels:   []int{100, 50},
+		DvfsWindow:   4,
+		DvfsLowUtil:  20,
+		DvfsHighUtil: 60,
+	}
+	controller := newDvfsController(config, 0, 1)
+	state := controller.rramState(0)
+
+	// 第一个窗口空闲：全速运行 4 个 tick，然后降到 50%。
+	for i := 0; i < 4; i++ {
+		if !controller.step(state, false) {
+			t.Fatalf("tick %d should run at nominal frequency", i)
+		}
+	}
+	if state.level != 1 {
+		t.Fatalf("expected step down after idle window, level=%d", state.level)
+	}
+
+	// 第二个窗口忙碌：半速时每两个 tick 执行一次，窗口结束后回到 100%。
+	ran := 0
+	for i := 0; i < 4; i++ {
+		if controller.step(state, true) {
+			ran++
+		}
+	}
+	if ran != 2 {
+		t.Fatalf("expected 2 of 4 ticks to run at 50%%, got %d", ran)
+	}
+	if state.level != 0 {
+		t.Fatalf("expected step up after busy window, level=%d", state.level)
+	}
+	if controller.transitions != 2 {
+		t.Fatalf("expected 2 transitions, got %d", controller.transitions)
+	}
+	if state.ticksAtLevel[0] != 4 || state.ticksAtLevel[1] != 4 || state.skippedTicks != 2 {
+		t.Fatalf("unexpected residency %v skipped=%d", state.ticksAtLevel, state.skippedTicks)
+	}
+}
+
+func TestDvfsSkippedRramTicksSaveStaticEnergy(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	params := rram.DefaultParameters()
+	platform.rramChiplets = append(platform.rramChiplets, rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params))
+	platform.dvfs = newDvfsController(&chiplet.Config{
+		Dvfs:         true,
+		DvfsLevels:   []int{100, 25},
+		DvfsWindow:   2,
+		DvfsLowUtil:  20,
+		DvfsHighUtil: 60,
+	}, 0, 1)
+
+	for i := 0; i < 10; i++ {
+		platform.runRramTick()
+	}
+
+	chip := platform.rramChiplets[0]
+	perCycle := chip.StaticEnergyPerCyclePJ()
+	state := platform.dvfs.rramState(0)
+	ran := 10 - state.skippedTicks
+	if ran >= 10 {
+		t.Fatalf("expected idle chiplet to skip ticks after stepping down")
+	}
+	if diff := chip.StaticEnergyPJ - float64(ran)*perCycle; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("static energy %.6f does not match %d executed ticks", chip.StaticEnergyPJ, ran)
+	}
+	if diff := platform.dvfs.energySavedPJ - float64(state.skippedTicks)*perCycle; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("energy saved %.6f does not match %d skipped ticks", platform.dvfs.energySavedPJ, state.skippedTicks)
+	}
+}
+
+func TestDvfsFrequencyLogRecordsLevelChanges(t *testing.T) {
+	t.Parallel()
+
+	controller := newDvfsController(&chiplet.Config{
+		Dvfs:         true,
+		DvfsLevels:   []int{100, 50},
+		DvfsWindow:   4,
+		DvfsLowUtil:  20,
+		DvfsHighUtil: 60,
+	}, 1, 1)
+
+	// digital 一直忙保持全速，rram 空闲一个窗口后降到 50%。
+	for cycle := 1; cycle <= 10; cycle++ {
+		controller.step(controller.digitalState(0), true)
+		controller.step(controller.rramState(0), false)
+		controller.sampleFrequencies(cycle, 1000, 800)
+	}
+
+	want := []string{"cycle,digital0_mhz,rram0_mhz", "1,1000,800", "4,1000,400", "10,1000,400"}
+	got := controller.frequencyLines(10, 1000, 800)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("row %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+	if changes := controller.frequencyChanges(); changes != 1 {
+		t.Fatalf("expected 1 frequency change, got %d", changes)
+	}
+	// 4 个 tick 在 800MHz、6 个 tick 在 400MHz。
+	if avg := controller.averageMhz(controller.rramState(0), 800); avg != 560 {
+		t.Fatalf("expected 560MHz average, got %.2f", avg)
+	}
+}
+
+func TestDvfsScalesDynamicEnergyByVoltageSquared(t *testing.T) {
+	t.Parallel()
+
+	run := func(level int) (*rram.Chiplet, *dvfsController) {
+		platform := newTestPlatformForGating()
+		chip := rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, rram.DefaultParameters())
+		platform.rramChiplets = append(platform.rramChiplets, chip)
+		// 单一档位：整个运行都停在 level，不会换档。
+		platform.dvfs = newDvfsController(&chiplet.Config{Dvfs: true, DvfsLevels: []int{level}, DvfsWindow: 1}, 0, 1)
+		chip.ScheduleTask(0, &rram.TaskSpec{
+			Rows: 128, Cols: 128, Depth: 128,
+			ActivationBits: 12, SliceBits: 2, PulseCount: 16, AdcSamples: 128,
+			PreCycles: 4, PostCycles: 4, Phase: rram.TaskPhaseExecute,
+		})
+		for cycles := 0; chip.Busy(); cycles++ {
+			platform.runRramTick()
+			if cycles > 100000 {
+				t.Fatalf("task did not drain at %d%%", level)
+			}
+		}
+		return chip, platform.dvfs
+	}
+
+	nominal, _ := run(100)
+	half, controller := run(50)
+	if nominal.DynamicEnergyPJ <= 0 {
+		t.Fatalf("expected the task to charge dynamic energy")
+	}
+	// 50% 档位电压减半，单次操作能耗为名义值的 1/4。
+	if diff := half.DynamicEnergyPJ - nominal.DynamicEnergyPJ/4; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("expected dynamic energy %.6f at 50%%, got %.6f", nominal.DynamicEnergyPJ/4, half.DynamicEnergyPJ)
+	}
+	saved := controller.energySavedPJ - float64(controller.rram[0].skippedTicks)*half.StaticEnergyPerCyclePJ()
+	if diff := saved - nominal.DynamicEnergyPJ*3/4; diff > 1e-6 || diff < -1e-6 {
+		t.Fatalf("expected %.6f dynamic energy saved, got %.6f", nominal.DynamicEnergyPJ*3/4, saved)
+	}
+}
@@ -0,0 +1,40 @@
+package simulator
+
+import "testing"
+
+func TestEdpLinesMultiplyEnergyByDomainDelay(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.currentCycle = 100
+	platform.digitalDomainCycles = 100
+	platform.rramDomainCycles = 50
+
+	// digital 2 pJ × 100 周期，rram 4 pJ × 50 周期，合计 6 pJ × 100 基准周期。
+	want := []string{
+		"ChipletPlatform_edp_digital: 2.000000e+02",
+		"ChipletPlatform_edp_rram: 2.000000e+02",
+		"ChipletPlatform_edp_total: 6.000000e+02",
+		"ChipletPlatform_ed2p_digital: 2.000000e+04",
+		"ChipletPlatform_ed2p_rram: 1.000000e+04",
+		"ChipletPlatform_ed2p_total: 6.000000e+04",
+	}
+	got := platform.edpLines(2, 4)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// 更慢的 rram 域只抬高 rram 与合计项，ED²P 比 EDP 放大得更多。
+	platform.rramDomainCycles = 100
+	platform.currentCycle = 200
+	slower := platform.edpLines(2, 4)
+	if slower[0] != want[0] || slower[1] != "ChipletPlatform_edp_rram: 4.000000e+02" ||
+		slower[2] != "ChipletPlatform_edp_total: 1.200000e+03" || slower[5] != "ChipletPlatform_ed2p_total: 2.400000e+05" {
+		t.Fatalf("unexpected EDP after a slower run: %v", slower)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"uPIMulator/src/assembler"
 	"uPIMulator/src/misc"
 	"uPIMulator/src/simulator/chiplet"
 	digitalpkg "uPIMulator/src/simulator/chiplet/digital"
@@ -117,6 +118,47 @@ func TestBuildDigitalDescriptorForMoETopKSelect(t *testing.T) {
 	}
 }
 
+func TestTopkSelectNlognCostExceedsLinear(t *testing.T) {
+	t.Parallel()
+
+	rows := 64
+	topK := 2
+	candidates := 256
+	newCmd := func() *chiplet.CommandDescriptor {
+		return &chiplet.CommandDescriptor{
+			Kind:  chiplet.CommandKindPeReduce,
+			Aux0:  uint32(rows),
+			SubOp: uint32(topK),
+			Metadata: map[string]interface{}{
+				"op":         "topk_select",
+				"tokens":     rows,
+				"top_k":      topK,
+				"candidates": candidates,
+			},
+		}
+	}
+
+	platform := newTestPlatformForGating()
+	platform.config.ReductionCostModel = chiplet.ReductionCostLinear
+	linear := platform.buildDigitalDescriptorFromCommand(newCmd(), 0)
+	platform.config.ReductionCostModel = chiplet.ReductionCostNlogn
+	nlogn := platform.buildDigitalDescriptorFromCommand(newCmd(), 0)
+	if linear == nil || nlogn == nil {
+		t.Fatalf("expected descriptors for both cost models")
+	}
+
+	if linear.ScalarOps != rows*topK {
+		t.Fatalf("linear model: expected %d ops, got %d", rows*topK, linear.ScalarOps)
+	}
+	// 256 candidates -> log2 = 8.
+	if want := rows * candidates * 8; nlogn.ScalarOps != want {
+		t.Fatalf("nlogn model: expected %d ops, got %d", want, nlogn.ScalarOps)
+	}
+	if nlogn.ScalarOps < 100*linear.ScalarOps {
+		t.Fatalf("expected nlogn (%d) to cost materially more than linear (%d)", nlogn.ScalarOps, linear.ScalarOps)
+	}
+}
+
 func TestMoeStatsTracking(t *testing.T) {
 	t.Parallel()
 
@@ -253,3 +295,243 @@ func TestHostGatingFetchConsumesSnapshot(t *testing.T) {
 		t.Fatalf("snapshot queue should be empty after consumption")
 	}
 }
+
+func TestDtypeConversionOpsFromMetadata(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	cmd := &chiplet.CommandDescriptor{
+		Kind: chiplet.CommandKindPeGemm,
+		Aux0: 32,
+		Aux1: 64,
+		Aux2: 64,
+		Metadata: map[string]interface{}{
+			"input_dtype":  "fp32",
+			"output_dtype": "FP16",
+		},
+	}
+	if desc := platform.buildDigitalDescriptorFromCommand(cmd, 0); desc.ConversionOps != 32*64 {
+		t.Fatalf("expected %d conversion ops, got %d", 32*64, desc.ConversionOps)
+	}
+	cmd.Metadata["output_dtype"] = "fp32"
+	if desc := platform.buildDigitalDescriptorFromCommand(cmd, 0); desc.ConversionOps != 0 {
+		t.Fatalf("expected no conversion for matching dtypes, got %d", desc.ConversionOps)
+	}
+}
+
+func TestMixedPrecisionPipelinePaysConversion(t *testing.T) {
+	t.Parallel()
+
+	// attention 以 producer 精度输出，softmax 声明 fp16；精度不同时汇编器为 softmax 标注
+	// input/output dtype，平台据此在 SPU 上计入转换 pass。
+	run := func(producerPrecision string) (int64, int64) {
+		spec := &assembler.ChipletModelSpec{Sequence: []assembler.ChipletStageSpec{
+			{Type: "attention", Rows: 64, Cols: 64, K: 64, Latency: 16,
+				Metadata: map[string]interface{}{"precision": producerPrecision}},
+			{Type: "softmax", Rows: 64, Cols: 64, Latency: 8, Dependencies: []int{0},
+				Metadata: map[string]interface{}{"precision": "fp16"}},
+		}}
+		loader := new(misc.ConfigLoader)
+		loader.Init()
+		config := chiplet.LoadConfig(loader)
+		commands, err := spec.BuildCommands(config, chiplet.BuildTopology(config))
+		if err != nil {
+			t.Fatalf("build commands: %v", err)
+		}
+		platform := runCommandGraph(t, commands, nil)
+		ops, cycles := int64(0), int64(0)
+		for _, chip := range platform.digitalChiplets {
+			for i := 0; i < 1<<20 && (chip.Busy() || chip.PendingTasks > 0); i++ {
+				chip.Tick()
+			}
+			ops += chip.DtypeConversionOps
+			cycles += chip.DtypeConversionCycles
+		}
+		return ops, cycles
+	}
+
+	if ops, cycles := run("fp16"); ops != 0 || cycles != 0 {
+		t.Fatalf("matching precisions should not convert, got %d ops / %d cycles", ops, cycles)
+	}
+	if ops, cycles := run("fp32"); ops != 64*64 || cycles <= 0 {
+		t.Fatalf("an fp32 producer feeding an fp16 stage should pay %d conversion ops on the SPU, got %d ops / %d cycles",
+			64*64, ops, cycles)
+	}
+}
+
+func TestMoeExpertLoadCoefficientOfVariation(t *testing.T) {
+	t.Parallel()
+
+	want := []string{
+		"ChipletPlatform_moe_experts_seen: 4",
+		"ChipletPlatform_moe_expert_load_mean: 4.0000",
+		"ChipletPlatform_moe_expert_load_stddev: 4.0000",
+		"ChipletPlatform_moe_expert_load_cov: 1.0000",
+		"ChipletPlatform_moe_expert_load_min: 0",
+		"ChipletPlatform_moe_expert_load_max: 8",
+	}
+	if got := moeExpertLoadLines(map[int]int64{0: 8, 1: 8, 2: 0, 3: 0}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected expert load lines:\n got %v\nwant %v", got, want)
+	}
+	if got := moeExpertLoadLines(map[int]int64{0: 5, 1: 5}); got[3] != "ChipletPlatform_moe_expert_load_cov: 0.0000" {
+		t.Fatalf("balanced routing should have zero CoV, got %s", got[3])
+	}
+}
+
+func TestMoEGatingSoftmaxCyclesScaleWithExperts(t *testing.T) {
+	t.Parallel()
+
+	softmaxCycles := func(experts int) int64 {
+		platform := newTestPlatformForGating()
+		platform.digitalChiplets = []*digitalpkg.Chiplet{
+			digitalpkg.NewChiplet(0, 1, 4, 4, 1, 1<<20, 1<<20, digitalpkg.DefaultParameters()),
+		}
+		cmd := &chiplet.CommandDescriptor{
+			Kind:       chiplet.CommandKindPeSpuOp,
+			Target:     chiplet.TaskTargetDigital,
+			ChipletID:  0,
+			Aux0:       64,
+			Aux1:       uint32(experts),
+			ExecDomain: chiplet.ExecDomainSpu,
+			Metadata: map[string]interface{}{
+				"op":          "moe_gating_softmax",
+				"tokens":      64,
+				"num_experts": experts,
+				"special_ops": 64 * experts * 4,
+			},
+		}
+
+		desc := platform.buildDigitalDescriptorFromCommand(cmd, 0)
+		if desc == nil || desc.Description != "moe_gating_softmax" || !desc.RequiresSpu || desc.ProblemN != experts {
+			t.Fatalf("unexpected softmax descriptor: %+v", desc)
+		}
+		platform.handleDigitalTask(&chiplet.Task{Payload: cmd})
+		return platform.moeGatingSoftmaxCycles
+	}
+
+	few := softmaxCycles(4)
+	many := softmaxCycles(64)
+	if few <= 0 || many <= few {
+		t.Fatalf("softmax cycles should grow with the expert count: 4 experts=%d 64 experts=%d", few, many)
+	}
+}
+
+func TestTransposeCostsMoreThanContiguousCopy(t *testing.T) {
+	t.Parallel()
+
+	// run 提交一个 1024x1024 的转置并推进 chiplet 直到完成，返回端到端周期数。
+	run := func(stridePenalty float64) (*ChipletPlatform, int) {
+		platform := newTestPlatformForGating()
+		platform.config.TransposeStridePenalty = 4.0
+		chip := digitalpkg.NewChiplet(0, 1, 4, 4, 1, 1<<24, 1<<24, digitalpkg.DefaultParameters())
+		platform.digitalChiplets = []*digitalpkg.Chiplet{chip}
+		cmd := &chiplet.CommandDescriptor{
+			Kind:      chiplet.CommandKindPeSpuOp,
+			Target:    chiplet.TaskTargetDigital,
+			ChipletID: 0,
+			Aux0:      1024,
+			Aux1:      1024,
+			Metadata:  map[string]interface{}{"op": "transpose", "stride_penalty": stridePenalty},
+		}
+		desc := platform.buildDigitalDescriptorFromCommand(cmd, 0)
+		if desc == nil || desc.Description != "transpose" || desc.StrideFactor != stridePenalty || desc.InputBytes != 1024*1024*2 {
+			t.Fatalf("unexpected transpose descriptor: %+v", desc)
+		}
+
+		platform.handleDigitalTask(&chiplet.Task{Payload: cmd})
+		cycles := 0
+		for ; cycles < 1<<20 && (chip.Busy() || chip.PendingTasks > 0); cycles++ {
+			chip.Tick()
+		}
+		if chip.ExecutedTasks != 1 {
+			t.Fatalf("transpose did not complete after %d cycles", cycles)
+		}
+		return platform, cycles
+	}
+
+	copyPlatform, contiguous := run(1.0)
+	platform, transpose := run(4.0)
+	if contiguous <= 0 || transpose <= 2*contiguous {
+		t.Fatalf("a strided transpose should take well over a contiguous copy: transpose=%d copy=%d", transpose, contiguous)
+	}
+	if copyPlatform.transposePenaltyCycles != 0 {
+		t.Fatalf("a contiguous pass should carry no stride penalty, got %d", copyPlatform.transposePenaltyCycles)
+	}
+	if platform.transposeTasks != 1 || platform.transposeCycles <= 0 || platform.transposePenaltyCycles <= 0 {
+		t.Fatalf("transpose stats not recorded: tasks=%d cycles=%d penalty=%d",
+			platform.transposeTasks, platform.transposeCycles, platform.transposePenaltyCycles)
+	}
+}
+
+// moeGatingGraph 返回 topk_select → host gating fetch 的最小 MoE 命令图，门控后由编排器展开专家命令组。
+func moeGatingGraph() []chiplet.CommandDescriptor {
+	return []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindPeReduce, Target: chiplet.TaskTargetDigital, ChipletID: 0, BufferID: 7, Aux0: 4, Aux1: 4,
+			Metadata: map[string]interface{}{
+				"op":                "topk_select",
+				"buffer_id":         7,
+				"top_k":             4,
+				"tokens":            4,
+				"features":          64,
+				"candidate_experts": []int{0, 1, 2, 3},
+				"gating_scores":     []float64{0.4, 0.3, 0.2, 0.1},
+				"activation_bytes":  4096,
+				"weight_bytes":      512,
+				"output_bytes":      2048,
+			}},
+		{ID: 1, Kind: chiplet.CommandKindHostGatingFetch, Target: chiplet.TaskTargetHost, ChipletID: 0, BufferID: 7, Dependencies: []int32{0},
+			Metadata: map[string]interface{}{"top_k": 4, "buffer_id": 7}},
+	}
+}
+
+func TestMoeAlltoallChargesCollectiveOnInterconnect(t *testing.T) {
+	t.Parallel()
+
+	run := func(bw int) *ChipletPlatform {
+		return runCommandGraph(t, moeGatingGraph(), func(config *chiplet.Config) {
+			config.MoeAlltoallBw = bw
+		})
+	}
+
+	pointToPoint := run(0)
+	collective := run(8)
+	if collective.orchestrator.MoeAlltoallCollectives() != 1 {
+		t.Fatalf("expected one all-to-all collective, got %d", collective.orchestrator.MoeAlltoallCollectives())
+	}
+	if got, want := collective.collectiveChargedCycles, collective.orchestrator.MoeAlltoallCycles(); got != want || got <= 0 {
+		t.Fatalf("platform should charge the collective phases on the link: charged=%d modeled=%d", got, want)
+	}
+	if len(collective.collectivePhases) != 0 {
+		t.Fatalf("completed collective phases should be cleared, got %v", collective.collectivePhases)
+	}
+	// 8B/cycle 的集合端口远慢于点对点链路，完成时间随之变长。
+	if collective.currentCycle <= pointToPoint.currentCycle {
+		t.Fatalf("a slow all-to-all should delay completion: p2p=%d collective=%d", pointToPoint.currentCycle, collective.currentCycle)
+	}
+}
+
+func TestCollectiveBroadcastDelaysCompletion(t *testing.T) {
+	t.Parallel()
+
+	run := func(broadcast int) *ChipletPlatform {
+		return runCommandGraph(t, moeGatingGraph(), func(config *chiplet.Config) {
+			config.MoeAlltoallBw = 256
+			config.BroadcastCycles = broadcast
+		})
+	}
+
+	plain := run(0)
+	broadcast := run(200)
+	cost := broadcast.orchestrator.CollectiveBroadcastCycles()
+	if cost <= 0 {
+		t.Fatalf("expected a broadcast cost, got %d", cost)
+	}
+	if delta := broadcast.collectiveChargedCycles - plain.collectiveChargedCycles; delta != cost {
+		t.Fatalf("broadcast should be charged on the link: charged delta=%d broadcast=%d", delta, cost)
+	}
+	// 广播按互连周期占用链路，换算回平台周期后完成时间至少推迟同样多。
+	if delay := int64(broadcast.currentCycle - plain.currentCycle); delay < cost {
+		t.Fatalf("broadcast should delay completion by at least %d cycles, got %d (plain=%d broadcast=%d)",
+			cost, delay, plain.currentCycle, broadcast.currentCycle)
+	}
+}
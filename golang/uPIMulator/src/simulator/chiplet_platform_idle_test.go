@@ -0,0 +1,40 @@
+package simulator
+
+import (
+	"reflect"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/digital"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestIdleReasonsAreSplitPerChipletType(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.digitalChiplets = []*digital.Chiplet{digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, digital.DefaultParameters())}
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, rram.DefaultParameters())}
+	platform.digitalReserveBlocked = make([]bool, 1)
+	platform.rramReserveBlocked = make([]bool, 1)
+
+	// 两个 chiplet 都空闲：先无就绪任务，再遇到互连背压，最后 RRAM 输入缓冲预留失败。
+	platform.recordIdleReasons(false)
+	platform.recordIdleReasons(true)
+	platform.rramReserveBlocked[0] = true
+	platform.recordIdleReasons(true)
+	// 有待处理任务的 chiplet 不计入空闲。
+	platform.rramChiplets[0].PendingTasks = 1
+	platform.recordIdleReasons(true)
+
+	want := []string{
+		"ChipletPlatform_digital_idle_cycles[no_ready_work]: 1",
+		"ChipletPlatform_digital_idle_cycles[throttled]: 3",
+		"ChipletPlatform_digital_idle_cycles[buffer_blocked]: 0",
+		"ChipletPlatform_rram_idle_cycles[no_ready_work]: 1",
+		"ChipletPlatform_rram_idle_cycles[throttled]: 1",
+		"ChipletPlatform_rram_idle_cycles[buffer_blocked]: 1",
+	}
+	if got := platform.idleReasonLines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected idle reasons:\n got %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/digital"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestBytesPerInterconnectPJByStage(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	params := digital.DefaultParameters()
+	params.Interconnect.EnergyPJPerByte = 0.5
+	chip := digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, params)
+	platform.digitalChiplets = []*digital.Chiplet{chip}
+
+	// to_rram travels two hops per byte (hop-weighted energy), host one hop.
+	platform.chargeInterconnectEnergy("transfer_to_rram", chip, 2048)
+	platform.totalTransferToRramBytes = 1024
+	platform.chargeInterconnectEnergy("transfer_host2d", chip, 1024)
+	platform.totalTransferHostLoadBytes = 1024
+	platform.totalTransferBytes = 2048
+
+	want := []string{
+		"ChipletPlatform_bytes_per_interconnect_pj: 1.3333",
+		"ChipletPlatform_bytes_per_interconnect_pj[to_rram]: 1.0000",
+		"ChipletPlatform_bytes_per_interconnect_pj[to_digital]: 0.0000",
+		"ChipletPlatform_bytes_per_interconnect_pj[host]: 2.0000",
+	}
+	got := platform.interconnectEfficiencyLines()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestOpsPerPJIsZeroSafeAndPerChiplet(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	chip := digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, digital.DefaultParameters())
+	if got := opsPerPJ(digitalOps(chip), digitalEnergyPJ(chip)); got != 0 {
+		t.Fatalf("an idle chiplet should report 0 ops/pJ, got %f", got)
+	}
+
+	chip.TotalMacs = 100
+	chip.SpuScalarOps = 20
+	chip.SpuVectorOps = 30
+	chip.SpuSpecialOps = 50
+	chip.DynamicEnergyPJ = 60
+	chip.StaticEnergyPJ = 30
+	chip.InterconnectEnergyPJ = 10
+	if got := opsPerPJ(digitalOps(chip), digitalEnergyPJ(chip)); got != 3 {
+		t.Fatalf("expected (2*100+20+30+50)/100 = 3 ops/pJ, got %f", got)
+	}
+
+	platform.config.RramSaRows = 128
+	if got := platform.rramMacEstimate(rram.Stats{TotalAdcSamples: 4}); got != 512 {
+		t.Fatalf("expected 4 ADC samples x 128 rows = 512 MACs, got %d", got)
+	}
+	if got := opsPerPJ(1024, 0); got != 0 {
+		t.Fatalf("zero energy must not divide, got %f", got)
+	}
+}
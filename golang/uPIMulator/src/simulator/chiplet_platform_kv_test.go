@@ -0,0 +1,92 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/host"
+)
+
+func newTestPlatformForKv(groupSize, kvHeads int) *ChipletPlatform {
+	platform := newTestPlatformForGating()
+	platform.config.KvCacheBytes = 1 << 20
+	platform.config.GqaGroupSize = groupSize
+	platform.config.KvHeads = kvHeads
+	platform.kvCache = host.NewKVCache(platform.config.KvCacheBytes)
+	platform.kvHeadCaches = make(map[int]*host.KVCache)
+	platform.kvHeadStats = make(map[int]*kvHeadCounter)
+	return platform
+}
+
+func kvLoad(platform *ChipletPlatform, head int) {
+	platform.handleKvAccess("transfer_host2d", 1024, map[string]interface{}{
+		"kv_layer": 0,
+		"kv_head":  head,
+		"kv_seq":   0,
+		"kv_token": 0,
+	})
+}
+
+func TestGqaGroupingSharesKvHeads(t *testing.T) {
+	t.Parallel()
+
+	// 8 query heads, 4 per KV head: only the first head of each group misses.
+	grouped := newTestPlatformForKv(4, 0)
+	for head := 0; head < 8; head++ {
+		kvLoad(grouped, head)
+	}
+	if grouped.kvCacheHits != 6 || grouped.kvCacheMisses != 2 {
+		t.Fatalf("expected 6 hits/2 misses with grouping, got %d/%d", grouped.kvCacheHits, grouped.kvCacheMisses)
+	}
+	if grouped.kvShadowHits != 0 || grouped.kvShadowMisses != 8 {
+		t.Fatalf("expected ungrouped shadow to miss every head, got %d/%d", grouped.kvShadowHits, grouped.kvShadowMisses)
+	}
+
+	want := []string{
+		"ChipletPlatform_kv_head[0]_hit_rate: 0.7500",
+		"ChipletPlatform_kv_head[1]_hit_rate: 0.7500",
+		"ChipletPlatform_kv_gqa_hit_rate: 0.7500",
+		"ChipletPlatform_kv_ungrouped_hit_rate: 0.0000",
+	}
+	got := grouped.kvHeadLines()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	flat := newTestPlatformForKv(1, 0)
+	for head := 0; head < 8; head++ {
+		kvLoad(flat, head)
+	}
+	if flat.kvCacheHits != 0 || flat.kvShadowCache != nil {
+		t.Fatalf("expected no sharing without grouping, hits=%d", flat.kvCacheHits)
+	}
+}
+
+func TestKvHeadRegionsEvictIndependently(t *testing.T) {
+	t.Parallel()
+
+	// Two regions of 2 KiB each: head 1 thrashing its region must not evict head 0.
+	platform := newTestPlatformForKv(1, 2)
+	platform.config.KvCacheBytes = 4096
+	kvLoad(platform, 0)
+	for token := 0; token < 4; token++ {
+		platform.handleKvAccess("transfer_host2d", 1024, map[string]interface{}{
+			"kv_layer": 0,
+			"kv_head":  1,
+			"kv_seq":   0,
+			"kv_token": token,
+		})
+	}
+	kvLoad(platform, 0)
+
+	if counter := platform.kvHeadStats[0]; counter == nil || counter.hits != 1 {
+		t.Fatalf("expected head 0 entry to survive head 1 evictions, got %+v", counter)
+	}
+	if len(platform.kvHeadCaches) != 2 || platform.kvHeadCaches[0].CapacityBytes() != 2048 {
+		t.Fatalf("expected two 2 KiB regions, got %d", len(platform.kvHeadCaches))
+	}
+}
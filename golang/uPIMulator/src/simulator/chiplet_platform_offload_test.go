@@ -0,0 +1,168 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/host"
+)
+
+func runHostLoads(t *testing.T, offload bool) *ChipletPlatform {
+	t.Helper()
+	tempDir := t.TempDir()
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	t.Cleanup(platform.Fini)
+	platform.config.ActivationOffload = offload
+
+	chunk := platform.digitalChiplets[0].BufferCapacity("activation") / 4
+	for i := 0; i < 6; i++ {
+		platform.handleTransferTask(&chiplet.Task{Payload: &chiplet.CommandDescriptor{
+			Kind:         chiplet.CommandKindTransferHost2D,
+			PayloadBytes: uint32(chunk),
+		}})
+	}
+	return platform
+}
+
+func TestActivationOffloadSpillsAndReloads(t *testing.T) {
+	t.Parallel()
+
+	baseline := runHostLoads(t, false)
+	if baseline.transferThrottleEventsTotal == 0 || baseline.activationSpillBytes != 0 {
+		t.Fatalf("without offload an overfull buffer should defer, throttle=%d spill=%d",
+			baseline.transferThrottleEventsTotal, baseline.activationSpillBytes)
+	}
+	if len(baseline.activationResidents[0]) != 0 {
+		t.Fatalf("without offload no resident records should be kept, got %d", len(baseline.activationResidents[0]))
+	}
+
+	platform := runHostLoads(t, true)
+	chip := platform.digitalChiplets[0]
+	chunk := chip.BufferCapacity("activation") / 4
+	if platform.transferThrottleEventsTotal != 0 {
+		t.Fatalf("offload should absorb the overflow, got %d deferrals", platform.transferThrottleEventsTotal)
+	}
+	if platform.activationSpillBytes < 2*chunk || platform.activationSpilled[0] != platform.activationSpillBytes {
+		t.Fatalf("expected at least %d spilled bytes, got %d (resident on host %d)",
+			2*chunk, platform.activationSpillBytes, platform.activationSpilled[0])
+	}
+	if platform.activationOffloadDma <= 0 {
+		t.Fatalf("spills should charge host DMA cycles")
+	}
+	if chip.BufferUsage("activation") > chip.BufferCapacity("activation") {
+		t.Fatalf("buffer overcommitted: %d > %d", chip.BufferUsage("activation"), chip.BufferCapacity("activation"))
+	}
+
+	// Draining activations to host frees space, so spilled data comes back.
+	platform.handleTransferTask(&chiplet.Task{Payload: &chiplet.CommandDescriptor{
+		Kind:         chiplet.CommandKindTransferD2Host,
+		PayloadBytes: uint32(chunk),
+	}})
+	if platform.activationReloadBytes <= 0 {
+		t.Fatalf("expected spilled activations to reload after a release")
+	}
+	if platform.activationSpilled[0] != platform.activationSpillBytes-platform.activationReloadBytes {
+		t.Fatalf("spill bookkeeping mismatch: on host %d, spilled %d, reloaded %d",
+			platform.activationSpilled[0], platform.activationSpillBytes, platform.activationReloadBytes)
+	}
+}
+
+func TestActivationSpillLeavesTaskReservationsAlone(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	t.Cleanup(platform.Fini)
+	platform.config.ActivationOffload = true
+	chip := platform.digitalChiplets[0]
+	chunk := chip.BufferCapacity("activation") / 4
+	load := func(node int, bytes int64) {
+		platform.handleTransferTask(&chiplet.Task{NodeID: node, Payload: &chiplet.CommandDescriptor{
+			Kind:         chiplet.CommandKindTransferHost2D,
+			PayloadBytes: uint32(bytes),
+		}})
+	}
+
+	// 3/4 的激活空间由芯粒自身任务持有，不属于任何 host2d 搬运。
+	for i := 0; i < 3; i++ {
+		if !chip.AdjustBuffer("Activation", chunk) {
+			t.Fatalf("failed to seed task-owned activations")
+		}
+	}
+	for node := 1; node <= 4; node++ {
+		load(node, chunk)
+	}
+	spilled := map[int]int64{}
+	for _, entry := range platform.activationResidents[0] {
+		spilled[entry.owner] = entry.spilled
+	}
+	if spilled[1] != chunk || spilled[2] != chunk || spilled[3] != chunk || spilled[4] != 0 {
+		t.Fatalf("spills should be charged to the oldest host2d owners, got %v", spilled)
+	}
+	if got := chip.BufferUsage("activation"); got != 4*chunk {
+		t.Fatalf("task-owned activations must stay resident: usage=%d want %d", got, 4*chunk)
+	}
+
+	// 片上只有一个 host2d chunk 可溢出，需要两个 chunk 的搬运不能挤占任务持有的字节。
+	before := platform.transferThrottleEventsTotal
+	load(5, 2*chunk)
+	if platform.transferThrottleEventsTotal == before || platform.activationSpillEvents != 3 {
+		t.Fatalf("load larger than the spillable bytes should defer: deferrals=%d spills=%d",
+			platform.transferThrottleEventsTotal-before, platform.activationSpillEvents)
+	}
+
+	// 回写主机时先释放片上的 host2d 字节；已溢出的字节只从溢出记录中移除。
+	platform.handleTransferTask(&chiplet.Task{Payload: &chiplet.CommandDescriptor{
+		Kind:         chiplet.CommandKindTransferD2Host,
+		PayloadBytes: uint32(4 * chunk),
+	}})
+	if platform.activationSpilled[0] != 0 || len(platform.activationResidents[0]) != 0 {
+		t.Fatalf("draining should retire every host2d owner: spilled=%d residents=%d",
+			platform.activationSpilled[0], len(platform.activationResidents[0]))
+	}
+	if got := chip.BufferUsage("activation"); got != 3*chunk {
+		t.Fatalf("task-owned activations must survive the drain: usage=%d want %d", got, 3*chunk)
+	}
+}
+
+func TestActivationOffloadDmaCarriesEccBytes(t *testing.T) {
+	t.Parallel()
+
+	dmaCycles := func(eccPct int) int64 {
+		tempDir := t.TempDir()
+		parser := new(misc.CommandLineParser)
+		parser.Init()
+		parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+		parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+		parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+		platform := new(ChipletPlatform)
+		platform.Init(parser)
+		t.Cleanup(platform.Fini)
+		platform.config.EccOverheadPct = eccPct
+		platform.chargeActivationOffload(host.DMATransferHostToDigital, 1<<20)
+		if platform.hostDmaLoadBytesTotal != 1<<20 {
+			t.Fatalf("host DMA byte counters should report the payload, got %d", platform.hostDmaLoadBytesTotal)
+		}
+		return platform.activationOffloadDma
+	}
+
+	if plain, ecc := dmaCycles(0), dmaCycles(50); plain <= 0 || ecc <= plain {
+		t.Fatalf("ECC bytes should lengthen the offload DMA estimate, got %d -> %d", plain, ecc)
+	}
+}
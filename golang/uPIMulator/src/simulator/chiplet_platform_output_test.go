@@ -0,0 +1,88 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestRramOutputWriteBandwidthStallsTransfer(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.RramOutputWriteBw = 64
+	params := rram.DefaultParameters()
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)}
+	platform.rramProcessingBytes = make([]int64, 1)
+	platform.rramOutputBuffered = make([]int64, 1)
+	platform.rramOutputPending = make([]int64, 1)
+	platform.rramOutputLimited = make([]int64, 1)
+
+	transfer := &chiplet.Task{
+		Target: chiplet.TaskTargetTransfer,
+		Payload: &chiplet.CommandDescriptor{
+			Kind:  chiplet.CommandKindTransferD2C,
+			Flags: chiplet.TransferFlagRramToDigital,
+			Queue: 0,
+		},
+	}
+
+	// 256B of post results at 64B/cycle take four RRAM ticks to land.
+	platform.releaseRramOutputForChiplet(0, 256)
+	ticks := 0
+	for platform.isTargetBusy(transfer) {
+		platform.drainRramOutputWrites(0)
+		ticks++
+	}
+	if ticks != 4 {
+		t.Fatalf("expected transfer to stall for 4 write cycles, got %d", ticks)
+	}
+	if platform.rramOutputLimited[0] != 3 {
+		t.Fatalf("expected 3 write-limited cycles, got %d", platform.rramOutputLimited[0])
+	}
+	if got := platform.rramChiplets[0].BufferUsage("output"); got != 256 {
+		t.Fatalf("expected 256B in output buffer, got %d", got)
+	}
+
+	platform.config.RramOutputWriteBw = 0
+	platform.releaseRramOutputForChiplet(0, 256)
+	if platform.isTargetBusy(transfer) || platform.rramChiplets[0].BufferUsage("output") != 512 {
+		t.Fatalf("expected unlimited bandwidth to write immediately")
+	}
+}
+
+func TestRramBandwidthOverBusyCycles(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	params := rram.DefaultParameters()
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)}
+	platform.rramInputBuffered = make([]int64, 1)
+	platform.rramProcessingBytes = make([]int64, 1)
+	platform.rramOutputBuffered = make([]int64, 1)
+	platform.rramInputConsumed = make([]int64, 1)
+	platform.rramOutputProduced = make([]int64, 1)
+
+	chip := platform.rramChiplets[0]
+	chip.AdjustBuffer("input", 1024)
+	platform.rramInputBuffered[0] = 1024
+	platform.consumeRramInput(0, 1024)
+	platform.releaseRramOutputForChiplet(0, 256)
+
+	want := []string{
+		"RramChiplet[0]_input_bytes: 1024",
+		"RramChiplet[0]_output_bytes: 256",
+		"RramChiplet[0]_input_bw: 8.0000",
+		"RramChiplet[0]_output_bw: 2.0000",
+	}
+	got := platform.rramBandwidthLines(0, 128)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+	if idle := platform.rramBandwidthLines(0, 0); idle[2] != "RramChiplet[0]_input_bw: 0.0000" {
+		t.Fatalf("expected zero bandwidth without busy cycles, got %q", idle[2])
+	}
+}
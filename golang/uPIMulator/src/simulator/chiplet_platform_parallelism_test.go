@@ -0,0 +1,41 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestParallelismLinesReportSerialFraction(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	params := rram.DefaultParameters()
+	for id := 0; id < 2; id++ {
+		platform.rramChiplets = append(platform.rramChiplets, rram.NewChiplet(id, 1, 1, 128, 128, 2, 2, 12, 0, 0, params))
+	}
+
+	// Cycles: both busy, one busy twice, then idle.
+	for _, busy := range [][2]int{{1, 1}, {1, 0}, {0, 1}, {0, 0}} {
+		platform.rramChiplets[0].PendingTasks = busy[0]
+		platform.rramChiplets[1].PendingTasks = busy[1]
+		platform.currentCycle++
+		platform.accumulateParallelism()
+	}
+
+	want := []string{
+		"ChipletPlatform_avg_active_chiplets: 1.0000",
+		"ChipletPlatform_effective_parallelism: 0.5000",
+		"ChipletPlatform_serial_cycle_fraction: 0.5000",
+		"ChipletPlatform_serial_removed_speedup: 3.0000",
+	}
+	got := platform.parallelismLines()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
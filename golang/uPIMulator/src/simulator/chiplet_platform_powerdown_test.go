@@ -0,0 +1,66 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/digital"
+)
+
+func TestIdlePowerdownTradesStaticEnergyForWakeLatency(t *testing.T) {
+	t.Parallel()
+
+	run := func(continuous bool) (*ChipletPlatform, *recordingScheduler, int) {
+		platform := newTestPlatformForGating()
+		platform.config.IdlePowerdownCycles = 20
+		platform.config.WakeupLatency = 10
+		platform.digitalChiplets = []*digital.Chiplet{digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, digital.DefaultParameters())}
+		platform.powerdown = newPowerdownController(platform.config, 1, 0)
+		platform.stager = new(chiplet.HostTaskStager)
+		platform.stager.Init()
+		scheduler := &recordingScheduler{}
+		platform.scheduler = scheduler
+		if continuous {
+			platform.digitalChiplets[0].PendingTasks = 1
+		}
+
+		// 100 个周期的间隙之后到达一个任务。
+		for i := 0; i < 100; i++ {
+			platform.Cycle()
+		}
+		platform.stager.Enqueue(&chiplet.Task{
+			Target:  chiplet.TaskTargetDigital,
+			Payload: &chiplet.CommandDescriptor{ChipletID: 0},
+		})
+		waited := 0
+		for len(scheduler.issued) == 0 && !continuous {
+			platform.Cycle()
+			waited++
+			if waited > 100 {
+				t.Fatalf("task never issued after wake-up")
+			}
+		}
+		return platform, scheduler, waited
+	}
+
+	bursty, _, wait := run(false)
+	if bursty.powerdown.powerdowns != 1 || bursty.powerdown.wakeups != 1 || bursty.powerdown.wakeLatencyCycles != 10 {
+		t.Fatalf("expected one powerdown and a 10-cycle wake, got %d/%d/%d",
+			bursty.powerdown.powerdowns, bursty.powerdown.wakeups, bursty.powerdown.wakeLatencyCycles)
+	}
+	if wait < 10 {
+		t.Fatalf("task should wait out the wake latency, waited %d cycles", wait)
+	}
+	if bursty.powerdown.energySavedPJ <= 0 {
+		t.Fatalf("powering down through the gap should save static energy")
+	}
+
+	continuous, _, _ := run(true)
+	if continuous.powerdown.powerdowns != 0 || continuous.powerdown.energySavedPJ != 0 {
+		t.Fatalf("a busy chiplet must never power down, got %d powerdowns", continuous.powerdown.powerdowns)
+	}
+	if bursty.digitalChiplets[0].StaticEnergyPJ >= continuous.digitalChiplets[0].StaticEnergyPJ {
+		t.Fatalf("bursty run should spend less static energy: %.3f >= %.3f",
+			bursty.digitalChiplets[0].StaticEnergyPJ, continuous.digitalChiplets[0].StaticEnergyPJ)
+	}
+}
@@ -0,0 +1,146 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+// runWeightLoadTrace replays weight-load commands against one RRAM chiplet
+// that holds two of three 100-byte layers. A "+" prefix marks a prefetch.
+func runWeightLoadTrace(t *testing.T, trace []string) *ChipletPlatform {
+	t.Helper()
+	platform := newTestPlatformForGating()
+	params := rram.DefaultParameters()
+	params.WeightCapacityBytes = 200
+	platform.rramChiplets = []*rram.Chiplet{rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, params)}
+	chip := platform.rramChiplets[0]
+
+	for _, entry := range trace {
+		meta := map[string]interface{}{"weight_tag": entry}
+		if entry[0] == '+' {
+			meta["weight_tag"] = entry[1:]
+			meta["prefetch"] = true
+		}
+		cmd := &chiplet.CommandDescriptor{Kind: chiplet.CommandKindRramWeightLoad, Latency: 2, Metadata: meta}
+		platform.serveWeightLoad(0, cmd, &rram.TaskSpec{WeightSize: 100})
+		for chip.PendingTasks > 0 {
+			chip.Tick()
+			platform.currentCycle++
+		}
+		platform.currentCycle++
+	}
+	return platform
+}
+
+func TestWeightPrefetchImprovesBatchBoundaryHits(t *testing.T) {
+	t.Parallel()
+
+	// 上一批只留下 l2 驻留，空出的容量足够在批边界预取下一批的首层。
+	baseline := runWeightLoadTrace(t, []string{"l2", "l0", "l1", "l2"})
+	prefetch := runWeightLoadTrace(t, []string{"l2", "+l0", "l0", "l1", "l2"})
+
+	if hits := baseline.rramChiplets[0].WeightLoadHits; hits != 0 {
+		t.Fatalf("without prefetch every layer should miss under LRU, got %d hits", hits)
+	}
+	if hits := prefetch.rramChiplets[0].WeightLoadHits; hits != 1 {
+		t.Fatalf("prefetched first layer should hit, got %d hits", hits)
+	}
+	if prefetch.weightPrefetchLoads != 1 || prefetch.weightPrefetchHits != 1 || prefetch.weightPrefetchWasted != 0 {
+		t.Fatalf("unexpected prefetch accounting loads=%d hits=%d wasted=%d",
+			prefetch.weightPrefetchLoads, prefetch.weightPrefetchHits, prefetch.weightPrefetchWasted)
+	}
+
+	// A prefetch evicted by later demand loads before its own use is wasted.
+	wasted := runWeightLoadTrace(t, []string{"+l0", "l1", "l2", "l0"})
+	if wasted.weightPrefetchWasted != 1 || wasted.weightPrefetchHits != 0 {
+		t.Fatalf("expected one wasted prefetch, got wasted=%d hits=%d",
+			wasted.weightPrefetchWasted, wasted.weightPrefetchHits)
+	}
+}
+
+func TestWeightPrefetchNeverEvictsResidentWeights(t *testing.T) {
+	t.Parallel()
+
+	platform := runWeightLoadTrace(t, []string{"l0", "l1", "+l2"})
+	chip := platform.rramChiplets[0]
+	if platform.weightPrefetchLoads != 0 || platform.weightPrefetchSkipped != 1 {
+		t.Fatalf("a prefetch into a full directory should be skipped, loads=%d skipped=%d",
+			platform.weightPrefetchLoads, platform.weightPrefetchSkipped)
+	}
+	if chip.WeightEvictions() != 0 {
+		t.Fatalf("prefetch must not evict resident weights, got %d evictions", chip.WeightEvictions())
+	}
+	for _, tag := range []string{"l0", "l1"} {
+		if _, ok := chip.LookupWeights(0, 0, tag); !ok {
+			t.Fatalf("weight %s should still be resident", tag)
+		}
+	}
+}
+
+func TestWastedWeightPrefetchChargesWastedWork(t *testing.T) {
+	t.Parallel()
+
+	used := runWeightLoadTrace(t, []string{"l2", "+l0", "l0"})
+	if energy, cycles := used.wastedWork(); energy != 0 || cycles != 0 {
+		t.Fatalf("a prefetch consumed by a demand load is not wasted: energy=%.2f cycles=%d", energy, cycles)
+	}
+
+	wasted := runWeightLoadTrace(t, []string{"+l0", "l1", "l2", "l0"})
+	energy, cycles := wasted.wastedWork()
+	if energy <= 0 || cycles <= 0 {
+		t.Fatalf("an evicted prefetch should count as wasted work: energy=%.2f cycles=%d", energy, cycles)
+	}
+	if energy > wasted.rramChiplets[0].WeightLoadEnergyPJ+wasted.rramChiplets[0].ProgramEnergyPJ {
+		t.Fatalf("wasted energy %.2f exceeds the total weight-load energy", energy)
+	}
+}
+
+func TestWeightLoadServedFromNeighborChiplet(t *testing.T) {
+	t.Parallel()
+
+	// 返回把 16KB 权重装入 chiplet dst 所需的周期数；holder>=0 时该 chiplet 已驻留同一权重。
+	load := func(share bool, holder int) (*ChipletPlatform, int) {
+		platform := newTestPlatformForGating()
+		platform.config.RramWeightNeighborShare = share
+		platform.rramChiplets = make([]*rram.Chiplet, len(platform.topology.Rram.MeshCoords))
+		for id := range platform.rramChiplets {
+			platform.rramChiplets[id] = rram.NewChiplet(id, 1, 1, 128, 128, 2, 2, 12, 0, 0, rram.DefaultParameters())
+		}
+		if holder >= 0 {
+			platform.rramChiplets[holder].RegisterWeights(0, 0, "expert0", 16*1024, 0)
+		}
+
+		dst := platform.rramChiplets[1]
+		cmd := &chiplet.CommandDescriptor{Kind: chiplet.CommandKindRramWeightLoad, ChipletID: 1, Latency: 24,
+			Metadata: map[string]interface{}{"weight_tag": "expert0"}}
+		platform.serveWeightLoad(1, cmd, &rram.TaskSpec{WeightSize: 16 * 1024})
+		cycles := 0
+		for ; cycles < 1<<16; cycles++ {
+			if _, ok := dst.LookupWeights(0, 0, "expert0"); ok {
+				break
+			}
+			dst.Tick()
+			platform.currentCycle++
+		}
+		return platform, cycles
+	}
+
+	neighbors := newTestPlatformForGating().topology.RramNeighbors(1)
+	if len(neighbors) == 0 {
+		t.Fatalf("default topology should give RRAM chiplet 1 a neighbor")
+	}
+	host, hostCycles := load(false, neighbors[0])
+	shared, sharedCycles := load(true, neighbors[0])
+
+	if host.weightNeighborHits != 0 {
+		t.Fatalf("neighbor sharing disabled should load from the host, got %d neighbor hits", host.weightNeighborHits)
+	}
+	if shared.weightNeighborHits != 1 || shared.weightNeighborBytes != 16*1024 {
+		t.Fatalf("expected one 16KB neighbor copy, got hits=%d bytes=%d", shared.weightNeighborHits, shared.weightNeighborBytes)
+	}
+	if sharedCycles >= hostCycles {
+		t.Fatalf("neighbor copy should land sooner than a host load: neighbor=%d host=%d", sharedCycles, hostCycles)
+	}
+}
@@ -0,0 +1,42 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/misc"
+)
+
+func TestProfilerSplitsSchedulerAndDeviceTime(t *testing.T) {
+	t.Parallel()
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", "", "")
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	defer platform.Fini()
+	if platform.profiler != nil || platform.profileLines() != nil {
+		t.Fatalf("profiler should be disabled by default")
+	}
+	platform.config.Profile = 1
+	platform.profiler = newCycleProfiler(platform.config)
+
+	for i := 0; i < 10000 && !platform.IsFinished(); i++ {
+		platform.Cycle()
+	}
+
+	profiler := platform.profiler
+	if profiler.totalNs <= 0 || profiler.schedulerNs <= 0 || profiler.deviceNs <= 0 || profiler.executeNs <= 0 {
+		t.Fatalf("expected every section to be timed: %+v", *profiler)
+	}
+	scheduler, device, other := profiler.split()
+	if scheduler+device+other != profiler.totalNs {
+		t.Fatalf("sections %d+%d+%d do not add up to total %d", scheduler, device, other, profiler.totalNs)
+	}
+	if got := len(platform.profileLines()); got != 8 {
+		t.Fatalf("expected 8 profile stat lines, got %d", got)
+	}
+}
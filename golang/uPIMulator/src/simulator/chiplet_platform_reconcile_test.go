@@ -0,0 +1,39 @@
+package simulator
+
+import (
+	"reflect"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+	"uPIMulator/src/simulator/chiplet/digital"
+)
+
+func TestTaskCountReconciliationFlagsTokenPrepPath(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.digitalChiplets = []*digital.Chiplet{digital.NewChiplet(0, 1, 4, 4, 1, 0, 0, digital.DefaultParameters())}
+
+	want := []string{
+		"ChipletPlatform_task_count_reconciliation_delta[digital]: 0",
+		"ChipletPlatform_task_count_reconciliation_delta[rram]: 0",
+		"ChipletPlatform_task_count_reconciliation_delta: 0",
+	}
+	if got := platform.taskReconciliationLines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected balanced counters, got %v", got)
+	}
+
+	// token prep 在平台侧计数，但不会提交到任何 chiplet。
+	platform.handleDigitalTask(&chiplet.Task{
+		Target:  chiplet.TaskTargetDigital,
+		Payload: &chiplet.CommandDescriptor{Kind: chiplet.CommandKindPeTokenPrep, ChipletID: 0},
+	})
+	want = []string{
+		"ChipletPlatform_task_count_reconciliation_delta[digital]: 1",
+		"ChipletPlatform_task_count_reconciliation_delta[rram]: 0",
+		"ChipletPlatform_task_count_reconciliation_delta: 1",
+	}
+	if got := platform.taskReconciliationLines(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected token prep to show up as a delta, got %v", got)
+	}
+}
@@ -0,0 +1,235 @@
+package simulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet"
+)
+
+func TestDeadlockDumpsReplayableRepro(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+
+	// Node 1 waits on a node that never exists, so the graph stalls after node 0.
+	commands := []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Latency: 1},
+		{ID: 1, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Latency: 1, Dependencies: []int32{0, 99}},
+		{ID: 2, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Latency: 1, Dependencies: []int32{1}},
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "chiplet_commands.json"), data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	defer platform.Fini()
+	platform.config.DeadlockCycles = 50
+	platform.config.DumpOnError = true
+
+	for i := 0; i < 10000 && !platform.IsFinished(); i++ {
+		platform.Cycle()
+	}
+
+	if !platform.aborted {
+		t.Fatalf("expected deadlock abort, ran %d cycles", platform.currentCycle)
+	}
+
+	reproDir := filepath.Join(tempDir, "chiplet_repro")
+	for _, name := range []string{"chiplet_commands.json", "chiplet_config.json", "options.txt", "repro.txt"} {
+		if _, err := os.Stat(filepath.Join(reproDir, name)); err != nil {
+			t.Fatalf("expected %s in repro dir: %v", name, err)
+		}
+	}
+
+	raw, err := os.ReadFile(filepath.Join(reproDir, "chiplet_commands.json"))
+	if err != nil {
+		t.Fatalf("read repro commands: %v", err)
+	}
+	var remaining []chiplet.CommandDescriptor
+	if err := json.Unmarshal(raw, &remaining); err != nil {
+		t.Fatalf("decode repro commands: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0].ID != 1 || remaining[1].ID != 2 {
+		t.Fatalf("expected remaining nodes 1 and 2, got %+v", remaining)
+	}
+	if deps := remaining[0].Dependencies; len(deps) != 1 || deps[0] != 99 {
+		t.Fatalf("expected node 1 to keep only the dangling dep, got %v", deps)
+	}
+
+	// Replay restores the saved config rather than the current command line.
+	replayConfig := restoreReproConfig(&chiplet.Config{ReproPath: reproDir})
+	if replayConfig.DeadlockCycles != 50 || !replayConfig.DumpOnError || replayConfig.ReproPath != reproDir {
+		t.Fatalf("replay config not restored from the snapshot: %+v", replayConfig)
+	}
+	if replayConfig.NumDigitalChiplets != platform.config.NumDigitalChiplets {
+		t.Fatalf("expected %d digital chiplets on replay, got %d", platform.config.NumDigitalChiplets, replayConfig.NumDigitalChiplets)
+	}
+
+	// Replaying the snapshot must hit the same deadlock.
+	replay := new(chiplet.HostOrchestrator)
+	replay.Init(replayConfig, platform.topology, filepath.Join(reproDir, "chiplet_commands.json"))
+	defer replay.Fini()
+	for i := 0; i < 100; i++ {
+		if tasks := replay.Advance(); len(tasks) > 0 {
+			t.Fatalf("replay should stay blocked, issued %d tasks", len(tasks))
+		}
+	}
+	if blocked := replay.BlockedNodes(); blocked != 2 {
+		t.Fatalf("replay should leave both nodes blocked, got %d", blocked)
+	}
+}
+
+func TestMaxCyclesStopsRunWithPendingWork(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+
+	// A long dependency chain that needs far more than 30 cycles to drain.
+	commands := make([]chiplet.CommandDescriptor, 0, 200)
+	for i := 0; i < 200; i++ {
+		cmd := chiplet.CommandDescriptor{ID: int32(i), Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Latency: 1}
+		if i > 0 {
+			cmd.Dependencies = []int32{int32(i - 1)}
+		}
+		commands = append(commands, cmd)
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "chiplet_commands.json"), data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	defer platform.Fini()
+	platform.config.DeadlockCycles = 0
+	platform.config.MaxCycles = 30
+
+	for i := 0; i < 10000 && !platform.IsFinished(); i++ {
+		platform.Cycle()
+	}
+
+	if !platform.cycleCapReached || platform.aborted {
+		t.Fatalf("expected cycle cap stop without abort, capped=%v aborted=%v", platform.cycleCapReached, platform.aborted)
+	}
+	if platform.currentCycle != 30 {
+		t.Fatalf("expected run to stop at cycle 30, got %d", platform.currentCycle)
+	}
+}
+
+func TestFinalGraphDumpRecordsExecutedNodes(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	commands := []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Latency: 1},
+		{ID: 1, Kind: chiplet.CommandKindPeElementwise, Target: chiplet.TaskTargetDigital, ChipletID: -1, Latency: 1, Dependencies: []int32{0}},
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "chiplet_commands.json"), data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	defer platform.Fini()
+	platform.config.DumpFinalGraph = 1
+
+	for i := 0; i < 10000 && !platform.IsFinished(); i++ {
+		platform.Cycle()
+	}
+	platform.Dump()
+
+	raw, err := os.ReadFile(filepath.Join(tempDir, "chiplet_graph_final.json"))
+	if err != nil {
+		t.Fatalf("read final graph: %v", err)
+	}
+	var nodes []chiplet.GraphNodeSnapshot
+	if err := json.Unmarshal(raw, &nodes); err != nil {
+		t.Fatalf("decode final graph: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %+v", nodes)
+	}
+	elementwise := nodes[1]
+	if elementwise.Kind != chiplet.CommandKindPeElementwise.String() || elementwise.Target != "digital" || elementwise.State != "completed" {
+		t.Fatalf("unexpected node 1 snapshot %+v", elementwise)
+	}
+	if elementwise.ChipletID < 0 || len(elementwise.Deps) != 1 || elementwise.Deps[0] != 0 {
+		t.Fatalf("node 1 should record its resolved chiplet and dependency on node 0: %+v", elementwise)
+	}
+}
+
+func TestUndeliverableTaskDumpsRepro(t *testing.T) {
+	t.Parallel()
+
+	run := func(dumpOnError bool, task *chiplet.Task) (*ChipletPlatform, string) {
+		tempDir := t.TempDir()
+		parser := new(misc.CommandLineParser)
+		parser.Init()
+		parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+		parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+		parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+		platform := new(ChipletPlatform)
+		platform.Init(parser)
+		t.Cleanup(platform.Fini)
+		platform.config.DumpOnError = dumpOnError
+		platform.ExecuteTask(task)
+		return platform, filepath.Join(tempDir, "chiplet_repro")
+	}
+
+	unknownTarget := func() *chiplet.Task {
+		return &chiplet.Task{NodeID: 3, Target: chiplet.TaskTarget(99), Payload: &chiplet.CommandDescriptor{ID: 3}}
+	}
+	outOfRange := func() *chiplet.Task {
+		return &chiplet.Task{NodeID: 4, Target: chiplet.TaskTargetRram,
+			Payload: &chiplet.CommandDescriptor{ID: 4, Kind: chiplet.CommandKindRramExecute, Target: chiplet.TaskTargetRram, ChipletID: 1 << 10}}
+	}
+
+	if platform, _ := run(false, unknownTarget()); platform.aborted {
+		t.Fatalf("without --chiplet_dump_on_error an unknown target should be dropped, got abort %q", platform.abortReason)
+	}
+	for name, task := range map[string]*chiplet.Task{"unknown target": unknownTarget(), "out-of-range chiplet": outOfRange()} {
+		platform, reproDir := run(true, task)
+		if !platform.aborted || !strings.Contains(platform.abortReason, "undeliverable task") {
+			t.Fatalf("%s: expected an undeliverable-task abort, got aborted=%v reason=%q", name, platform.aborted, platform.abortReason)
+		}
+		if _, err := os.Stat(filepath.Join(reproDir, "repro.txt")); err != nil {
+			t.Fatalf("%s: expected a repro snapshot: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package simulator
+
+import (
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// runRetirementChain 驱动 bootstrap 的 6 节点依赖链：每个周期先发射就绪节点并立即
+// 完成，再在周期末退休到期任务。返回最后一个节点的发射周期。
+func runRetirementChain(t *testing.T, latency int) (*ChipletPlatform, int) {
+	t.Helper()
+
+	platform := newTestPlatformForGating()
+	platform.config.RetirementLatency = latency
+	orchestrator := new(chiplet.HostOrchestrator)
+	orchestrator.Init(platform.config, platform.topology, "")
+	platform.orchestrator = orchestrator
+
+	lastIssue := -1
+	for cycle := 1; cycle <= 1000 && orchestrator.HasPendingWork(); cycle++ {
+		platform.currentCycle = cycle
+		for _, task := range orchestrator.Advance() {
+			lastIssue = cycle
+			platform.retireTask(task.NodeID)
+		}
+		platform.drainRetirements()
+	}
+	if orchestrator.HasPendingWork() || len(platform.retirementQueue) != 0 {
+		t.Fatalf("latency=%d: chain did not drain", latency)
+	}
+	return platform, lastIssue
+}
+
+func TestRetirementLatencyAccumulatesAlongDependencyChain(t *testing.T) {
+	t.Parallel()
+
+	const latency = 5
+	base, baseIssue := runRetirementChain(t, 0)
+	delayed, delayedIssue := runRetirementChain(t, latency)
+
+	// 6 节点链有 5 条依赖边，每一跳都要多等一个 retirement 延迟。
+	if got, want := delayedIssue-baseIssue, 5*latency; got != want {
+		t.Fatalf("last node issued %d cycles later, want %d", got, want)
+	}
+	if base.retirementStallCycles != 0 || base.retiredTasks != 6 {
+		t.Fatalf("no retirement latency: stall=%d retired=%d, want 0/6", base.retirementStallCycles, base.retiredTasks)
+	}
+	if delayed.retirementStallCycles != 6*latency || delayed.retiredTasks != 6 {
+		t.Fatalf("retirement stall=%d retired=%d, want %d/6", delayed.retirementStallCycles, delayed.retiredTasks, 6*latency)
+	}
+}
+
+func TestBarrierCostGrowsWithProducerFanIn(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.BarrierProducerCycles = 4
+
+	wait := map[int]int{}
+	for _, producers := range []int{2, 8} {
+		task := &chiplet.Task{
+			NodeID: producers,
+			Target: chiplet.TaskTargetDigital,
+			Payload: &chiplet.CommandDescriptor{
+				Kind:     chiplet.CommandKindPeBarrier,
+				Target:   chiplet.TaskTargetDigital,
+				Latency:  1,
+				Metadata: map[string]interface{}{"op": "moe_barrier", "producers": producers},
+			},
+		}
+		wait[producers] = platform.barrierWaitCycles(task)
+		platform.retireTaskAfter(task.NodeID, wait[producers])
+	}
+
+	if wait[2] != 1+2*4 || wait[8] != 1+8*4 {
+		t.Fatalf("barrier waits = %v, want 9 for 2 producers and 33 for 8", wait)
+	}
+	if len(platform.retirementQueue) != 2 || platform.retirementQueue[1].readyCycle != 33 {
+		t.Fatalf("8-producer barrier should hold its dependents for 33 cycles: %+v", platform.retirementQueue)
+	}
+	if platform.barrierWaitCyclesTotal != 42 || platform.barrierProducers != 10 || platform.barriers != 2 {
+		t.Fatalf("barrier stats total=%d producers=%d barriers=%d, want 42/10/2",
+			platform.barrierWaitCyclesTotal, platform.barrierProducers, platform.barriers)
+	}
+
+	// 非 barrier 任务不受影响。
+	if got := platform.barrierWaitCycles(&chiplet.Task{Payload: &chiplet.CommandDescriptor{Kind: chiplet.CommandKindPeGemm}}); got != 0 {
+		t.Fatalf("non-barrier task should not wait, got %d", got)
+	}
+}
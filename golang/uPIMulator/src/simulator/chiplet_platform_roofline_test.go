@@ -0,0 +1,40 @@
+package simulator
+
+import "testing"
+
+func TestRooflineLinesClassifyLayersAgainstRidge(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.topology.Digital.NumChiplets = 4
+	platform.topology.Digital.PesPerChiplet = 2
+	platform.topology.Digital.PeRows = 16
+	platform.topology.Digital.PeCols = 16
+	platform.config.TransferBandwidthDr = 64
+	platform.rooflineStats = map[string]*rooflineEntry{
+		"attn_qk": {tasks: 1, flops: 128 * 1024, bytes: 1024},
+		"softmax": {tasks: 1, flops: 1024, bytes: 1024},
+	}
+
+	// 4 个芯粒合计 4×2×16×16 = 2048 MAC/cycle = 4096 flops/cycle，带宽 64 B/cycle，
+	// ridge = 64 flops/byte。
+	want := []string{
+		"ChipletPlatform_roofline_peak_macs_per_cycle: 2048",
+		"ChipletPlatform_roofline_peak_flops_per_cycle: 4096.00",
+		"ChipletPlatform_roofline_bandwidth_bytes_per_cycle: 64.00",
+		"ChipletPlatform_roofline_ridge_intensity: 64.000000",
+		"ChipletPlatform_roofline[attn_qk]_intensity: 128.000000",
+		"ChipletPlatform_roofline[attn_qk]_bound[compute]: 1",
+		"ChipletPlatform_roofline[softmax]_intensity: 1.000000",
+		"ChipletPlatform_roofline[softmax]_bound[memory]: 1",
+	}
+	got := platform.rooflineLines()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d lines, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package simulator
+
+import (
+	"math/rand"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestResultSamplingKeepsFirstLastAndOutliers(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.ResultSampleRate = 0
+	platform.config.ResultErrorThreshold = 0.5
+	platform.resultSampler = rand.New(rand.NewSource(1))
+	platform.resultSeen = make([]bool, 2)
+	platform.resultTail = make([]string, 2)
+	platform.resultLog = []string{"header"}
+
+	normal := rram.ResultSummary{Valid: true, Final: 1.0, Reference: 1.1, HasReference: true}
+	outlier := rram.ResultSummary{Valid: true, Final: 1.0, Reference: 3.0, HasReference: true}
+
+	platform.recordRramResult(0, normal)  // first result, kept
+	platform.recordRramResult(0, normal)  // dropped
+	platform.recordRramResult(0, outlier) // anomaly, kept
+	platform.recordRramResult(0, normal)  // dropped, but last for chiplet 0
+	platform.recordRramResult(1, normal)  // first result, kept
+
+	if got := len(platform.resultLog) - 1; got != 3 {
+		t.Fatalf("expected 3 sampled rows before flush, got %d", got)
+	}
+
+	platform.flushResultTails()
+
+	if platform.resultsTotal != 5 {
+		t.Fatalf("expected 5 total results, got %d", platform.resultsTotal)
+	}
+	if platform.resultsSampled != 4 {
+		t.Fatalf("expected 4 sampled results after flush, got %d", platform.resultsSampled)
+	}
+	if got := len(platform.resultLog) - 1; got != 4 {
+		t.Fatalf("expected 4 rows after flush, got %d", got)
+	}
+}
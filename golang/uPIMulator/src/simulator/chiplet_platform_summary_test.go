@@ -0,0 +1,96 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+func TestPrintSummaryReportsTotalsAndWarnings(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.currentCycle = 100
+	platform.totalTransferBytes = 4096
+	platform.lmHeadTokens = 4
+	platform.aborted = true
+	platform.abortReason = "deadlock: test"
+
+	platform.config.Summary = 0
+	if platform.summaryEnabled() {
+		t.Fatalf("expected --chiplet_summary=0 to disable the summary")
+	}
+	platform.config.Summary = 1
+	if !platform.summaryEnabled() {
+		t.Fatalf("expected --chiplet_summary=1 to enable the summary")
+	}
+
+	var output bytes.Buffer
+	platform.printSummary(&output)
+
+	for _, want := range []string{
+		"cycles                 100",
+		"tokens                 4",
+		"transfer_bytes         4096",
+		"warning                aborted at cycle 100: deadlock: test",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Fatalf("summary missing %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestWarningsFileListsCategoriesWithSeverity(t *testing.T) {
+	t.Parallel()
+
+	readWarnings := func(platform *ChipletPlatform) []platformWarning {
+		platform.writeWarningsFile()
+		raw, err := os.ReadFile(filepath.Join(platform.binDirpath, "chiplet_warnings.json"))
+		if err != nil {
+			t.Fatalf("read warnings: %v", err)
+		}
+		var warnings []platformWarning
+		if err := json.Unmarshal(raw, &warnings); err != nil {
+			t.Fatalf("decode warnings %s: %v", raw, err)
+		}
+		if warnings == nil {
+			t.Fatalf("expected a JSON array, got %s", raw)
+		}
+		return warnings
+	}
+
+	clean := newTestPlatformForGating()
+	clean.binDirpath = t.TempDir()
+	if warnings := readWarnings(clean); len(warnings) != 0 {
+		t.Fatalf("expected an empty array for a clean run, got %+v", warnings)
+	}
+
+	noisy := newTestPlatformForGating()
+	noisy.binDirpath = t.TempDir()
+	noisy.currentCycle = 10
+	noisy.aborted = true
+	noisy.abortReason = "deadlock: test"
+	noisy.unrecognizedCommands = 3
+	noisy.rramChiplets = append(noisy.rramChiplets, rram.NewChiplet(0, 1, 1, 128, 128, 2, 2, 12, 0, 0, rram.DefaultParameters()))
+
+	want := map[string]platformWarning{
+		"abort":                {Severity: "error", Count: 1},
+		"unrecognized_command": {Severity: "warning", Count: 3},
+		"idle_chiplet":         {Severity: "info", Count: 1},
+	}
+	warnings := readWarnings(noisy)
+	if len(warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %+v", len(want), warnings)
+	}
+	for _, warning := range warnings {
+		expected, ok := want[warning.Category]
+		if !ok || warning.Severity != expected.Severity || warning.Count != expected.Count || warning.Message == "" {
+			t.Fatalf("unexpected warning %+v", warning)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package simulator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotIntervalAppendsCumulativeRows(t *testing.T) {
+	platform := newTestPlatformForGating()
+	platform.binDirpath = t.TempDir()
+	platform.snapshotInterval = 10
+	platform.nextSnapshotCycle = 10
+	platform.lastSnapshotCycle = -1
+	platform.timeseriesLog = []string{timeseriesHeader}
+	t.Cleanup(platform.Fini)
+
+	var file *os.File
+	for cycle := 1; cycle <= 25; cycle++ {
+		platform.currentCycle = cycle
+		platform.totalTransferBytes += 100
+		platform.maybeSnapshot()
+		if cycle == 10 {
+			file = platform.timeseriesFile
+		}
+	}
+	// 文件只打开一次，每次快照只追加新行，已写出的行不再留在内存里重写。
+	if file == nil || platform.timeseriesFile != file || len(platform.timeseriesLog) != 0 {
+		t.Fatalf("expected one open file and no buffered rows, file=%v reused=%v buffered=%d",
+			file != nil, platform.timeseriesFile == file, len(platform.timeseriesLog))
+	}
+	if platform.snapshotCount() != 2 {
+		t.Fatalf("expected 2 snapshots by cycle 25, got %d", platform.snapshotCount())
+	}
+
+	data, err := os.ReadFile(filepath.Join(platform.binDirpath, "chiplet_timeseries.csv"))
+	if err != nil {
+		t.Fatalf("read timeseries: %v", err)
+	}
+	rows := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(rows) != 3 || rows[0] != timeseriesHeader {
+		t.Fatalf("expected header plus 2 rows, got %q", rows)
+	}
+	if !strings.HasPrefix(rows[1], "10,") || !strings.HasPrefix(rows[2], "20,") {
+		t.Fatalf("expected snapshots at cycles 10 and 20, got %q", rows[1:])
+	}
+	// 早先的行保留在文件中，值是当时的累计值而非被覆盖。
+	if fields := strings.Split(rows[1], ","); fields[5] != "1000" {
+		t.Fatalf("expected cumulative transfer bytes 1000 at cycle 10, got %s", fields[5])
+	}
+	if fields := strings.Split(rows[2], ","); fields[5] != "2000" {
+		t.Fatalf("expected cumulative transfer bytes 2000 at cycle 20, got %s", fields[5])
+	}
+}
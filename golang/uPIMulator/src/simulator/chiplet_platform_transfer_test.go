@@ -0,0 +1,484 @@
+package simulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet"
+)
+
+func TestTransferMinLatencyFloorAppliesToNocEstimate(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.TransferBandwidthDr = 4096
+	platform.config.TransferMinLatency = 16
+
+	// 64B over 1 hop costs 2 cycles in the bandwidth model; the floor raises it.
+	if got := platform.estimateNocCycles("transfer_to_rram", 64, 1, 0, 0, -1, -1, nil); got != 16 {
+		t.Fatalf("expected floored latency 16, got %d", got)
+	}
+	// A large transfer already exceeds the floor and is left unchanged.
+	if got := platform.estimateNocCycles("transfer_to_rram", 4096*32, 1, 0, 0, -1, -1, nil); got != 33 {
+		t.Fatalf("expected unfloored latency 33, got %d", got)
+	}
+	if platform.transferFloorHits != 1 {
+		t.Fatalf("expected 1 floor hit, got %d", platform.transferFloorHits)
+	}
+
+	platform.config.TransferMinLatency = 0
+	if got := platform.estimateNocCycles("transfer_to_rram", 64, 1, 0, 0, -1, -1, nil); got != 2 {
+		t.Fatalf("expected floor disabled, got %d", got)
+	}
+}
+
+func TestBooksimCoverageCountsFallbackTransfers(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.estimateNocCycles("transfer_to_rram", 64, 1, 0, 0, -1, -1, nil)
+	if platform.booksimEstimated != 0 || platform.booksimFallbacks != 0 {
+		t.Fatalf("without BookSim requested nothing should be counted, got %d/%d", platform.booksimEstimated, platform.booksimFallbacks)
+	}
+
+	// BookSim 已请求但客户端不可用：每次估计都落回带宽模型。
+	platform.config.NocUseBooksim = true
+	platform.estimateNocCycles("transfer_to_rram", 64, 1, 0, 0, -1, -1, nil)
+	platform.estimateNocCycles("transfer_to_digital", 64, 1, -1, -1, 0, 0, nil)
+	if platform.booksimFallbacks != 2 || booksimCoverage(platform.booksimEstimated, platform.booksimFallbacks) != 0 {
+		t.Fatalf("expected 2 fallbacks and zero coverage, got %d fallbacks", platform.booksimFallbacks)
+	}
+	if got := booksimCoverage(3, 1); got != 0.75 {
+		t.Fatalf("expected coverage 0.75, got %.4f", got)
+	}
+}
+
+type recordingScheduler struct {
+	issued []*chiplet.Task
+}
+
+func (this *recordingScheduler) Init(*chiplet.Config, *chiplet.Topology, chiplet.TaskExecutor) {}
+func (this *recordingScheduler) Fini()                                                         {}
+func (this *recordingScheduler) EnqueueTask(task *chiplet.Task) {
+	this.issued = append(this.issued, task)
+}
+func (this *recordingScheduler) Tick()        {}
+func (this *recordingScheduler) IsIdle() bool { return true }
+
+func TestTransferPriorityLetsCriticalPreemptBulk(t *testing.T) {
+	t.Parallel()
+
+	transfer := func(class string, bytes int, enqueue int) *chiplet.Task {
+		return &chiplet.Task{
+			Target:       chiplet.TaskTargetTransfer,
+			EnqueueCycle: enqueue,
+			Payload: &chiplet.CommandDescriptor{
+				Kind:         chiplet.CommandKindTransferC2D,
+				Flags:        chiplet.TransferFlagDigitalToRram,
+				PayloadBytes: uint32(bytes),
+				Metadata:     map[string]interface{}{"priority": class},
+			},
+		}
+	}
+
+	run := func(priority int) *ChipletPlatform {
+		tempDir := t.TempDir()
+		parser := new(misc.CommandLineParser)
+		parser.Init()
+		parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+		parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+		parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+		platform := new(ChipletPlatform)
+		platform.Init(parser)
+		t.Cleanup(platform.Fini)
+		platform.orchestrator = nil
+		platform.config.TransferMode = "cycle_accurate"
+		platform.config.TransferBandwidthDr = 64
+		platform.config.TransferPriority = priority
+		scheduler := &recordingScheduler{}
+		platform.scheduler = scheduler
+
+		// 两个 bulk 传输先占满链路，critical 传输在链路忙时到达。
+		platform.stager.Enqueue(transfer("bulk", 4096, 0))
+		platform.stager.Enqueue(transfer("bulk", 4096, 0))
+		for platform.currentCycle <= 10 || platform.stager.HasPending() || len(platform.inflightTransfers) > 0 {
+			if platform.currentCycle == 10 {
+				platform.stager.Enqueue(transfer("critical", 512, 10))
+			}
+			platform.drainStager()
+			for _, task := range scheduler.issued {
+				platform.ExecuteTask(task)
+			}
+			scheduler.issued = nil
+			platform.runInterconnectTick()
+			platform.currentCycle++
+			platform.completeInflightTransfers()
+			if platform.currentCycle > 1000 {
+				t.Fatalf("priority=%d: transfers did not drain", priority)
+			}
+		}
+		if platform.retiredTasks != 3 {
+			t.Fatalf("priority=%d: expected 3 retired transfers, got %d", priority, platform.retiredTasks)
+		}
+		return platform
+	}
+
+	fifo := run(0)
+	if fifo.transferPreemptions != 0 {
+		t.Fatalf("strict FIFO must not preempt, got %d", fifo.transferPreemptions)
+	}
+
+	prio := run(1)
+	if prio.transferPreemptions != 2 {
+		t.Fatalf("expected both bulk transfers to be preempted, got %d", prio.transferPreemptions)
+	}
+	if len(prio.preemptedTransfers) != 0 {
+		t.Fatalf("preempted transfers were not resumed: %d left", len(prio.preemptedTransfers))
+	}
+	critical := prio.transferPriorityLatency["critical"]
+	bulk := prio.transferPriorityLatency["bulk"]
+	if critical.samples != 1 || bulk.samples != 2 {
+		t.Fatalf("expected 1 critical and 2 bulk completions, got %d and %d", critical.samples, bulk.samples)
+	}
+	fifoCritical := fifo.transferPriorityLatency["critical"].average()
+	fifoBulk := fifo.transferPriorityLatency["bulk"].average()
+	if critical.average() >= fifoCritical {
+		t.Fatalf("preemption should cut critical latency: fifo=%.2f prio=%.2f", fifoCritical, critical.average())
+	}
+	if bulk.average() <= fifoBulk {
+		t.Fatalf("bulk should pay for the preemption: fifo=%.2f prio=%.2f", fifoBulk, bulk.average())
+	}
+	// FIFO 下 critical 排在最后，其完成时刻即链路排空时刻；抢占后 bulk 只重发剩余
+	// 字节，链路总占用不变，最后一个 bulk 应在同一周期完成。
+	if drained := 10 + int(fifoCritical); prio.currentCycle != drained {
+		t.Fatalf("re-queued bulk transfers should only resend their remaining bytes: link drained at %d, want %d",
+			prio.currentCycle, drained)
+	}
+}
+
+func TestAnalyticalTransferModeSkipsLinkOccupancy(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{"cycle_accurate", "analytical"} {
+		platform := newTestPlatformForGating()
+		platform.config.TransferMode = mode
+		platform.stager = new(chiplet.HostTaskStager)
+		platform.stager.Init()
+		scheduler := &recordingScheduler{}
+		platform.scheduler = scheduler
+
+		platform.occupyInterconnect(8)
+		platform.stager.Enqueue(&chiplet.Task{Target: chiplet.TaskTargetTransfer, Payload: &chiplet.CommandDescriptor{}})
+		platform.drainStager()
+
+		analytical := mode == "analytical"
+		if platform.transferMode() != mode {
+			t.Fatalf("expected mode %s, got %s", mode, platform.transferMode())
+		}
+		if analytical {
+			if platform.transferThrottleUntil != 0 || platform.analyticalTransferCycles != 8 || len(scheduler.issued) != 1 {
+				t.Fatalf("analytical: throttle=%d budget=%d issued=%d, want 0/8/1",
+					platform.transferThrottleUntil, platform.analyticalTransferCycles, len(scheduler.issued))
+			}
+			continue
+		}
+		if platform.transferThrottleUntil != 8 || platform.analyticalTransferCycles != 0 || len(scheduler.issued) != 0 {
+			t.Fatalf("cycle_accurate: throttle=%d budget=%d issued=%d, want 8/0/0",
+				platform.transferThrottleUntil, platform.analyticalTransferCycles, len(scheduler.issued))
+		}
+	}
+}
+
+func TestHalfDuplexSerializesOpposingTransfers(t *testing.T) {
+	t.Parallel()
+
+	latency := map[int]int{}
+	for _, halfDuplex := range []int{0, 1} {
+		platform := newTestPlatformForGating()
+		platform.config.HalfDuplex = halfDuplex
+
+		// 同一周期内同一条链路上的 to_rram 与 to_digital 传输。
+		toRram := platform.shareDuplexLink("transfer_to_rram", 0, 0, 10)
+		toDigital := platform.shareDuplexLink("transfer_to_digital", 0, 0, 10)
+		if toRram != 10 {
+			t.Fatalf("half_duplex=%d: first transfer should not wait, got %d", halfDuplex, toRram)
+		}
+		latency[halfDuplex] = toDigital
+
+		// 同向传输以及其他链路不受影响。
+		if sameDir := platform.shareDuplexLink("transfer_to_digital", 0, 0, 10); sameDir != 10 {
+			t.Fatalf("half_duplex=%d: same-direction transfer waited, got %d", halfDuplex, sameDir)
+		}
+		if other := platform.shareDuplexLink("transfer_to_rram", 1, 0, 10); other != 10 {
+			t.Fatalf("half_duplex=%d: transfer on another link waited, got %d", halfDuplex, other)
+		}
+	}
+
+	if latency[0] != 10 {
+		t.Fatalf("full duplex should overlap opposing transfers, got %d", latency[0])
+	}
+	if latency[1] != 20 {
+		t.Fatalf("half duplex should serialize opposing transfers, got %d", latency[1])
+	}
+}
+
+func TestHalfDuplexCountsContention(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.config.HalfDuplex = 1
+
+	platform.shareDuplexLink("transfer_to_rram", 0, 0, 12)
+	platform.currentCycle = 4
+	if got := platform.shareDuplexLink("transfer_to_digital", 0, 0, 6); got != 14 {
+		t.Fatalf("expected 8 wait + 6 transfer cycles, got %d", got)
+	}
+	platform.currentCycle = 40
+	if got := platform.shareDuplexLink("transfer_to_rram", 0, 0, 6); got != 6 {
+		t.Fatalf("link is idle again, expected no wait, got %d", got)
+	}
+	if platform.duplexContentionEvents != 1 || platform.duplexContentionCycles != 8 {
+		t.Fatalf("contention events=%d cycles=%d, want 1/8",
+			platform.duplexContentionEvents, platform.duplexContentionCycles)
+	}
+	if platform.linkDuplexMode() != "half" {
+		t.Fatalf("expected half duplex mode, got %s", platform.linkDuplexMode())
+	}
+}
+
+func TestTransferInflightBytesTrackBacklog(t *testing.T) {
+	t.Parallel()
+
+	platform := newTestPlatformForGating()
+	platform.stager = new(chiplet.HostTaskStager)
+	platform.stager.Init()
+
+	for _, bytes := range []uint32{4096, 2048} {
+		platform.SubmitTask(&chiplet.Task{
+			Target:  chiplet.TaskTargetTransfer,
+			Payload: &chiplet.CommandDescriptor{PayloadBytes: bytes},
+		})
+	}
+	// 非传输任务不计入。
+	platform.SubmitTask(&chiplet.Task{Target: chiplet.TaskTargetHost, Payload: &chiplet.CommandDescriptor{PayloadBytes: 512}})
+	if platform.transferIssuedBytes != 6144 || platform.transferInflightPeakBytes != 6144 {
+		t.Fatalf("issued=%d peak=%d, want 6144/6144", platform.transferIssuedBytes, platform.transferInflightPeakBytes)
+	}
+
+	platform.transferCompletedBytes += 4096
+	platform.transferDroppedBytes += 2048
+	if inflight := platform.transferInflightBytes(); inflight != 0 {
+		t.Fatalf("expected drained backlog, got %d in flight", inflight)
+	}
+	if platform.transferInflightPeakBytes != 6144 {
+		t.Fatalf("peak should be retained, got %d", platform.transferInflightPeakBytes)
+	}
+}
+
+func TestEccOverheadInflatesTransferCyclesNotOccupancy(t *testing.T) {
+	t.Parallel()
+
+	run := func(pct int) *ChipletPlatform {
+		tempDir := t.TempDir()
+		parser := new(misc.CommandLineParser)
+		parser.Init()
+		parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+		parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+		parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+		platform := new(ChipletPlatform)
+		platform.Init(parser)
+		t.Cleanup(platform.Fini)
+		platform.config.TransferMode = "analytical"
+		platform.config.TransferBandwidthDr = 64
+		platform.config.EccOverheadPct = pct
+
+		platform.handleTransferTask(&chiplet.Task{Payload: &chiplet.CommandDescriptor{
+			Kind:         chiplet.CommandKindTransferC2D,
+			Flags:        chiplet.TransferFlagDigitalToRram,
+			PayloadBytes: 8192,
+		}})
+		return platform
+	}
+
+	plain := run(0)
+	ecc := run(25)
+	if plain.eccOverheadBytes != 0 || ecc.eccOverheadBytes != 2048 {
+		t.Fatalf("ecc overhead bytes: plain=%d ecc=%d, want 0/2048", plain.eccOverheadBytes, ecc.eccOverheadBytes)
+	}
+	// 8192B 载荷在 64B/cycle 下需 128 周期，25% ECC 额外 32 周期。
+	if delta := ecc.analyticalTransferCycles - plain.analyticalTransferCycles; delta != 32 {
+		t.Fatalf("expected ECC to add 32 transfer cycles, got %d (plain=%d ecc=%d)",
+			delta, plain.analyticalTransferCycles, ecc.analyticalTransferCycles)
+	}
+	if got := ecc.rramChiplets[0].BufferUsage("input"); got != 8192 {
+		t.Fatalf("buffer occupancy should stay at the payload size, got %d", got)
+	}
+}
+
+// runCommandGraph 把 commands 写入临时 bin 目录，按 tune 调整配置后运行平台直到结束。
+func runCommandGraph(t *testing.T, commands []chiplet.CommandDescriptor, tune func(*chiplet.Config)) *ChipletPlatform {
+	t.Helper()
+	tempDir := t.TempDir()
+	data, err := json.Marshal(commands)
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "chiplet_commands.json"), data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	platform := new(ChipletPlatform)
+	platform.Init(parser)
+	t.Cleanup(platform.Fini)
+	if tune != nil {
+		tune(platform.config)
+	}
+	for i := 0; i < 100000 && !platform.IsFinished(); i++ {
+		platform.Cycle()
+	}
+	if !platform.IsFinished() {
+		t.Fatalf("command graph did not drain after %d cycles", platform.currentCycle)
+	}
+	return platform
+}
+
+func TestPipelinedTransferShortensRun(t *testing.T) {
+	t.Parallel()
+
+	// 数字 GEMM 产出激活 → 搬往 RRAM → 依赖它的回传需等待链路空闲。
+	commands := []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindPeGemm, Target: chiplet.TaskTargetDigital, ChipletID: 0, Aux0: 64, Aux1: 64, Aux2: 64},
+		{ID: 1, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagDigitalToRram,
+			Queue: 0, ChipletID: 0, PayloadBytes: 16 * 1024, Dependencies: []int32{0}},
+		{ID: 2, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagRramToDigital,
+			Queue: 0, ChipletID: 0, PayloadBytes: 64, Dependencies: []int32{1}},
+	}
+	run := func(chunks int) *ChipletPlatform {
+		return runCommandGraph(t, commands, func(config *chiplet.Config) {
+			config.TransferBandwidthDr = 64
+			config.TransferPipelineChunks = chunks
+		})
+	}
+
+	serial := run(0)
+	pipelined := run(4)
+	if serial.pipelinedTransfers != 0 || pipelined.pipelinedTransfers != 1 || pipelined.pipelinedHiddenCycles <= 0 {
+		t.Fatalf("expected one pipelined transfer: serial=%d pipelined=%d hidden=%d",
+			serial.pipelinedTransfers, pipelined.pipelinedTransfers, pipelined.pipelinedHiddenCycles)
+	}
+	// 链路占用按互连时钟计，换算回平台周期后节省量不小于隐藏的互连周期。
+	if saved := int64(serial.currentCycle - pipelined.currentCycle); saved < pipelined.pipelinedHiddenCycles {
+		t.Fatalf("hidden cycles should shorten the run: serial=%d pipelined=%d hidden=%d",
+			serial.currentCycle, pipelined.currentCycle, pipelined.pipelinedHiddenCycles)
+	}
+}
+
+func TestAnalyticalTransferBudgetCountsTowardTotalCycles(t *testing.T) {
+	t.Parallel()
+
+	// 两次串行搬运：cycle_accurate 下后者需等待链路，analytical 下链路立即空闲，
+	// 但延迟预算仍需计入总周期。
+	commands := []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagDigitalToRram,
+			Queue: 0, ChipletID: 0, PayloadBytes: 16 * 1024},
+		{ID: 1, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagRramToDigital,
+			Queue: 0, ChipletID: 0, PayloadBytes: 64, Dependencies: []int32{0}},
+	}
+	run := func(mode string) *ChipletPlatform {
+		return runCommandGraph(t, commands, func(config *chiplet.Config) {
+			config.TransferBandwidthDr = 64
+			config.TransferMode = mode
+		})
+	}
+
+	accurate := run("cycle_accurate")
+	analytical := run("analytical")
+	if analytical.analyticalTransferCycles <= 0 || analytical.currentCycle >= accurate.currentCycle {
+		t.Fatalf("analytical mode should skip link occupancy: budget=%d analytical=%d accurate=%d",
+			analytical.analyticalTransferCycles, analytical.currentCycle, accurate.currentCycle)
+	}
+	if got := analytical.totalCycles(); got <= int64(analytical.currentCycle) || got < int64(accurate.currentCycle)/2 {
+		t.Fatalf("analytical budget should count toward total cycles: total=%d simulated=%d accurate=%d",
+			got, analytical.currentCycle, accurate.currentCycle)
+	}
+	if accurate.totalCycles() != int64(accurate.currentCycle) {
+		t.Fatalf("cycle_accurate total should equal simulated cycles: total=%d simulated=%d",
+			accurate.totalCycles(), accurate.currentCycle)
+	}
+}
+
+func TestTransferScheduleOverheadDominatesTinyTransfers(t *testing.T) {
+	t.Parallel()
+
+	// 8 个串行的 64 B 搬运：数据只占 1 个链路周期，描述符编程开销才是主导。
+	commands := make([]chiplet.CommandDescriptor, 0, 8)
+	for id := int32(0); id < 8; id++ {
+		cmd := chiplet.CommandDescriptor{ID: id, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer,
+			Flags: chiplet.TransferFlagDigitalToRram, Queue: 0, ChipletID: 0, PayloadBytes: 64}
+		if id > 0 {
+			cmd.Dependencies = []int32{id - 1}
+		}
+		commands = append(commands, cmd)
+	}
+	run := func(overhead int) *ChipletPlatform {
+		return runCommandGraph(t, commands, func(config *chiplet.Config) {
+			config.TransferBandwidthDr = 64
+			config.TransferScheduleCycles = overhead
+		})
+	}
+
+	free := run(0)
+	costly := run(32)
+	if free.transferScheduleCycles != 0 || costly.transferScheduleCycles != 8*32 {
+		t.Fatalf("expected 0 and %d overhead cycles, got %d and %d", 8*32, free.transferScheduleCycles, costly.transferScheduleCycles)
+	}
+	if added := costly.currentCycle - free.currentCycle; added <= free.currentCycle {
+		t.Fatalf("descriptor programming should dominate tiny transfers: free=%d costly=%d", free.currentCycle, costly.currentCycle)
+	}
+	if free.totalTransferBytes != costly.totalTransferBytes {
+		t.Fatalf("the overhead must not change the bytes moved: %d vs %d", free.totalTransferBytes, costly.totalTransferBytes)
+	}
+}
+
+func TestTransferSizeHistogramBucketsByStage(t *testing.T) {
+	t.Parallel()
+
+	commands := []chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagDigitalToRram,
+			Queue: 0, ChipletID: 0, PayloadBytes: 64},
+		{ID: 1, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagDigitalToRram,
+			Queue: 0, ChipletID: 0, PayloadBytes: 100, Dependencies: []int32{0}},
+		{ID: 2, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagDigitalToRram,
+			Queue: 0, ChipletID: 0, PayloadBytes: 128, Dependencies: []int32{1}},
+		{ID: 3, Kind: chiplet.CommandKindTransferSchedule, Target: chiplet.TaskTargetTransfer, Flags: chiplet.TransferFlagRramToDigital,
+			Queue: 0, ChipletID: 0, PayloadBytes: 4096, Dependencies: []int32{2}},
+	}
+	platform := runCommandGraph(t, commands, nil)
+
+	// 100 B 向上取整到 128 B 桶，与 128 B 的搬运同桶。
+	want := []string{
+		"ChipletPlatform_transfer_size_hist_to_digital_4096: 1",
+		"ChipletPlatform_transfer_size_hist_to_rram_64: 1",
+		"ChipletPlatform_transfer_size_hist_to_rram_128: 2",
+	}
+	got := platform.transferSizeHistLines()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
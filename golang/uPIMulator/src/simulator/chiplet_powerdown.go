@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// powerdownRetentionFraction 为掉电（保持态）下仍需支付的静态功耗比例。
+const powerdownRetentionFraction = 0.1
+
+// powerdownState 记录单个 chiplet 的空闲计数与掉电/唤醒状态。
+type powerdownState struct {
+	idleCycles    int
+	asleep        bool
+	wakeRemaining int
+	asleepCycles  int64
+}
+
+// powerdownController 在 chiplet 连续空闲 idleThreshold 个平台周期后将其切入
+// 低漏电保持态：保持态下跳过域时钟 tick，静态能耗只按 powerdownRetentionFraction
+// 计入，其余累加到 energySavedPJ。下一个任务到达时先唤醒，唤醒期间任务被推迟
+// wakeLatency 个平台周期。
+type powerdownController struct {
+	idleThreshold     int
+	wakeLatency       int
+	digital           []*powerdownState
+	rram              []*powerdownState
+	powerdowns        int64
+	wakeups           int64
+	wakeLatencyCycles int64
+	energySavedPJ     float64
+}
+
+func newPowerdownController(config *chiplet.Config, numDigital, numRram int) *powerdownController {
+	if config == nil || config.IdlePowerdownCycles <= 0 {
+		return nil
+	}
+
+	controller := &powerdownController{
+		idleThreshold: config.IdlePowerdownCycles,
+		wakeLatency:   config.WakeupLatency,
+		digital:       make([]*powerdownState, numDigital),
+		rram:          make([]*powerdownState, numRram),
+	}
+	for i := range controller.digital {
+		controller.digital[i] = &powerdownState{}
+	}
+	for i := range controller.rram {
+		controller.rram[i] = &powerdownState{}
+	}
+	return controller
+}
+
+func (this *powerdownController) digitalState(id int) *powerdownState {
+	if this == nil || id < 0 || id >= len(this.digital) {
+		return nil
+	}
+	return this.digital[id]
+}
+
+func (this *powerdownController) rramState(id int) *powerdownState {
+	if this == nil || id < 0 || id >= len(this.rram) {
+		return nil
+	}
+	return this.rram[id]
+}
+
+// asleep 报告 state 是否处于保持态（不含唤醒中）。
+func (this *powerdownController) asleep(state *powerdownState) bool {
+	return this != nil && state != nil && state.asleep
+}
+
+// retain 为保持态下的 ticks 个域 tick 计入保持漏电，并返回应计入 chiplet 的能耗。
+func (this *powerdownController) retain(ticks int, perTickPJ float64) float64 {
+	if this == nil || ticks <= 0 {
+		return 0
+	}
+	full := float64(ticks) * perTickPJ
+	retained := full * powerdownRetentionFraction
+	this.energySavedPJ += full - retained
+	return retained
+}
+
+// blocks 在任务指向一个掉电或唤醒中的 chiplet 时返回 true。掉电的 chiplet
+// 由此开始唤醒，唤醒延迟计入 wakeLatencyCycles。
+func (this *powerdownController) blocks(state *powerdownState) bool {
+	if this == nil || state == nil {
+		return false
+	}
+	if state.asleep {
+		state.asleep = false
+		state.idleCycles = 0
+		state.wakeRemaining = this.wakeLatency
+		this.wakeups++
+		this.wakeLatencyCycles += int64(this.wakeLatency)
+	}
+	return state.wakeRemaining > 0
+}
+
+// observe 在每个平台周期末推进 state：忙碌清零空闲计数，连续空闲满阈值后掉电。
+func (this *powerdownController) observe(state *powerdownState, busy bool) {
+	if this == nil || state == nil {
+		return
+	}
+	if state.wakeRemaining > 0 {
+		state.wakeRemaining--
+		return
+	}
+	if state.asleep {
+		state.asleepCycles++
+		return
+	}
+	if busy {
+		state.idleCycles = 0
+		return
+	}
+	state.idleCycles++
+	if state.idleCycles >= this.idleThreshold {
+		state.asleep = true
+		this.powerdowns++
+	}
+}
@@ -0,0 +1,96 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// cycleProfiler 在 --chiplet_profile 下统计每个平台周期的墙钟时间，区分控制面
+// （orchestrator Advance、stager 排空、调度器 EnqueueTask/Tick）与器件模型
+// （ExecuteTask 派发及各 chiplet/互连 tick）。未开启时为 nil，所有方法均为空操作。
+type cycleProfiler struct {
+	totalNs     int64
+	schedulerNs int64
+	executeNs   int64
+	deviceNs    int64
+}
+
+func newCycleProfiler(config *chiplet.Config) *cycleProfiler {
+	if config == nil || config.Profile == 0 {
+		return nil
+	}
+	return &cycleProfiler{}
+}
+
+// start 返回计时起点；profiler 为 nil 时不读取时钟。
+func (this *cycleProfiler) start() time.Time {
+	if this == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (this *cycleProfiler) addTotal(start time.Time) {
+	if this != nil {
+		this.totalNs += int64(time.Since(start))
+	}
+}
+
+func (this *cycleProfiler) addScheduler(start time.Time) {
+	if this != nil {
+		this.schedulerNs += int64(time.Since(start))
+	}
+}
+
+// addExecute 记录嵌套在调度器 Tick 内的 ExecuteTask 时间，它属于器件模型。
+func (this *cycleProfiler) addExecute(start time.Time) {
+	if this != nil {
+		this.executeNs += int64(time.Since(start))
+	}
+}
+
+func (this *cycleProfiler) addDevice(start time.Time) {
+	if this != nil {
+		this.deviceNs += int64(time.Since(start))
+	}
+}
+
+// split 返回控制面、器件模型与其余（统计、日志等）各自的纳秒数。
+func (this *cycleProfiler) split() (int64, int64, int64) {
+	scheduler := this.schedulerNs - this.executeNs
+	if scheduler < 0 {
+		scheduler = 0
+	}
+	device := this.deviceNs + this.executeNs
+	other := this.totalNs - scheduler - device
+	if other < 0 {
+		other = 0
+	}
+	return scheduler, device, other
+}
+
+func (this *cycleProfiler) percent(ns int64) float64 {
+	if this.totalNs <= 0 {
+		return 0
+	}
+	return 100 * float64(ns) / float64(this.totalNs)
+}
+
+func (this *ChipletPlatform) profileLines() []string {
+	if this.profiler == nil {
+		return nil
+	}
+	scheduler, device, other := this.profiler.split()
+	return []string{
+		fmt.Sprintf("ChipletPlatform_profile_total_ns: %d", this.profiler.totalNs),
+		fmt.Sprintf("ChipletPlatform_profile_scheduler_ns: %d", scheduler),
+		fmt.Sprintf("ChipletPlatform_profile_device_ns: %d", device),
+		fmt.Sprintf("ChipletPlatform_profile_other_ns: %d", other),
+		fmt.Sprintf("ChipletPlatform_profile_scheduler_pct: %.2f", this.profiler.percent(scheduler)),
+		fmt.Sprintf("ChipletPlatform_profile_device_pct: %.2f", this.profiler.percent(device)),
+		fmt.Sprintf("ChipletPlatform_profile_other_pct: %.2f", this.profiler.percent(other)),
+		fmt.Sprintf("ChipletPlatform_profile_cycles_per_wall_sec: %.1f", this.cyclesPerWallSecond()),
+	}
+}
@@ -0,0 +1,207 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet"
+)
+
+// schedulerCompareResult 记录一次调度器运行结束时的关键指标。
+type schedulerCompareResult struct {
+	scheduler   string
+	cycles      int
+	digitalUtil float64
+	rramUtil    float64
+	deferrals   int
+	p50         int
+	p99         int
+	maxLatency  int
+	aborted     bool
+}
+
+// SchedulerComparePlatform 实现 --chiplet_scheduler_compare：在同一命令图上依次
+// 以两个调度器各跑一遍 ChipletPlatform。命令图只读取一次，每次运行前重新初始化
+// 平台并载入其副本，保证两次运行的输入与初始状态一致；各次运行的输出写入
+// <bin_dirpath>/scheduler_<name>，对比结果写入 chiplet_scheduler_compare.csv。
+type SchedulerComparePlatform struct {
+	command_line_parser *misc.CommandLineParser
+	binDirpath          string
+	schedulers          []string
+	commands            []chiplet.CommandDescriptor
+	index               int
+	current             *ChipletPlatform
+	results             []schedulerCompareResult
+}
+
+func (this *SchedulerComparePlatform) Init(command_line_parser *misc.CommandLineParser) {
+	config_loader := new(misc.ConfigLoader)
+	config_loader.Init()
+
+	this.command_line_parser = command_line_parser
+	this.binDirpath = command_line_parser.StringParameter("bin_dirpath")
+	this.schedulers = chiplet.ParseSchedulerCompare(config_loader.ChipletSchedulerCompare())
+	if err := validateSchedulerCompare(this.schedulers); err != nil {
+		panic(err)
+	}
+	this.commands = nil
+	if this.binDirpath != "" {
+		commands, err := chiplet.ReadCommandGraph(filepath.Join(this.binDirpath, "chiplet_commands.json"))
+		if err == nil {
+			this.commands = commands
+		}
+	}
+	this.index = 0
+	this.results = make([]schedulerCompareResult, 0, len(this.schedulers))
+	this.startRun()
+}
+
+// validateSchedulerCompare 检查对比的调度器均已注册且互不相同。
+func validateSchedulerCompare(names []string) error {
+	known := make(map[string]bool)
+	for _, name := range chiplet.SchedulerNames() {
+		known[name] = true
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("chiplet_scheduler_compare: unknown scheduler %q (available: %s)",
+				name, strings.Join(chiplet.SchedulerNames(), ", "))
+		}
+		if seen[name] {
+			return errors.New("chiplet_scheduler_compare must name two different schedulers")
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func (this *SchedulerComparePlatform) Fini() {
+	if this.current != nil {
+		this.current.Fini()
+		this.current = nil
+	}
+}
+
+func (this *SchedulerComparePlatform) IsFinished() bool {
+	return this.current == nil
+}
+
+func (this *SchedulerComparePlatform) Cycle() {
+	if this.current == nil {
+		return
+	}
+	if this.current.IsFinished() {
+		this.finishRun()
+		return
+	}
+	this.current.Cycle()
+}
+
+func (this *SchedulerComparePlatform) Dump() {
+	if this.binDirpath == "" {
+		return
+	}
+	file_dumper := new(misc.FileDumper)
+	file_dumper.Init(filepath.Join(this.binDirpath, "chiplet_scheduler_compare.csv"))
+	file_dumper.WriteLines(schedulerCompareLines(this.results))
+	fmt.Printf("[chiplet] 调度器对比已写入 %s\n", filepath.Join(this.binDirpath, "chiplet_scheduler_compare.csv"))
+}
+
+func (this *SchedulerComparePlatform) startRun() {
+	if this.index >= len(this.schedulers) {
+		this.current = nil
+		return
+	}
+	name := this.schedulers[this.index]
+	fmt.Printf("[chiplet] 调度器对比：第 %d/%d 次运行，scheduler=%s\n", this.index+1, len(this.schedulers), name)
+	this.current = new(ChipletPlatform)
+	this.current.schedulerOverride = name
+	this.current.commandGraph = this.commands
+	if this.binDirpath != "" {
+		dir := filepath.Join(this.binDirpath, "scheduler_"+name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			panic(err)
+		}
+		this.current.outputDirpath = dir
+	}
+	this.current.Init(this.command_line_parser)
+}
+
+func (this *SchedulerComparePlatform) finishRun() {
+	this.results = append(this.results, collectSchedulerCompareResult(this.schedulers[this.index], this.current))
+	this.current.Dump()
+	this.current.Fini()
+	this.index++
+	this.startRun()
+}
+
+func collectSchedulerCompareResult(name string, platform *ChipletPlatform) schedulerCompareResult {
+	result := schedulerCompareResult{
+		scheduler: name,
+		cycles:    platform.currentCycle,
+		aborted:   platform.aborted,
+	}
+	result.digitalUtil, result.rramUtil = platform.utilization()
+	for _, count := range platform.digitalDeferrals {
+		result.deferrals += count
+	}
+	for _, count := range platform.rramDeferrals {
+		result.deferrals += count
+	}
+	_, result.p50, result.p99, result.maxLatency = platform.batchLatencyPercentiles()
+	return result
+}
+
+// schedulerCompareLines 以 metric 为行、调度器为列输出对比表；两列时追加 delta（后者减前者）。
+func schedulerCompareLines(results []schedulerCompareResult) []string {
+	header := "metric"
+	for _, result := range results {
+		header += "," + result.scheduler
+	}
+	if len(results) == 2 {
+		header += ",delta"
+	}
+	lines := []string{header}
+
+	metrics := []struct {
+		name  string
+		value func(schedulerCompareResult) float64
+		float bool
+	}{
+		{"total_cycles", func(r schedulerCompareResult) float64 { return float64(r.cycles) }, false},
+		{"digital_util", func(r schedulerCompareResult) float64 { return r.digitalUtil }, true},
+		{"rram_util", func(r schedulerCompareResult) float64 { return r.rramUtil }, true},
+		{"deferrals", func(r schedulerCompareResult) float64 { return float64(r.deferrals) }, false},
+		{"batch_latency_p50", func(r schedulerCompareResult) float64 { return float64(r.p50) }, false},
+		{"batch_latency_p99", func(r schedulerCompareResult) float64 { return float64(r.p99) }, false},
+		{"batch_latency_max", func(r schedulerCompareResult) float64 { return float64(r.maxLatency) }, false},
+		{"aborted", func(r schedulerCompareResult) float64 {
+			if r.aborted {
+				return 1
+			}
+			return 0
+		}, false},
+	}
+	format := func(value float64, float bool) string {
+		if float {
+			return fmt.Sprintf("%.4f", value)
+		}
+		return fmt.Sprintf("%d", int64(value))
+	}
+	for _, metric := range metrics {
+		line := metric.name
+		for _, result := range results {
+			line += "," + format(metric.value(result), metric.float)
+		}
+		if len(results) == 2 {
+			line += "," + format(metric.value(results[1])-metric.value(results[0]), metric.float)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
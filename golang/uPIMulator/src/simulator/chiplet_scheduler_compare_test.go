@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet"
+)
+
+func TestSchedulerCompareRunsBothSchedulersOnSameGraph(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	commandPath := filepath.Join(tempDir, "chiplet_commands.json")
+	data, err := json.Marshal([]chiplet.CommandDescriptor{
+		{ID: 0, Kind: chiplet.CommandKindPeGemm, Target: chiplet.TaskTargetDigital, ChipletID: 0, Aux0: 64, Aux1: 64, Aux2: 64},
+		{ID: 1, Kind: chiplet.CommandKindPeGemm, Target: chiplet.TaskTargetDigital, ChipletID: 0, Aux0: 64, Aux1: 64, Aux2: 64},
+	})
+	if err != nil {
+		t.Fatalf("marshal commands: %v", err)
+	}
+	if err := os.WriteFile(commandPath, data, 0o644); err != nil {
+		t.Fatalf("write commands: %v", err)
+	}
+	commands, err := chiplet.ReadCommandGraph(commandPath)
+	if err != nil {
+		t.Fatalf("read commands: %v", err)
+	}
+	// 图只读一次：之后删除文件，两次运行都必须使用已载入的副本。
+	if err := os.Remove(commandPath); err != nil {
+		t.Fatalf("remove commands: %v", err)
+	}
+
+	parser := new(misc.CommandLineParser)
+	parser.Init()
+	parser.AddOption(misc.STRING, "bin_dirpath", tempDir, tempDir)
+	parser.AddOption(misc.INT, "chiplet_progress_interval", "0", "disable progress logging for tests")
+	parser.AddOption(misc.INT, "chiplet_stats_flush_interval", "0", "disable periodic stats flush for tests")
+
+	compare := &SchedulerComparePlatform{
+		command_line_parser: parser,
+		binDirpath:          tempDir,
+		schedulers:          []string{"fifo", "oldest_batch"},
+		commands:            commands,
+	}
+	compare.startRun()
+	defer compare.Fini()
+
+	for i := 0; i < 200000 && !compare.IsFinished(); i++ {
+		compare.Cycle()
+	}
+	if !compare.IsFinished() {
+		t.Fatalf("comparison did not finish")
+	}
+	compare.Dump()
+
+	if len(compare.results) != 2 || compare.results[0].scheduler != "fifo" || compare.results[1].scheduler != "oldest_batch" {
+		t.Fatalf("expected fifo and oldest_batch results, got %+v", compare.results)
+	}
+	for _, result := range compare.results {
+		if result.cycles <= 0 {
+			t.Fatalf("expected %s to run for some cycles, got %d", result.scheduler, result.cycles)
+		}
+	}
+
+	for _, name := range compare.schedulers {
+		log, err := os.ReadFile(filepath.Join(tempDir, "scheduler_"+name, "chiplet_log.txt"))
+		if err != nil {
+			t.Fatalf("read %s run log: %v", name, err)
+		}
+		if !strings.Contains(string(log), "ChipletPlatform_digital_tasks_total: 2\n") {
+			t.Fatalf("%s run should execute the two loaded commands", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "chiplet_log.txt")); !os.IsNotExist(err) {
+		t.Fatalf("runs must not write into the shared bin directory")
+	}
+
+	data, err = os.ReadFile(filepath.Join(tempDir, "chiplet_scheduler_compare.csv"))
+	if err != nil {
+		t.Fatalf("read comparison: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if lines[0] != "metric,fifo,oldest_batch,delta" || !strings.HasPrefix(lines[1], "total_cycles,") {
+		t.Fatalf("unexpected comparison layout:\n%s", data)
+	}
+}
+
+func TestSchedulerCompareRejectsUnknownSchedulers(t *testing.T) {
+	t.Parallel()
+
+	if err := validateSchedulerCompare([]string{"fifo", "oldest_batch"}); err != nil {
+		t.Fatalf("registered schedulers should validate: %v", err)
+	}
+	if err := validateSchedulerCompare([]string{"fifo", "no_such_scheduler"}); err == nil {
+		t.Fatalf("expected an unknown scheduler to be rejected")
+	}
+	if err := validateSchedulerCompare([]string{"fifo", "fifo"}); err == nil {
+		t.Fatalf("expected a duplicate scheduler to be rejected")
+	}
+}
@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"uPIMulator/src/misc"
+	"uPIMulator/src/simulator/chiplet/rram"
+)
+
+const (
+	warningSeverityInfo    = "info"
+	warningSeverityWarning = "warning"
+	warningSeverityError   = "error"
+)
+
+// platformWarning 是 chiplet_warnings.json 中的一项：category 固定、可供 CI 匹配，
+// message 与运行摘要中打印的文字一致。
+type platformWarning struct {
+	Category string `json:"category"`
+	Severity string `json:"severity"`
+	Count    int64  `json:"count"`
+	Message  string `json:"message"`
+}
+
+// collectWarnings 汇总各项诊断计数器，计数为 0 的类别不列出。
+func (this *ChipletPlatform) collectWarnings() []platformWarning {
+	warnings := make([]platformWarning, 0)
+	add := func(category, severity string, count int64, message string) {
+		if count > 0 {
+			warnings = append(warnings, platformWarning{Category: category, Severity: severity, Count: count, Message: message})
+		}
+	}
+
+	if this.aborted {
+		add("abort", warningSeverityError, 1, fmt.Sprintf("aborted at cycle %d: %s", this.currentCycle, this.abortReason))
+	}
+	if this.cycleCapReached {
+		add("cycle_cap", warningSeverityWarning, 1, fmt.Sprintf("stopped at --chiplet_max_cycles=%d with work pending", this.config.MaxCycles))
+	}
+	n := int64(len(this.topologyAnomalies))
+	add("topology_anomaly", warningSeverityWarning, n, fmt.Sprintf("%d topology hop-distance anomalies", n))
+	n = int64(len(this.undersizedBufferWarnings))
+	add("undersized_buffer", warningSeverityWarning, n, fmt.Sprintf("%d undersized buffer warnings", n))
+	n = int64(this.unrecognizedCommands)
+	add("unrecognized_command", warningSeverityWarning, n, fmt.Sprintf("%d unrecognized commands modelled with defaults", n))
+	if this.orchestrator != nil {
+		n = int64(this.orchestrator.PipelineChecksumMismatches())
+		add("checksum_mismatch", warningSeverityError, n, fmt.Sprintf("%d pipeline checksum mismatches", n))
+		n = int64(this.orchestrator.CrossTargetMissingTransfers())
+		add("missing_transfer", warningSeverityError, n, fmt.Sprintf("%d cross-target edges without a transfer", n))
+	}
+	crossCheck := rram.Stats{}
+	for _, chip := range this.rramChiplets {
+		crossCheck.Accumulate(chip.Stats())
+	}
+	n = crossCheck.DigitalCheckViolations
+	add("rram_digital_discrepancy", warningSeverityError, n,
+		fmt.Sprintf("%d RRAM results differ from the digital recomputation beyond error_abs", n))
+	n = int64(this.idleChipletCount())
+	add("idle_chiplet", warningSeverityInfo, n, fmt.Sprintf("%d chiplets never busy", n))
+	return warnings
+}
+
+// idleChipletCount 返回整个运行中从未忙碌过的 chiplet 数；尚未推进周期时为 0。
+func (this *ChipletPlatform) idleChipletCount() int {
+	if this.currentCycle == 0 {
+		return 0
+	}
+	idle := 0
+	for _, chip := range this.digitalChiplets {
+		if chip != nil && chip.BusyCycles == 0 {
+			idle++
+		}
+	}
+	for _, chip := range this.rramChiplets {
+		if chip != nil && chip.BusyCycles == 0 {
+			idle++
+		}
+	}
+	return idle
+}
+
+// writeWarningsFile 在最终 Dump 时写出 chiplet_warnings.json；没有告警时写出空数组，
+// 便于 CI 直接按 severity 判定运行是否失败。
+func (this *ChipletPlatform) writeWarningsFile() {
+	if this.binDirpath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(this.collectWarnings(), "", "  ")
+	if err != nil {
+		fmt.Printf("[chiplet] warning: failed to encode warnings: %v\n", err)
+		return
+	}
+	warningsDumper := new(misc.FileDumper)
+	warningsDumper.Init(filepath.Join(this.binDirpath, "chiplet_warnings.json"))
+	warningsDumper.WriteLines([]string{string(data)})
+}
@@ -0,0 +1,135 @@
+package booksim
+
+import "container/list"
+
+// CacheKey 标识一次可复用的延迟查询：源/目的节点与按粒度取整后的字节数。
+type CacheKey struct {
+	Src    int
+	Dst    int
+	Bucket int64
+}
+
+type cacheEntry struct {
+	key     CacheKey
+	cycles  int
+	expires int
+}
+
+// EstimateCache 缓存 BookSim 的延迟估算结果，避免对相同查询反复往返外部服务。
+// 容量满时淘汰最久未使用的条目；ttl>0 时条目在写入 ttl 个周期后失效。
+type EstimateCache struct {
+	capacity int
+	bucket   int64
+	ttl      int
+	entries  map[CacheKey]*list.Element
+	order    *list.List
+	hits     int64
+	misses   int64
+	expired  int64
+}
+
+// NewEstimateCache 创建缓存；capacity<=0 时返回 nil（即不缓存）。
+func NewEstimateCache(capacity int, bucketBytes int64, ttl int) *EstimateCache {
+	if capacity <= 0 {
+		return nil
+	}
+	if bucketBytes <= 0 {
+		bucketBytes = 1
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return &EstimateCache{
+		capacity: capacity,
+		bucket:   bucketBytes,
+		ttl:      ttl,
+		entries:  make(map[CacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Key 把 bytes 向上取整到 bucket 粒度，返回查询键及应发送给服务的字节数。
+func (c *EstimateCache) Key(src, dst int, bytes int64) (CacheKey, int64) {
+	bucket := int64(1)
+	if c != nil {
+		bucket = c.bucket
+	}
+	rounded := (bytes + bucket - 1) / bucket * bucket
+	return CacheKey{Src: src, Dst: dst, Bucket: rounded}, rounded
+}
+
+// Get 在 now 周期查找缓存；过期条目会被移除并记为未命中。
+func (c *EstimateCache) Get(key CacheKey, now int) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return 0, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && now >= entry.expires {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		c.expired++
+		c.misses++
+		return 0, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits++
+	return entry.cycles, true
+}
+
+// Put 在 now 周期写入估算结果。
+func (c *EstimateCache) Put(key CacheKey, cycles int, now int) {
+	if c == nil {
+		return
+	}
+	expires := now + c.ttl
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.cycles = cycles
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, cycles: cycles, expires: expires})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Hits 返回命中次数，即节省的 BookSim 调用数。
+func (c *EstimateCache) Hits() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.hits
+}
+
+// Misses 返回未命中次数（含过期）。
+func (c *EstimateCache) Misses() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.misses
+}
+
+// Expired 返回因 TTL 失效的条目数。
+func (c *EstimateCache) Expired() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.expired
+}
+
+// Len 返回当前缓存条目数。
+func (c *EstimateCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.order.Len()
+}
@@ -0,0 +1,57 @@
+package booksim
+
+import "testing"
+
+func TestEstimateCacheBucketsAndExpires(t *testing.T) {
+	t.Parallel()
+
+	cache := NewEstimateCache(2, 64, 10)
+
+	key, rounded := cache.Key(0, 3, 100)
+	if rounded != 128 {
+		t.Fatalf("expected 100B to round up to 128, got %d", rounded)
+	}
+	if other, _ := cache.Key(0, 3, 70); other != key {
+		t.Fatalf("expected 70B and 100B to share a bucket")
+	}
+
+	if _, ok := cache.Get(key, 0); ok {
+		t.Fatalf("expected miss on empty cache")
+	}
+	cache.Put(key, 42, 0)
+	if cycles, ok := cache.Get(key, 5); !ok || cycles != 42 {
+		t.Fatalf("expected hit with 42 cycles, got %d ok=%v", cycles, ok)
+	}
+	if _, ok := cache.Get(key, 10); ok {
+		t.Fatalf("expected entry to expire after ttl")
+	}
+	if cache.Hits() != 1 || cache.Misses() != 2 || cache.Expired() != 1 {
+		t.Fatalf("unexpected counters hits=%d misses=%d expired=%d", cache.Hits(), cache.Misses(), cache.Expired())
+	}
+}
+
+func TestEstimateCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	cache := NewEstimateCache(2, 1, 0)
+	a, _ := cache.Key(0, 1, 8)
+	b, _ := cache.Key(0, 2, 8)
+	c, _ := cache.Key(0, 3, 8)
+	cache.Put(a, 1, 0)
+	cache.Put(b, 2, 0)
+	cache.Get(a, 1)
+	cache.Put(c, 3, 1)
+
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.Len())
+	}
+	if _, ok := cache.Get(b, 2); ok {
+		t.Fatalf("expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get(a, 2); !ok {
+		t.Fatalf("expected recently used entry to survive")
+	}
+	if NewEstimateCache(0, 64, 0) != nil {
+		t.Fatalf("expected zero capacity to disable the cache")
+	}
+}
@@ -37,6 +37,7 @@ func TestChipletPhase5Smoke(t *testing.T) {
 				filepath.Join(tempDir, "chiplet_log.txt"),
 				filepath.Join(tempDir, "chiplet_cycle_log.csv"),
 				filepath.Join(tempDir, "chiplet_results.csv"),
+				filepath.Join(tempDir, "chiplet_roofline.csv"),
 			}
 			for _, src := range toCopy {
 				data, err := os.ReadFile(src)
@@ -111,6 +112,10 @@ func TestChipletPhase5Smoke(t *testing.T) {
 		"transfer_host_store_bytes",
 		"transfer_throttle_events_total",
 		"transfer_throttle_cycles_total",
+		"stager_depth",
+		"transfer_issued_bytes_total",
+		"transfer_completed_bytes_total",
+		"transfer_inflight_bytes",
 	}
 	if header := cycleLines[0]; header != strings.Join(expectedHeader, ",") {
 		t.Fatalf("unexpected cycle log header: %s", header)
@@ -129,6 +134,15 @@ func TestChipletPhase5Smoke(t *testing.T) {
 		t.Fatalf("expected result log entries, got %d lines", len(lines))
 	}
 
+	rooflinePath := filepath.Join(tempDir, "chiplet_roofline.csv")
+	rooflineData, err := os.ReadFile(rooflinePath)
+	if err != nil {
+		t.Fatalf("reading roofline report: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(rooflineData)), "\n"); len(lines) <= 1 {
+		t.Fatalf("expected roofline entries, got %d lines", len(lines))
+	}
+
 	logPath := filepath.Join(tempDir, "chiplet_log.txt")
 	logData, err := os.ReadFile(logPath)
 	if err != nil {
@@ -147,6 +161,8 @@ func TestChipletPhase5Smoke(t *testing.T) {
 		"ChipletPlatform_transfer_throttle_cycles_total",
 		"ChipletPlatform_kv_cache_loads_total",
 		"ChipletPlatform_kv_cache_hits_total",
+		"ChipletPlatform_stager_peak_depth",
+		"ChipletPlatform_total_cycles",
 	}
 	for _, key := range requiredKeys {
 		if !strings.Contains(logText, key+":") {
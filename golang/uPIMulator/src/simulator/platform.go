@@ -18,6 +18,11 @@ func newPlatformForMode(mode misc.PlatformMode) Platform {
 	case misc.PlatformModeUpmem:
 		return new(UpmemPlatform)
 	case misc.PlatformModeChiplet:
+		config_loader := new(misc.ConfigLoader)
+		config_loader.Init()
+		if config_loader.ChipletSchedulerCompare() != "" {
+			return new(SchedulerComparePlatform)
+		}
 		return new(ChipletPlatform)
 	default:
 		panic(fmt.Sprintf("unsupported platform mode: %s", mode))